@@ -2,17 +2,23 @@ package main
 
 import (
 	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/api"
 	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/handler"
+	"Koukyo_discord_bot/internal/metrics"
 	"Koukyo_discord_bot/internal/models"
 	"Koukyo_discord_bot/internal/monitor"
 	"Koukyo_discord_bot/internal/notifications"
+	"Koukyo_discord_bot/internal/storage"
 	"Koukyo_discord_bot/internal/utils"
 	"Koukyo_discord_bot/internal/version"
+	"Koukyo_discord_bot/internal/webhooks"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 	_ "time/tzdata"
@@ -57,37 +63,102 @@ func main() {
 	activityLimiter := utils.NewRateLimiter(2)
 	defer activityLimiter.Close()
 
-	// ユーザー活動トラッカーの初期化
-	activityTracker := activity.NewTracker(activity.Config{
-		TopLeftTileX:  1818,
-		TopLeftTileY:  806,
-		TopLeftPixelX: 989,
-		TopLeftPixelY: 358,
-		Width:         107,
-		Height:        142,
-	}, activityLimiter, dataDir)
-	activityTracker.Start()
-	defer activityTracker.Stop()
-
-	// WebSocket監視の開始
+	// 監視アートワーク定義の読み込み（artworks.json が無ければ皇居のみ）
+	artworksPath := filepath.Join(dataDir, config.ArtworksFileName)
+	artworks, err := config.LoadArtworks(artworksPath)
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", artworksPath, err)
+	}
+	_, statErr := os.Stat(artworksPath)
+	artworksExplicit := statErr == nil
+	artworks[0].WebSocketURL = firstNonEmpty(artworks[0].WebSocketURL, cfg.WebSocketURL)
+	artworks[0].PollURL = firstNonEmpty(artworks[0].PollURL, os.Getenv("MONITOR_POLL_URL"))
+
+	// アートワークごとにユーザー活動トラッカーと監視を開始
 	powerSaveMode := os.Getenv("POWER_SAVE_MODE") == "1"
-	if cfg.WebSocketURL != "" {
-		globalMonitor = monitor.NewMonitor(cfg.WebSocketURL)
+	recordDir := strings.TrimSpace(os.Getenv("MONITOR_RECORD_DIR"))
+	replayFile := strings.TrimSpace(os.Getenv("MONITOR_REPLAY_FILE"))
+	replaySpeed := 1.0
+	if v := strings.TrimSpace(os.Getenv("MONITOR_REPLAY_SPEED")); v != "" {
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			replaySpeed = parsed
+		} else {
+			log.Printf("Invalid MONITOR_REPLAY_SPEED=%q; using 1.0", v)
+		}
+	}
+	// キャプチャ再生は本番のユーザー統計・通知状態を汚さないよう作業用ディレクトリで動かし、通知も送らない
+	replayDataDir := ""
+	if replayFile != "" {
+		replayDataDir = strings.TrimSpace(os.Getenv("MONITOR_REPLAY_DATA_DIR"))
+		if replayDataDir == "" {
+			dir, err := os.MkdirTemp("", "koukyo-replay-")
+			if err != nil {
+				log.Fatalf("Failed to create replay data dir: %v", err)
+			}
+			replayDataDir = dir
+		}
+		if sameDir(replayDataDir, dataDir) {
+			log.Fatalf("MONITOR_REPLAY_DATA_DIR must not be the real data dir (%s)", dataDir)
+		}
+		log.Printf("Replay mode: primary artwork uses scratch data dir %s and sends no notifications", replayDataDir)
+	}
+	var replayMonitor *monitor.Monitor
+	// 組み込み DB はトラッカーの停止（最後の保存）の後に閉じる
+	defer storage.CloseAll()
+	log.Printf("Activity storage backend: %s", storage.Backend())
+	monitors := monitor.NewSet()
+	trackers := make(map[string]*activity.Tracker, len(artworks))
+	for i, art := range artworks {
+		if art.WebSocketURL == "" && !artworksExplicit && replayFile == "" {
+			log.Println("WEBSOCKET_URL not set, skipping monitor")
+			continue
+		}
+		replaying := i == 0 && replayFile != ""
+		artDataDir := config.ArtworkDataDir(dataDir, art, i == 0)
+		if replaying {
+			artDataDir = replayDataDir
+		}
+		tracker := activity.NewTracker(activity.Config{
+			TopLeftTileX:  art.TileX,
+			TopLeftTileY:  art.TileY,
+			TopLeftPixelX: art.PixelX,
+			TopLeftPixelY: art.PixelY,
+			Width:         art.Width,
+			Height:        art.Height,
+		}, activityLimiter, artDataDir)
+		tracker.Start()
+		defer tracker.Stop()
+		trackers[art.ID] = tracker
+
+		mon := monitor.NewArtworkMonitor(art, artDataDir)
 		if powerSaveMode {
-			log.Println("Power-save mode enabled: setting PowerSaveMode on monitor state")
-			globalMonitor.State.SetPowerSaveMode(true)
+			log.Printf("Power-save mode enabled: setting PowerSaveMode on monitor state (artwork=%s)", art.ID)
+			mon.State.SetPowerSaveMode(true)
+		}
+		mon.SetActivityTracker(tracker)
+		if replaying {
+			// キャプチャ再生は primary アートワークのみ
+			mon.SetReplay(replayFile, replaySpeed)
+			replayMonitor = mon
+		} else if recordDir != "" {
+			rec, err := monitor.NewRecorder(recordDir, art.ID)
+			if err != nil {
+				log.Printf("Failed to start monitor recorder (artwork=%s): %v", art.ID, err)
+			} else {
+				log.Printf("Recording monitor stream: %s", rec.Path())
+				mon.SetRecorder(rec)
+			}
 		}
-		globalMonitor.SetActivityTracker(activityTracker)
 
-		if err := globalMonitor.Start(); err != nil {
-			log.Printf("Failed to start monitor: %v", err)
+		if err := mon.Start(); err != nil {
+			log.Printf("Failed to start monitor (artwork=%s): %v", art.ID, err)
 			log.Println("Continuing without monitor...")
-		} else {
-			log.Printf("Monitor started: %s", cfg.WebSocketURL)
+			continue
 		}
-	} else {
-		log.Println("WEBSOCKET_URL not set, skipping monitor")
+		log.Printf("Monitor started: artwork=%s url=%s", art.ID, art.WebSocketURL)
+		monitors.Add(mon)
 	}
+	globalMonitor = monitors.Primary()
 
 	dg, err := discordgo.New("Bot " + cfg.Token)
 	if err != nil {
@@ -103,18 +174,87 @@ func main() {
 	// Intentsを設定
 	dg.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsMessageContent | discordgo.IntentsGuilds
 
-	// 通知システムの初期化
+	// 外部 Webhook（webhooks.json がある場合のみ）
+	webhooksPath := filepath.Join(dataDir, webhooks.FileName)
+	endpoints, err := webhooks.LoadEndpoints(webhooksPath)
+	if err != nil {
+		log.Printf("Failed to load %s: %v; webhooks disabled", webhooksPath, err)
+	}
+	var webhookDispatcher *webhooks.Dispatcher
+	if len(endpoints) > 0 {
+		webhookDispatcher = webhooks.New(endpoints, webhooks.Options{})
+		log.Printf("Webhooks enabled: %d endpoint(s)", len(endpoints))
+	}
+
+	// 送信失敗した通知の保存先（全アートワーク共通）
+	outbox := notifications.NewOutbox(dg, dataDir)
+
+	// 通知システムの初期化（アートワークごとに通知ストリームを持つ）
 	var notifier *notifications.Notifier
-	if globalMonitor != nil {
-		notifier = notifications.NewNotifier(dg, globalMonitor, settingsManager, dataDir)
-		notifier.StartMonitoring()
-		log.Println("Notification system started")
+	allNotifiers := make([]*notifications.Notifier, 0, monitors.Len())
+	for _, mon := range monitors.All() {
+		art := mon.Artwork()
+		if mon == replayMonitor {
+			// コマンド用に作るだけで、監視ループ・outbox・Webhook・新規ユーザー通知はつながない
+			replayNotifier := notifications.NewNotifier(dg, mon, settingsManager, replayDataDir)
+			if mon == globalMonitor {
+				notifier = replayNotifier
+			}
+			allNotifiers = append(allNotifiers, replayNotifier)
+			log.Printf("Notifications disabled for replayed artwork=%s", art.ID)
+			continue
+		}
+		if mon == globalMonitor {
+			notifier = notifications.NewNotifier(dg, mon, settingsManager, dataDir)
+			notifier.SetWebhooks(webhookDispatcher)
+			notifier.SetOutbox(outbox)
+			notifier.StartMonitoring()
+			trackers[art.ID].SetNewUserCallback(notifier.NotifyNewUser)
+			allNotifiers = append(allNotifiers, notifier)
+			log.Println("Notification system started")
+			continue
+		}
+		artNotifier := notifications.NewNotifier(dg, mon, settingsManager, config.ArtworkDataDir(dataDir, art, false))
+		artNotifier.SetWebhooks(webhookDispatcher)
+		artNotifier.SetOutbox(outbox)
+		artNotifier.StartArtworkMonitoring()
+		trackers[art.ID].SetNewUserCallback(artNotifier.NotifyNewUser)
+		allNotifiers = append(allNotifiers, artNotifier)
 	}
-	if notifier != nil {
-		activityTracker.SetNewUserCallback(notifier.NotifyNewUser)
+
+	// メトリクスエンドポイント（METRICS_ADDR 指定時のみ）
+	if addr := strings.TrimSpace(os.Getenv("METRICS_ADDR")); addr != "" {
+		registry := metrics.NewRegistry()
+		registry.Register(metrics.BuildInfoCollector(version.Version))
+		registry.Register(metrics.MonitorCollector(monitors))
+		registry.Register(metrics.NotifierCollector(allNotifiers))
+		registry.Register(metrics.RateLimiterCollector(map[string]*utils.RateLimiter{
+			"default":  limiter,
+			"activity": activityLimiter,
+		}))
+		registry.Register(metrics.TrackerCollector(trackers))
+		registry.Register(metrics.TileCacheCollector())
+		metricsServer := metrics.Serve(addr, registry)
+		defer metricsServer.Close()
+	}
+
+	// 読み取り専用 HTTP API（API_ADDR 指定時のみ。API_TOKEN 必須）
+	if addr := strings.TrimSpace(os.Getenv("API_ADDR")); addr != "" {
+		token := strings.TrimSpace(os.Getenv("API_TOKEN"))
+		if token == "" {
+			log.Println("API_ADDR is set but API_TOKEN is empty; HTTP API disabled")
+		} else {
+			apiServer := api.Serve(addr, api.New(api.Options{
+				Token:     token,
+				Monitors:  monitors,
+				Notifiers: allNotifiers,
+				DataDir:   dataDir,
+			}))
+			defer apiServer.Close()
+		}
 	}
 
-	h := handler.NewHandler("!", botInfo, globalMonitor, settingsManager, notifier, limiter, activityLimiter, dataDir) // settingsManager を渡す
+	h := handler.NewHandler("!", botInfo, monitors, settingsManager, notifier, limiter, activityLimiter, dataDir) // settingsManager を渡す
 	dg.AddHandler(h.OnReady)
 	dg.AddHandler(h.OnResumed)
 	dg.AddHandler(h.OnMessage)
@@ -125,6 +265,7 @@ func main() {
 		log.Printf("Failed to open Discord session: %v", err)
 		return
 	}
+	outbox.Start()
 
 	log.Printf("Bot started - Version: %s, Date: %s\n", version.Version, time.Now().Format("2006-01-02"))
 	sigCh := make(chan os.Signal, 1)
@@ -135,11 +276,17 @@ func main() {
 
 	shutdownDone := make(chan struct{})
 	go func() {
+		// 再起動後に通知状態を引き継げるよう先に保存する
+		for _, n := range allNotifiers {
+			n.SaveState()
+		}
 		h.Cleanup(dg)
-		if globalMonitor != nil {
-			globalMonitor.Stop()
+		for _, mon := range monitors.All() {
+			mon.Stop()
 		}
 		dg.Close()
+		// イベントの発生元（監視・Discord）を止めてから未送信の Webhook を流す
+		webhookDispatcher.Close(5 * time.Second)
 		close(shutdownDone)
 	}()
 	select {
@@ -149,3 +296,22 @@ func main() {
 		log.Println("Shutdown timed out after 10s, forcing exit")
 	}
 }
+
+// sameDir a と b が同じディレクトリを指すか
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"Koukyo_discord_bot/internal/emulator"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ローカル wplace バックエンド / 監視 WebSocket エミュレーター。
+// 表示される WPLACE_BACKEND_URL と WEBSOCKET_URL を Bot の環境変数に設定して使う。
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8787", "listen address")
+	tilesDir := flag.String("tiles", "", "tile directory ({x}/{y}.png); empty serves transparent tiles")
+	scenarioPath := flag.String("scenario", "", "scenario JSON for the monitor WebSocket and pixel API")
+	flag.Parse()
+
+	var scenario *emulator.Scenario
+	if *scenarioPath != "" {
+		sc, err := emulator.LoadScenario(*scenarioPath)
+		if err != nil {
+			log.Fatalf("Failed to load scenario: %v", err)
+		}
+		scenario = sc
+	}
+
+	srv := emulator.NewServer(*tilesDir, scenario)
+	if err := srv.Start(*addr); err != nil {
+		log.Fatalf("Failed to start emulator: %v", err)
+	}
+	defer srv.Close()
+
+	log.Printf("WPLACE_BACKEND_URL=%s", srv.URL())
+	if scenario != nil {
+		log.Printf("WEBSOCKET_URL=%s", srv.WebSocketURL())
+	}
+
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM)
+	<-sc
+	log.Println("Shutting down emulator...")
+}
@@ -1,11 +1,13 @@
 package achievements
 
 import (
+	"Koukyo_discord_bot/internal/storage"
 	"Koukyo_discord_bot/internal/utils"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -29,7 +31,11 @@ type Store struct {
 	Users map[string]*UserAchievements `json:"users"`
 }
 
+// Load path の実績を読む。STORAGE_BACKEND=bolt なら path と同じディレクトリの DB から読む
 func Load(path string) (*Store, error) {
+	if storage.Backend() == storage.BackendBolt {
+		return loadFromDB(filepath.Dir(path))
+	}
 	var store Store
 	source, err := utils.ReadJSONFileWithBackup(path, &store)
 	switch {
@@ -50,10 +56,14 @@ func Load(path string) (*Store, error) {
 	}
 }
 
+// Save path に実績を書く。STORAGE_BACKEND=bolt なら path と同じディレクトリの DB に書く
 func Save(path string, store *Store) error {
 	if store == nil {
 		return nil
 	}
+	if storage.Backend() == storage.BackendBolt {
+		return saveToDB(filepath.Dir(path), store)
+	}
 	data, err := json.MarshalIndent(store, "", "  ")
 	if err != nil {
 		return err
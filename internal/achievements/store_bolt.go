@@ -0,0 +1,115 @@
+package achievements
+
+import (
+	"Koukyo_discord_bot/internal/storage"
+	"Koukyo_discord_bot/internal/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// FileName 実績の保存ファイル名（JSON バックエンド）
+const FileName = "achievements.json"
+
+// achievementsBucket ユーザーのキー（Store.Users と同じ）→ UserAchievements の JSON
+var achievementsBucket = []byte("achievements")
+
+func init() {
+	storage.RegisterMigration(storage.Migration{Name: "achievements-json", Run: migrateAchievementsJSON})
+}
+
+func loadFromDB(dataDir string) (*Store, error) {
+	db, err := storage.Open(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	store := &Store{Users: map[string]*UserAchievements{}}
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(achievementsBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var user UserAchievements
+			if err := json.Unmarshal(v, &user); err != nil {
+				return fmt.Errorf("decode achievements %s: %w", k, err)
+			}
+			store.Users[string(k)] = &user
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// saveToDB 変わったユーザーだけ書き、Store から消えたユーザーを削除する
+func saveToDB(dataDir string, store *Store) error {
+	db, err := storage.Open(dataDir)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(achievementsBucket)
+		if err != nil {
+			return err
+		}
+		return putUsers(bucket, store.Users)
+	})
+}
+
+func putUsers(bucket *bolt.Bucket, users map[string]*UserAchievements) error {
+	var stale [][]byte
+	if err := bucket.ForEach(func(k, _ []byte) error {
+		if users[string(k)] == nil {
+			stale = append(stale, bytes.Clone(k))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range stale {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	for key, user := range users {
+		if user == nil {
+			continue
+		}
+		data, err := json.Marshal(user)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(bucket.Get([]byte(key)), data) {
+			continue
+		}
+		if err := bucket.Put([]byte(key), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateAchievementsJSON achievements.json を DB へ取り込む（ファイルはそのまま残す）
+func migrateAchievementsJSON(dataDir string, tx *bolt.Tx) error {
+	bucket, err := tx.CreateBucketIfNotExists(achievementsBucket)
+	if err != nil {
+		return err
+	}
+	var store Store
+	_, err = utils.ReadJSONFileWithBackup(filepath.Join(dataDir, FileName), &store)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return putUsers(bucket, store.Users)
+}
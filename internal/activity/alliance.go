@@ -0,0 +1,240 @@
+package activity
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AllianceActiveDays アクティブメンバーとみなす日数（今日を含む、JST）
+const AllianceActiveDays = 7
+
+// AllianceMember 同盟に所属するユーザー
+type AllianceMember struct {
+	ID            string
+	Name          string
+	VandalCount   int
+	RestoredCount int
+	LastSeen      time.Time
+	Active        bool // 直近 AllianceActiveDays 日に荒らし/修復がある
+}
+
+// AllianceStats 同盟ごとの集計（メンバーの user_activity を合算）
+type AllianceStats struct {
+	ID            int
+	Name          string
+	VandalCount   int
+	RestoredCount int
+	Members       []AllianceMember // 荒らし数の多い順
+	ActiveMembers int
+	FirstSeen     string    // 最初に活動した日（JST の日付キー。不明なら空）
+	LastSeen      time.Time // メンバーの最終観測
+	DailyVandal   map[string]int
+	DailyRestored map[string]int
+	DailyMembers  map[string]int // 日ごとに活動したメンバー数
+}
+
+// AlliancePeriodEntry 期間内の同盟ランキングの1行
+type AlliancePeriodEntry struct {
+	ID            int
+	Name          string
+	VandalCount   int
+	RestoredCount int
+	Members       int // 期間内に活動したメンバー数
+}
+
+// Score 修復 - 荒らし
+func (a AllianceStats) Score() int {
+	return a.RestoredCount - a.VandalCount
+}
+
+// DisplayName 「名前#ID」形式（名前が無ければ ID のみ）
+func (a AllianceStats) DisplayName() string {
+	return allianceDisplayName(a.Name, a.ID)
+}
+
+// DisplayName 「名前#ID」形式（名前が無ければ ID のみ）
+func (e AlliancePeriodEntry) DisplayName() string {
+	return allianceDisplayName(e.Name, e.ID)
+}
+
+func allianceDisplayName(name string, id int) string {
+	if name == "" {
+		return "ID:" + strconv.Itoa(id)
+	}
+	return name + "#" + strconv.Itoa(id)
+}
+
+// BuildAllianceStats 所属同盟のあるユーザーを同盟ごとに合算する（荒らし数の多い順、同数は ID 順）。
+// now（JST）から AllianceActiveDays 日以内に活動したメンバーを active とする
+func BuildAllianceStats(entries map[string]*UserActivity, now time.Time) []AllianceStats {
+	local := now.In(leaderboardJST)
+	activeFrom := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, leaderboardJST).
+		AddDate(0, 0, 1-AllianceActiveDays).Format("2006-01-02")
+
+	byID := make(map[int]*AllianceStats)
+	nameSeen := make(map[int]time.Time)
+	for id, entry := range entries {
+		if entry == nil || entry.AllianceID == 0 {
+			continue
+		}
+		stats := byID[entry.AllianceID]
+		if stats == nil {
+			stats = &AllianceStats{
+				ID:            entry.AllianceID,
+				DailyVandal:   make(map[string]int),
+				DailyRestored: make(map[string]int),
+				DailyMembers:  make(map[string]int),
+			}
+			byID[entry.AllianceID] = stats
+		}
+		lastSeen := parseActivityTime(entry.LastSeen)
+		// 同盟名は最後に観測したメンバーのものを使う
+		if entry.AllianceName != "" && (stats.Name == "" || lastSeen.After(nameSeen[stats.ID])) {
+			stats.Name = entry.AllianceName
+			nameSeen[stats.ID] = lastSeen
+		}
+		if lastSeen.After(stats.LastSeen) {
+			stats.LastSeen = lastSeen
+		}
+		stats.VandalCount += entry.VandalCount
+		stats.RestoredCount += entry.RestoredCount
+
+		member := AllianceMember{
+			ID:            id,
+			Name:          entry.Name,
+			VandalCount:   entry.VandalCount,
+			RestoredCount: entry.RestoredCount,
+			LastSeen:      lastSeen,
+		}
+		days := make(map[string]bool)
+		for dateKey, count := range entry.DailyVandalCounts {
+			if count > 0 {
+				stats.DailyVandal[dateKey] += count
+				days[dateKey] = true
+			}
+		}
+		for dateKey, count := range entry.DailyRestoredCounts {
+			if count > 0 {
+				stats.DailyRestored[dateKey] += count
+				days[dateKey] = true
+			}
+		}
+		for dateKey := range days {
+			stats.DailyMembers[dateKey]++
+			if stats.FirstSeen == "" || dateKey < stats.FirstSeen {
+				stats.FirstSeen = dateKey
+			}
+			if dateKey >= activeFrom {
+				member.Active = true
+			}
+		}
+		if member.Active {
+			stats.ActiveMembers++
+		}
+		stats.Members = append(stats.Members, member)
+	}
+
+	out := make([]AllianceStats, 0, len(byID))
+	for _, stats := range byID {
+		sort.Slice(stats.Members, func(i, j int) bool {
+			a, b := stats.Members[i], stats.Members[j]
+			if a.VandalCount != b.VandalCount {
+				return a.VandalCount > b.VandalCount
+			}
+			if a.RestoredCount != b.RestoredCount {
+				return a.RestoredCount > b.RestoredCount
+			}
+			return a.ID < b.ID
+		})
+		out = append(out, *stats)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].VandalCount != out[j].VandalCount {
+			return out[i].VandalCount > out[j].VandalCount
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// FindAlliance ID で同盟を探す
+func FindAlliance(stats []AllianceStats, id int) (AllianceStats, bool) {
+	for _, a := range stats {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return AllianceStats{}, false
+}
+
+// SearchAlliances 名前の部分一致（大文字小文字を区別しない）または ID の完全一致で探す
+func SearchAlliances(stats []AllianceStats, query string) []AllianceStats {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	var out []AllianceStats
+	for _, a := range stats {
+		if strconv.Itoa(a.ID) == query || strings.Contains(strings.ToLower(a.Name), query) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// BuildAlliancePeriodRanking 期間（JST の日付キー）内の同盟ごとの荒らし/修復数（荒らし数の多い順、活動の無い同盟は除く）
+func BuildAlliancePeriodRanking(entries map[string]*UserActivity, dates []string) []AlliancePeriodEntry {
+	byID := make(map[int]*AlliancePeriodEntry)
+	nameSeen := make(map[int]time.Time)
+	for _, entry := range entries {
+		if entry == nil || entry.AllianceID == 0 {
+			continue
+		}
+		vandal, restored := 0, 0
+		for _, dateKey := range dates {
+			vandal += entry.DailyVandalCounts[dateKey]
+			restored += entry.DailyRestoredCounts[dateKey]
+		}
+		if vandal <= 0 && restored <= 0 {
+			continue
+		}
+		row := byID[entry.AllianceID]
+		if row == nil {
+			row = &AlliancePeriodEntry{ID: entry.AllianceID}
+			byID[entry.AllianceID] = row
+		}
+		lastSeen := parseActivityTime(entry.LastSeen)
+		if entry.AllianceName != "" && (row.Name == "" || lastSeen.After(nameSeen[row.ID])) {
+			row.Name = entry.AllianceName
+			nameSeen[row.ID] = lastSeen
+		}
+		row.VandalCount += vandal
+		row.RestoredCount += restored
+		row.Members++
+	}
+
+	out := make([]AlliancePeriodEntry, 0, len(byID))
+	for _, row := range byID {
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].VandalCount != out[j].VandalCount {
+			return out[i].VandalCount > out[j].VandalCount
+		}
+		if out[i].RestoredCount != out[j].RestoredCount {
+			return out[i].RestoredCount > out[j].RestoredCount
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func parseActivityTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,54 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildAllianceStats(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, leaderboardJST)
+	entries := map[string]*UserActivity{
+		"1": {Name: "alice", AllianceID: 10, AllianceName: "Old Name", LastSeen: "2026-03-01T00:00:00Z", VandalCount: 5,
+			DailyVandalCounts: map[string]int{"2026-03-01": 5}},
+		"2": {Name: "bob", AllianceID: 10, AllianceName: "Raiders", LastSeen: "2026-03-13T10:00:00.5Z", VandalCount: 8, RestoredCount: 1,
+			DailyVandalCounts: map[string]int{"2026-02-20": 3, "2026-03-13": 5}, DailyRestoredCounts: map[string]int{"2026-03-13": 1}},
+		"3": {Name: "carol", AllianceID: 20, AllianceName: "Fixers", RestoredCount: 30,
+			DailyRestoredCounts: map[string]int{"2026-03-08": 30}},
+		"4": {Name: "solo", VandalCount: 100},
+	}
+
+	stats := BuildAllianceStats(entries, now)
+	if len(stats) != 2 || stats[0].ID != 10 || stats[1].ID != 20 {
+		t.Fatalf("unexpected alliances: %+v", stats)
+	}
+	raiders := stats[0]
+	if raiders.Name != "Raiders" || raiders.DisplayName() != "Raiders#10" {
+		t.Errorf("name should come from the latest member: %q", raiders.Name)
+	}
+	if raiders.VandalCount != 13 || raiders.RestoredCount != 1 || raiders.Score() != -12 {
+		t.Errorf("unexpected totals: %+v", raiders)
+	}
+	if len(raiders.Members) != 2 || raiders.Members[0].ID != "2" || raiders.ActiveMembers != 1 || !raiders.Members[0].Active {
+		t.Errorf("unexpected members: %+v (active=%d)", raiders.Members, raiders.ActiveMembers)
+	}
+	if raiders.FirstSeen != "2026-02-20" || !raiders.LastSeen.Equal(time.Date(2026, 3, 13, 10, 0, 0, 5e8, time.UTC)) {
+		t.Errorf("unexpected first/last seen: %s / %s", raiders.FirstSeen, raiders.LastSeen)
+	}
+	if raiders.DailyVandal["2026-03-13"] != 5 || raiders.DailyMembers["2026-03-13"] != 1 || raiders.DailyMembers["2026-03-01"] != 1 {
+		t.Errorf("unexpected daily series: %+v %+v", raiders.DailyVandal, raiders.DailyMembers)
+	}
+
+	if got := SearchAlliances(stats, "fix"); len(got) != 1 || got[0].ID != 20 {
+		t.Errorf("search by name: %+v", got)
+	}
+	if got := SearchAlliances(stats, "10"); len(got) != 1 || got[0].ID != 10 {
+		t.Errorf("search by id: %+v", got)
+	}
+
+	ranking := BuildAlliancePeriodRanking(entries, []string{"2026-03-08", "2026-03-13"})
+	if len(ranking) != 2 || ranking[0].ID != 10 || ranking[0].VandalCount != 5 || ranking[0].Members != 1 || ranking[1].RestoredCount != 30 {
+		t.Fatalf("unexpected period ranking: %+v", ranking)
+	}
+}
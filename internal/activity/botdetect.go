@@ -0,0 +1,249 @@
+package activity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// BotReviewConfirmed モデレーターが bot と判断した
+	BotReviewConfirmed = "confirmed"
+	// BotReviewDismissed モデレーターが誤検知と判断した（以後は再フラグしない）
+	BotReviewDismissed = "dismissed"
+
+	BotEvidenceCadence    = "cadence"
+	BotEvidenceScanline   = "scanline"
+	BotEvidenceChargeRate = "charge_rate"
+
+	// paintLogSize / paintLogWindow ユーザーごとに判定用に覚えておく確定帰属の件数と期間
+	paintLogSize   = 500
+	paintLogWindow = 6 * time.Hour
+	// botMinEvents 判定に使う最少の確定帰属数
+	botMinEvents = 20
+	// botCadenceMaxCV 塗る間隔の変動係数がこれ以下なら一定間隔とみなす
+	botCadenceMaxCV = 0.15
+	// botScanlineMinRatio 隣のピクセルへ1つずつ進む割合がこれ以上なら走査線順とみなす
+	botScanlineMinRatio = 0.9
+	// botResolvedFrames 間隔と順序の判定には、監視フレームの間隔のこれ倍以上離れた帰属どうしだけを使う。
+	// 帰属の時刻はフレームが届いた時刻なので、毎フレーム塗る人は間隔がフレーム間隔そのものになり一定に見える
+	botResolvedFrames = 2
+	// wplaceChargeInterval wplace のチャージ回復間隔（1px あたり）
+	wplaceChargeInterval = 30 * time.Second
+	// botChargeAllowance 回復分に上乗せして許す px 数（最大チャージ・購入分の余裕）
+	botChargeAllowance = 150
+)
+
+// BotSuspicion 自動描画（bot/スクリプト）の疑いと根拠。モデレーターの確認結果を持つ
+type BotSuspicion struct {
+	FlaggedAt  string        `json:"flagged_at"`
+	Evidence   []BotEvidence `json:"evidence"`
+	Review     string        `json:"review,omitempty"` // 空なら未確認（BotReviewConfirmed / BotReviewDismissed）
+	ReviewedBy string        `json:"reviewed_by,omitempty"`
+	ReviewedAt string        `json:"reviewed_at,omitempty"`
+}
+
+// BotEvidence 判定の根拠1つ
+type BotEvidence struct {
+	Kind   string  `json:"kind"`
+	Detail string  `json:"detail"`
+	Score  float64 `json:"score"`
+	Events int     `json:"events"`
+}
+
+// paintObservation 確定帰属1件（差分に現れた/消えた時刻と座標）
+type paintObservation struct {
+	At    time.Time
+	Frame time.Duration // 検出時の監視フレームの間隔（At の分解能。0 なら不明）
+	X     int
+	Y     int
+}
+
+// recordPaintLocked 確定帰属を判定用の履歴に積み、新たに疑いが立ったら true（t.mu を保持して呼ぶ）
+func (t *Tracker) recordPaintLocked(entry *UserActivity, key string, px Pixel, now time.Time) bool {
+	at := now
+	if detected, ok := t.detectedAt[key]; ok {
+		at = detected
+	}
+	history := append(t.paintLog[entry.ID], paintObservation{At: at, Frame: t.frameInterval, X: px.AbsX, Y: px.AbsY})
+	if len(history) > paintLogSize {
+		history = history[len(history)-paintLogSize:]
+	}
+	t.paintLog[entry.ID] = history
+
+	if entry.SuspectedBot != nil {
+		return false
+	}
+	evidence := detectBotEvidence(history)
+	if len(evidence) == 0 {
+		return false
+	}
+	entry.SuspectedBot = &BotSuspicion{FlaggedAt: now.Format(time.RFC3339Nano), Evidence: evidence}
+	return true
+}
+
+// ReviewBotSuspicion bot の疑いにモデレーターの確認結果を付ける
+func (t *Tracker) ReviewBotSuspicion(userID, verdict, reviewer string) (UserActivity, error) {
+	if verdict != BotReviewConfirmed && verdict != BotReviewDismissed {
+		return UserActivity{}, fmt.Errorf("unknown verdict: %s", verdict)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry := t.activity[userID]
+	if entry == nil || entry.SuspectedBot == nil {
+		return UserActivity{}, fmt.Errorf("user %s is not flagged", userID)
+	}
+	entry.SuspectedBot.Review = verdict
+	entry.SuspectedBot.ReviewedBy = reviewer
+	entry.SuspectedBot.ReviewedAt = time.Now().UTC().Format(time.RFC3339Nano)
+	t.dirtyActivity = true
+	t.dirtyUsers[userID] = struct{}{}
+	return cloneUserActivity(entry), nil
+}
+
+func prunePaintLog(store map[string][]paintObservation, cutoff time.Time) {
+	for userID, history := range store {
+		kept := history[:0]
+		for _, ob := range history {
+			if !ob.At.Before(cutoff) {
+				kept = append(kept, ob)
+			}
+		}
+		if len(kept) == 0 {
+			delete(store, userID)
+			continue
+		}
+		store[userID] = kept
+	}
+}
+
+// detectBotEvidence 確定帰属の履歴から自動描画らしさの根拠を集める
+func detectBotEvidence(history []paintObservation) []BotEvidence {
+	if len(history) < botMinEvents {
+		return nil
+	}
+	obs := append([]paintObservation(nil), history...)
+	sort.SliceStable(obs, func(i, j int) bool { return obs[i].At.Before(obs[j].At) })
+
+	var out []BotEvidence
+	if ev, ok := cadenceEvidence(obs); ok {
+		out = append(out, ev)
+	}
+	if ev, ok := scanlineEvidence(obs); ok {
+		out = append(out, ev)
+	}
+	if ev, ok := chargeRateEvidence(obs); ok {
+		out = append(out, ev)
+	}
+	return out
+}
+
+// resolvedStep prev から cur までの間隔が監視フレームの分解能より十分長く、間隔・順序の判定に使えるか
+func resolvedStep(prev, cur paintObservation) bool {
+	gap := cur.At.Sub(prev.At)
+	if gap <= 0 {
+		return false
+	}
+	frame := max(prev.Frame, cur.Frame)
+	return gap >= botResolvedFrames*frame
+}
+
+// cadenceEvidence 1px ずつほぼ一定の間隔で塗っている（同時や隣のフレームで検出した分は間隔に数えない）
+func cadenceEvidence(obs []paintObservation) (BotEvidence, bool) {
+	intervals := make([]float64, 0, len(obs))
+	for idx := 1; idx < len(obs); idx++ {
+		if resolvedStep(obs[idx-1], obs[idx]) {
+			intervals = append(intervals, obs[idx].At.Sub(obs[idx-1].At).Seconds())
+		}
+	}
+	if len(intervals) < botMinEvents-1 {
+		return BotEvidence{}, false
+	}
+	mean, std := meanStd(intervals)
+	if mean < 1 {
+		return BotEvidence{}, false
+	}
+	cv := std / mean
+	if cv > botCadenceMaxCV {
+		return BotEvidence{}, false
+	}
+	return BotEvidence{
+		Kind:   BotEvidenceCadence,
+		Detail: fmt.Sprintf("間隔 %.1f秒 ± %.1f秒（%d回）", mean, std, len(intervals)),
+		Score:  cv,
+		Events: len(intervals) + 1,
+	}, true
+}
+
+// scanlineEvidence フレームの分解能より離れた連続した帰属が、ほとんど縦横の隣のピクセルへ1つずつ進んでいる
+func scanlineEvidence(obs []paintObservation) (BotEvidence, bool) {
+	steps, adjacent := 0, 0
+	for idx := 1; idx < len(obs); idx++ {
+		prev, cur := obs[idx-1], obs[idx]
+		if !resolvedStep(prev, cur) {
+			continue
+		}
+		steps++
+		dx, dy := cur.X-prev.X, cur.Y-prev.Y
+		if (dy == 0 && (dx == 1 || dx == -1)) || (dx == 0 && (dy == 1 || dy == -1)) {
+			adjacent++
+		}
+	}
+	if steps < botMinEvents-1 {
+		return BotEvidence{}, false
+	}
+	ratio := float64(adjacent) / float64(steps)
+	if ratio < botScanlineMinRatio {
+		return BotEvidence{}, false
+	}
+	return BotEvidence{
+		Kind:   BotEvidenceScanline,
+		Detail: fmt.Sprintf("隣のピクセルへ順に %d/%d 回（%.0f%%）", adjacent, steps, ratio*100),
+		Score:  ratio,
+		Events: steps + 1,
+	}, true
+}
+
+// chargeRateEvidence ある期間に塗った数が、チャージ回復（30秒に1px）と余裕分の合計を超えている
+func chargeRateEvidence(obs []paintObservation) (BotEvidence, bool) {
+	// excess(i, j) = (j - i + 1) - (t_j - t_i)/interval を最大にする区間を1回の走査で探す
+	bestExcess, bestFrom, bestTo := math.Inf(-1), 0, 0
+	maxStart, maxStartIdx := math.Inf(-1), 0
+	interval := wplaceChargeInterval.Seconds()
+	origin := obs[0].At
+	for j, ob := range obs {
+		tj := ob.At.Sub(origin).Seconds() / interval
+		if start := tj - float64(j); start > maxStart {
+			maxStart, maxStartIdx = start, j
+		}
+		if excess := float64(j+1) - tj + maxStart; excess > bestExcess {
+			bestExcess, bestFrom, bestTo = excess, maxStartIdx, j
+		}
+	}
+	if bestExcess <= botChargeAllowance {
+		return BotEvidence{}, false
+	}
+	span := obs[bestTo].At.Sub(obs[bestFrom].At)
+	painted := bestTo - bestFrom + 1
+	regen := int(span / wplaceChargeInterval)
+	return BotEvidence{
+		Kind:   BotEvidenceChargeRate,
+		Detail: fmt.Sprintf("%s で %d px（回復分 %d px + 余裕 %d px を超過）", span.Round(time.Second), painted, regen, botChargeAllowance),
+		Score:  bestExcess,
+		Events: painted,
+	}, true
+}
+
+func meanStd(values []float64) (float64, float64) {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
@@ -0,0 +1,121 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func hasBotEvidence(evidence []BotEvidence, kind string) bool {
+	for _, ev := range evidence {
+		if ev.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDetectBotEvidenceCadenceAndScanline(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := make([]paintObservation, 0, 30)
+	for i := 0; i < 30; i++ {
+		history = append(history, paintObservation{At: base.Add(time.Duration(i) * 31 * time.Second), Frame: 5 * time.Second, X: 100 + i, Y: 50})
+	}
+	evidence := detectBotEvidence(history)
+	if !hasBotEvidence(evidence, BotEvidenceCadence) || !hasBotEvidence(evidence, BotEvidenceScanline) {
+		t.Fatalf("expected cadence and scanline evidence, got %+v", evidence)
+	}
+	if hasBotEvidence(evidence, BotEvidenceChargeRate) {
+		t.Fatalf("painting within the charge rate should not be flagged: %+v", evidence)
+	}
+}
+
+func TestDetectBotEvidenceChargeRate(t *testing.T) {
+	t.Parallel()
+
+	// 10分で 400px（回復分 20px + 余裕 150px を大きく超える）。間隔と位置はばらつかせる
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := make([]paintObservation, 0, 400)
+	for i := 0; i < 400; i++ {
+		offset := time.Duration(i*1500+(i*i%7)*300) * time.Millisecond
+		history = append(history, paintObservation{At: base.Add(offset), X: (i * 37) % 200, Y: (i * 11) % 90})
+	}
+	evidence := detectBotEvidence(history)
+	if !hasBotEvidence(evidence, BotEvidenceChargeRate) {
+		t.Fatalf("expected charge rate evidence, got %+v", evidence)
+	}
+	if hasBotEvidence(evidence, BotEvidenceScanline) {
+		t.Fatalf("scattered pixels should not look like a scanline: %+v", evidence)
+	}
+}
+
+func TestDetectBotEvidenceIgnoresHumanLikePainting(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	gaps := []int{4, 35, 9, 60, 2, 18, 41, 7, 90, 12, 3, 27, 55, 6, 14, 70, 8, 22, 5, 33, 48, 11}
+	history := make([]paintObservation, 0, len(gaps))
+	at := base
+	for i, gap := range gaps {
+		at = at.Add(time.Duration(gap) * time.Second)
+		history = append(history, paintObservation{At: at, X: (i * 13) % 40, Y: (i * 7) % 25})
+	}
+	if evidence := detectBotEvidence(history); len(evidence) != 0 {
+		t.Fatalf("irregular painting should not be flagged: %+v", evidence)
+	}
+	// 件数が足りなければ判定しない
+	if evidence := detectBotEvidence(history[:botMinEvents-1]); evidence != nil {
+		t.Fatalf("short history should not be judged: %+v", evidence)
+	}
+}
+
+func TestDetectBotEvidenceIgnoresFrameQuantizedHumanPainting(t *testing.T) {
+	t.Parallel()
+
+	// 人が1フレームに1px以上のペースで線を引くと、帰属の時刻はフレームの受信時刻そのものになる
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	frame := 5 * time.Second
+	history := make([]paintObservation, 0, 60)
+	for i := 0; i < 60; i++ {
+		jitter := time.Duration(i%3) * 40 * time.Millisecond
+		history = append(history, paintObservation{At: base.Add(time.Duration(i)*frame + jitter), Frame: frame, X: 100 + i, Y: 50})
+	}
+	if evidence := detectBotEvidence(history); len(evidence) != 0 {
+		t.Fatalf("frame-quantized human painting should not be flagged: %+v", evidence)
+	}
+}
+
+func TestRecordPaintFlagsOnceAndReview(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker(Config{Width: 10, Height: 10}, nil, "")
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker.mu.Lock()
+	entry := tracker.activityEntryLocked("42")
+	flagged := 0
+	for i := 0; i < 30; i++ {
+		if tracker.recordPaintLocked(entry, pixelKey(i, 0), Pixel{AbsX: i, AbsY: 0}, base.Add(time.Duration(i)*40*time.Second)) {
+			flagged++
+		}
+	}
+	tracker.mu.Unlock()
+	if flagged != 1 {
+		t.Fatalf("user should be flagged exactly once, got %d", flagged)
+	}
+
+	if _, err := tracker.ReviewBotSuspicion("42", "maybe", "mod"); err == nil {
+		t.Fatal("unknown verdict should be rejected")
+	}
+	if _, err := tracker.ReviewBotSuspicion("7", BotReviewConfirmed, "mod"); err == nil {
+		t.Fatal("unflagged user should be rejected")
+	}
+	user, err := tracker.ReviewBotSuspicion("42", BotReviewDismissed, "mod")
+	if err != nil {
+		t.Fatalf("ReviewBotSuspicion returned error: %v", err)
+	}
+	if user.SuspectedBot == nil || user.SuspectedBot.Review != BotReviewDismissed || user.SuspectedBot.ReviewedBy != "mod" {
+		t.Fatalf("unexpected review: %+v", user.SuspectedBot)
+	}
+}
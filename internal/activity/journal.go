@@ -0,0 +1,281 @@
+package activity
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"Koukyo_discord_bot/internal/utils"
+)
+
+const (
+	// PixelJournalFile ピクセル単位の帰属イベントの追記ログ（1行1イベントの JSON）
+	PixelJournalFile = "pixel_journal.jsonl"
+
+	// JournalKindVandal 荒らし（差分になったピクセルへの帰属）
+	JournalKindVandal = "vandal"
+	// JournalKindRestore 修復（差分が解消したピクセルへの帰属）
+	JournalKindRestore = "restore"
+
+	defaultJournalRetention = 30 * 24 * time.Hour
+	// journalCompactThreshold 前回の圧縮からこの行数を追記したら圧縮する
+	journalCompactThreshold = 50000
+)
+
+// JournalEvent processPixel が行った1ピクセル分の帰属
+type JournalEvent struct {
+	X        int       `json:"x"`
+	Y        int       `json:"y"`
+	Painter  string    `json:"p"`
+	Kind     string    `json:"k"`           // JournalKind*
+	At       time.Time `json:"t"`           // 帰属した時刻（UTC）
+	Inferred bool      `json:"i,omitempty"` // 省電力推定による帰属（ピクセルAPIで直接確認していない）
+}
+
+// PixelJournal 帰属イベントをメモリに溜め、flush で追記する。保持期間を過ぎたイベントは圧縮で落とす
+type PixelJournal struct {
+	path      string
+	retention time.Duration
+
+	mu       sync.Mutex
+	pending  []JournalEvent
+	appended int // 前回の圧縮以降に追記した行数
+
+	// fileMu ファイルへの追記と圧縮を直列にする。I/O 中も mu は持たないので Record は待たされない
+	fileMu sync.Mutex
+}
+
+// NewPixelJournal dataDir/pixel_journal.jsonl のジャーナル（retention が 0 以下なら30日保持）。
+// 再起動を挟んでも圧縮の閾値が効くよう、既存ファイルの行数を追記済みとして数える
+func NewPixelJournal(dataDir string, retention time.Duration) *PixelJournal {
+	if retention <= 0 {
+		retention = defaultJournalRetention
+	}
+	j := &PixelJournal{
+		path:      filepath.Join(dataDir, PixelJournalFile),
+		retention: retention,
+	}
+	lines, err := countJournalLines(j.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("failed to count %s lines: %v", PixelJournalFile, err)
+	}
+	j.appended = lines
+	return j
+}
+
+// countJournalLines ファイルの行数（改行の数）
+func countJournalLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	lines := 0
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := f.Read(buf)
+		lines += bytes.Count(buf[:n], []byte{'\n'})
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+}
+
+// Record イベントを追記待ちに積む
+func (j *PixelJournal) Record(events ...JournalEvent) {
+	if j == nil || len(events) == 0 {
+		return
+	}
+	j.mu.Lock()
+	j.pending = append(j.pending, events...)
+	j.mu.Unlock()
+}
+
+// Flush 追記待ちのイベントをファイル末尾に書き出す。閾値を超えて追記していれば圧縮する。
+// 書き出す分を取り出してから I/O を行い、失敗したら追記待ちの先頭へ戻す
+func (j *PixelJournal) Flush(now time.Time) error {
+	if j == nil {
+		return nil
+	}
+	j.fileMu.Lock()
+	defer j.fileMu.Unlock()
+
+	j.mu.Lock()
+	batch := j.pending
+	j.pending = nil
+	j.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := j.appendFile(batch); err != nil {
+		j.mu.Lock()
+		j.pending = append(batch, j.pending...)
+		j.mu.Unlock()
+		return err
+	}
+	j.mu.Lock()
+	j.appended += len(batch)
+	compact := j.appended >= journalCompactThreshold
+	j.mu.Unlock()
+
+	if compact {
+		return j.compactFile(now)
+	}
+	return nil
+}
+
+func (j *PixelJournal) appendFile(events []JournalEvent) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Compact 保持期間を過ぎたイベントと、同じピクセル・塗り手・種別の連続した重複を落として書き直す
+func (j *PixelJournal) Compact(now time.Time) error {
+	if j == nil {
+		return nil
+	}
+	j.fileMu.Lock()
+	defer j.fileMu.Unlock()
+	return j.compactFile(now)
+}
+
+// compactFile fileMu を保持して呼ぶ
+func (j *PixelJournal) compactFile(now time.Time) error {
+	events, err := readJournalFile(j.path, now.Add(-j.retention), nil)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	events = compactJournalEvents(events)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+	if err := utils.WriteFileAtomic(j.path, buf.Bytes()); err != nil {
+		return err
+	}
+	j.mu.Lock()
+	j.appended = 0
+	j.mu.Unlock()
+	return nil
+}
+
+// compactJournalEvents ピクセルごとに、直前と同じ塗り手・種別のイベントを省く（最初の帰属時刻を残す）
+func compactJournalEvents(events []JournalEvent) []JournalEvent {
+	sort.SliceStable(events, func(a, b int) bool {
+		return events[a].At.Before(events[b].At)
+	})
+	type pixel struct{ x, y int }
+	last := make(map[pixel]JournalEvent)
+	out := events[:0]
+	for _, ev := range events {
+		key := pixel{ev.X, ev.Y}
+		if prev, ok := last[key]; ok && prev.Painter == ev.Painter && prev.Kind == ev.Kind {
+			continue
+		}
+		last[key] = ev
+		out = append(out, ev)
+	}
+	return out
+}
+
+// ReadPixelJournal dataDir のジャーナルから since 以降で match に合うイベントを時刻順に返す（ファイルが無ければ空）
+func ReadPixelJournal(dataDir string, since time.Time, match func(JournalEvent) bool) ([]JournalEvent, error) {
+	events, err := readJournalFile(filepath.Join(dataDir, PixelJournalFile), since, match)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(events, func(a, b int) bool {
+		return events[a].At.Before(events[b].At)
+	})
+	return events, nil
+}
+
+// readJournalFile 読めない行（書き込み途中の末尾など）は飛ばす
+func readJournalFile(path string, since time.Time, match func(JournalEvent) bool) ([]JournalEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []JournalEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev JournalEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		if ev.At.Before(since) || (match != nil && !match(ev)) {
+			continue
+		}
+		events = append(events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// journalEventsFor 帰属したピクセル群のイベント。probeKey のピクセルは推定でない限り直接確認とする
+func journalEventsFor(pixels []Pixel, painterID, kind string, at time.Time, probeKey string, aliased bool) []JournalEvent {
+	events := make([]JournalEvent, 0, len(pixels))
+	for _, px := range pixels {
+		events = append(events, JournalEvent{
+			X:        px.AbsX,
+			Y:        px.AbsY,
+			Painter:  painterID,
+			Kind:     kind,
+			At:       at,
+			Inferred: aliased || pixelKey(px.AbsX, px.AbsY) != probeKey,
+		})
+	}
+	return events
+}
+
+// pixelsNotIn a にあって b に無いピクセル
+func pixelsNotIn(a, b map[string]Pixel) []Pixel {
+	out := make([]Pixel, 0)
+	for key, px := range a {
+		if _, ok := b[key]; !ok {
+			out = append(out, px)
+		}
+	}
+	return out
+}
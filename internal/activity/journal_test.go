@@ -0,0 +1,146 @@
+package activity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPixelJournalFlushAndCompact(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	j := NewPixelJournal(dir, 7*24*time.Hour)
+	now := time.Date(2026, 3, 14, 3, 0, 0, 0, time.UTC)
+	old := now.Add(-8 * 24 * time.Hour)
+
+	j.Record(
+		JournalEvent{X: 1, Y: 2, Painter: "9", Kind: JournalKindVandal, At: old},
+		JournalEvent{X: 1, Y: 2, Painter: "5", Kind: JournalKindVandal, At: now.Add(-time.Hour)},
+		// 推定の一括帰属で同じ塗り手・種別が重なる
+		JournalEvent{X: 1, Y: 2, Painter: "5", Kind: JournalKindVandal, At: now.Add(-30 * time.Minute), Inferred: true},
+		JournalEvent{X: 1, Y: 2, Painter: "7", Kind: JournalKindRestore, At: now},
+		JournalEvent{X: 3, Y: 4, Painter: "5", Kind: JournalKindVandal, At: now.Add(-time.Minute), Inferred: true},
+	)
+	if err := j.Flush(now); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	// 書き込み途中の行は読み飛ばす
+	f, err := os.OpenFile(filepath.Join(dir, PixelJournalFile), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"x":1,"y":`)
+	_ = f.Close()
+
+	all, err := ReadPixelJournal(dir, time.Time{}, nil)
+	if err != nil || len(all) != 5 {
+		t.Fatalf("expected 5 events before compaction, got %d (%v)", len(all), err)
+	}
+
+	if err := j.Compact(now); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	events, err := ReadPixelJournal(dir, time.Time{}, func(ev JournalEvent) bool { return ev.X == 1 && ev.Y == 2 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Painter != "5" || events[0].Inferred || events[1].Kind != JournalKindRestore {
+		t.Fatalf("unexpected compacted timeline: %+v", events)
+	}
+
+	byPainter, _ := ReadPixelJournal(dir, now.Add(-2*time.Hour), func(ev JournalEvent) bool { return ev.Painter == "5" })
+	if len(byPainter) != 2 || byPainter[1].X != 3 || !byPainter[1].Inferred {
+		t.Fatalf("unexpected painter events: %+v", byPainter)
+	}
+}
+
+func TestJournalEventsForMarksInferredPixels(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	probe := Pixel{AbsX: 10, AbsY: 20}
+	other := Pixel{AbsX: 11, AbsY: 20}
+
+	events := journalEventsFor([]Pixel{probe, other}, "5", JournalKindVandal, at, pixelKey(10, 20), false)
+	if len(events) != 2 || events[0].Inferred || !events[1].Inferred {
+		t.Fatalf("only the probed pixel should be direct: %+v", events)
+	}
+	aliased := journalEventsFor([]Pixel{probe}, "5", JournalKindRestore, at, pixelKey(10, 20), true)
+	if !aliased[0].Inferred {
+		t.Fatalf("aliased attribution should be inferred: %+v", aliased)
+	}
+
+	diff := map[string]Pixel{pixelKey(10, 20): probe, pixelKey(11, 20): other}
+	baseline := map[string]Pixel{pixelKey(11, 20): other}
+	if got := pixelsNotIn(diff, baseline); len(got) != 1 || got[0] != probe {
+		t.Fatalf("unexpected pixelsNotIn: %+v", got)
+	}
+}
+
+func TestNewPixelJournalSeedsAppendedFromExistingFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 14, 3, 0, 0, 0, time.UTC)
+	j := NewPixelJournal(dir, 0)
+	j.Record(
+		JournalEvent{X: 1, Y: 2, Painter: "5", Kind: JournalKindVandal, At: now},
+		JournalEvent{X: 3, Y: 4, Painter: "5", Kind: JournalKindVandal, At: now},
+	)
+	if err := j.Flush(now); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	reopened := NewPixelJournal(dir, 0)
+	if reopened.appended != 2 {
+		t.Fatalf("appended after reopen = %d, want 2", reopened.appended)
+	}
+}
+
+func TestPixelJournalRecordDoesNotWaitForFileIO(t *testing.T) {
+	t.Parallel()
+
+	j := NewPixelJournal(t.TempDir(), 0)
+	// 圧縮などのファイル I/O 中でも追記待ちに積める
+	j.fileMu.Lock()
+	defer j.fileMu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		j.Record(JournalEvent{X: 1, Y: 2, Painter: "5", Kind: JournalKindVandal, At: time.Now()})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Record blocked while the journal file was busy")
+	}
+}
+
+func TestPixelJournalKeepsEventsWhenFlushFails(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "missing")
+	now := time.Date(2026, 3, 14, 3, 0, 0, 0, time.UTC)
+	j := NewPixelJournal(dir, 0)
+	j.Record(JournalEvent{X: 1, Y: 2, Painter: "5", Kind: JournalKindVandal, At: now})
+	if err := j.Flush(now); err == nil {
+		t.Fatal("flush into a missing directory should fail")
+	}
+	j.Record(JournalEvent{X: 3, Y: 4, Painter: "5", Kind: JournalKindVandal, At: now})
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Flush(now); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	events, err := ReadPixelJournal(dir, time.Time{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].X != 1 || events[1].X != 3 {
+		t.Fatalf("events after retry = %+v", events)
+	}
+}
@@ -0,0 +1,210 @@
+package activity
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// リーダーボードの種別
+const (
+	LeaderboardVandal   = "vandal"
+	LeaderboardRestore  = "restore"
+	LeaderboardActivity = "activity"
+)
+
+// リーダーボードの集計期間
+const (
+	LeaderboardWeek    = "7d"
+	LeaderboardMonth   = "30d"
+	LeaderboardAllTime = "all"
+)
+
+// leaderboardCompareDays 通算の順位変動を比べる日数（7日前時点の通算と比べる）
+const leaderboardCompareDays = 7
+
+var leaderboardJST = time.FixedZone("JST", 9*3600)
+
+// LeaderboardEntry リーダーボードの1行
+type LeaderboardEntry struct {
+	ID         string
+	Name       string
+	AllianceID int
+	Alliance   string
+	Count      int
+	Rank       int
+	PrevRank   int // 直前の期間の順位（0 なら圏外＝新規）
+}
+
+// Leaderboard 集計結果
+type Leaderboard struct {
+	Kind    string
+	Period  string
+	From    string // 集計初日（JST の日付キー。通算は空）
+	To      string // 集計最終日（JST の日付キー）
+	Entries []LeaderboardEntry
+}
+
+// NormalizeLeaderboardKind 種別の入力を正規化する（不明なら activity）
+func NormalizeLeaderboardKind(kind string) string {
+	switch kind {
+	case LeaderboardVandal, "grf":
+		return LeaderboardVandal
+	case LeaderboardRestore, "fix":
+		return LeaderboardRestore
+	default:
+		return LeaderboardActivity
+	}
+}
+
+// NormalizeLeaderboardPeriod 期間の入力を正規化する（不明なら 7d）
+func NormalizeLeaderboardPeriod(period string) string {
+	switch period {
+	case LeaderboardMonth, "30", "month", "monthly":
+		return LeaderboardMonth
+	case LeaderboardAllTime, "alltime", "total":
+		return LeaderboardAllTime
+	default:
+		return LeaderboardWeek
+	}
+}
+
+// leaderboardDays 期間の日数（通算は 0）
+func leaderboardDays(period string) int {
+	switch period {
+	case LeaderboardMonth:
+		return 30
+	case LeaderboardAllTime:
+		return 0
+	default:
+		return 7
+	}
+}
+
+// BuildLeaderboard end（JST の日付、その日を含む）までの期間で集計し、直前の同じ長さの期間との順位変動を付ける。
+// 通算は end までの合計を、その7日前時点の通算と比べる。
+func BuildLeaderboard(entries map[string]*UserActivity, kind, period string, end time.Time) Leaderboard {
+	kind = NormalizeLeaderboardKind(kind)
+	period = NormalizeLeaderboardPeriod(period)
+	local := end.In(leaderboardJST)
+	endDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, leaderboardJST)
+	lb := Leaderboard{Kind: kind, Period: period, To: endDay.Format("2006-01-02")}
+
+	days := leaderboardDays(period)
+	compare := days
+	if days > 0 {
+		lb.From = endDay.AddDate(0, 0, 1-days).Format("2006-01-02")
+	} else {
+		compare = leaderboardCompareDays
+	}
+
+	current := make(map[string]int, len(entries))
+	previous := make(map[string]int, len(entries))
+	for id, entry := range entries {
+		daily := leaderboardDailyCounts(entry, kind)
+		recent := sumDailyCounts(daily, endDay.AddDate(0, 0, 1-compare), endDay)
+		if days > 0 {
+			current[id] = recent
+			previous[id] = sumDailyCounts(daily, endDay.AddDate(0, 0, 1-2*days), endDay.AddDate(0, 0, -days))
+			continue
+		}
+		// 通算: 集計最終日より後の分を除き、直近7日分を引いたものが比較対象
+		total := leaderboardTotal(entry, kind) - sumDailyCountsAfter(daily, endDay)
+		current[id] = total
+		previous[id] = total - recent
+	}
+
+	prevRanks := rankCounts(previous)
+	for id, rank := range rankCounts(current) {
+		entry := entries[id]
+		name := entry.Name
+		if name == "" {
+			name = fmt.Sprintf("ID:%s", id)
+		}
+		lb.Entries = append(lb.Entries, LeaderboardEntry{
+			ID:         id,
+			Name:       name,
+			AllianceID: entry.AllianceID,
+			Alliance:   entry.AllianceName,
+			Count:      current[id],
+			Rank:       rank,
+			PrevRank:   prevRanks[id],
+		})
+	}
+	sort.Slice(lb.Entries, func(i, j int) bool {
+		return lb.Entries[i].Rank < lb.Entries[j].Rank
+	})
+	return lb
+}
+
+// RankChange 順位変動（正なら上昇）。新規なら ok=false
+func (e LeaderboardEntry) RankChange() (delta int, ok bool) {
+	if e.PrevRank == 0 {
+		return 0, false
+	}
+	return e.PrevRank - e.Rank, true
+}
+
+func leaderboardDailyCounts(entry *UserActivity, kind string) map[string]int {
+	switch kind {
+	case LeaderboardVandal:
+		return entry.DailyVandalCounts
+	case LeaderboardRestore:
+		return entry.DailyRestoredCounts
+	default:
+		return entry.DailyActivityScores
+	}
+}
+
+func leaderboardTotal(entry *UserActivity, kind string) int {
+	switch kind {
+	case LeaderboardVandal:
+		return entry.VandalCount
+	case LeaderboardRestore:
+		return entry.RestoredCount
+	default:
+		return entry.ActivityScore
+	}
+}
+
+// sumDailyCounts [from, to]（JST の日）の合計
+func sumDailyCounts(daily map[string]int, from, to time.Time) int {
+	sum := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		sum += daily[day.Format("2006-01-02")]
+	}
+	return sum
+}
+
+// sumDailyCountsAfter day より後の日の合計
+func sumDailyCountsAfter(daily map[string]int, day time.Time) int {
+	key := day.Format("2006-01-02")
+	sum := 0
+	for dateKey, count := range daily {
+		if dateKey > key {
+			sum += count
+		}
+	}
+	return sum
+}
+
+// rankCounts 正の値を持つ ID に降順の順位を付ける（同数は ID 順）
+func rankCounts(counts map[string]int) map[string]int {
+	ids := make([]string, 0, len(counts))
+	for id, count := range counts {
+		if count > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if counts[ids[i]] == counts[ids[j]] {
+			return ids[i] < ids[j]
+		}
+		return counts[ids[i]] > counts[ids[j]]
+	})
+	ranks := make(map[string]int, len(ids))
+	for i, id := range ids {
+		ranks[id] = i + 1
+	}
+	return ranks
+}
@@ -0,0 +1,61 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildLeaderboardRankMovement(t *testing.T) {
+	t.Parallel()
+
+	end := time.Date(2026, 3, 14, 12, 0, 0, 0, leaderboardJST)
+	entries := map[string]*UserActivity{
+		// 前の7日は1位、今週は2位
+		"a": {Name: "alice", VandalCount: 30, DailyVandalCounts: map[string]int{"2026-03-02": 20, "2026-03-10": 5, "2026-03-15": 5}},
+		// 前の7日は2位、今週は1位
+		"b": {Name: "bob", VandalCount: 12, DailyVandalCounts: map[string]int{"2026-03-03": 2, "2026-03-14": 10}},
+		// 今週初登場
+		"c": {Name: "", VandalCount: 1, DailyVandalCounts: map[string]int{"2026-03-08": 1}},
+		// 期間外のみ
+		"d": {Name: "dave", VandalCount: 9, DailyVandalCounts: map[string]int{"2026-02-01": 9}},
+	}
+
+	lb := BuildLeaderboard(entries, "grf", "7", end)
+	if lb.Kind != LeaderboardVandal || lb.Period != LeaderboardWeek || lb.From != "2026-03-08" || lb.To != "2026-03-14" {
+		t.Fatalf("unexpected header: %+v", lb)
+	}
+	if len(lb.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", lb.Entries)
+	}
+	want := []struct {
+		id          string
+		count, rank int
+		delta       int
+		isNew       bool
+	}{
+		{"b", 10, 1, 1, false},
+		{"a", 5, 2, -1, false},
+		{"c", 1, 3, 0, true},
+	}
+	for i, w := range want {
+		e := lb.Entries[i]
+		delta, ok := e.RankChange()
+		if e.ID != w.id || e.Count != w.count || e.Rank != w.rank || ok == w.isNew || delta != w.delta {
+			t.Errorf("entry %d = %+v (delta=%d ok=%v), want %+v", i, e, delta, ok, w)
+		}
+	}
+	if lb.Entries[2].Name != "ID:c" {
+		t.Errorf("missing name should fall back to ID, got %q", lb.Entries[2].Name)
+	}
+
+	// 通算: 3/15 の分は含めず、7日前時点の通算と比べる
+	all := BuildLeaderboard(entries, LeaderboardVandal, LeaderboardAllTime, end)
+	if all.From != "" || all.Entries[0].ID != "a" || all.Entries[0].Count != 25 || all.Entries[0].PrevRank != 1 {
+		t.Fatalf("unexpected all-time leader: %+v", all.Entries)
+	}
+	for _, e := range all.Entries {
+		if e.ID == "b" && (e.Rank != 2 || e.PrevRank != 3) {
+			t.Fatalf("bob should move 3 -> 2: %+v", e)
+		}
+	}
+}
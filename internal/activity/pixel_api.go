@@ -49,7 +49,7 @@ func FetchPixelInfo(
 	if client == nil {
 		client = NewPixelHTTPClient()
 	}
-	url := fmt.Sprintf("https://backend.wplace.live/s0/pixel/%d/%d?x=%d&y=%d", tileX, tileY, pixelX, pixelY)
+	url := fmt.Sprintf("%s/s0/pixel/%d/%d?x=%d&y=%d", utils.WplaceBackendURL(), tileX, tileY, pixelX, pixelY)
 
 	doReq := func() (interface{}, error) {
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
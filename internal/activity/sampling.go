@@ -0,0 +1,432 @@
+package activity
+
+import (
+	"log"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSamplingMinPixels = 60
+	defaultSampleSize        = 30
+	// samplingRefineInterval 抽出後の残りを照会する間隔（キューが空のときだけ。API 予算の半分程度）
+	samplingRefineInterval = 1 * time.Second
+	// samplingBatchTTL これを過ぎた推定は残りの照会をやめ、推定のまま確定する
+	samplingBatchTTL = 1 * time.Hour
+	// samplingConfidenceZ 信頼区間の z 値（95%）
+	samplingConfidenceZ = 1.96
+)
+
+// samplingBatch 抽出照会で塗り手の割合を推定している、まとまった変化（荒らしなら増えた、修復なら消えたピクセル）
+type samplingBatch struct {
+	Kind        string // JournalKindVandal / JournalKindRestore
+	DateKey     string
+	StartedAt   time.Time
+	Total       int
+	Outstanding int            // 結果待ちの抽出ピクセル（0 になったら推定を計上する）
+	Probing     int            // 結果待ちの追加照会
+	Remaining   []Pixel        // まだ照会していないピクセル（ランダム順なので途中まででも無作為抽出になる）
+	Resolved    map[string]int // 照会で確定した塗り手→ピクセル数
+	Credited    map[string]int // 塗り手ごとに計上済みのピクセル数
+	Estimated   map[string]int // Credited のうち推定分
+}
+
+type samplingProbe struct {
+	batch  *samplingBatch
+	sample bool
+}
+
+type samplingNotice struct {
+	kind string
+	user UserActivity
+}
+
+// SamplingShare 推定中の塗り手1人分（Low/High は割合の95%信頼区間から求めたピクセル数）
+type SamplingShare struct {
+	UserID    string
+	Name      string
+	Sampled   int
+	Share     float64
+	Pixels    int
+	Estimated int
+	Low       int
+	High      int
+}
+
+// SamplingEstimate 推定中のまとまった変化
+type SamplingEstimate struct {
+	Kind      string
+	StartedAt time.Time
+	Total     int
+	Resolved  int
+	Remaining int
+	Painters  []SamplingShare
+}
+
+// startSamplingLocked 照会対象が多ければ無作為に抽出した分だけを返し、残りは推定と後からの照会に回す（t.mu を保持して呼ぶ）
+func (t *Tracker) startSamplingLocked(kind string, pixels []Pixel, dateKey string, now time.Time) []Pixel {
+	if t.samplingMinPixels <= 0 || len(pixels) < t.samplingMinPixels || len(pixels) <= t.sampleSize {
+		return pixels
+	}
+	candidates := make([]Pixel, 0, len(pixels))
+	for _, px := range pixels {
+		key := pixelKey(px.AbsX, px.AbsY)
+		if _, ok := t.pending[key]; ok {
+			continue
+		}
+		if _, ok := t.samplingProbes[key]; ok {
+			continue
+		}
+		candidates = append(candidates, px)
+	}
+	if len(candidates) <= t.sampleSize {
+		return pixels
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	sample := candidates[:t.sampleSize]
+	batch := &samplingBatch{
+		Kind:        kind,
+		DateKey:     dateKey,
+		StartedAt:   now,
+		Total:       len(candidates),
+		Outstanding: len(sample),
+		Remaining:   append([]Pixel(nil), candidates[t.sampleSize:]...),
+		Resolved:    make(map[string]int),
+		Credited:    make(map[string]int),
+		Estimated:   make(map[string]int),
+	}
+	for _, px := range sample {
+		t.samplingProbes[pixelKey(px.AbsX, px.AbsY)] = samplingProbe{batch: batch, sample: true}
+	}
+	t.samplingBatches = append(t.samplingBatches, batch)
+	log.Printf("activity sampling started: kind=%s pixels=%d sample=%d", kind, batch.Total, len(sample))
+	return sample
+}
+
+// handleSampledPixel 抽出・追加照会したピクセルの塗り手を反映する（対象外なら false）
+func (t *Tracker) handleSampledPixel(px Pixel, key string, isDiff bool, painter *PaintedBy, now time.Time) bool {
+	t.mu.Lock()
+	probe, ok := t.samplingProbes[key]
+	if !ok {
+		t.mu.Unlock()
+		return false
+	}
+	delete(t.samplingProbes, key)
+	batch := probe.batch
+	if probe.sample {
+		batch.Outstanding--
+	} else {
+		batch.Probing--
+	}
+
+	// 照会までの間に逆向きに変わったピクセルは確定させず、推定のまま残す
+	var extra []samplingNotice
+	if (batch.Kind == JournalKindVandal) == isDiff {
+		painterID := strconv.Itoa(painter.ID)
+		entry := t.activityEntryLocked(painterID)
+		applyPainterProfile(entry, painter)
+		if t.observePainterStatusLocked(entry, painter, now) {
+			extra = append(extra, samplingNotice{kind: NotifyKindRestricted, user: cloneUserActivity(entry)})
+		}
+		entry.LastSeen = now.Format(time.RFC3339Nano)
+		entry.LastPixel = &PixelRef{X: px.AbsX, Y: px.AbsY}
+		batch.Resolved[painterID]++
+		if isDiff {
+			t.vandalState.PixelToPainter[key] = painterID
+		} else {
+			delete(t.vandalState.PixelToPainter, key)
+		}
+		t.journal.Record(JournalEvent{X: px.AbsX, Y: px.AbsY, Painter: painterID, Kind: batch.Kind, At: now})
+		if t.recordPaintLocked(entry, key, px, now) {
+			log.Printf("activity suspected bot: user=%s evidence=%d", painterID, len(entry.SuspectedBot.Evidence))
+			extra = append(extra, samplingNotice{kind: NotifyKindBot, user: cloneUserActivity(entry)})
+		}
+		t.dirtyActivity = true
+		t.dirtyUsers[painterID] = struct{}{}
+		t.dirtyVandalState = true
+	}
+	notices := append(t.rebalanceSamplingBatchLocked(batch, now), extra...)
+	t.finishSamplingBatchIfDoneLocked(batch)
+	cb := t.newUserCB
+	t.mu.Unlock()
+
+	if cb != nil {
+		for _, n := range notices {
+			cb(n.kind, n.user)
+		}
+	}
+	return true
+}
+
+// abandonSampledPixel 照会に失敗したピクセルを推定のまま残す
+func (t *Tracker) abandonSampledPixel(key string) {
+	t.mu.Lock()
+	probe, ok := t.samplingProbes[key]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	delete(t.samplingProbes, key)
+	if probe.sample {
+		probe.batch.Outstanding--
+	} else {
+		probe.batch.Probing--
+	}
+	notices := t.rebalanceSamplingBatchLocked(probe.batch, time.Now().UTC())
+	t.finishSamplingBatchIfDoneLocked(probe.batch)
+	cb := t.newUserCB
+	t.mu.Unlock()
+
+	if cb != nil {
+		for _, n := range notices {
+			cb(n.kind, n.user)
+		}
+	}
+}
+
+// rebalanceSamplingBatchLocked 確定分＋未確定分の按分に合わせて各塗り手の計上を増減する（抽出の結果待ちの間は確定分のみ）
+func (t *Tracker) rebalanceSamplingBatchLocked(batch *samplingBatch, now time.Time) []samplingNotice {
+	var estimate map[string]int
+	if batch.Outstanding <= 0 {
+		estimate = apportionPixels(batch.Resolved, batch.Total-sumCounts(batch.Resolved))
+	}
+	ids := make([]string, 0, len(batch.Resolved)+len(batch.Credited))
+	for id := range batch.Resolved {
+		ids = append(ids, id)
+	}
+	for id := range batch.Credited {
+		if _, ok := batch.Resolved[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var notices []samplingNotice
+	for _, id := range ids {
+		target := batch.Resolved[id] + estimate[id]
+		delta := target - batch.Credited[id]
+		estimatedDelta := estimate[id] - batch.Estimated[id]
+		if delta == 0 && estimatedDelta == 0 {
+			continue
+		}
+		entry := t.activityEntryLocked(id)
+		if batch.Kind == JournalKindVandal {
+			entry.VandalCount += delta
+			entry.DailyVandalCounts[batch.DateKey] += delta
+			entry.ActivityScore -= delta
+			entry.DailyActivityScores[batch.DateKey] -= delta
+			entry.EstimatedVandalCount += estimatedDelta
+		} else {
+			entry.RestoredCount += delta
+			entry.DailyRestoredCounts[batch.DateKey] += delta
+			entry.ActivityScore += delta
+			entry.DailyActivityScores[batch.DateKey] += delta
+			entry.EstimatedRestoredCount += estimatedDelta
+		}
+		batch.Credited[id] = target
+		batch.Estimated[id] = estimate[id]
+		t.dirtyActivity = true
+		t.dirtyUsers[id] = struct{}{}
+
+		if delta <= 0 {
+			continue
+		}
+		if batch.Kind == JournalKindVandal {
+			windowCount := recordRecentEvents(t.recentVandalEvents, id, now, newUserNotifyWindow, delta)
+			if !entry.VandalNotified && windowCount >= newUserNotifyThreshold {
+				entry.VandalNotified = true
+				notices = append(notices, samplingNotice{kind: "vandal", user: cloneUserActivity(entry)})
+			}
+		} else {
+			windowCount := recordRecentEvents(t.recentFixEvents, id, now, newUserNotifyWindow, delta)
+			if !entry.FixNotified && windowCount >= newUserNotifyThreshold {
+				entry.FixNotified = true
+				notices = append(notices, samplingNotice{kind: "fix", user: cloneUserActivity(entry)})
+			}
+		}
+	}
+	return notices
+}
+
+// finishSamplingBatchIfDoneLocked 照会し終えた推定を一覧から外す（確定しなかった分は推定として残る）
+func (t *Tracker) finishSamplingBatchIfDoneLocked(batch *samplingBatch) {
+	if batch.Outstanding > 0 || batch.Probing > 0 || len(batch.Remaining) > 0 {
+		return
+	}
+	for idx, b := range t.samplingBatches {
+		if b == batch {
+			t.samplingBatches = append(t.samplingBatches[:idx], t.samplingBatches[idx+1:]...)
+			resolved := sumCounts(batch.Resolved)
+			log.Printf("activity sampling finished: kind=%s pixels=%d resolved=%d estimated=%d", batch.Kind, batch.Total, resolved, batch.Total-resolved)
+			return
+		}
+	}
+}
+
+// nextRefinePixel 推定の残りから次に照会するピクセル（キューが空で 429 バックオフ中でないときだけ）
+func (t *Tracker) nextRefinePixel(now time.Time) (Pixel, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, batch := range append([]*samplingBatch(nil), t.samplingBatches...) {
+		if now.Sub(batch.StartedAt) > samplingBatchTTL {
+			batch.Remaining = nil
+			t.finishSamplingBatchIfDoneLocked(batch)
+		}
+	}
+	if len(t.queue) > 0 || len(t.pending) > 0 || now.Before(t.backoffUntil) {
+		return Pixel{}, false
+	}
+	for _, batch := range append([]*samplingBatch(nil), t.samplingBatches...) {
+		if batch.Outstanding > 0 {
+			continue
+		}
+		for len(batch.Remaining) > 0 {
+			px := batch.Remaining[0]
+			batch.Remaining = batch.Remaining[1:]
+			key := pixelKey(px.AbsX, px.AbsY)
+			_, inDiff := t.currentDiff[key]
+			if inDiff != (batch.Kind == JournalKindVandal) {
+				continue
+			}
+			if _, busy := t.samplingProbes[key]; busy {
+				continue
+			}
+			t.samplingProbes[key] = samplingProbe{batch: batch}
+			t.detectedAt[key] = batch.StartedAt
+			batch.Probing++
+			return px, true
+		}
+		t.finishSamplingBatchIfDoneLocked(batch)
+	}
+	return Pixel{}, false
+}
+
+func (t *Tracker) samplingRefineWorker() {
+	ticker := time.NewTicker(samplingRefineInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if px, ok := t.nextRefinePixel(now.UTC()); ok {
+				t.enqueuePixel(px)
+			}
+		}
+	}
+}
+
+// SamplingEstimates 推定中の変化ごとの塗り手の割合と信頼区間
+func (t *Tracker) SamplingEstimates() []SamplingEstimate {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]SamplingEstimate, 0, len(t.samplingBatches))
+	for _, batch := range t.samplingBatches {
+		resolved := sumCounts(batch.Resolved)
+		est := SamplingEstimate{
+			Kind:      batch.Kind,
+			StartedAt: batch.StartedAt,
+			Total:     batch.Total,
+			Resolved:  resolved,
+			Remaining: len(batch.Remaining),
+		}
+		for id, count := range batch.Resolved {
+			low, high := batch.estimateRange(id)
+			share := SamplingShare{
+				UserID:    id,
+				Sampled:   count,
+				Share:     float64(count) / float64(resolved),
+				Pixels:    batch.Credited[id],
+				Estimated: batch.Estimated[id],
+				Low:       count + low,
+				High:      count + high,
+			}
+			if entry := t.activity[id]; entry != nil {
+				share.Name = entry.Name
+			}
+			est.Painters = append(est.Painters, share)
+		}
+		sort.Slice(est.Painters, func(i, j int) bool {
+			if est.Painters[i].Pixels == est.Painters[j].Pixels {
+				return est.Painters[i].UserID < est.Painters[j].UserID
+			}
+			return est.Painters[i].Pixels > est.Painters[j].Pixels
+		})
+		out = append(out, est)
+	}
+	return out
+}
+
+// estimateRange id の未確定分のピクセル数の95%信頼区間
+func (b *samplingBatch) estimateRange(id string) (int, int) {
+	resolved := sumCounts(b.Resolved)
+	unresolved := float64(b.Total - resolved)
+	low, high := wilsonInterval(b.Resolved[id], resolved, b.Total)
+	return int(math.Floor(low * unresolved)), int(math.Ceil(high * unresolved))
+}
+
+// wilsonInterval 抽出 n 件中 k 件のときの割合の95%信頼区間（母数 total の有限母集団修正つき）
+func wilsonInterval(k, n, total int) (float64, float64) {
+	if n <= 0 {
+		return 0, 1
+	}
+	z := samplingConfidenceZ
+	nf := float64(n)
+	p := float64(k) / nf
+	denom := 1 + z*z/nf
+	center := (p + z*z/(2*nf)) / denom
+	half := z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf)) / denom
+	if total > 1 && total >= n {
+		half *= math.Sqrt(float64(total-n) / float64(total-1))
+	}
+	return max(center-half, 0), min(center+half, 1)
+}
+
+// apportionPixels pixels を確定数の比で按分する（最大剰余法なので合計は pixels と一致する）
+func apportionPixels(resolved map[string]int, pixels int) map[string]int {
+	total := sumCounts(resolved)
+	out := make(map[string]int, len(resolved))
+	if total <= 0 || pixels <= 0 {
+		return out
+	}
+	type remainder struct {
+		id   string
+		frac float64
+	}
+	rems := make([]remainder, 0, len(resolved))
+	assigned := 0
+	for id, count := range resolved {
+		exact := float64(count) * float64(pixels) / float64(total)
+		whole := int(math.Floor(exact))
+		out[id] = whole
+		assigned += whole
+		rems = append(rems, remainder{id: id, frac: exact - float64(whole)})
+	}
+	sort.Slice(rems, func(i, j int) bool {
+		if rems[i].frac == rems[j].frac {
+			return rems[i].id < rems[j].id
+		}
+		return rems[i].frac > rems[j].frac
+	})
+	for idx := 0; assigned < pixels; idx++ {
+		out[rems[idx%len(rems)].id]++
+		assigned++
+	}
+	return out
+}
+
+func sumCounts(counts map[string]int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
@@ -0,0 +1,152 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApportionPixelsKeepsTotal(t *testing.T) {
+	t.Parallel()
+
+	got := apportionPixels(map[string]int{"a": 1, "b": 1, "c": 1}, 10)
+	if sum := sumCounts(got); sum != 10 {
+		t.Fatalf("apportioned %d pixels, want 10: %+v", sum, got)
+	}
+	if got["a"] != 4 || got["b"] != 3 || got["c"] != 3 {
+		t.Fatalf("largest remainder should go to the first id on ties: %+v", got)
+	}
+	if got := apportionPixels(nil, 10); len(got) != 0 {
+		t.Fatalf("no samples should estimate nothing: %+v", got)
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	t.Parallel()
+
+	low, high := wilsonInterval(7, 10, 100)
+	if !(low < 0.7 && 0.7 < high) || low < 0 || high > 1 {
+		t.Fatalf("interval should contain the sample share: [%f, %f]", low, high)
+	}
+	wideLow, wideHigh := wilsonInterval(7, 10, 1000000)
+	if high-low >= wideHigh-wideLow {
+		t.Fatalf("finite population should narrow the interval: %f vs %f", high-low, wideHigh-wideLow)
+	}
+	// 全数を照会したら区間は幅を持たない
+	if low, high := wilsonInterval(7, 10, 10); high-low > 1e-9 {
+		t.Fatalf("full census should collapse the interval: [%f, %f]", low, high)
+	}
+}
+
+func TestSamplingEstimatesAndRefines(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker(Config{Width: 20, Height: 10}, nil, "")
+	tracker.samplingMinPixels = 50
+	tracker.sampleSize = 10
+
+	tracker.mu.Lock()
+	tracker.currentDiff[pixelKey(0, 9)] = Pixel{AbsX: 0, AbsY: 9}
+	tracker.currentDiff[pixelKey(1, 9)] = Pixel{AbsX: 1, AbsY: 9}
+	tracker.mu.Unlock()
+
+	// 2ピクセル消えて100ピクセル増えた（省電力推定の対象外）
+	next := make(map[[2]int]bool)
+	for i := 0; i < 100; i++ {
+		next[[2]int{i % 20, i / 20}] = true
+	}
+	if err := tracker.UpdateDiffImage(mustEncodeDiffPNG(t, next)); err != nil {
+		t.Fatalf("UpdateDiffImage returned error: %v", err)
+	}
+	if got := len(tracker.queue); got != 12 {
+		t.Fatalf("expected 10 sampled + 2 removed pixels queued, got %d", got)
+	}
+
+	tracker.mu.Lock()
+	if len(tracker.samplingBatches) != 1 || tracker.samplingBatches[0].Total != 100 || len(tracker.samplingBatches[0].Remaining) != 90 {
+		t.Fatalf("unexpected batches: %+v", tracker.samplingBatches)
+	}
+	sampled := make([]string, 0, len(tracker.samplingProbes))
+	for key := range tracker.samplingProbes {
+		sampled = append(sampled, key)
+	}
+	tracker.mu.Unlock()
+
+	now := time.Now().UTC()
+	for idx, key := range sampled {
+		painter := &PaintedBy{ID: 1, Name: "alice"}
+		if idx >= 7 {
+			painter = &PaintedBy{ID: 2, Name: "bob"}
+		}
+		if !tracker.handleSampledPixel(tracker.currentDiff[key], key, true, painter, now) {
+			t.Fatalf("pixel %s should be a sample probe", key)
+		}
+	}
+
+	tracker.mu.Lock()
+	alice, bob := tracker.activity["1"], tracker.activity["2"]
+	if alice.VandalCount != 70 || alice.EstimatedVandalCount != 63 {
+		t.Fatalf("alice: vandal=%d estimated=%d, want 70/63", alice.VandalCount, alice.EstimatedVandalCount)
+	}
+	if bob.VandalCount != 30 || bob.EstimatedVandalCount != 27 {
+		t.Fatalf("bob: vandal=%d estimated=%d, want 30/27", bob.VandalCount, bob.EstimatedVandalCount)
+	}
+	tracker.mu.Unlock()
+
+	counts := tracker.GetCurrentDiffPainterCounts(0)
+	if len(counts) != 2 || counts[0].UserID != "1" || counts[0].Pixels != 70 || counts[0].Estimated != 63 {
+		t.Fatalf("unexpected diff painter counts: %+v", counts)
+	}
+	for _, c := range counts {
+		if c.Low < c.Pixels-c.Estimated || c.Low > c.Pixels || c.Pixels > c.High || c.High >= c.Pixels+c.Estimated+90 {
+			t.Fatalf("diff painter count outside its interval: %+v", c)
+		}
+	}
+	estimates := tracker.SamplingEstimates()
+	if len(estimates) != 1 || len(estimates[0].Painters) != 2 {
+		t.Fatalf("unexpected estimates: %+v", estimates)
+	}
+	for _, share := range estimates[0].Painters {
+		if share.Low > share.Pixels || share.Pixels > share.High {
+			t.Fatalf("estimate outside its interval: %+v", share)
+		}
+	}
+
+	// キューが空になるまでは残りを照会しない
+	if _, ok := tracker.nextRefinePixel(now); ok {
+		t.Fatal("refinement should wait for the queue to drain")
+	}
+	for len(tracker.queue) > 0 {
+		<-tracker.queue
+	}
+	tracker.mu.Lock()
+	clear(tracker.pending)
+	tracker.mu.Unlock()
+
+	px, ok := tracker.nextRefinePixel(now)
+	if !ok {
+		t.Fatal("expected a refinement probe")
+	}
+	tracker.handleSampledPixel(px, pixelKey(px.AbsX, px.AbsY), true, &PaintedBy{ID: 2, Name: "bob"}, now)
+
+	tracker.mu.Lock()
+	if total := alice.VandalCount + bob.VandalCount; total != 100 {
+		t.Fatalf("credited %d pixels after refinement, want 100", total)
+	}
+	if estimated := alice.EstimatedVandalCount + bob.EstimatedVandalCount; estimated != 89 {
+		t.Fatalf("estimated %d pixels after refinement, want 89", estimated)
+	}
+	tracker.mu.Unlock()
+
+	// 期限を過ぎたら推定のまま確定する
+	if _, ok := tracker.nextRefinePixel(now.Add(2 * samplingBatchTTL)); ok {
+		t.Fatal("expired batch should not be refined")
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if len(tracker.samplingBatches) != 0 {
+		t.Fatalf("expired batch should be finished: %+v", tracker.samplingBatches)
+	}
+	if alice.VandalCount+bob.VandalCount != 100 {
+		t.Fatal("finishing should keep the estimated credits")
+	}
+}
@@ -0,0 +1,176 @@
+package activity
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// NotifyKindRestricted 既知の荒らしユーザーが BAN / タイムアウトされた
+	NotifyKindRestricted = "restricted"
+
+	// statusRecheckInterval 上位荒らしユーザーの状態を照会し直す間隔（1回1ユーザー）
+	statusRecheckInterval = 30 * time.Second
+	// statusRecheckMinAge 同じユーザーを照会し直すまでの最短間隔
+	statusRecheckMinAge     = 6 * time.Hour
+	defaultStatusRecheckTop = 20
+	// statusHistorySize ユーザーごとに残す状態変化の件数
+	statusHistorySize = 20
+)
+
+// StatusChange wplace 上の BAN / タイムアウト状態の変化
+type StatusChange struct {
+	At       string `json:"at"`
+	Banned   bool   `json:"banned"`
+	TimedOut bool   `json:"timed_out"`
+}
+
+// isKnownVandal 荒らしとして通知済みか、荒らしが修復より多く新規通知の閾値以上
+func isKnownVandal(entry *UserActivity) bool {
+	if entry.VandalNotified {
+		return true
+	}
+	return entry.VandalCount > entry.RestoredCount && entry.VandalCount >= newUserNotifyThreshold
+}
+
+// observePainterStatusLocked 塗り手情報の BAN / タイムアウト状態を記録し、既知の荒らしユーザーが新たに制限されたら true（t.mu を保持して呼ぶ）
+func (t *Tracker) observePainterStatusLocked(entry *UserActivity, painter *PaintedBy, now time.Time) bool {
+	stamp := now.Format(time.RFC3339Nano)
+	entry.StatusCheckedAt = stamp
+	if entry.Banned == painter.Banned && entry.TimedOut == painter.TimedOut {
+		return false
+	}
+	restricted := (painter.Banned && !entry.Banned) || (painter.TimedOut && !entry.TimedOut)
+	entry.Banned = painter.Banned
+	entry.TimedOut = painter.TimedOut
+	entry.StatusHistory = append(entry.StatusHistory, StatusChange{At: stamp, Banned: painter.Banned, TimedOut: painter.TimedOut})
+	if len(entry.StatusHistory) > statusHistorySize {
+		entry.StatusHistory = entry.StatusHistory[len(entry.StatusHistory)-statusHistorySize:]
+	}
+	t.dirtyActivity = true
+	t.dirtyUsers[entry.ID] = struct{}{}
+	log.Printf("activity status changed: user=%s banned=%t timed_out=%t", entry.ID, entry.Banned, entry.TimedOut)
+	return restricted && isKnownVandal(entry)
+}
+
+func (t *Tracker) statusRecheckWorker() {
+	if t.statusRecheckTop <= 0 {
+		return
+	}
+	ticker := time.NewTicker(statusRecheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.recheckVandalStatus(now.UTC())
+		}
+	}
+}
+
+// nextStatusRecheck 状態を照会し直す上位荒らしユーザーと、その人が塗ったピクセル。
+// 照会キュー・抽出照会の残りが無く、429 バックオフ中でないときだけ選ぶ
+func (t *Tracker) nextStatusRecheck(now time.Time) (string, Pixel, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.queue) > 0 || len(t.pending) > 0 || len(t.samplingBatches) > 0 || now.Before(t.backoffUntil) {
+		return "", Pixel{}, false
+	}
+	top := make([]*UserActivity, 0, len(t.activity))
+	for _, entry := range t.activity {
+		if entry.VandalCount > 0 {
+			top = append(top, entry)
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].VandalCount == top[j].VandalCount {
+			return top[i].ID < top[j].ID
+		}
+		return top[i].VandalCount > top[j].VandalCount
+	})
+	if len(top) > t.statusRecheckTop {
+		top = top[:t.statusRecheckTop]
+	}
+
+	// 現在も荒らし中のピクセルを優先し、無ければ最後に塗ったピクセルを照会する
+	wanted := make(map[string]bool, len(top))
+	for _, entry := range top {
+		wanted[entry.ID] = true
+	}
+	pixels := make(map[string]Pixel, len(top))
+	for key, painterID := range t.vandalState.PixelToPainter {
+		if _, done := pixels[painterID]; done || !wanted[painterID] {
+			continue
+		}
+		if px, ok := t.currentDiff[key]; ok {
+			pixels[painterID] = px
+		}
+	}
+	for _, entry := range top {
+		if _, ok := pixels[entry.ID]; !ok && entry.LastPixel != nil {
+			pixels[entry.ID] = Pixel{AbsX: entry.LastPixel.X, AbsY: entry.LastPixel.Y}
+		}
+	}
+
+	var pick *UserActivity
+	var pickChecked time.Time
+	for _, entry := range top {
+		if _, ok := pixels[entry.ID]; !ok {
+			continue
+		}
+		checked, _ := time.Parse(time.RFC3339Nano, entry.StatusCheckedAt)
+		if now.Sub(checked) < statusRecheckMinAge {
+			continue
+		}
+		if pick == nil || checked.Before(pickChecked) {
+			pick, pickChecked = entry, checked
+		}
+	}
+	if pick == nil {
+		return "", Pixel{}, false
+	}
+	// 照会に失敗しても同じユーザーを選び続けないよう、先に照会時刻を進める
+	pick.StatusCheckedAt = now.Format(time.RFC3339Nano)
+	return pick.ID, pixels[pick.ID], true
+}
+
+// recheckVandalStatus 上位荒らしユーザー1人の状態を照会し直す（ピクセルが塗り替えられていたら何もしない）
+func (t *Tracker) recheckVandalStatus(now time.Time) {
+	userID, px, ok := t.nextStatusRecheck(now)
+	if !ok {
+		return
+	}
+	painter, err := t.fetchPainter(px)
+	if err != nil {
+		log.Printf("activity status recheck error for user %s: %v", userID, err)
+		return
+	}
+	if painter == nil || strconv.Itoa(painter.ID) != userID {
+		activityDebugf("activity status recheck: pixel of user %s was repainted", userID)
+		return
+	}
+
+	t.mu.Lock()
+	entry := t.activity[userID]
+	if entry == nil {
+		t.mu.Unlock()
+		return
+	}
+	applyPainterProfile(entry, painter)
+	restricted := t.observePainterStatusLocked(entry, painter, time.Now().UTC())
+	t.dirtyActivity = true
+	t.dirtyUsers[userID] = struct{}{}
+	cb := t.newUserCB
+	var userCopy UserActivity
+	if restricted {
+		userCopy = cloneUserActivity(entry)
+	}
+	t.mu.Unlock()
+
+	if restricted && cb != nil {
+		cb(NotifyKindRestricted, userCopy)
+	}
+}
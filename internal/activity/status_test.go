@@ -0,0 +1,93 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestObservePainterStatusRecordsChanges(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker(Config{Width: 10, Height: 10}, nil, "")
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	vandal := tracker.activityEntryLocked("1")
+	vandal.VandalCount = 40
+	fixer := tracker.activityEntryLocked("2")
+	fixer.RestoredCount = 40
+	fixer.VandalCount = 6
+
+	if tracker.observePainterStatusLocked(vandal, &PaintedBy{ID: 1}, base) {
+		t.Fatal("unchanged status should not notify")
+	}
+	if len(vandal.StatusHistory) != 0 || vandal.StatusCheckedAt == "" {
+		t.Fatalf("unchanged status should only update the check time: %+v", vandal)
+	}
+	if !tracker.observePainterStatusLocked(vandal, &PaintedBy{ID: 1, TimedOut: true}, base.Add(time.Hour)) {
+		t.Fatal("known vandal getting timed out should notify")
+	}
+	if tracker.observePainterStatusLocked(vandal, &PaintedBy{ID: 1, TimedOut: true}, base.Add(2*time.Hour)) {
+		t.Fatal("same status should notify only once")
+	}
+	if tracker.observePainterStatusLocked(vandal, &PaintedBy{ID: 1}, base.Add(3*time.Hour)) {
+		t.Fatal("lifting a restriction should not notify")
+	}
+	if !tracker.observePainterStatusLocked(vandal, &PaintedBy{ID: 1, Banned: true}, base.Add(4*time.Hour)) {
+		t.Fatal("known vandal getting banned should notify")
+	}
+	if len(vandal.StatusHistory) != 3 || !vandal.Banned || vandal.TimedOut {
+		t.Fatalf("unexpected status history: banned=%t timed_out=%t %+v", vandal.Banned, vandal.TimedOut, vandal.StatusHistory)
+	}
+
+	if tracker.observePainterStatusLocked(fixer, &PaintedBy{ID: 2, Banned: true}, base) {
+		t.Fatal("mostly restoring user is not a known vandal")
+	}
+	if !fixer.Banned || len(fixer.StatusHistory) != 1 {
+		t.Fatalf("status should still be recorded: %+v", fixer.StatusHistory)
+	}
+}
+
+func TestNextStatusRecheckPicksTopVandals(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker(Config{Width: 10, Height: 10}, nil, "")
+	tracker.statusRecheckTop = 2
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker.mu.Lock()
+	top := tracker.activityEntryLocked("1")
+	top.VandalCount = 50
+	top.LastPixel = &PixelRef{X: 1, Y: 1}
+	second := tracker.activityEntryLocked("2")
+	second.VandalCount = 30
+	second.LastPixel = &PixelRef{X: 2, Y: 2}
+	second.StatusCheckedAt = now.Add(-time.Hour).Format(time.RFC3339Nano)
+	third := tracker.activityEntryLocked("3")
+	third.VandalCount = 10
+	third.LastPixel = &PixelRef{X: 3, Y: 3}
+	// 現在も荒らし中のピクセルは最後に塗ったピクセルより優先する
+	tracker.currentDiff[pixelKey(5, 5)] = Pixel{AbsX: 5, AbsY: 5}
+	tracker.vandalState.PixelToPainter[pixelKey(5, 5)] = "1"
+	tracker.mu.Unlock()
+
+	userID, px, ok := tracker.nextStatusRecheck(now)
+	if !ok || userID != "1" || px != (Pixel{AbsX: 5, AbsY: 5}) {
+		t.Fatalf("expected top vandal at its vandalized pixel, got %q %+v %t", userID, px, ok)
+	}
+	// 2位は最近確認済み、3位は対象外
+	if userID, _, ok := tracker.nextStatusRecheck(now); ok {
+		t.Fatalf("no user should be due, got %q", userID)
+	}
+	if userID, _, ok := tracker.nextStatusRecheck(now.Add(statusRecheckMinAge)); !ok || userID != "2" {
+		t.Fatalf("expected the least recently checked vandal, got %q %t", userID, ok)
+	}
+
+	tracker.mu.Lock()
+	tracker.pending[pixelKey(0, 0)] = Pixel{}
+	tracker.mu.Unlock()
+	if _, _, ok := tracker.nextStatusRecheck(now.Add(48 * time.Hour)); ok {
+		t.Fatal("recheck should wait while attribution lookups are pending")
+	}
+}
@@ -1,31 +1,162 @@
 package activity
 
 import (
+	"Koukyo_discord_bot/internal/storage"
 	"Koukyo_discord_bot/internal/utils"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
-var userActivityFileMu sync.Mutex
+const (
+	// UserActivityFile ユーザーごとの活動統計
+	UserActivityFile = "user_activity.json"
+	// VandalStateFile 荒らし中のピクセルと塗り手
+	VandalStateFile = "vandalized_pixels.json"
+	// DailyCountsFile 日ごとの荒らし/修復ピクセル数
+	DailyCountsFile = "vandal_daily.json"
+)
 
-func LoadUserActivityMap(dataDir string) (map[string]*UserActivity, error) {
+// Repository ユーザー活動データの保存先（STORAGE_BACKEND で JSON ファイルか組み込み DB を選ぶ）
+type Repository interface {
+	// LoadUsers 全ユーザー（無ければ空）
+	LoadUsers() (map[string]*UserActivity, error)
+	// GetUser ID で1人（無ければ nil）
+	GetUser(id string) (*UserActivity, error)
+	// FindUsersByName wplace の名前の部分一致（大文字小文字を区別しない）
+	FindUsersByName(query string) (map[string]*UserActivity, error)
+	// FindUsersByDiscordName 連携した Discord 名の部分一致（大文字小文字を区別しない）
+	FindUsersByDiscordName(query string) (map[string]*UserActivity, error)
+	// FindUsersByDiscordID 連携した Discord ID の完全一致
+	FindUsersByDiscordID(discordID string) (map[string]*UserActivity, error)
+	// FindUsersByAlliance 所属同盟 ID の完全一致
+	FindUsersByAlliance(allianceID int) (map[string]*UserActivity, error)
+	// SaveUsers changed のユーザーを書き、deleted を消す（JSON ファイルは all を丸ごと書き直す）
+	SaveUsers(all map[string]*UserActivity, changed, deleted []string) error
+	// UpdateUsers 全ユーザーを読み、update で変えた分を保存する
+	UpdateUsers(update func(map[string]*UserActivity) error) error
+	// LoadState vandalized_pixels.json などの状態を読む。recovered はバックアップから復元したか（無ければ os.ErrNotExist）
+	LoadState(name string, v any) (recovered bool, err error)
+	// SaveState 状態を書く
+	SaveState(name string, data []byte) error
+}
+
+// OpenRepository dataDir の保存先を開く
+func OpenRepository(dataDir string) (Repository, error) {
 	if dataDir == "" {
 		return nil, fmt.Errorf("dataDir is empty")
 	}
-	path := filepath.Join(dataDir, "user_activity.json")
+	if storage.Backend() == storage.BackendBolt {
+		db, err := storage.Open(dataDir)
+		if err != nil {
+			return nil, err
+		}
+		return newBoltRepository(db)
+	}
+	return &jsonRepository{dataDir: dataDir}, nil
+}
+
+func LoadUserActivityMap(dataDir string) (map[string]*UserActivity, error) {
+	repo, err := OpenRepository(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return repo.LoadUsers()
+}
+
+func UpdateUserActivityMap(dataDir string, update func(map[string]*UserActivity) error) error {
+	repo, err := OpenRepository(dataDir)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateUsers(update)
+}
+
+// matchUserName 名前の部分一致（query は小文字）
+func matchUserName(name, query string) bool {
+	return name != "" && strings.Contains(strings.ToLower(name), query)
+}
+
+var userActivityFileMu sync.Mutex
+
+// jsonRepository データディレクトリ直下の JSON ファイル（書き込みは常に丸ごと）
+type jsonRepository struct {
+	dataDir string
+}
+
+func (r *jsonRepository) LoadUsers() (map[string]*UserActivity, error) {
 	userActivityFileMu.Lock()
 	defer userActivityFileMu.Unlock()
-	return loadUserActivityMapUnlocked(path)
+	return loadUserActivityMapUnlocked(filepath.Join(r.dataDir, UserActivityFile))
 }
 
-func UpdateUserActivityMap(dataDir string, update func(map[string]*UserActivity) error) error {
-	if dataDir == "" {
+func (r *jsonRepository) GetUser(id string) (*UserActivity, error) {
+	raw, err := r.LoadUsers()
+	if err != nil {
+		return nil, err
+	}
+	return raw[id], nil
+}
+
+func (r *jsonRepository) filterUsers(match func(*UserActivity) bool) (map[string]*UserActivity, error) {
+	raw, err := r.LoadUsers()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]*UserActivity)
+	for id, entry := range raw {
+		if entry != nil && match(entry) {
+			out[id] = entry
+		}
+	}
+	return out, nil
+}
+
+func (r *jsonRepository) FindUsersByName(query string) (map[string]*UserActivity, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, nil
+	}
+	return r.filterUsers(func(e *UserActivity) bool { return matchUserName(e.Name, query) })
+}
+
+func (r *jsonRepository) FindUsersByDiscordName(query string) (map[string]*UserActivity, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, nil
+	}
+	return r.filterUsers(func(e *UserActivity) bool { return matchUserName(e.Discord, query) })
+}
+
+func (r *jsonRepository) FindUsersByDiscordID(discordID string) (map[string]*UserActivity, error) {
+	discordID = strings.TrimSpace(discordID)
+	if discordID == "" {
+		return nil, nil
+	}
+	return r.filterUsers(func(e *UserActivity) bool { return strings.EqualFold(e.DiscordID, discordID) })
+}
+
+func (r *jsonRepository) FindUsersByAlliance(allianceID int) (map[string]*UserActivity, error) {
+	if allianceID == 0 {
+		return nil, nil
+	}
+	return r.filterUsers(func(e *UserActivity) bool { return e.AllianceID == allianceID })
+}
+
+func (r *jsonRepository) SaveUsers(all map[string]*UserActivity, changed, deleted []string) error {
+	if r.dataDir == "" {
 		return fmt.Errorf("dataDir is empty")
 	}
-	path := filepath.Join(dataDir, "user_activity.json")
+	userActivityFileMu.Lock()
+	defer userActivityFileMu.Unlock()
+	return saveUserActivityMapUnlocked(filepath.Join(r.dataDir, UserActivityFile), all)
+}
+
+func (r *jsonRepository) UpdateUsers(update func(map[string]*UserActivity) error) error {
+	path := filepath.Join(r.dataDir, UserActivityFile)
 	userActivityFileMu.Lock()
 	defer userActivityFileMu.Unlock()
 
@@ -42,14 +173,20 @@ func UpdateUserActivityMap(dataDir string, update func(map[string]*UserActivity)
 	return saveUserActivityMapUnlocked(path, raw)
 }
 
-func saveUserActivityMap(dataDir string, raw map[string]*UserActivity) error {
-	if dataDir == "" {
+func (r *jsonRepository) LoadState(name string, v any) (bool, error) {
+	path := filepath.Join(r.dataDir, name)
+	source, err := utils.ReadJSONFileWithBackup(path, v)
+	if err != nil {
+		return false, err
+	}
+	return source == utils.BackupPath(path), nil
+}
+
+func (r *jsonRepository) SaveState(name string, data []byte) error {
+	if r.dataDir == "" {
 		return fmt.Errorf("dataDir is empty")
 	}
-	path := filepath.Join(dataDir, "user_activity.json")
-	userActivityFileMu.Lock()
-	defer userActivityFileMu.Unlock()
-	return saveUserActivityMapUnlocked(path, raw)
+	return utils.WriteFileAtomic(filepath.Join(r.dataDir, name), data)
 }
 
 func loadUserActivityMapUnlocked(path string) (map[string]*UserActivity, error) {
@@ -0,0 +1,366 @@
+package activity
+
+import (
+	"Koukyo_discord_bot/internal/storage"
+	"Koukyo_discord_bot/internal/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	usersBucket = []byte("users")
+	stateBucket = []byte("activity_state")
+
+	// 索引のキーは「小文字の値 \x00 ユーザーID」（値は空）
+	nameIndexBucket        = []byte("idx_user_name")
+	discordNameIndexBucket = []byte("idx_user_discord_name")
+	discordIDIndexBucket   = []byte("idx_user_discord_id")
+	allianceIndexBucket    = []byte("idx_user_alliance")
+
+	activityBuckets = [][]byte{usersBucket, stateBucket, nameIndexBucket, discordNameIndexBucket, discordIDIndexBucket, allianceIndexBucket}
+)
+
+func init() {
+	storage.RegisterMigration(storage.Migration{Name: "activity-json", Run: migrateActivityJSON})
+}
+
+// boltRepository 組み込み DB。ユーザーは1件ずつ保存し、名前・Discord・同盟の索引を持つ
+type boltRepository struct {
+	db *bolt.DB
+}
+
+func newBoltRepository(db *bolt.DB) (*boltRepository, error) {
+	ready := true
+	_ = db.View(func(tx *bolt.Tx) error {
+		for _, name := range activityBuckets {
+			if tx.Bucket(name) == nil {
+				ready = false
+			}
+		}
+		return nil
+	})
+	if !ready {
+		if err := db.Update(createActivityBuckets); err != nil {
+			return nil, err
+		}
+	}
+	return &boltRepository{db: db}, nil
+}
+
+func createActivityBuckets(tx *bolt.Tx) error {
+	for _, name := range activityBuckets {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *boltRepository) LoadUsers() (map[string]*UserActivity, error) {
+	out := make(map[string]*UserActivity)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).ForEach(func(k, v []byte) error {
+			var entry UserActivity
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("decode user %s: %w", k, err)
+			}
+			out[string(k)] = &entry
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (r *boltRepository) GetUser(id string) (*UserActivity, error) {
+	var entry *UserActivity
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		entry, err = getUser(tx, id)
+		return err
+	})
+	return entry, err
+}
+
+func (r *boltRepository) FindUsersByName(query string) (map[string]*UserActivity, error) {
+	return r.scanIndex(nameIndexBucket, strings.ToLower(strings.TrimSpace(query)))
+}
+
+func (r *boltRepository) FindUsersByDiscordName(query string) (map[string]*UserActivity, error) {
+	return r.scanIndex(discordNameIndexBucket, strings.ToLower(strings.TrimSpace(query)))
+}
+
+func (r *boltRepository) FindUsersByDiscordID(discordID string) (map[string]*UserActivity, error) {
+	return r.seekIndex(discordIDIndexBucket, strings.ToLower(strings.TrimSpace(discordID)))
+}
+
+func (r *boltRepository) FindUsersByAlliance(allianceID int) (map[string]*UserActivity, error) {
+	if allianceID == 0 {
+		return nil, nil
+	}
+	return r.seekIndex(allianceIndexBucket, strconv.Itoa(allianceID))
+}
+
+// seekIndex 索引の値が value と一致するユーザー
+func (r *boltRepository) seekIndex(bucket []byte, value string) (map[string]*UserActivity, error) {
+	if value == "" {
+		return nil, nil
+	}
+	prefix := []byte(value + "\x00")
+	return r.collectIndex(bucket, func(c *bolt.Cursor, visit func(k []byte) error) error {
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			if err := visit(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// scanIndex 索引の値に query を含むユーザー（索引のキーだけを走査し、本体は一致した分だけ読む）
+func (r *boltRepository) scanIndex(bucket []byte, query string) (map[string]*UserActivity, error) {
+	if query == "" {
+		return nil, nil
+	}
+	return r.collectIndex(bucket, func(c *bolt.Cursor, visit func(k []byte) error) error {
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			value, _, ok := splitIndexKey(k)
+			if !ok || !strings.Contains(value, query) {
+				continue
+			}
+			if err := visit(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *boltRepository) collectIndex(bucket []byte, walk func(c *bolt.Cursor, visit func(k []byte) error) error) (map[string]*UserActivity, error) {
+	out := make(map[string]*UserActivity)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return walk(tx.Bucket(bucket).Cursor(), func(k []byte) error {
+			_, id, ok := splitIndexKey(k)
+			if !ok {
+				return nil
+			}
+			entry, err := getUser(tx, id)
+			if err != nil {
+				return err
+			}
+			if entry != nil {
+				out[id] = entry
+			}
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (r *boltRepository) SaveUsers(all map[string]*UserActivity, changed, deleted []string) error {
+	if len(changed) == 0 && len(deleted) == 0 {
+		return nil
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		for _, id := range changed {
+			entry := all[id]
+			if entry == nil {
+				continue
+			}
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if err := putUser(tx, id, data); err != nil {
+				return err
+			}
+		}
+		for _, id := range deleted {
+			if _, ok := all[id]; ok {
+				continue
+			}
+			if err := deleteUser(tx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *boltRepository) UpdateUsers(update func(map[string]*UserActivity) error) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		users := tx.Bucket(usersBucket)
+		before := make(map[string][]byte)
+		raw := make(map[string]*UserActivity)
+		err := users.ForEach(func(k, v []byte) error {
+			var entry UserActivity
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("decode user %s: %w", k, err)
+			}
+			before[string(k)] = bytes.Clone(v)
+			raw[string(k)] = &entry
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := update(raw); err != nil {
+			return err
+		}
+		for id, entry := range raw {
+			if entry == nil {
+				continue
+			}
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if bytes.Equal(before[id], data) {
+				continue
+			}
+			if err := putUser(tx, id, data); err != nil {
+				return err
+			}
+		}
+		for id := range before {
+			if raw[id] == nil {
+				if err := deleteUser(tx, id); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (r *boltRepository) LoadState(name string, v any) (bool, error) {
+	err := r.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(stateBucket).Get([]byte(name))
+		if data == nil {
+			return fmt.Errorf("%s: %w", name, os.ErrNotExist)
+		}
+		return json.Unmarshal(data, v)
+	})
+	return false, err
+}
+
+func (r *boltRepository) SaveState(name string, data []byte) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateBucket).Put([]byte(name), data)
+	})
+}
+
+func getUser(tx *bolt.Tx, id string) (*UserActivity, error) {
+	data := tx.Bucket(usersBucket).Get([]byte(id))
+	if data == nil {
+		return nil, nil
+	}
+	var entry UserActivity
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("decode user %s: %w", id, err)
+	}
+	return &entry, nil
+}
+
+// putUser 本体を書き、前の値の索引を付け替える
+func putUser(tx *bolt.Tx, id string, data []byte) error {
+	if err := deleteUser(tx, id); err != nil {
+		return err
+	}
+	var entry UserActivity
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+	if err := tx.Bucket(usersBucket).Put([]byte(id), data); err != nil {
+		return err
+	}
+	for bucket, key := range userIndexKeys(id, &entry) {
+		if err := tx.Bucket([]byte(bucket)).Put(key, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteUser(tx *bolt.Tx, id string) error {
+	prev, err := getUser(tx, id)
+	if err != nil || prev == nil {
+		return err
+	}
+	for bucket, key := range userIndexKeys(id, prev) {
+		if err := tx.Bucket([]byte(bucket)).Delete(key); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(usersBucket).Delete([]byte(id))
+}
+
+// userIndexKeys 索引バケット名→キー（値が空の項目は索引しない）
+func userIndexKeys(id string, entry *UserActivity) map[string][]byte {
+	keys := make(map[string][]byte, 4)
+	add := func(bucket []byte, value string) {
+		if value != "" {
+			keys[string(bucket)] = []byte(value + "\x00" + id)
+		}
+	}
+	add(nameIndexBucket, strings.ToLower(strings.TrimSpace(entry.Name)))
+	add(discordNameIndexBucket, strings.ToLower(strings.TrimSpace(entry.Discord)))
+	add(discordIDIndexBucket, strings.ToLower(strings.TrimSpace(entry.DiscordID)))
+	if entry.AllianceID != 0 {
+		add(allianceIndexBucket, strconv.Itoa(entry.AllianceID))
+	}
+	return keys
+}
+
+func splitIndexKey(key []byte) (string, string, bool) {
+	value, id, ok := bytes.Cut(key, []byte{0})
+	if !ok {
+		return "", "", false
+	}
+	return string(value), string(id), true
+}
+
+// migrateActivityJSON user_activity.json / vandalized_pixels.json / vandal_daily.json を DB へ取り込む（JSON ファイルはそのまま残す）
+func migrateActivityJSON(dataDir string, tx *bolt.Tx) error {
+	if err := createActivityBuckets(tx); err != nil {
+		return err
+	}
+	var entries map[string]*UserActivity
+	_, err := utils.ReadJSONFileWithBackup(filepath.Join(dataDir, UserActivityFile), &entries)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for id, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if err := putUser(tx, id, data); err != nil {
+			return err
+		}
+	}
+	for _, name := range []string{VandalStateFile, DailyCountsFile} {
+		var state json.RawMessage
+		_, err := utils.ReadJSONFileWithBackup(filepath.Join(dataDir, name), &state)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(stateBucket).Put([]byte(name), state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
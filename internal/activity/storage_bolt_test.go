@@ -0,0 +1,105 @@
+package activity
+
+import (
+	"Koukyo_discord_bot/internal/storage"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBoltRepositoryMigratesAndIndexes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile := func(name, body string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(UserActivityFile, `{
+		"1": {"id": "1", "name": "Alice", "allianceId": 10, "discord": "alice_dc", "discord_id": "111", "vandal_count": 3},
+		"2": {"id": "2", "name": "bob", "allianceId": 10, "restored_count": 5},
+		"3": {"id": "3", "name": "Malice", "discord_id": "333"}
+	}`)
+	writeFile(DailyCountsFile, `{"vandal": {"2026-03-14": 3}, "fix": {}}`)
+
+	db, err := storage.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = storage.Close(dir) })
+	repo, err := newBoltRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := repo.LoadUsers()
+	if err != nil || len(all) != 3 || all["1"].VandalCount != 3 {
+		t.Fatalf("migrated users: %+v (%v)", all, err)
+	}
+	var daily DailyPixelCounts
+	if _, err := repo.LoadState(DailyCountsFile, &daily); err != nil || daily.Vandal["2026-03-14"] != 3 {
+		t.Fatalf("migrated daily counts: %+v (%v)", daily, err)
+	}
+	if _, err := repo.LoadState(VandalStateFile, &VandalState{}); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing state should be ErrNotExist, got %v", err)
+	}
+
+	if got, _ := repo.FindUsersByName("ALICE"); len(got) != 2 || got["1"] == nil || got["3"] == nil {
+		t.Errorf("name search: %+v", got)
+	}
+	if got, _ := repo.FindUsersByDiscordName("alice_"); len(got) != 1 || got["1"] == nil {
+		t.Errorf("discord name search: %+v", got)
+	}
+	if got, _ := repo.FindUsersByDiscordID("333"); len(got) != 1 || got["3"] == nil {
+		t.Errorf("discord id search: %+v", got)
+	}
+	if got, _ := repo.FindUsersByAlliance(10); len(got) != 2 {
+		t.Errorf("alliance search: %+v", got)
+	}
+
+	// 名前と同盟を変えたら索引も付け替わる
+	all["2"].Name = "Robert"
+	all["2"].AllianceID = 20
+	if err := repo.SaveUsers(all, []string{"2"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.FindUsersByName("bob"); len(got) != 0 {
+		t.Errorf("old name should not match: %+v", got)
+	}
+	if got, _ := repo.FindUsersByName("robert"); len(got) != 1 || got["2"].AllianceID != 20 {
+		t.Errorf("renamed user: %+v", got)
+	}
+	if got, _ := repo.FindUsersByAlliance(10); len(got) != 1 {
+		t.Errorf("old alliance index should be removed: %+v", got)
+	}
+
+	err = repo.UpdateUsers(func(raw map[string]*UserActivity) error {
+		delete(raw, "3")
+		raw["4"] = &UserActivity{ID: "4", Name: "dave", DiscordID: "333"}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.FindUsersByDiscordID("333"); len(got) != 1 || got["4"] == nil {
+		t.Errorf("discord id after update: %+v", got)
+	}
+	if entry, _ := repo.GetUser("3"); entry != nil {
+		t.Errorf("deleted user still present: %+v", entry)
+	}
+
+	// 2回目以降に開いても JSON ファイルから取り込み直さない
+	if err := storage.Close(dir); err != nil {
+		t.Fatal(err)
+	}
+	db, err = storage.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, _ = newBoltRepository(db)
+	if entry, _ := repo.GetUser("3"); entry != nil {
+		t.Errorf("migration ran twice: %+v", entry)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
 	"sort"
 	"strconv"
 	"sync"
@@ -70,12 +69,25 @@ type UserActivity struct {
 	LastPixel           *PixelRef      `json:"last_pixel,omitempty"`
 	VandalNotified      bool           `json:"vandal_notified,omitempty"`
 	FixNotified         bool           `json:"fix_notified,omitempty"`
+	// 抽出照会による推定で計上した分（VandalCount / RestoredCount に含まれる）
+	EstimatedVandalCount   int `json:"estimated_vandal_count,omitempty"`
+	EstimatedRestoredCount int `json:"estimated_restored_count,omitempty"`
+	// 自動描画（bot/スクリプト）の疑い（一度立ったらモデレーターの確認結果とともに残す）
+	SuspectedBot *BotSuspicion `json:"suspected_bot,omitempty"`
+	// wplace 上の BAN / タイムアウト状態と変化の履歴（StatusCheckedAt は最後に確認した時刻）
+	Banned          bool           `json:"banned,omitempty"`
+	TimedOut        bool           `json:"timed_out,omitempty"`
+	StatusHistory   []StatusChange `json:"status_history,omitempty"`
+	StatusCheckedAt string         `json:"status_checked_at,omitempty"`
 }
 
 type PainterPixelCount struct {
-	UserID string `json:"user_id"`
-	Name   string `json:"name,omitempty"`
-	Pixels int    `json:"pixels"`
+	UserID    string `json:"user_id"`
+	Name      string `json:"name,omitempty"`
+	Pixels    int    `json:"pixels"`
+	Estimated int    `json:"estimated,omitempty"`     // Pixels のうち抽出照会による推定分
+	Low       int    `json:"estimated_low,omitempty"` // 推定分がある場合の Pixels の95%信頼区間
+	High      int    `json:"estimated_high,omitempty"`
 }
 
 type VandalState struct {
@@ -120,6 +132,7 @@ type Tracker struct {
 	diffQueue    chan []byte
 	ctx          context.Context
 	cancel       context.CancelFunc
+	workers      sync.WaitGroup // Start で起動したワーカー（Stop で終了を待つ）
 	mu           sync.Mutex
 	currentDiff  map[string]Pixel
 	activity     map[string]*UserActivity
@@ -131,6 +144,8 @@ type Tracker struct {
 	recentVandalEvents map[string][]time.Time
 	recentFixEvents    map[string][]time.Time
 	dirtyActivity      bool
+	dirtyUsers         map[string]struct{} // 前回の保存以降に変わったユーザー
+	deletedUsers       map[string]struct{} // 前回の保存以降に GC で消したユーザー
 	dirtyVandalState   bool
 	dirtyDailyCounts   bool
 	flushInterval      time.Duration
@@ -138,10 +153,25 @@ type Tracker struct {
 	activityGCInterval time.Duration
 	powerSaveInference powerSaveInferenceState
 	restoreInference   powerSaveInferenceState
-}
-
+	journal            *PixelJournal
+	repo               Repository
+	samplingMinPixels  int // 照会対象がこれ以上なら抽出照会に切り替える（0 で無効）
+	sampleSize         int
+	samplingBatches    []*samplingBatch
+	samplingProbes     map[string]samplingProbe
+	detectedAt         map[string]time.Time          // 照会待ちのピクセルが差分に現れた/消えた時刻
+	paintLog           map[string][]paintObservation // bot 判定用の確定帰属の履歴
+	lastFrameAt        time.Time                     // 直前に差分画像を受け取った時刻
+	frameInterval      time.Duration                 // 直近の差分画像の受信間隔（帰属時刻の分解能）
+	statusRecheckTop   int                           // 状態を照会し直す上位荒らしユーザー数（0 で無効）
+}
+
+// NewUserCallback kind は "vandal" / "fix"（新規ユーザー）、NotifyKindBot（bot の疑い）、NotifyKindRestricted（既知の荒らしの BAN / タイムアウト）
 type NewUserCallback func(kind string, user UserActivity)
 
+// NotifyKindBot 自動描画の疑いが新たに立った
+const NotifyKindBot = "bot"
+
 var activityDebugLogging = os.Getenv("ACTIVITY_DEBUG_LOG") == "1"
 
 func activityDebugf(format string, args ...interface{}) {
@@ -174,15 +204,93 @@ func NewTracker(cfg Config, limiter *utils.RateLimiter, dataDir string) *Tracker
 		backoffDelay:       2 * time.Second,
 		recentVandalEvents: make(map[string][]time.Time),
 		recentFixEvents:    make(map[string][]time.Time),
+		dirtyUsers:         make(map[string]struct{}),
+		deletedUsers:       make(map[string]struct{}),
 		flushInterval:      loadDurationFromEnv("ACTIVITY_FLUSH_INTERVAL_SECONDS", defaultStateFlushInterval, time.Second, 10*time.Minute),
 		recentGCInterval:   loadDurationFromEnv("ACTIVITY_RECENT_GC_INTERVAL_SECONDS", defaultRecentEventsInterval, 10*time.Second, 10*time.Minute),
 		activityGCInterval: loadDurationFromEnv("ACTIVITY_GC_INTERVAL_SECONDS", defaultActivityGCInterval, 1*time.Hour, 7*24*time.Hour),
+		journal:            NewPixelJournal(dataDir, loadDaysFromEnv("PIXEL_JOURNAL_RETENTION_DAYS", defaultJournalRetention, 1, 365)),
+		samplingMinPixels:  loadIntFromEnv("ATTRIBUTION_SAMPLING_MIN_PIXELS", defaultSamplingMinPixels, 0, 100000),
+		sampleSize:         loadIntFromEnv("ATTRIBUTION_SAMPLE_SIZE", defaultSampleSize, 5, 1000),
+		samplingProbes:     make(map[string]samplingProbe),
+		detectedAt:         make(map[string]time.Time),
+		paintLog:           make(map[string][]paintObservation),
+		statusRecheckTop:   loadIntFromEnv("STATUS_RECHECK_TOP_VANDALS", defaultStatusRecheckTop, 0, 500),
+	}
+	repo, err := OpenRepository(dataDir)
+	if err != nil {
+		if dataDir != "" {
+			log.Printf("failed to open activity storage, falling back to JSON files: %v", err)
+		}
+		repo = &jsonRepository{dataDir: dataDir}
 	}
+	t.repo = repo
 	t.loadState()
 	t.loadDailyCounts()
 	return t
 }
 
+// TrackerStats キュー・保留・429バックオフの状態
+type TrackerStats struct {
+	Queued           int           // ピクセルAPI照会待ちのキュー長
+	Pending          int           // 照会待ち/照会中のピクセル数
+	CurrentDiff      int           // 現在の差分ピクセル数
+	BackoffActive    bool          // 429 によるバックオフ中か
+	BackoffRemaining time.Duration // バックオフの残り時間
+	BackoffDelay     time.Duration // 次に 429 を受けたときのバックオフ時間
+	SamplingPixels   int           // 抽出照会で推定中のうち、まだ照会していないピクセル数
+}
+
+// Stats 現在の処理状況を返す
+func (t *Tracker) Stats() TrackerStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := TrackerStats{
+		Queued:       len(t.queue),
+		Pending:      len(t.pending),
+		CurrentDiff:  len(t.currentDiff),
+		BackoffDelay: t.backoffDelay,
+	}
+	for _, batch := range t.samplingBatches {
+		stats.SamplingPixels += len(batch.Remaining)
+	}
+	if remaining := time.Until(t.backoffUntil); !t.backoffUntil.IsZero() && remaining > 0 {
+		stats.BackoffActive = true
+		stats.BackoffRemaining = remaining
+	}
+	return stats
+}
+
+// ActivityCount ユーザーごとの累計荒らし/修復数
+type ActivityCount struct {
+	Name     string
+	Alliance string
+	Vandal   int
+	Restored int
+}
+
+// ActivityCounts 全ユーザーの累計荒らし/修復数のコピーを返す（期間内の増分計算用）
+func (t *Tracker) ActivityCounts() map[string]ActivityCount {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]ActivityCount, len(t.activity))
+	for id, entry := range t.activity {
+		if entry == nil {
+			continue
+		}
+		out[id] = ActivityCount{
+			Name:     entry.Name,
+			Alliance: entry.AllianceName,
+			Vandal:   entry.VandalCount,
+			Restored: entry.RestoredCount,
+		}
+	}
+	return out
+}
+
 func (t *Tracker) SetNewUserCallback(cb NewUserCallback) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -203,15 +311,24 @@ func (t *Tracker) ArmPowerSaveResumeInference(diffPixels int) {
 }
 
 func (t *Tracker) Start() {
-	go t.runWorker("worker", t.worker)
-	go t.runWorker("diffWorker", t.diffWorker)
-	go t.runWorker("flushWorker", t.flushWorker)
-	go t.runWorker("recentEventsGCWorker", t.recentEventsGCWorker)
-	go t.runWorker("activityGCWorker", t.activityGCWorker)
+	t.startWorker("worker", t.worker)
+	t.startWorker("diffWorker", t.diffWorker)
+	t.startWorker("flushWorker", t.flushWorker)
+	t.startWorker("recentEventsGCWorker", t.recentEventsGCWorker)
+	t.startWorker("activityGCWorker", t.activityGCWorker)
+	t.startWorker("samplingRefineWorker", t.samplingRefineWorker)
+	t.startWorker("statusRecheckWorker", t.statusRecheckWorker)
+}
+
+func (t *Tracker) startWorker(name string, fn func()) {
+	t.workers.Go(func() { t.runWorker(name, fn) })
 }
 
+// Stop ワーカーを止めて終了を待ち、未保存の状態を書き出す
 func (t *Tracker) Stop() {
 	t.cancel()
+	t.workers.Wait()
+	t.flushDirtyState()
 }
 
 func (t *Tracker) GetCurrentDiffPainterCounts(limit int) []PainterPixelCount {
@@ -220,7 +337,7 @@ func (t *Tracker) GetCurrentDiffPainterCounts(limit int) []PainterPixelCount {
 	}
 
 	t.mu.Lock()
-	if len(t.vandalState.PixelToPainter) == 0 {
+	if len(t.vandalState.PixelToPainter) == 0 && len(t.samplingBatches) == 0 {
 		t.mu.Unlock()
 		return nil
 	}
@@ -231,6 +348,24 @@ func (t *Tracker) GetCurrentDiffPainterCounts(limit int) []PainterPixelCount {
 		}
 		countsByUser[userID]++
 	}
+	// 塗り手未確定のピクセルは推定中の按分で数える
+	estimatedByUser := make(map[string]int)
+	lowByUser := make(map[string]int)  // 推定分の区間の下限と点推定との差
+	highByUser := make(map[string]int) // 同じく上限との差
+	for _, batch := range t.samplingBatches {
+		if batch.Kind != JournalKindVandal {
+			continue
+		}
+		for userID, count := range batch.Estimated {
+			if count > 0 {
+				countsByUser[userID] += count
+				estimatedByUser[userID] += count
+				low, high := batch.estimateRange(userID)
+				lowByUser[userID] += low - count
+				highByUser[userID] += high - count
+			}
+		}
+	}
 	nameByUser := make(map[string]string, len(countsByUser))
 	for userID := range countsByUser {
 		if entry := t.activity[userID]; entry != nil {
@@ -241,11 +376,17 @@ func (t *Tracker) GetCurrentDiffPainterCounts(limit int) []PainterPixelCount {
 
 	list := make([]PainterPixelCount, 0, len(countsByUser))
 	for userID, pixels := range countsByUser {
-		list = append(list, PainterPixelCount{
-			UserID: userID,
-			Name:   nameByUser[userID],
-			Pixels: pixels,
-		})
+		item := PainterPixelCount{
+			UserID:    userID,
+			Name:      nameByUser[userID],
+			Pixels:    pixels,
+			Estimated: estimatedByUser[userID],
+		}
+		if item.Estimated > 0 {
+			item.Low = pixels + lowByUser[userID]
+			item.High = pixels + highByUser[userID]
+		}
+		list = append(list, item)
 	}
 	sort.Slice(list, func(i, j int) bool {
 		if list[i].Pixels == list[j].Pixels {
@@ -394,6 +535,10 @@ func (t *Tracker) UpdateDiffImage(pngBytes []byte) error {
 	queueAdded := addedPixels
 	queueRemoved := removedPixels
 	now := time.Now().UTC()
+	if !t.lastFrameAt.IsZero() {
+		t.frameInterval = now.Sub(t.lastFrameAt)
+	}
+	t.lastFrameAt = now
 	if t.powerSaveInference.Active && now.After(t.powerSaveInference.ExpiresAt) {
 		resetPowerSaveInference(&t.powerSaveInference)
 	}
@@ -459,6 +604,15 @@ func (t *Tracker) UpdateDiffImage(pngBytes []byte) error {
 			queueRemoved = nil
 		}
 	}
+	// Large mixed changes: probe a random sample and estimate the rest.
+	queueAdded = t.startSamplingLocked(JournalKindVandal, queueAdded, dateKey, now)
+	queueRemoved = t.startSamplingLocked(JournalKindRestore, queueRemoved, dateKey, now)
+	for _, px := range queueAdded {
+		t.detectedAt[pixelKey(px.AbsX, px.AbsY)] = now
+	}
+	for _, px := range queueRemoved {
+		t.detectedAt[pixelKey(px.AbsX, px.AbsY)] = now
+	}
 	t.mu.Unlock()
 
 	if len(queueAdded) == 0 && len(queueRemoved) == 0 {
@@ -509,6 +663,7 @@ func (t *Tracker) processPixel(px Pixel) {
 	defer func() {
 		t.mu.Lock()
 		delete(t.pending, key)
+		delete(t.detectedAt, key)
 		t.mu.Unlock()
 	}()
 
@@ -518,6 +673,7 @@ func (t *Tracker) processPixel(px Pixel) {
 		t.mu.Lock()
 		clearInferenceProbeOnFetchFailure(&t.powerSaveInference, &t.restoreInference)
 		t.mu.Unlock()
+		t.abandonSampledPixel(key)
 		return
 	}
 	if painter == nil {
@@ -525,10 +681,14 @@ func (t *Tracker) processPixel(px Pixel) {
 		t.mu.Lock()
 		clearInferenceProbeOnFetchFailure(&t.powerSaveInference, &t.restoreInference)
 		t.mu.Unlock()
+		t.abandonSampledPixel(key)
 		return
 	}
 
 	now := time.Now().UTC()
+	if t.handleSampledPixel(px, key, isDiff, painter, now) {
+		return
+	}
 	jst := time.FixedZone("JST", 9*3600)
 	dateKey := now.In(jst).Format("2006-01-02")
 
@@ -553,39 +713,14 @@ func (t *Tracker) processPixel(px Pixel) {
 		restoreInferenceActive, effectivePainterID, restoreInferenceCredit = beginPowerSaveInference(&t.restoreInference, detectedPainterID, now)
 	}
 
-	entry := t.activity[effectivePainterID]
-	if entry == nil {
-		entry = &UserActivity{
-			ID:   effectivePainterID,
-			Name: fmt.Sprintf("ID:%s", effectivePainterID),
-		}
-		ensureActivityMaps(entry)
-		t.activity[effectivePainterID] = entry
-	} else {
-		ensureActivityMaps(entry)
-	}
+	entry := t.activityEntryLocked(effectivePainterID)
 
 	// Keep profile fields trusted: only overwrite when we are updating the
 	// actually detected painter, not an inferred/aliased one.
+	statusRestricted := false
 	if effectivePainterID == detectedPainterID {
-		if painter.Name != "" {
-			entry.Name = painter.Name
-		}
-		if painter.AllianceID != 0 {
-			entry.AllianceID = painter.AllianceID
-		}
-		if painter.AllianceName != "" {
-			entry.AllianceName = painter.AllianceName
-		}
-		if painter.Discord != "" {
-			entry.Discord = painter.Discord
-		}
-		if painter.DiscordID != "" {
-			entry.DiscordID = painter.DiscordID
-		}
-		if painter.Picture != "" {
-			entry.Picture = painter.Picture
-		}
+		applyPainterProfile(entry, painter)
+		statusRestricted = t.observePainterStatusLocked(entry, painter, now)
 	}
 
 	entry.LastSeen = now.Format(time.RFC3339Nano)
@@ -593,10 +728,16 @@ func (t *Tracker) processPixel(px Pixel) {
 
 	notifyKind := ""
 	shouldNotify := false
+	botFlagged := false
+	aliased := effectivePainterID != detectedPainterID
+	journalPixels := []Pixel{px}
 	if isDiff {
 		if inferenceActive {
 			t.vandalState.PixelToPainter[key] = effectivePainterID
 			if inferenceCredit > 0 {
+				if claimed := pixelsNotIn(t.currentDiff, t.powerSaveInference.Baseline); len(claimed) > 0 {
+					journalPixels = claimed
+				}
 				assigned := claimCurrentDiffPixels(t.currentDiff, effectivePainterID, &t.vandalState, t.powerSaveInference.Baseline)
 				credited := inferenceCredit
 				if assigned > 0 {
@@ -628,6 +769,7 @@ func (t *Tracker) processPixel(px Pixel) {
 			entry.ActivityScore--
 			entry.DailyActivityScores[dateKey]--
 			t.vandalState.PixelToPainter[key] = effectivePainterID
+			botFlagged = t.recordPaintLocked(entry, key, px, now)
 			windowCount := recordRecentEvent(t.recentVandalEvents, effectivePainterID, now, newUserNotifyWindow)
 			if !entry.VandalNotified && windowCount >= newUserNotifyThreshold {
 				notifyKind = "vandal"
@@ -639,6 +781,9 @@ func (t *Tracker) processPixel(px Pixel) {
 		if restoreInferenceActive {
 			if restoreInferenceCredit > 0 {
 				credited := restoreInferenceCredit
+				if removed := pixelsNotIn(t.restoreInference.Baseline, t.currentDiff); len(removed) > 0 {
+					journalPixels = removed
+				}
 				restored := countRemovedFromBaseline(t.currentDiff, t.restoreInference.Baseline)
 				if restored > 0 {
 					credited = restored
@@ -667,6 +812,7 @@ func (t *Tracker) processPixel(px Pixel) {
 			entry.DailyRestoredCounts[dateKey]++
 			entry.ActivityScore++
 			entry.DailyActivityScores[dateKey]++
+			botFlagged = t.recordPaintLocked(entry, key, px, now)
 			windowCount := recordRecentEvent(t.recentFixEvents, effectivePainterID, now, newUserNotifyWindow)
 			if !entry.FixNotified && windowCount >= newUserNotifyThreshold {
 				notifyKind = "fix"
@@ -676,12 +822,18 @@ func (t *Tracker) processPixel(px Pixel) {
 		}
 		delete(t.vandalState.PixelToPainter, key)
 	}
+	journalKind := JournalKindRestore
+	if isDiff {
+		journalKind = JournalKindVandal
+	}
+	t.journal.Record(journalEventsFor(journalPixels, effectivePainterID, journalKind, now, key, aliased)...)
 
 	t.dirtyActivity = true
+	t.dirtyUsers[effectivePainterID] = struct{}{}
 	t.dirtyVandalState = true
 	cb := t.newUserCB
 	var userCopy UserActivity
-	if shouldNotify {
+	if shouldNotify || botFlagged || statusRestricted {
 		userCopy = cloneUserActivity(entry)
 	}
 	t.mu.Unlock()
@@ -689,6 +841,51 @@ func (t *Tracker) processPixel(px Pixel) {
 	if shouldNotify && cb != nil {
 		cb(notifyKind, userCopy)
 	}
+	if botFlagged {
+		log.Printf("activity suspected bot: user=%s evidence=%d", effectivePainterID, len(userCopy.SuspectedBot.Evidence))
+		if cb != nil {
+			cb(NotifyKindBot, userCopy)
+		}
+	}
+	if statusRestricted && cb != nil {
+		cb(NotifyKindRestricted, userCopy)
+	}
+}
+
+// activityEntryLocked ユーザーの活動記録（無ければ作る。t.mu を保持して呼ぶ）
+func (t *Tracker) activityEntryLocked(userID string) *UserActivity {
+	entry := t.activity[userID]
+	if entry == nil {
+		entry = &UserActivity{
+			ID:   userID,
+			Name: fmt.Sprintf("ID:%s", userID),
+		}
+		t.activity[userID] = entry
+	}
+	ensureActivityMaps(entry)
+	return entry
+}
+
+// applyPainterProfile ピクセル API の塗り手情報で名前・同盟・Discord などを更新する（空の項目は上書きしない）
+func applyPainterProfile(entry *UserActivity, painter *PaintedBy) {
+	if painter.Name != "" {
+		entry.Name = painter.Name
+	}
+	if painter.AllianceID != 0 {
+		entry.AllianceID = painter.AllianceID
+	}
+	if painter.AllianceName != "" {
+		entry.AllianceName = painter.AllianceName
+	}
+	if painter.Discord != "" {
+		entry.Discord = painter.Discord
+	}
+	if painter.DiscordID != "" {
+		entry.DiscordID = painter.DiscordID
+	}
+	if painter.Picture != "" {
+		entry.Picture = painter.Picture
+	}
 }
 
 func (t *Tracker) fetchPainter(px Pixel) (*PaintedBy, error) {
@@ -752,12 +949,10 @@ func (t *Tracker) resetBackoff() {
 	t.mu.Unlock()
 }
 func (t *Tracker) loadState() {
-	activityPath := filepath.Join(t.dataDir, "user_activity.json")
-	var entries map[string]*UserActivity
-	source, err := utils.ReadJSONFileWithBackup(activityPath, &entries)
+	entries, err := t.repo.LoadUsers()
 	switch {
 	case err == nil:
-		dirty := source == utils.BackupPath(activityPath)
+		dirty := false
 		for _, entry := range entries {
 			expectedScore := entry.RestoredCount - entry.VandalCount
 			if entry.DailyActivityScores == nil || entry.ActivityScore != expectedScore {
@@ -783,21 +978,19 @@ func (t *Tracker) loadState() {
 				log.Printf("failed to migrate user activity: %v", err)
 			}
 		}
-	case errors.Is(err, os.ErrNotExist):
 	default:
 		log.Printf("failed to load user activity: %v", err)
 	}
 
-	vandalPath := filepath.Join(t.dataDir, "vandalized_pixels.json")
 	var state VandalState
-	source, err = utils.ReadJSONFileWithBackup(vandalPath, &state)
+	recovered, err := t.repo.LoadState(VandalStateFile, &state)
 	switch {
 	case err == nil:
 		if state.PixelToPainter == nil {
 			state.PixelToPainter = make(map[string]string)
 		}
 		t.vandalState = state
-		if source == utils.BackupPath(vandalPath) {
+		if recovered {
 			t.dirtyVandalState = true
 		}
 	case errors.Is(err, os.ErrNotExist):
@@ -807,9 +1000,8 @@ func (t *Tracker) loadState() {
 }
 
 func (t *Tracker) loadDailyCounts() {
-	path := filepath.Join(t.dataDir, "vandal_daily.json")
 	var counts DailyPixelCounts
-	source, err := utils.ReadJSONFileWithBackup(path, &counts)
+	recovered, err := t.repo.LoadState(DailyCountsFile, &counts)
 	switch {
 	case err == nil:
 		if counts.Vandal == nil {
@@ -819,7 +1011,7 @@ func (t *Tracker) loadDailyCounts() {
 			counts.Fix = make(map[string]int)
 		}
 		t.dailyCounts = counts
-		if source == utils.BackupPath(path) {
+		if recovered {
 			t.dirtyDailyCounts = true
 		}
 	case errors.Is(err, os.ErrNotExist):
@@ -831,7 +1023,11 @@ func (t *Tracker) loadDailyCounts() {
 func (t *Tracker) saveActivitySnapshot() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	return saveUserActivityMap(t.dataDir, t.activity)
+	ids := make([]string, 0, len(t.activity))
+	for id := range t.activity {
+		ids = append(ids, id)
+	}
+	return t.repo.SaveUsers(t.activity, ids, nil)
 }
 
 func (t *Tracker) flushWorker() {
@@ -856,6 +1052,7 @@ func (t *Tracker) flushDirtyState() {
 	}
 	payloads := make([]filePayload, 0, 2)
 	var activitySnapshot map[string]*UserActivity
+	var changedUsers, deletedUsers map[string]struct{}
 	flushActivity := false
 
 	t.mu.Lock()
@@ -867,12 +1064,15 @@ func (t *Tracker) flushDirtyState() {
 		for id, entry := range t.activity {
 			activitySnapshot[id] = entry
 		}
+		changedUsers, deletedUsers = t.dirtyUsers, t.deletedUsers
+		t.dirtyUsers = make(map[string]struct{})
+		t.deletedUsers = make(map[string]struct{})
 	}
 	if flushVandal {
 		if data, err := json.MarshalIndent(t.vandalState, "", "  "); err != nil {
 			log.Printf("failed to marshal vandal state: %v", err)
 		} else {
-			payloads = append(payloads, filePayload{name: "vandalized_pixels.json", data: data})
+			payloads = append(payloads, filePayload{name: VandalStateFile, data: data})
 			t.dirtyVandalState = false
 		}
 	}
@@ -880,27 +1080,40 @@ func (t *Tracker) flushDirtyState() {
 		if data, err := json.MarshalIndent(t.dailyCounts, "", "  "); err != nil {
 			log.Printf("failed to marshal daily counts: %v", err)
 		} else {
-			payloads = append(payloads, filePayload{name: "vandal_daily.json", data: data})
+			payloads = append(payloads, filePayload{name: DailyCountsFile, data: data})
 			t.dirtyDailyCounts = false
 		}
 	}
 	t.mu.Unlock()
 
 	if flushActivity {
-		if err := saveUserActivityMap(t.dataDir, activitySnapshot); err != nil {
-			log.Printf("failed to save user_activity.json: %v", err)
+		if err := t.repo.SaveUsers(activitySnapshot, setKeys(changedUsers), setKeys(deletedUsers)); err != nil {
+			log.Printf("failed to save %s: %v", UserActivityFile, err)
+			// 次回の保存でやり直す
+			t.mu.Lock()
+			for id := range changedUsers {
+				t.dirtyUsers[id] = struct{}{}
+			}
+			for id := range deletedUsers {
+				t.deletedUsers[id] = struct{}{}
+			}
+			t.mu.Unlock()
 		} else {
 			t.mu.Lock()
-			t.dirtyActivity = false
+			t.dirtyActivity = len(t.dirtyUsers) > 0 || len(t.deletedUsers) > 0
 			t.mu.Unlock()
 		}
 	}
 
 	for _, p := range payloads {
-		if err := t.writeFileAtomic(p.name, p.data); err != nil {
+		if err := t.repo.SaveState(p.name, p.data); err != nil {
 			log.Printf("failed to save %s: %v", p.name, err)
 		}
 	}
+
+	if err := t.journal.Flush(time.Now().UTC()); err != nil {
+		log.Printf("failed to append %s: %v", PixelJournalFile, err)
+	}
 }
 
 func (t *Tracker) recentEventsGCWorker() {
@@ -922,6 +1135,7 @@ func (t *Tracker) cleanupRecentEvents(now time.Time) {
 	t.mu.Lock()
 	pruneRecentEventStore(t.recentVandalEvents, cutoff)
 	pruneRecentEventStore(t.recentFixEvents, cutoff)
+	prunePaintLog(t.paintLog, now.Add(-paintLogWindow))
 	t.mu.Unlock()
 }
 
@@ -929,16 +1143,25 @@ func (t *Tracker) activityGCWorker() {
 	ticker := time.NewTicker(t.activityGCInterval)
 	defer ticker.Stop()
 
+	t.compactJournal(time.Now().UTC())
 	for {
 		select {
 		case <-t.ctx.Done():
 			return
 		case <-ticker.C:
 			t.cleanupActivity(time.Now().UTC())
+			t.compactJournal(time.Now().UTC())
 		}
 	}
 }
 
+// compactJournal 保持期間を過ぎた帰属イベントを落とす
+func (t *Tracker) compactJournal(now time.Time) {
+	if err := t.journal.Compact(now); err != nil {
+		log.Printf("failed to compact %s: %v", PixelJournalFile, err)
+	}
+}
+
 func (t *Tracker) cleanupActivity(now time.Time) {
 	cutoff := now.AddDate(0, 0, -activityRetentionDays)
 	t.mu.Lock()
@@ -954,6 +1177,8 @@ func (t *Tracker) cleanupActivity(now time.Time) {
 		}
 		if err == nil && lastSeen.Before(cutoff) {
 			delete(t.activity, id)
+			delete(t.dirtyUsers, id)
+			t.deletedUsers[id] = struct{}{}
 			removedCount++
 		}
 	}
@@ -969,12 +1194,13 @@ func (t *Tracker) cleanupActivity(now time.Time) {
 	}
 }
 
-func (t *Tracker) writeFileAtomic(filename string, payload []byte) error {
-	if t.dataDir == "" {
-		return fmt.Errorf("dataDir is empty")
+// setKeys 集合のキー
+func setKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
 	}
-	path := filepath.Join(t.dataDir, filename)
-	return utils.WriteFileAtomic(path, payload)
+	return keys
 }
 
 func ensureActivityMaps(entry *UserActivity) {
@@ -1002,6 +1228,12 @@ func cloneUserActivity(src *UserActivity) UserActivity {
 		lastPixel := *src.LastPixel
 		dst.LastPixel = &lastPixel
 	}
+	if src.SuspectedBot != nil {
+		suspicion := *src.SuspectedBot
+		suspicion.Evidence = append([]BotEvidence(nil), src.SuspectedBot.Evidence...)
+		dst.SuspectedBot = &suspicion
+	}
+	dst.StatusHistory = append([]StatusChange(nil), src.StatusHistory...)
 	return dst
 }
 
@@ -1355,6 +1587,35 @@ func pruneRecentEventStore(store map[string][]time.Time, cutoff time.Time) {
 	}
 }
 
+// loadDaysFromEnv 日数の環境変数を読む（範囲外は丸める）
+func loadDaysFromEnv(envKey string, defaultValue time.Duration, minDays, maxDays int) time.Duration {
+	raw := os.Getenv(envKey)
+	if raw == "" {
+		return defaultValue
+	}
+	days, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("invalid %s=%q: %v", envKey, raw, err)
+		return defaultValue
+	}
+	days = min(max(days, minDays), maxDays)
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// loadIntFromEnv 整数の環境変数を読む（範囲外は丸める）
+func loadIntFromEnv(envKey string, defaultValue, minValue, maxValue int) int {
+	raw := os.Getenv(envKey)
+	if raw == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("invalid %s=%q: %v", envKey, raw, err)
+		return defaultValue
+	}
+	return min(max(value, minValue), maxValue)
+}
+
 func loadDurationFromEnv(
 	envKey string,
 	defaultValue time.Duration,
@@ -0,0 +1,419 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"Koukyo_discord_bot/internal/achievements"
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/monitor"
+)
+
+type artworkJSON struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Primary   bool   `json:"primary"`
+	TileX     int    `json:"tile_x"`
+	TileY     int    `json:"tile_y"`
+	PixelX    int    `json:"pixel_x"`
+	PixelY    int    `json:"pixel_y"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Connected bool   `json:"connected"`
+	Source    string `json:"source,omitempty"`
+}
+
+type latestJSON struct {
+	Artwork   string               `json:"artwork"`
+	Connected bool                 `json:"connected"`
+	Source    string               `json:"source,omitempty"`
+	PowerSave bool                 `json:"power_save"`
+	Timestamp *time.Time           `json:"timestamp,omitempty"`
+	Data      *monitor.MonitorData `json:"data"`
+}
+
+type diffRecordJSON struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Percentage float64   `json:"percentage"`
+}
+
+type dailyMetricJSON struct {
+	Latest   float64   `json:"latest"`
+	LatestAt time.Time `json:"latest_at"`
+	Max      float64   `json:"max"`
+	Min      float64   `json:"min"`
+	Average  float64   `json:"average"`
+	PeakAt   time.Time `json:"peak_at"`
+	Count    int       `json:"count"`
+}
+
+type dailySummaryJSON struct {
+	Date     string          `json:"date"`
+	Overall  dailyMetricJSON `json:"overall"`
+	Weighted dailyMetricJSON `json:"weighted"`
+}
+
+type heatmapJSON struct {
+	GridWidth    int      `json:"grid_width"`
+	GridHeight   int      `json:"grid_height"`
+	SourceWidth  int      `json:"source_width"`
+	SourceHeight int      `json:"source_height"`
+	Counts       []uint32 `json:"counts"`
+}
+
+type userDetailJSON struct {
+	User         *activity.UserActivity     `json:"user"`
+	Achievements []achievements.Achievement `json:"achievements"`
+}
+
+type targetJSON struct {
+	Artwork         string     `json:"artwork"`
+	Kind            string     `json:"kind"`
+	ID              string     `json:"id"`
+	Label           string     `json:"label"`
+	Origin          string     `json:"origin"`
+	IntervalSeconds int        `json:"interval_seconds"`
+	Running         bool       `json:"running"`
+	NextRun         *time.Time `json:"next_run,omitempty"`
+	LastCheckedAt   *time.Time `json:"last_checked_at,omitempty"`
+	Percent         float64    `json:"percent"`
+	DiffPixels      int        `json:"diff_pixels"`
+	LastError       string     `json:"last_error,omitempty"`
+}
+
+type monitorHandler func(w http.ResponseWriter, r *http.Request, mon *monitor.Monitor)
+
+// withMonitor {artwork} を Monitor に解決する
+func (s *Server) withMonitor(h monitorHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mon, ok := s.opts.Monitors.Get(r.PathValue("artwork"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "artwork not found")
+			return
+		}
+		h(w, r, mon)
+	}
+}
+
+func (s *Server) handleArtworks(w http.ResponseWriter, r *http.Request) {
+	primary := s.opts.Monitors.Primary()
+	items := make([]artworkJSON, 0, s.opts.Monitors.Len())
+	for _, mon := range s.opts.Monitors.All() {
+		art := mon.Artwork()
+		items = append(items, artworkJSON{
+			ID: art.ID, Name: art.Name, Primary: mon == primary,
+			TileX: art.TileX, TileY: art.TileY, PixelX: art.PixelX, PixelY: art.PixelY,
+			Width: art.Width, Height: art.Height,
+			Connected: mon.IsConnected(),
+			Source:    mon.ActiveSourceName(),
+		})
+	}
+	writePage(w, r, items)
+}
+
+func (s *Server) handleLatest(w http.ResponseWriter, r *http.Request, mon *monitor.Monitor) {
+	resp := latestJSON{
+		Artwork:   mon.Artwork().ID,
+		Connected: mon.IsConnected(),
+		Source:    mon.ActiveSourceName(),
+		PowerSave: mon.State.IsPowerSaveMode(),
+		Data:      mon.GetLatestData(),
+	}
+	if resp.Data != nil && !resp.Data.Timestamp.IsZero() {
+		ts := resp.Data.Timestamp
+		resp.Timestamp = &ts
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleHistory ?range=（1h / 6h / 24h / 7d / all。既定 1h）&weighted=1
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request, mon *monitor.Monitor) {
+	duration, err := parseRange(r.URL.Query().Get("range"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	weighted := parseBool(r.URL.Query().Get("weighted"))
+	history := mon.State.GetDiffHistory(duration, weighted)
+	items := make([]diffRecordJSON, 0, len(history))
+	for _, rec := range history {
+		items = append(items, diffRecordJSON{Timestamp: rec.Timestamp, Percentage: rec.Percentage})
+	}
+	writePage(w, r, items)
+}
+
+func (s *Server) handleDailyList(w http.ResponseWriter, r *http.Request, mon *monitor.Monitor) {
+	dates := mon.State.GetDailySummaryDates()
+	items := make([]dailySummaryJSON, 0, len(dates))
+	for _, date := range dates {
+		if summary, ok := mon.State.GetDailySummary(date); ok {
+			items = append(items, toDailySummaryJSON(date, summary))
+		}
+	}
+	writePage(w, r, items)
+}
+
+func (s *Server) handleDaily(w http.ResponseWriter, r *http.Request, mon *monitor.Monitor) {
+	date := r.PathValue("date")
+	summary, ok := mon.State.GetDailySummary(date)
+	if !ok {
+		writeError(w, http.StatusNotFound, "daily summary not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, toDailySummaryJSON(date, summary))
+}
+
+func (s *Server) handleHeatmap(w http.ResponseWriter, r *http.Request, mon *monitor.Monitor) {
+	counts, gridW, gridH, srcW, srcH := mon.State.GetHeatmapSnapshot()
+	if counts == nil {
+		writeError(w, http.StatusNotFound, "heatmap not available yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, heatmapJSON{
+		GridWidth: gridW, GridHeight: gridH,
+		SourceWidth: srcW, SourceHeight: srcH,
+		Counts: counts,
+	})
+}
+
+func (s *Server) handlePainters(w http.ResponseWriter, r *http.Request, mon *monitor.Monitor) {
+	items := mon.GetCurrentDiffPainterCounts(0)
+	if items == nil {
+		items = []activity.PainterPixelCount{}
+	}
+	writePage(w, r, items)
+}
+
+// handleUsers ?sort=score|vandal|restored|last_seen&name=（部分一致）&discord_id=
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request, mon *monitor.Monitor) {
+	q := r.URL.Query()
+	less, err := userSorter(q.Get("sort"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	name := strings.ToLower(strings.TrimSpace(q.Get("name")))
+	discordID := strings.TrimSpace(q.Get("discord_id"))
+	raw, err := loadUsers(s.artworkDataDir(mon), name, discordID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load user activity")
+		return
+	}
+	items := make([]*activity.UserActivity, 0, len(raw))
+	for id, entry := range raw {
+		if entry == nil {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(entry.Name), name) {
+			continue
+		}
+		if discordID != "" && entry.DiscordID != discordID {
+			continue
+		}
+		items = append(items, withID(id, entry))
+	}
+	sort.Slice(items, func(i, j int) bool { return less(items[i], items[j]) })
+	writePage(w, r, items)
+}
+
+// loadUsers 条件があれば索引で絞ってから読む（残りの条件は呼び出し側で判定する）
+func loadUsers(dataDir, name, discordID string) (map[string]*activity.UserActivity, error) {
+	repo, err := activity.OpenRepository(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case discordID != "":
+		return repo.FindUsersByDiscordID(discordID)
+	case name != "":
+		return repo.FindUsersByName(name)
+	default:
+		return repo.LoadUsers()
+	}
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request, mon *monitor.Monitor) {
+	repo, err := activity.OpenRepository(s.artworkDataDir(mon))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load user activity")
+		return
+	}
+	id := r.PathValue("id")
+	entry, err := repo.GetUser(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load user activity")
+		return
+	}
+	if entry == nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	resp := userDetailJSON{User: withID(id, entry), Achievements: []achievements.Achievement{}}
+	if store, err := achievements.Load(s.achievementsPath()); err == nil {
+		if ua := store.GetByIdentity(entry.DiscordID, id); ua != nil && ua.Achievements != nil {
+			resp.Achievements = ua.Achievements
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleAchievements ?discord_id=&wplace_id= 指定時は該当ユーザーのみ
+func (s *Server) handleAchievements(w http.ResponseWriter, r *http.Request) {
+	store, err := achievements.Load(s.achievementsPath())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load achievements")
+		return
+	}
+	q := r.URL.Query()
+	discordID := strings.TrimSpace(q.Get("discord_id"))
+	wplaceID := strings.TrimSpace(q.Get("wplace_id"))
+	items := make([]*achievements.UserAchievements, 0, len(store.Users))
+	if discordID != "" || wplaceID != "" {
+		if ua := store.GetByIdentity(discordID, wplaceID); ua != nil {
+			items = append(items, ua)
+		}
+		writePage(w, r, items)
+		return
+	}
+	for _, ua := range store.Users {
+		if ua != nil {
+			items = append(items, ua)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if len(items[i].Achievements) != len(items[j].Achievements) {
+			return len(items[i].Achievements) > len(items[j].Achievements)
+		}
+		return items[i].WplaceID+items[i].DiscordID < items[j].WplaceID+items[j].DiscordID
+	})
+	writePage(w, r, items)
+}
+
+func (s *Server) handleTargets(w http.ResponseWriter, r *http.Request) {
+	items := make([]targetJSON, 0)
+	for _, n := range s.opts.Notifiers {
+		for _, st := range n.TargetStatuses() {
+			items = append(items, targetJSON{
+				Artwork:         n.ArtworkID(),
+				Kind:            st.Kind,
+				ID:              st.ID,
+				Label:           st.Label,
+				Origin:          st.Origin,
+				IntervalSeconds: int(st.Interval / time.Second),
+				Running:         st.Running,
+				NextRun:         optionalTime(st.NextRun),
+				LastCheckedAt:   optionalTime(st.LastCheckedAt),
+				Percent:         st.Percent,
+				DiffPixels:      st.DiffPixels,
+				LastError:       st.LastError,
+			})
+		}
+	}
+	writePage(w, r, items)
+}
+
+func (s *Server) artworkDataDir(mon *monitor.Monitor) string {
+	return config.ArtworkDataDir(s.opts.DataDir, mon.Artwork(), mon == s.opts.Monitors.Primary())
+}
+
+func (s *Server) achievementsPath() string {
+	return filepath.Join(s.opts.DataDir, achievements.FileName)
+}
+
+func toDailySummaryJSON(date string, summary monitor.DailySummary) dailySummaryJSON {
+	return dailySummaryJSON{
+		Date:     date,
+		Overall:  toDailyMetricJSON(summary.Overall),
+		Weighted: toDailyMetricJSON(summary.Weighted),
+	}
+}
+
+func toDailyMetricJSON(m monitor.DailyMetricSummary) dailyMetricJSON {
+	out := dailyMetricJSON{
+		Latest: m.Latest, LatestAt: m.LatestAt,
+		Max: m.Max, Min: m.Min,
+		PeakAt: m.PeakAt, Count: m.Count,
+	}
+	if m.Count > 0 {
+		out.Average = m.Sum / float64(m.Count)
+	}
+	return out
+}
+
+// withID マップのキーを ID に反映したコピーを返す（保存ファイルでは ID が空のことがある）
+func withID(id string, entry *activity.UserActivity) *activity.UserActivity {
+	cp := *entry
+	if cp.ID == "" {
+		cp.ID = id
+	}
+	return &cp
+}
+
+func userSorter(key string) (func(a, b *activity.UserActivity) bool, error) {
+	byID := func(a, b *activity.UserActivity) bool { return a.ID < b.ID }
+	desc := func(value func(*activity.UserActivity) int) func(a, b *activity.UserActivity) bool {
+		return func(a, b *activity.UserActivity) bool {
+			if va, vb := value(a), value(b); va != vb {
+				return va > vb
+			}
+			return byID(a, b)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "", "score":
+		return desc(func(u *activity.UserActivity) int { return u.ActivityScore }), nil
+	case "vandal":
+		return desc(func(u *activity.UserActivity) int { return u.VandalCount }), nil
+	case "restored":
+		return desc(func(u *activity.UserActivity) int { return u.RestoredCount }), nil
+	case "last_seen":
+		return func(a, b *activity.UserActivity) bool {
+			if a.LastSeen != b.LastSeen {
+				return a.LastSeen > b.LastSeen
+			}
+			return byID(a, b)
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid sort: %q", key)
+}
+
+func parseRange(v string) (time.Duration, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	switch {
+	case v == "":
+		return time.Hour, nil
+	case v == "all":
+		return 0, nil
+	case strings.HasSuffix(v, "d"):
+		var days int
+		if _, err := fmt.Sscanf(v, "%dd", &days); err != nil || days <= 0 {
+			return 0, fmt.Errorf("invalid range: %q", v)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid range: %q", v)
+	}
+	return d, nil
+}
+
+func parseBool(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 500
+)
+
+// Page ページングされた一覧レスポンス（page は 1 始まり）
+type Page[T any] struct {
+	Items      []T `json:"items"`
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+type pageParams struct {
+	page    int
+	perPage int
+}
+
+// parsePageParams ?page=&per_page= を読む（per_page は maxPerPage で頭打ち）
+func parsePageParams(r *http.Request) (pageParams, error) {
+	p := pageParams{page: 1, perPage: defaultPerPage}
+	q := r.URL.Query()
+	if v := strings.TrimSpace(q.Get("page")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("invalid page: %q", v)
+		}
+		p.page = n
+	}
+	if v := strings.TrimSpace(q.Get("per_page")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("invalid per_page: %q", v)
+		}
+		p.perPage = min(n, maxPerPage)
+	}
+	return p, nil
+}
+
+func paginate[T any](items []T, p pageParams) Page[T] {
+	total := len(items)
+	// 範囲外のページは掛け算する前に弾く（巨大な page でのオーバーフロー対策）
+	start := total
+	if p.page-1 <= total/p.perPage {
+		start = min((p.page-1)*p.perPage, total)
+	}
+	end := min(start+p.perPage, total)
+	out := Page[T]{
+		Items:      items[start:end],
+		Page:       p.page,
+		PerPage:    p.perPage,
+		Total:      total,
+		TotalPages: (total + p.perPage - 1) / p.perPage,
+	}
+	if out.Items == nil {
+		out.Items = []T{}
+	}
+	return out
+}
+
+// writePage ページ指定を解釈して一覧を返す
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	p, err := parsePageParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, paginate(items, p))
+}
@@ -0,0 +1,103 @@
+// Package api Discord コマンドと同じ監視・活動データを読み取り専用の JSON で公開する。
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"Koukyo_discord_bot/internal/monitor"
+	"Koukyo_discord_bot/internal/notifications"
+)
+
+// Options API が参照するデータ源
+type Options struct {
+	// Token Authorization: Bearer で要求するトークン（必須）
+	Token     string
+	Monitors  *monitor.Set
+	Notifiers []*notifications.Notifier
+	// DataDir ベースのデータディレクトリ（achievements.json と primary の user_activity.json）
+	DataDir string
+}
+
+// Server 読み取り専用 HTTP API
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+}
+
+// New Server を作成する
+func New(opts Options) *Server {
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /api/v1/artworks", s.handleArtworks)
+	s.mux.HandleFunc("GET /api/v1/artworks/{artwork}/latest", s.withMonitor(s.handleLatest))
+	s.mux.HandleFunc("GET /api/v1/artworks/{artwork}/history", s.withMonitor(s.handleHistory))
+	s.mux.HandleFunc("GET /api/v1/artworks/{artwork}/daily", s.withMonitor(s.handleDailyList))
+	s.mux.HandleFunc("GET /api/v1/artworks/{artwork}/daily/{date}", s.withMonitor(s.handleDaily))
+	s.mux.HandleFunc("GET /api/v1/artworks/{artwork}/heatmap", s.withMonitor(s.handleHeatmap))
+	s.mux.HandleFunc("GET /api/v1/artworks/{artwork}/painters", s.withMonitor(s.handlePainters))
+	s.mux.HandleFunc("GET /api/v1/artworks/{artwork}/users", s.withMonitor(s.handleUsers))
+	s.mux.HandleFunc("GET /api/v1/artworks/{artwork}/users/{id}", s.withMonitor(s.handleUser))
+	s.mux.HandleFunc("GET /api/v1/achievements", s.handleAchievements)
+	s.mux.HandleFunc("GET /api/v1/targets", s.handleTargets)
+}
+
+// Handler 認証付きの http.Handler
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="koukyo"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		s.mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.opts.Token == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(s.opts.Token)) == 1
+}
+
+// Serve addr で API を公開する。停止は返り値の Close で行う。
+func Serve(addr string, srv *Server) *http.Server {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("api: server stopped: %v", err)
+		}
+	}()
+	log.Printf("HTTP API listening on http://%s/api/v1/", addr)
+	return httpServer
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("api: write response failed: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
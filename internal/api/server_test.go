@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/monitor"
+)
+
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	dataDir := t.TempDir()
+	users := map[string]*activity.UserActivity{
+		"1": {Name: "alice", ActivityScore: 5, VandalCount: 1},
+		"2": {Name: "bob", ActivityScore: 9, RestoredCount: 9, DiscordID: "42"},
+		"3": {Name: "carol", ActivityScore: 1, VandalCount: 7},
+	}
+	payload, err := json.Marshal(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "user_activity.json"), payload, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mon := monitor.NewArtworkMonitor(config.DefaultArtwork(), "")
+	t.Cleanup(mon.State.StopHeatmapWorker)
+	for _, pct := range []float64{1, 2, 3} {
+		mon.State.UpdateData(&monitor.MonitorData{Type: "update", DiffPercentage: pct, DiffPixels: int(pct), TotalPixels: 100})
+	}
+	set := monitor.NewSet()
+	set.Add(mon)
+	return New(Options{Token: "secret", Monitors: set, DataDir: dataDir}), mon.Artwork().ID
+}
+
+func get(t *testing.T, srv *Server, path, token string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if out != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("decode %s: %v body=%s", path, err, rec.Body.String())
+		}
+	}
+	return rec.Code
+}
+
+func TestAPIRequiresBearerToken(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServer(t)
+	if code := get(t, srv, "/api/v1/artworks", "", nil); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", code)
+	}
+	if code := get(t, srv, "/api/v1/artworks", "wrong", nil); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with wrong token, got %d", code)
+	}
+	if code := get(t, New(Options{Monitors: monitor.NewSet()}), "/api/v1/artworks", "", nil); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 when no token is configured, got %d", code)
+	}
+	if code := get(t, srv, "/api/v1/artworks", "secret", nil); code != http.StatusOK {
+		t.Fatalf("expected 200 with token, got %d", code)
+	}
+}
+
+func TestAPIPaginatesHistoryAndUsers(t *testing.T) {
+	t.Parallel()
+	srv, artwork := newTestServer(t)
+
+	var history Page[diffRecordJSON]
+	if code := get(t, srv, "/api/v1/artworks/"+artwork+"/history?range=all&per_page=2&page=2", "secret", &history); code != http.StatusOK {
+		t.Fatalf("history status %d", code)
+	}
+	if history.Total != 3 || history.TotalPages != 2 || len(history.Items) != 1 || history.Items[0].Percentage != 3 {
+		t.Fatalf("unexpected history page: %+v", history)
+	}
+
+	var users Page[activity.UserActivity]
+	if code := get(t, srv, "/api/v1/artworks/"+artwork+"/users?sort=vandal&per_page=1", "secret", &users); code != http.StatusOK {
+		t.Fatalf("users status %d", code)
+	}
+	if users.Total != 3 || len(users.Items) != 1 || users.Items[0].ID != "3" {
+		t.Fatalf("unexpected users page: %+v", users)
+	}
+
+	var filtered Page[activity.UserActivity]
+	get(t, srv, "/api/v1/artworks/"+artwork+"/users?discord_id=42", "secret", &filtered)
+	if filtered.Total != 1 || filtered.Items[0].Name != "bob" {
+		t.Fatalf("unexpected discord_id filter result: %+v", filtered)
+	}
+
+	var beyond Page[activity.UserActivity]
+	if code := get(t, srv, "/api/v1/artworks/"+artwork+"/users?page=9223372036854775807&per_page=2", "secret", &beyond); code != http.StatusOK {
+		t.Fatalf("expected 200 for huge page, got %d", code)
+	}
+	if beyond.Total != 3 || len(beyond.Items) != 0 {
+		t.Fatalf("unexpected page past the end: %+v", beyond)
+	}
+
+	if code := get(t, srv, "/api/v1/artworks/"+artwork+"/users?page=0", "secret", nil); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for page=0, got %d", code)
+	}
+	if code := get(t, srv, "/api/v1/artworks/unknown/latest", "secret", nil); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown artwork, got %d", code)
+	}
+}
+
+func TestAPILatestAndUserDetail(t *testing.T) {
+	t.Parallel()
+	srv, artwork := newTestServer(t)
+
+	var latest latestJSON
+	if code := get(t, srv, "/api/v1/artworks/"+artwork+"/latest", "secret", &latest); code != http.StatusOK {
+		t.Fatalf("latest status %d", code)
+	}
+	if latest.Data == nil || latest.Data.DiffPercentage != 3 || latest.Timestamp == nil {
+		t.Fatalf("unexpected latest: %+v", latest)
+	}
+
+	var detail userDetailJSON
+	if code := get(t, srv, "/api/v1/artworks/"+artwork+"/users/2", "secret", &detail); code != http.StatusOK {
+		t.Fatalf("user status %d", code)
+	}
+	if detail.User == nil || detail.User.ID != "2" || detail.User.RestoredCount != 9 {
+		t.Fatalf("unexpected user detail: %+v", detail)
+	}
+}
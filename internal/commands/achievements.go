@@ -2,6 +2,7 @@ package commands
 
 import (
 	"Koukyo_discord_bot/internal/achievements"
+	"Koukyo_discord_bot/internal/config"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -12,11 +13,12 @@ import (
 )
 
 type AchievementsCommand struct {
-	dataDir string
+	dataDir  string
+	settings *config.SettingsManager
 }
 
-func NewAchievementsCommand(dataDir string) *AchievementsCommand {
-	return &AchievementsCommand{dataDir: dataDir}
+func NewAchievementsCommand(dataDir string, settings *config.SettingsManager) *AchievementsCommand {
+	return &AchievementsCommand{dataDir: dataDir, settings: settings}
 }
 
 func (c *AchievementsCommand) Name() string { return "achievements" }
@@ -30,7 +32,7 @@ func (c *AchievementsCommand) ExecuteText(s *discordgo.Session, m *discordgo.Mes
 		_, err := s.ChannelMessageSend(m.ChannelID, "❌ ユーザー情報を取得できませんでした。")
 		return err
 	}
-	embed, err := c.buildAchievementsEmbed(user.ID, discordTag(user))
+	embed, err := c.buildAchievementsEmbed(user.ID, discordTag(user), c.settings.GuildLocation(m.GuildID))
 	if err != nil {
 		_, e := s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
 		return e
@@ -50,7 +52,7 @@ func (c *AchievementsCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.In
 			},
 		})
 	}
-	embed, err := c.buildAchievementsEmbed(user.ID, discordTag(user))
+	embed, err := c.buildAchievementsEmbed(user.ID, discordTag(user), c.settings.GuildLocation(i.GuildID))
 	if err != nil {
 		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -76,11 +78,11 @@ func (c *AchievementsCommand) SlashDefinition() *discordgo.ApplicationCommand {
 	}
 }
 
-func (c *AchievementsCommand) buildAchievementsEmbed(discordID, displayName string) (*discordgo.MessageEmbed, error) {
+func (c *AchievementsCommand) buildAchievementsEmbed(discordID, displayName string, loc *time.Location) (*discordgo.MessageEmbed, error) {
 	if c.dataDir == "" {
 		return nil, fmt.Errorf("dataDir is empty")
 	}
-	storePath := filepath.Join(c.dataDir, "achievements.json")
+	storePath := filepath.Join(c.dataDir, achievements.FileName)
 	store, err := achievements.Load(storePath)
 	if err != nil {
 		return nil, err
@@ -122,7 +124,7 @@ func (c *AchievementsCommand) buildAchievementsEmbed(discordID, displayName stri
 		}
 		if a.AwardedAt != "" {
 			if t, err := time.Parse(time.RFC3339, a.AwardedAt); err == nil {
-				line += fmt.Sprintf(" (%s)", t.In(time.FixedZone("JST", 9*3600)).Format("2006-01-02"))
+				line += fmt.Sprintf(" (%s)", t.In(loc).Format("2006-01-02"))
 			}
 		}
 		lines = append(lines, line)
@@ -0,0 +1,297 @@
+package commands
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/embeds"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	allianceListPageSize   = 10
+	allianceMemberPageSize = 10
+	allianceListPrefix     = "alliance_list:"
+	alliancePrefix         = "alliance:"
+	allianceSelectID       = "alliance_select"
+)
+
+// AllianceCommand 同盟ごとの荒らし/修復の集計（一覧・検索・詳細とメンバー一覧）
+type AllianceCommand struct {
+	dataDir  string
+	settings *config.SettingsManager
+}
+
+func NewAllianceCommand(dataDir string, settings *config.SettingsManager) *AllianceCommand {
+	return &AllianceCommand{dataDir: dataDir, settings: settings}
+}
+
+func (c *AllianceCommand) Name() string { return "alliance" }
+func (c *AllianceCommand) Description() string {
+	return "同盟ごとの荒らし/修復の集計（一覧・検索・詳細）"
+}
+
+func (c *AllianceCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	_, err := s.ChannelMessageSend(m.ChannelID, "このコマンドはスラッシュコマンドで利用してください。")
+	return err
+}
+
+func (c *AllianceCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	query := ""
+	allianceID := 0
+	page := 0
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "name":
+			query = strings.TrimSpace(opt.StringValue())
+		case "id":
+			allianceID = int(opt.IntValue())
+		case "page":
+			page = int(opt.IntValue()) - 1
+		}
+	}
+	loc := c.settings.GuildLocation(i.GuildID)
+
+	stats, err := loadAllianceStats(c.dataDir)
+	if err != nil {
+		return respondUserListError(s, i, err)
+	}
+
+	if allianceID != 0 {
+		a, ok := activity.FindAlliance(stats, allianceID)
+		if !ok {
+			return respondUserListError(s, i, fmt.Errorf("該当する同盟が見つかりません"))
+		}
+		embed, components := buildAllianceDetailMessage(a, page, loc)
+		return respondAllianceMessage(s, i, embed, components)
+	}
+
+	if query != "" {
+		matches := activity.SearchAlliances(stats, query)
+		switch len(matches) {
+		case 0:
+			return respondUserListError(s, i, fmt.Errorf("該当する同盟が見つかりません"))
+		case 1:
+			embed, components := buildAllianceDetailMessage(matches[0], page, loc)
+			return respondAllianceMessage(s, i, embed, components)
+		}
+		embed, components := buildAllianceSearchMessage(query, matches)
+		return respondAllianceMessage(s, i, embed, components)
+	}
+
+	embed, components := buildAllianceListMessage(stats, page)
+	return respondAllianceMessage(s, i, embed, components)
+}
+
+func (c *AllianceCommand) SlashDefinition() *discordgo.ApplicationCommand {
+	minValue := 1.0
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "name",
+				Description: "同盟名 (部分一致) または同盟ID",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "id",
+				Description: "同盟ID",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "page",
+				Description: "ページ番号 (1から。詳細ではメンバー一覧のページ)",
+				Required:    false,
+				MinValue:    &minValue,
+			},
+		},
+	}
+}
+
+// HandleAllianceListPagination alliance_list:<page> のボタンを処理
+func HandleAllianceListPagination(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string) {
+	page, _ := strconv.Atoi(strings.TrimPrefix(i.MessageComponentData().CustomID, allianceListPrefix))
+	stats, err := loadAllianceStats(dataDir)
+	if err != nil {
+		_ = respondUserListError(s, i, err)
+		return
+	}
+	embed, components := buildAllianceListMessage(stats, page)
+	updateAllianceMessage(s, i, embed, components)
+}
+
+// HandleAlliancePagination alliance:<id>:<page> のボタン（メンバー一覧のページ送り）を処理
+func HandleAlliancePagination(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string, loc *time.Location) {
+	parts := strings.Split(strings.TrimPrefix(i.MessageComponentData().CustomID, alliancePrefix), ":")
+	if len(parts) != 2 {
+		return
+	}
+	allianceID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return
+	}
+	page, _ := strconv.Atoi(parts[1])
+	showAllianceDetail(s, i, dataDir, allianceID, page, loc)
+}
+
+// HandleAllianceSelect 一覧・検索結果のセレクトメニューで選んだ同盟の詳細を表示
+func HandleAllianceSelect(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string, loc *time.Location) {
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return
+	}
+	allianceID, err := strconv.Atoi(values[0])
+	if err != nil {
+		return
+	}
+	showAllianceDetail(s, i, dataDir, allianceID, 0, loc)
+}
+
+func showAllianceDetail(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string, allianceID, page int, loc *time.Location) {
+	stats, err := loadAllianceStats(dataDir)
+	if err != nil {
+		_ = respondUserListError(s, i, err)
+		return
+	}
+	a, ok := activity.FindAlliance(stats, allianceID)
+	if !ok {
+		_ = respondUserListError(s, i, fmt.Errorf("該当する同盟が見つかりません"))
+		return
+	}
+	embed, components := buildAllianceDetailMessage(a, page, loc)
+	updateAllianceMessage(s, i, embed, components)
+}
+
+func loadAllianceStats(dataDir string) ([]activity.AllianceStats, error) {
+	entries, err := activity.LoadUserActivityMap(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return activity.BuildAllianceStats(entries, time.Now()), nil
+}
+
+// buildAllianceListMessage 同盟一覧のページ、ページ送りボタン、ページ内の同盟を選ぶメニュー
+func buildAllianceListMessage(stats []activity.AllianceStats, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed, page, maxPage := embeds.BuildAllianceListEmbed(stats, page, allianceListPageSize)
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "前へ",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("%s%d", allianceListPrefix, page-1),
+					Disabled: page <= 0,
+				},
+				discordgo.Button{
+					Label:    "次へ",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("%s%d", allianceListPrefix, page+1),
+					Disabled: page >= maxPage,
+				},
+			},
+		},
+	}
+	start := page * allianceListPageSize
+	if start < len(stats) {
+		visible := stats[start:min(start+allianceListPageSize, len(stats))]
+		components = append(components, allianceSelectRow(visible))
+	}
+	return embed, components
+}
+
+// buildAllianceSearchMessage 複数ヒットしたときの候補一覧と選択メニュー
+func buildAllianceSearchMessage(query string, matches []activity.AllianceStats) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	limit := min(len(matches), userActivityMaxSelectItems)
+	lines := make([]string, 0, limit)
+	for i, a := range matches[:limit] {
+		lines = append(lines, fmt.Sprintf("%d. %s (メンバー %d人)", i+1, a.DisplayName(), len(a.Members)))
+	}
+	description := fmt.Sprintf("検索: %s / 候補: %d件", query, len(matches))
+	if len(matches) > limit {
+		description += fmt.Sprintf("（表示は先頭%d件まで）", limit)
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "同盟候補を選択",
+		Description: description + "\n" + strings.Join(lines, "\n"),
+		Color:       0x9B59B6,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+	return embed, []discordgo.MessageComponent{allianceSelectRow(matches[:limit])}
+}
+
+func allianceSelectRow(stats []activity.AllianceStats) discordgo.ActionsRow {
+	options := make([]discordgo.SelectMenuOption, 0, len(stats))
+	for _, a := range stats {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       truncateLabel(a.DisplayName(), 100),
+			Value:       strconv.Itoa(a.ID),
+			Description: truncateLabel(fmt.Sprintf("荒らし %d / 修復 %d / メンバー %d人", a.VandalCount, a.RestoredCount, len(a.Members)), 100),
+		})
+	}
+	return discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    allianceSelectID,
+				Placeholder: "同盟を選択",
+				Options:     options,
+			},
+		},
+	}
+}
+
+// buildAllianceDetailMessage 同盟詳細とメンバー一覧のページ送りボタン
+func buildAllianceDetailMessage(a activity.AllianceStats, page int, loc *time.Location) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed, page, maxPage := embeds.BuildAllianceDetailEmbed(a, page, allianceMemberPageSize, time.Now(), loc)
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "前へ",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("%s%d:%d", alliancePrefix, a.ID, page-1),
+					Disabled: page <= 0,
+				},
+				discordgo.Button{
+					Label:    "次へ",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("%s%d:%d", alliancePrefix, a.ID, page+1),
+					Disabled: page >= maxPage,
+				},
+				discordgo.Button{
+					Label:    "同盟一覧",
+					Style:    discordgo.SecondaryButton,
+					CustomID: allianceListPrefix + "0",
+				},
+			},
+		},
+	}
+	return embed, components
+}
+
+func respondAllianceMessage(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+func updateAllianceMessage(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
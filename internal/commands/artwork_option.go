@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/monitor"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const artworkOptionName = "artwork"
+
+// appendArtworkOption 監視アートワークが複数ある場合のみ artwork 選択肢を追加する
+func appendArtworkOption(opts []*discordgo.ApplicationCommandOption, set *monitor.Set) []*discordgo.ApplicationCommandOption {
+	if set.Len() <= 1 {
+		return opts
+	}
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, set.Len())
+	for _, mon := range set.All() {
+		art := mon.Artwork()
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  art.DisplayName(),
+			Value: art.ID,
+		})
+		// Discord の選択肢上限
+		if len(choices) >= 25 {
+			break
+		}
+	}
+	return append(opts, &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        artworkOptionName,
+		Description: "対象アートワーク（省略時は既定）",
+		Required:    false,
+		Choices:     choices,
+	})
+}
+
+// artworkIDFromOptions スラッシュコマンドのオプションから artwork を取り出す
+func artworkIDFromOptions(opts []*discordgo.ApplicationCommandInteractionDataOption) string {
+	for _, opt := range opts {
+		if opt.Name == artworkOptionName {
+			return opt.StringValue()
+		}
+	}
+	return ""
+}
+
+// artworkIDFromArgs テキストコマンドの引数から artwork=<id> を取り出し、残りの引数を返す
+func artworkIDFromArgs(args []string) (string, []string) {
+	id := ""
+	rest := make([]string, 0, len(args))
+	for _, a := range args {
+		if strings.HasPrefix(a, artworkOptionName+"=") {
+			id = strings.TrimPrefix(a, artworkOptionName+"=")
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return id, rest
+}
+
+// resolveArtworkMonitor ID から Monitor を解決する（空IDは primary）
+func resolveArtworkMonitor(set *monitor.Set, id string) (*monitor.Monitor, error) {
+	mon, ok := set.Get(id)
+	if ok {
+		return mon, nil
+	}
+	if strings.TrimSpace(id) == "" {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("❌ 不明なアートワークです: `%s`", id)
+}
+
+// artworkDataDirFor Monitor に対応するアートワークのデータディレクトリを返す
+func artworkDataDirFor(set *monitor.Set, mon *monitor.Monitor, baseDir string) string {
+	if mon == nil {
+		return baseDir
+	}
+	return config.ArtworkDataDir(baseDir, mon.Artwork(), mon == set.Primary())
+}
+
+// artworkTitleSuffix 既定以外のアートワークでタイトルに付ける表示名
+func artworkTitleSuffix(mon *monitor.Monitor) string {
+	if mon == nil {
+		return ""
+	}
+	art := mon.Artwork()
+	if art.ID == config.DefaultArtworkID {
+		return ""
+	}
+	return " - " + art.DisplayName()
+}
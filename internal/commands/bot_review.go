@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"Koukyo_discord_bot/internal/embeds"
+	"Koukyo_discord_bot/internal/monitor"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// parseBotReviewCustomID 「botreview:<verdict>:<userID>:<artworkID>」を分解
+func parseBotReviewCustomID(customID string) (verdict, userID, artworkID string, ok bool) {
+	if !strings.HasPrefix(customID, embeds.BotReviewPrefix) {
+		return "", "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(customID, embeds.BotReviewPrefix), ":", 3)
+	if len(parts) != 3 || parts[1] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// HandleBotReviewButton モデレーション通知の確認/誤検知ボタン（管理者のみ）。結果をユーザー活動に記録し、ボタンを外す
+func HandleBotReviewButton(s *discordgo.Session, i *discordgo.InteractionCreate, monitors *monitor.Set, loc *time.Location) {
+	respondError := func(content string) {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+	if !isAdminOrGold(s, i.GuildID, interactionUserID(i)) {
+		respondError("❌ この操作は管理者のみ使用できます。")
+		return
+	}
+	verdict, userID, artworkID, ok := parseBotReviewCustomID(i.MessageComponentData().CustomID)
+	if !ok {
+		return
+	}
+	mon, err := resolveArtworkMonitor(monitors, artworkID)
+	if err != nil {
+		respondError(err.Error())
+		return
+	}
+	if mon == nil {
+		mon = monitors.Primary()
+	}
+	user, err := mon.ReviewBotSuspicion(userID, verdict, discordTag(interactionUser(i)))
+	if err != nil {
+		log.Printf("Failed to record bot review for %s: %v", userID, err)
+		respondError("❌ エラー: " + err.Error())
+		return
+	}
+
+	var embedsOut []*discordgo.MessageEmbed
+	if i.Message != nil && len(i.Message.Embeds) > 0 {
+		embed := i.Message.Embeds[0]
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "確認結果",
+			Value: embeds.FormatBotReview(user.SuspectedBot, loc),
+		})
+		embedsOut = []*discordgo.MessageEmbed{embed}
+	}
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     embedsOut,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
@@ -2,29 +2,26 @@ package commands
 
 import (
 	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/embeds"
 	"Koukyo_discord_bot/internal/monitor"
 	"bytes"
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-var commandJST = time.FixedZone("JST", 9*3600)
-
 // GraphCommand 差分率のグラフ表示
 type GraphCommand struct {
-	mon     *monitor.Monitor
-	dataDir string
+	monitors *monitor.Set
+	settings *config.SettingsManager
+	dataDir  string
 }
 
-func NewGraphCommand(mon *monitor.Monitor, dataDir string) *GraphCommand {
-	return &GraphCommand{mon: mon, dataDir: dataDir}
+func NewGraphCommand(monitors *monitor.Set, settings *config.SettingsManager, dataDir string) *GraphCommand {
+	return &GraphCommand{monitors: monitors, settings: settings, dataDir: dataDir}
 }
 
 func (c *GraphCommand) Name() string { return "graph" }
@@ -33,17 +30,17 @@ func (c *GraphCommand) Description() string {
 }
 
 // executeDiff は、差分率グラフ生成の共通ロジック
-func (c *GraphCommand) executeDiff(metric string, duration time.Duration) (*discordgo.MessageEmbed, *bytes.Buffer, error) {
-	if c.mon == nil {
+func (c *GraphCommand) executeDiff(mon *monitor.Monitor, metric string, duration time.Duration, loc *time.Location) (*discordgo.MessageEmbed, *bytes.Buffer, error) {
+	if mon == nil {
 		return nil, nil, fmt.Errorf("graphでエラーが発生しました: 監視システムが初期化されていません。")
 	}
-	if !c.mon.State.HasData() {
+	if !mon.State.HasData() {
 		return nil, nil, fmt.Errorf("graphでエラーが発生しました: 監視データがまだ受信できていません。")
 	}
 
 	weighted := (metric == "weighted")
-	history := c.mon.State.GetDiffHistory(duration, weighted)
-	pngBuf, err := embeds.BuildDiffGraphPNG(history)
+	history := mon.State.GetDiffHistory(duration, weighted)
+	pngBuf, err := embeds.BuildDiffGraphPNG(history, loc)
 	if err != nil {
 		return nil, nil, fmt.Errorf("グラフ生成に失敗しました: %w", err)
 	}
@@ -52,12 +49,13 @@ func (c *GraphCommand) executeDiff(metric string, duration time.Duration) (*disc
 	if weighted {
 		title = "加重差分率グラフ"
 	}
-	nowJST := time.Now().In(commandJST)
+	title += artworkTitleSuffix(mon)
+	now := time.Now()
 	embed := &discordgo.MessageEmbed{
 		Title:       title,
-		Description: fmt.Sprintf("範囲: %s / データ点: %d / 時刻軸: JST", humanDuration(duration), len(history)),
+		Description: fmt.Sprintf("範囲: %s / データ点: %d / 時刻軸: %s", humanDuration(duration), len(history), zoneName(now, loc)),
 		Color:       0x63A4FF,
-		Timestamp:   nowJST.Format(time.RFC3339),
+		Timestamp:   now.Format(time.RFC3339),
 		Image:       &discordgo.MessageEmbedImage{URL: "attachment://diff_graph.png"},
 	}
 
@@ -65,7 +63,7 @@ func (c *GraphCommand) executeDiff(metric string, duration time.Duration) (*disc
 }
 
 // executeVandal は、日次荒らし件数グラフ生成の共通ロジック
-func (c *GraphCommand) executeVandal(duration time.Duration) (*discordgo.MessageEmbed, *bytes.Buffer, error) {
+func (c *GraphCommand) executeVandal(mon *monitor.Monitor, duration time.Duration) (*discordgo.MessageEmbed, *bytes.Buffer, error) {
 	if c.dataDir == "" {
 		return nil, nil, fmt.Errorf("graphでエラーが発生しました: dataDirが未設定です。")
 	}
@@ -76,7 +74,7 @@ func (c *GraphCommand) executeVandal(duration time.Duration) (*discordgo.Message
 	if days > 60 {
 		days = 60
 	}
-	labels, counts, err := buildDailyVandalCounts(c.dataDir, days)
+	labels, counts, err := buildDailyVandalCounts(artworkDataDirFor(c.monitors, mon, c.dataDir), days)
 	if err != nil {
 		return nil, nil, fmt.Errorf("graphでエラーが発生しました: %w", err)
 	}
@@ -84,12 +82,11 @@ func (c *GraphCommand) executeVandal(duration time.Duration) (*discordgo.Message
 	if err != nil {
 		return nil, nil, fmt.Errorf("グラフ生成に失敗しました: %w", err)
 	}
-	nowJST := time.Now().In(commandJST)
 	embed := &discordgo.MessageEmbed{
-		Title:       "荒らし件数グラフ",
+		Title:       "荒らし件数グラフ" + artworkTitleSuffix(mon),
 		Description: fmt.Sprintf("範囲: 過去%d日(JST) / データ点: %d", days, len(labels)),
 		Color:       0xE74C3C,
-		Timestamp:   nowJST.Format(time.RFC3339),
+		Timestamp:   time.Now().Format(time.RFC3339),
 		Image:       &discordgo.MessageEmbedImage{URL: "attachment://vandal_graph.png"},
 	}
 	return embed, pngBuf, nil
@@ -100,7 +97,8 @@ func (c *GraphCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCre
 	metric := "overall"
 	duration := 1 * time.Hour
 
-	// 引数: type=diff|vandal, metric=overall|weighted, duration=30m|1h|6h|24h
+	// 引数: type=diff|vandal, metric=overall|weighted, duration=30m|1h|6h|24h, artwork=<id>
+	artworkID, args := artworkIDFromArgs(args)
 	for _, a := range args {
 		if strings.HasPrefix(a, "type=") {
 			graphType = strings.TrimPrefix(a, "type=")
@@ -120,12 +118,14 @@ func (c *GraphCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCre
 	var (
 		embed  *discordgo.MessageEmbed
 		pngBuf *bytes.Buffer
-		err    error
 	)
-	if graphType == "vandal" {
-		embed, pngBuf, err = c.executeVandal(duration)
-	} else {
-		embed, pngBuf, err = c.executeDiff(metric, duration)
+	mon, err := resolveArtworkMonitor(c.monitors, artworkID)
+	if err == nil {
+		if graphType == "vandal" {
+			embed, pngBuf, err = c.executeVandal(mon, duration)
+		} else {
+			embed, pngBuf, err = c.executeDiff(mon, metric, duration, c.settings.GuildLocation(m.GuildID))
+		}
 	}
 	if err != nil {
 		_, e := s.ChannelMessageSend(m.ChannelID, err.Error())
@@ -172,12 +172,14 @@ func (c *GraphCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.Interacti
 	var (
 		embed  *discordgo.MessageEmbed
 		pngBuf *bytes.Buffer
-		err    error
 	)
-	if graphType == "vandal" {
-		embed, pngBuf, err = c.executeVandal(duration)
-	} else {
-		embed, pngBuf, err = c.executeDiff(metric, duration)
+	mon, err := resolveArtworkMonitor(c.monitors, artworkIDFromOptions(opts))
+	if err == nil {
+		if graphType == "vandal" {
+			embed, pngBuf, err = c.executeVandal(mon, duration)
+		} else {
+			embed, pngBuf, err = c.executeDiff(mon, metric, duration, c.settings.GuildLocation(i.GuildID))
+		}
 	}
 	if err != nil {
 		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -206,7 +208,7 @@ func (c *GraphCommand) SlashDefinition() *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
 		Name:        c.Name(),
 		Description: c.Description(),
-		Options: []*discordgo.ApplicationCommandOption{
+		Options: appendArtworkOption([]*discordgo.ApplicationCommandOption{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "type",
@@ -233,10 +235,15 @@ func (c *GraphCommand) SlashDefinition() *discordgo.ApplicationCommand {
 				Description: "範囲: 30m | 1h | 6h | 24h",
 				Required:    false,
 			},
-		},
+		}, c.monitors),
 	}
 }
 
+// zoneName 表示用のタイムゾーン略称（例: JST, CET）
+func zoneName(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("MST")
+}
+
 func parseDuration(s string) (time.Duration, error) {
 	switch s {
 	case "30m":
@@ -260,26 +267,22 @@ func humanDuration(d time.Duration) string {
 
 func buildDailyVandalCounts(dataDir string, days int) ([]string, []int, error) {
 	countsByDate := make(map[string]int)
-	dailyPath := filepath.Join(dataDir, "vandal_daily.json")
-	if data, err := os.ReadFile(dailyPath); err == nil {
-		var daily activity.DailyPixelCounts
-		if err := json.Unmarshal(data, &daily); err == nil && daily.Vandal != nil {
-			for dateKey, count := range daily.Vandal {
-				countsByDate[dateKey] += count
-			}
+	repo, err := activity.OpenRepository(dataDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	var daily activity.DailyPixelCounts
+	if _, err := repo.LoadState(activity.DailyCountsFile, &daily); err == nil && daily.Vandal != nil {
+		for dateKey, count := range daily.Vandal {
+			countsByDate[dateKey] += count
 		}
 	}
 
 	if len(countsByDate) == 0 {
-		path := filepath.Join(dataDir, "user_activity.json")
-		data, err := os.ReadFile(path)
+		raw, err := repo.LoadUsers()
 		if err != nil {
 			return nil, nil, fmt.Errorf("user_activity.jsonの読み込みに失敗しました: %w", err)
 		}
-		var raw map[string]*activity.UserActivity
-		if err := json.Unmarshal(data, &raw); err != nil {
-			return nil, nil, fmt.Errorf("user_activity.jsonの解析に失敗しました: %w", err)
-		}
 		for _, entry := range raw {
 			for dateKey, count := range entry.DailyVandalCounts {
 				countsByDate[dateKey] += count
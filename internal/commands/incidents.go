@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/embeds"
+	"Koukyo_discord_bot/internal/incidents"
+	"Koukyo_discord_bot/internal/monitor"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const incidentsPerPage = 10
+
+// IncidentsCommand 差分発生～0%復帰のインシデント一覧/詳細
+type IncidentsCommand struct {
+	monitors *monitor.Set
+	settings *config.SettingsManager
+	dataDir  string
+}
+
+func NewIncidentsCommand(monitors *monitor.Set, settings *config.SettingsManager, dataDir string) *IncidentsCommand {
+	return &IncidentsCommand{monitors: monitors, settings: settings, dataDir: dataDir}
+}
+
+func (c *IncidentsCommand) Name() string { return "incidents" }
+func (c *IncidentsCommand) Description() string {
+	return "過去のインシデント（荒らし～修復完了）の一覧/詳細を表示します"
+}
+
+func (c *IncidentsCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	artworkID, rest := artworkIDFromArgs(args)
+	id, page := 0, 1
+	for _, a := range rest {
+		switch {
+		case strings.HasPrefix(a, "page="):
+			page, _ = strconv.Atoi(strings.TrimPrefix(a, "page="))
+		case strings.HasPrefix(a, "#"):
+			id, _ = strconv.Atoi(strings.TrimPrefix(a, "#"))
+		default:
+			id, _ = strconv.Atoi(a)
+		}
+	}
+	embed, files, err := c.build(artworkID, id, page, c.settings.GuildLocation(m.GuildID))
+	if err != nil {
+		_, sendErr := s.ChannelMessageSend(m.ChannelID, err.Error())
+		return sendErr
+	}
+	_, err = s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  files,
+	})
+	return err
+}
+
+func (c *IncidentsCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	opts := i.ApplicationCommandData().Options
+	id, page := 0, 1
+	for _, opt := range opts {
+		switch opt.Name {
+		case "id":
+			id = int(opt.IntValue())
+		case "page":
+			page = int(opt.IntValue())
+		}
+	}
+	embed, files, err := c.build(artworkIDFromOptions(opts), id, page, c.settings.GuildLocation(i.GuildID))
+	if err != nil {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: err.Error(),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Files:  files,
+		},
+	})
+}
+
+func (c *IncidentsCommand) SlashDefinition() *discordgo.ApplicationCommand {
+	minValue := 1.0
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: appendArtworkOption([]*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "id",
+				Description: "詳細を表示するインシデント番号",
+				Required:    false,
+				MinValue:    &minValue,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "page",
+				Description: "一覧のページ番号",
+				Required:    false,
+				MinValue:    &minValue,
+			},
+		}, c.monitors),
+	}
+}
+
+func (c *IncidentsCommand) build(artworkID string, id, page int, loc *time.Location) (*discordgo.MessageEmbed, []*discordgo.File, error) {
+	mon, err := resolveArtworkMonitor(c.monitors, artworkID)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir := artworkDataDirFor(c.monitors, mon, c.dataDir)
+	list, err := incidents.Load(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ インシデント履歴の読み込みに失敗しました: %v", err)
+	}
+	if id > 0 {
+		for _, inc := range list {
+			if inc.ID == id {
+				embed, files := buildIncidentDetailEmbed(dir, inc, mon, loc)
+				return embed, files, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("❌ インシデント #%d は見つかりません。", id)
+	}
+	return buildIncidentListEmbed(list, page, mon, loc), nil, nil
+}
+
+func buildIncidentListEmbed(list []*incidents.Incident, page int, mon *monitor.Monitor, loc *time.Location) *discordgo.MessageEmbed {
+	totalPages := max((len(list)+incidentsPerPage-1)/incidentsPerPage, 1)
+	page = min(max(page, 1), totalPages)
+	start := (page - 1) * incidentsPerPage
+	end := min(start+incidentsPerPage, len(list))
+
+	now := time.Now()
+	lines := make([]string, 0, end-start)
+	for _, inc := range list[start:end] {
+		lines = append(lines, incidents.SummaryLine(inc, now, loc))
+	}
+	desc := "記録されたインシデントはありません。"
+	if len(lines) > 0 {
+		desc = strings.Join(lines, "\n") + "\n\n`/incidents id:<番号>` で詳細を表示"
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "🧾 インシデント一覧" + artworkTitleSuffix(mon),
+		Description: desc,
+		Color:       0x5865F2,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("ページ %d/%d | 全 %d件 | 時刻: %s", page, totalPages, len(list), zoneName(now, loc)),
+		},
+		Timestamp: now.Format(time.RFC3339),
+	}
+}
+
+func buildIncidentDetailEmbed(dir string, inc *incidents.Incident, mon *monitor.Monitor, loc *time.Location) (*discordgo.MessageEmbed, []*discordgo.File) {
+	now := time.Now()
+	status := "✅ 修復済み"
+	color := 0x00FF00
+	zone := zoneName(now, loc)
+	end := inc.EndedAt.In(loc).Format("2006-01-02 15:04:05")
+	recovery := incidents.FormatDuration(inc.TimeToRecovery())
+	if inc.Open() {
+		status = "🚨 進行中"
+		color = 0xE74C3C
+		end = "—"
+		recovery = "—"
+	}
+	peak := fmt.Sprintf("%.2f%% (%d/%d px)", inc.PeakPercentage, inc.PeakDiffPixels, inc.TotalPixels)
+	if inc.PeakWeighted != nil {
+		peak += fmt.Sprintf("\n加重 %.2f%%", *inc.PeakWeighted)
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("🧾 インシデント #%d%s", inc.ID, artworkTitleSuffix(mon)),
+		Description: status,
+		Color:       color,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: fmt.Sprintf("開始 (%s)", zone), Value: inc.StartedAt.In(loc).Format("2006-01-02 15:04:05"), Inline: true},
+			{Name: fmt.Sprintf("終了 (%s)", zone), Value: end, Inline: true},
+			{Name: "継続時間", Value: incidents.FormatDuration(inc.Duration(now)), Inline: true},
+			{Name: "ピーク", Value: peak, Inline: true},
+			{Name: fmt.Sprintf("ピーク時刻 (%s)", zone), Value: inc.PeakAt.In(loc).Format("15:04:05"), Inline: true},
+			{Name: "ピークから復旧まで", Value: recovery, Inline: true},
+			{Name: fmt.Sprintf("🚨 荒らし (%d人 / %dpx)", len(inc.Vandals), inc.VandalPixels()), Value: incidents.ParticipantLines(inc.Vandals, 10), Inline: false},
+			{Name: fmt.Sprintf("🛠️ 修復 (%d人 / %dpx)", len(inc.Fixers), inc.FixerPixels()), Value: incidents.ParticipantLines(inc.Fixers, 10), Inline: false},
+		},
+		Timestamp: now.Format(time.RFC3339),
+	}
+
+	diffImg, err := os.ReadFile(incidents.ImagePath(dir, inc.PeakDiffImage))
+	if inc.PeakDiffImage == "" || err != nil {
+		return embed, nil
+	}
+	name := "incident_peak_diff.png"
+	var file *discordgo.File
+	if liveImg, liveErr := os.ReadFile(incidents.ImagePath(dir, inc.PeakLiveImage)); inc.PeakLiveImage != "" && liveErr == nil {
+		if combined, err := embeds.CombineImages(liveImg, diffImg); err == nil {
+			name = "incident_peak.png"
+			file = &discordgo.File{Name: name, ContentType: "image/png", Reader: combined}
+		} else {
+			log.Printf("incidents: combine peak images failed id=%d: %v", inc.ID, err)
+		}
+	}
+	if file == nil {
+		file = &discordgo.File{Name: name, ContentType: "image/png", Reader: bytes.NewReader(diffImg)}
+	}
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + name}
+	return embed, []*discordgo.File{file}
+}
@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/embeds"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	leaderboardPageSize = 10
+	leaderboardPrefix   = "leaderboard:"
+)
+
+// LeaderboardCommand 直近7日/30日/通算の荒らし・修復・総合スコアのリーダーボード
+type LeaderboardCommand struct {
+	dataDir string
+}
+
+func NewLeaderboardCommand(dataDir string) *LeaderboardCommand {
+	return &LeaderboardCommand{dataDir: dataDir}
+}
+
+func (c *LeaderboardCommand) Name() string { return "leaderboard" }
+func (c *LeaderboardCommand) Description() string {
+	return "荒らし/修復/総合スコアのリーダーボード（直近7日・30日・通算、順位変動付き）"
+}
+
+func (c *LeaderboardCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	kind, period, page := activity.LeaderboardActivity, activity.LeaderboardWeek, 0
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "type="):
+			kind = activity.NormalizeLeaderboardKind(strings.TrimPrefix(arg, "type="))
+		case strings.HasPrefix(arg, "period="):
+			period = activity.NormalizeLeaderboardPeriod(strings.TrimPrefix(arg, "period="))
+		case strings.HasPrefix(arg, "page="):
+			page, _ = strconv.Atoi(strings.TrimPrefix(arg, "page="))
+			page--
+		}
+	}
+	embed, components, file, err := buildLeaderboardMessage(c.dataDir, kind, period, page)
+	if err != nil {
+		_, sendErr := s.ChannelMessageSend(m.ChannelID, "❌ エラー: "+err.Error())
+		return sendErr
+	}
+	_, err = s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+		Files:      buildOptionalFiles(file),
+	})
+	return err
+}
+
+func (c *LeaderboardCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	kind, period, page := activity.LeaderboardActivity, activity.LeaderboardWeek, 0
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "type":
+			kind = activity.NormalizeLeaderboardKind(opt.StringValue())
+		case "period":
+			period = activity.NormalizeLeaderboardPeriod(opt.StringValue())
+		case "page":
+			page = int(opt.IntValue()) - 1
+		}
+	}
+	embed, components, file, err := buildLeaderboardMessage(c.dataDir, kind, period, page)
+	if err != nil {
+		return respondUserListError(s, i, err)
+	}
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Files:      buildOptionalFiles(file),
+		},
+	})
+}
+
+func (c *LeaderboardCommand) SlashDefinition() *discordgo.ApplicationCommand {
+	minValue := 1.0
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "type",
+				Description: "種別 (activity / vandal / restore)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "総合スコア", Value: activity.LeaderboardActivity},
+					{Name: "荒らし", Value: activity.LeaderboardVandal},
+					{Name: "修復", Value: activity.LeaderboardRestore},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "period",
+				Description: "期間 (7d / 30d / all)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "直近7日", Value: activity.LeaderboardWeek},
+					{Name: "直近30日", Value: activity.LeaderboardMonth},
+					{Name: "通算", Value: activity.LeaderboardAllTime},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "page",
+				Description: "ページ番号 (1から)",
+				Required:    false,
+				MinValue:    &minValue,
+			},
+		},
+	}
+}
+
+// HandleLeaderboardPagination leaderboard:<type>:<period>:<page> のボタンを処理。
+// 表彰台画像はページに依らないため、元のメッセージの添付をそのまま使う
+func HandleLeaderboardPagination(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string) {
+	parts := strings.Split(strings.TrimPrefix(i.MessageComponentData().CustomID, leaderboardPrefix), ":")
+	if len(parts) != 3 {
+		return
+	}
+	page, _ := strconv.Atoi(parts[2])
+	embed, components, _, err := buildLeaderboardMessage(dataDir, parts[0], parts[1], page)
+	if err != nil {
+		_ = respondUserListError(s, i, err)
+		return
+	}
+	embed.Image = nil
+	if i.Message != nil && len(i.Message.Embeds) > 0 && i.Message.Embeds[0].Image != nil {
+		embed.Image = &discordgo.MessageEmbedImage{URL: i.Message.Embeds[0].Image.URL}
+	}
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+// buildLeaderboardMessage 今日（JST）までの期間で集計したページ・ページ送りボタン・表彰台画像
+func buildLeaderboardMessage(dataDir, kind, period string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, *discordgo.File, error) {
+	entries, err := activity.LoadUserActivityMap(dataDir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	lb := activity.BuildLeaderboard(entries, kind, period, time.Now())
+	embed, page, maxPage := embeds.BuildLeaderboardEmbed(lb, page, leaderboardPageSize)
+	var file *discordgo.File
+	if len(lb.Entries) > 0 {
+		if buf, err := embeds.BuildLeaderboardPodium(lb); err != nil {
+			log.Printf("Failed to render leaderboard podium: %v", err)
+		} else {
+			file = &discordgo.File{Name: "leaderboard_podium.png", ContentType: "image/png", Reader: buf}
+			embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + file.Name}
+		}
+	}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "前へ",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("%s%s:%s:%d", leaderboardPrefix, lb.Kind, lb.Period, page-1),
+					Disabled: page <= 0,
+				},
+				discordgo.Button{
+					Label:    "次へ",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("%s%s:%s:%d", leaderboardPrefix, lb.Kind, lb.Period, page+1),
+					Disabled: page >= maxPage,
+				},
+			},
+		},
+	}
+	return embed, components, file, nil
+}
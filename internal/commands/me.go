@@ -1,13 +1,13 @@
 package commands
 
 import (
-	"bytes"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -16,13 +16,15 @@ import (
 type MeCommand struct {
 	dataDir    string
 	limiter    *utils.RateLimiter
+	settings   *config.SettingsManager
 	httpClient *http.Client
 }
 
-func NewMeCommand(dataDir string, limiter *utils.RateLimiter) *MeCommand {
+func NewMeCommand(dataDir string, limiter *utils.RateLimiter, settings *config.SettingsManager) *MeCommand {
 	return &MeCommand{
 		dataDir:    dataDir,
 		limiter:    limiter,
+		settings:   settings,
 		httpClient: activity.NewPixelHTTPClient(),
 	}
 }
@@ -39,7 +41,7 @@ func (c *MeCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate
 		_, err := s.ChannelMessageSend(m.ChannelID, "❌ ユーザー情報を取得できませんでした。")
 		return err
 	}
-	return c.respondMeMessage(s, m.ChannelID, user.ID, user)
+	return c.respondMeMessage(s, m.ChannelID, user.ID, user, c.settings.GuildLocation(m.GuildID))
 }
 
 func (c *MeCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
@@ -53,9 +55,10 @@ func (c *MeCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionC
 			},
 		})
 	}
-	embed, file, err := c.buildMeEmbedByDiscordID(user.ID, user)
+	loc := c.settings.GuildLocation(i.GuildID)
+	embed, file, err := c.buildMeEmbedByDiscordID(user.ID, user, loc)
 	if err != nil {
-		return c.startLinkFlow(s, user, func(content string) error {
+		return c.startLinkFlow(s, user, loc, func(content string) error {
 			return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -81,10 +84,10 @@ func (c *MeCommand) SlashDefinition() *discordgo.ApplicationCommand {
 	}
 }
 
-func (c *MeCommand) respondMeMessage(s *discordgo.Session, channelID, discordID string, user *discordgo.User) error {
-	embed, file, err := c.buildMeEmbedByDiscordID(discordID, user)
+func (c *MeCommand) respondMeMessage(s *discordgo.Session, channelID, discordID string, user *discordgo.User, loc *time.Location) error {
+	embed, file, err := c.buildMeEmbedByDiscordID(discordID, user, loc)
 	if err != nil {
-		return c.startLinkFlow(s, user, func(content string) error {
+		return c.startLinkFlow(s, user, loc, func(content string) error {
 			_, e := s.ChannelMessageSend(channelID, content)
 			return e
 		})
@@ -96,16 +99,16 @@ func (c *MeCommand) respondMeMessage(s *discordgo.Session, channelID, discordID
 	return err
 }
 
-func (c *MeCommand) buildMeEmbedByDiscordID(discordID string, user *discordgo.User) (*discordgo.MessageEmbed, *discordgo.File, error) {
+func (c *MeCommand) buildMeEmbedByDiscordID(discordID string, user *discordgo.User, loc *time.Location) (*discordgo.MessageEmbed, *discordgo.File, error) {
 	entry, err := loadUserActivityByID(c.dataDir, "", discordID)
 	if err != nil {
 		return nil, nil, err
 	}
-	embed, file := buildMeCardEmbed(entry, user)
+	embed, file := buildMeCardEmbed(c.dataDir, entry, user, loc)
 	return embed, file, nil
 }
 
-func buildMeCardEmbed(entry userActivityEntry, user *discordgo.User) (*discordgo.MessageEmbed, *discordgo.File) {
+func buildMeCardEmbed(dataDir string, entry userActivityEntry, user *discordgo.User, loc *time.Location) (*discordgo.MessageEmbed, *discordgo.File) {
 	name := utils.FormatUserDisplayName(entry.Name, entry.ID)
 	alliance := entry.Alliance
 	if alliance == "" {
@@ -124,8 +127,7 @@ func buildMeCardEmbed(entry userActivityEntry, user *discordgo.User) (*discordgo
 	}
 	lastSeenText := "-"
 	if !entry.LastSeen.IsZero() {
-		jst := time.FixedZone("JST", 9*3600)
-		lastSeenText = entry.LastSeen.In(jst).Format("2006-01-02 15:04:05")
+		lastSeenText = entry.LastSeen.In(loc).Format("2006-01-02 15:04:05 MST")
 	}
 	mention := ""
 	if user != nil {
@@ -140,15 +142,15 @@ func buildMeCardEmbed(entry userActivityEntry, user *discordgo.User) (*discordgo
 			{Name: "ゲーム内ユーザー", Value: name, Inline: true},
 			{Name: "同盟", Value: alliance, Inline: true},
 			{Name: "Discord ID", Value: discordID, Inline: true},
-			{Name: "荒らし数", Value: fmt.Sprintf("%d", entry.VandalCount), Inline: true},
-			{Name: "修復数", Value: fmt.Sprintf("%d", entry.RestoredCount), Inline: true},
+			{Name: "荒らし数", Value: formatEstimatedCount(entry.VandalCount, entry.EstimatedVandal), Inline: true},
+			{Name: "修復数", Value: formatEstimatedCount(entry.RestoredCount, entry.EstimatedRestored), Inline: true},
 			{Name: "スコア", Value: fmt.Sprintf("%d", entry.Score), Inline: true},
 			{Name: "最終観測", Value: lastSeenText, Inline: false},
 		},
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
 
-	file := buildMeCardImageFile(entry)
+	file := buildProfileCardFile(dataDir, entry)
 	if file != nil {
 		embed.Image = &discordgo.MessageEmbedImage{
 			URL: "attachment://" + file.Name,
@@ -157,26 +159,6 @@ func buildMeCardEmbed(entry userActivityEntry, user *discordgo.User) (*discordgo
 	return embed, file
 }
 
-func buildMeCardImageFile(entry userActivityEntry) *discordgo.File {
-	if entry.Picture != "" {
-		if file := utils.DecodePictureDataURL(entry.Picture); file != nil {
-			return file
-		}
-	}
-	if entry.ID == "" {
-		return nil
-	}
-	data, err := utils.BuildIdenticonPNG(entry.ID, userActivityIconSize)
-	if err != nil {
-		return nil
-	}
-	return &discordgo.File{
-		Name:        "user_identicon.png",
-		ContentType: "image/png",
-		Reader:      bytes.NewReader(data),
-	}
-}
-
 func discordTag(user *discordgo.User) string {
 	if user == nil {
 		return ""
@@ -108,6 +108,7 @@ func (m *meLinkManager) release(userID string) {
 func (c *MeCommand) startLinkFlow(
 	s *discordgo.Session,
 	user *discordgo.User,
+	loc *time.Location,
 	sendFallback func(content string) error,
 ) error {
 	session, remaining, created := globalMeLinkManager.acquire(user.ID)
@@ -155,11 +156,11 @@ func (c *MeCommand) startLinkFlow(
 		_ = sendFallback("📩 DMに認証用URLを送信しました。進捗はこのチャンネルにも通知します。")
 	}
 
-	go c.pollLinkResult(s, user, session)
+	go c.pollLinkResult(s, user, session, loc)
 	return nil
 }
 
-func (c *MeCommand) pollLinkResult(s *discordgo.Session, user *discordgo.User, session *meLinkSession) {
+func (c *MeCommand) pollLinkResult(s *discordgo.Session, user *discordgo.User, session *meLinkSession, loc *time.Location) {
 	defer globalMeLinkManager.release(user.ID)
 
 	ctx, cancel := context.WithTimeout(context.Background(), meLinkTimeout)
@@ -202,7 +203,7 @@ func (c *MeCommand) pollLinkResult(s *discordgo.Session, user *discordgo.User, s
 				}
 				return
 			}
-			embed, file := buildMeCardEmbed(entry, user)
+			embed, file := buildMeCardEmbed(c.dataDir, entry, user, loc)
 			_ = sendDMEmbed(s, user.ID, embed, file)
 			if session.notify != nil {
 				session.notify("✅ 連携が完了しました。DMにユーザーカードを送信しました。")
@@ -240,17 +241,25 @@ func updateUserActivityLink(dataDir string, painter *activity.PaintedBy, user *d
 		entry.LastSeen = time.Now().UTC().Format(time.RFC3339Nano)
 		raw[painterID] = entry
 		result = userActivityEntry{
-			ID:            painterID,
-			Name:          entry.Name,
-			AllianceID:    entry.AllianceID,
-			Alliance:      entry.AllianceName,
-			Discord:       entry.Discord,
-			DiscordID:     entry.DiscordID,
-			Picture:       entry.Picture,
-			VandalCount:   entry.VandalCount,
-			RestoredCount: entry.RestoredCount,
-			Score:         entry.ActivityScore,
-			LastSeen:      parseUserListTime(entry.LastSeen),
+			ID:                painterID,
+			Name:              entry.Name,
+			AllianceID:        entry.AllianceID,
+			Alliance:          entry.AllianceName,
+			Discord:           entry.Discord,
+			DiscordID:         entry.DiscordID,
+			Picture:           entry.Picture,
+			VandalCount:       entry.VandalCount,
+			RestoredCount:     entry.RestoredCount,
+			Score:             entry.ActivityScore,
+			LastSeen:          parseUserListTime(entry.LastSeen),
+			DailyVandal:       entry.DailyVandalCounts,
+			DailyRestored:     entry.DailyRestoredCounts,
+			EstimatedVandal:   entry.EstimatedVandalCount,
+			EstimatedRestored: entry.EstimatedRestoredCount,
+			SuspectedBot:      entry.SuspectedBot,
+			Banned:            entry.Banned,
+			TimedOut:          entry.TimedOut,
+			StatusHistory:     entry.StatusHistory,
 		}
 		return nil
 	})
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"Koukyo_discord_bot/internal/config"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type ModerationChannelCommand struct {
+	settings *config.SettingsManager
+}
+
+func NewModerationChannelCommand(settings *config.SettingsManager) *ModerationChannelCommand {
+	return &ModerationChannelCommand{settings: settings}
+}
+
+func (c *ModerationChannelCommand) Name() string { return "moderationchannel" }
+func (c *ModerationChannelCommand) Description() string {
+	return "モデレーション通知（bot の疑い）の送信先チャンネルを設定します"
+}
+
+func (c *ModerationChannelCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	if !isAdminOrGold(s, m.GuildID, m.Author.ID) {
+		_, err := s.ChannelMessageSend(m.ChannelID, "❌ このコマンドは管理者のみ使用できます。")
+		return err
+	}
+	if len(args) > 0 && strings.EqualFold(args[0], "off") {
+		c.settings.UpdateGuildSetting(m.GuildID, func(gs *config.GuildSettings) {
+			gs.ModerationChannel = nil
+		})
+		_, err := s.ChannelMessageSend(m.ChannelID, "✅ モデレーション通知チャンネルを解除しました。")
+		return err
+	}
+	channelID := m.ChannelID
+	c.settings.UpdateGuildSetting(m.GuildID, func(gs *config.GuildSettings) {
+		gs.ModerationChannel = &channelID
+	})
+	_, err := s.ChannelMessageSend(m.ChannelID, "✅ このチャンネルをモデレーション通知先に設定しました。")
+	return err
+}
+
+func (c *ModerationChannelCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if !isAdminOrGold(s, i.GuildID, interactionUserID(i)) {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ このコマンドは管理者のみ使用できます。",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	var targetChannelID string
+	mode := "set"
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "channel":
+			targetChannelID = opt.ChannelValue(nil).ID
+		case "mode":
+			mode = opt.StringValue()
+		}
+	}
+
+	if strings.EqualFold(mode, "off") || strings.EqualFold(mode, "disable") {
+		c.settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+			gs.ModerationChannel = nil
+		})
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "✅ モデレーション通知チャンネルを解除しました。",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if targetChannelID == "" {
+		targetChannelID = i.ChannelID
+	}
+
+	c.settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+		gs.ModerationChannel = &targetChannelID
+	})
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("✅ モデレーション通知チャンネルを <#%s> に設定しました。", targetChannelID),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c *ModerationChannelCommand) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionChannel,
+				Name:        "channel",
+				Description: "モデレーション通知の送信先チャンネル",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "mode",
+				Description: "off を指定すると解除します",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "set", Value: "set"},
+					{Name: "off", Value: "off"},
+				},
+			},
+		},
+	}
+}
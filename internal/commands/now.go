@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/embeds"
 	"Koukyo_discord_bot/internal/monitor"
 	"log"
@@ -9,11 +10,12 @@ import (
 )
 
 type NowCommand struct {
-	monitor *monitor.Monitor
+	monitors *monitor.Set
+	settings *config.SettingsManager
 }
 
-func NewNowCommand(mon *monitor.Monitor) *NowCommand {
-	return &NowCommand{monitor: mon}
+func NewNowCommand(monitors *monitor.Set, settings *config.SettingsManager) *NowCommand {
+	return &NowCommand{monitors: monitors, settings: settings}
 }
 
 func (c *NowCommand) Name() string {
@@ -25,18 +27,24 @@ func (c *NowCommand) Description() string {
 }
 
 func (c *NowCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
-	if c.monitor == nil {
+	artworkID, _ := artworkIDFromArgs(args)
+	mon, err := resolveArtworkMonitor(c.monitors, artworkID)
+	if err != nil {
+		_, sendErr := s.ChannelMessageSend(m.ChannelID, err.Error())
+		return sendErr
+	}
+	if mon == nil {
 		_, err := s.ChannelMessageSend(m.ChannelID, "❌ nowでエラーが発生しました: 監視システムが初期化されていません。")
 		return err
 	}
-	if !c.monitor.State.HasData() {
+	if !mon.State.HasData() {
 		_, err := s.ChannelMessageSend(m.ChannelID, "❌ nowでエラーが発生しました: 監視データがまだ受信できていません。")
 		return err
 	}
-	embed := embeds.BuildNowEmbed(c.monitor)
+	embed := embeds.BuildNowEmbed(mon, c.settings.GuildLocation(m.GuildID))
 
 	// 画像データを取得
-	images := c.monitor.GetLatestImages()
+	images := mon.GetLatestImages()
 	if images != nil && len(images.LiveImage) > 0 && len(images.DiffImage) > 0 {
 		// 画像結合（Live + Diff）
 		combinedImage, err := embeds.CombineImages(images.LiveImage, images.DiffImage)
@@ -65,13 +73,23 @@ func (c *NowCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreat
 	}
 
 	// 画像がない場合は通常のEmbedのみ
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
+	_, err = s.ChannelMessageSendEmbed(m.ChannelID, embed)
 	return err
 }
 
 func (c *NowCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	log.Println("ExecuteSlash: /now command called")
-	if c.monitor == nil {
+	mon, err := resolveArtworkMonitor(c.monitors, artworkIDFromOptions(i.ApplicationCommandData().Options))
+	if err != nil {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: err.Error(),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+	if mon == nil {
 		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -80,7 +98,7 @@ func (c *NowCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.Interaction
 			},
 		})
 	}
-	if !c.monitor.State.HasData() {
+	if !mon.State.HasData() {
 		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -91,7 +109,7 @@ func (c *NowCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.Interaction
 	}
 
 	// まず即座にDeferredで応答（3秒制限回避）
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
 	if err != nil {
@@ -102,11 +120,11 @@ func (c *NowCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.Interaction
 
 	// 実際のデータ取得とEmbed生成
 	log.Println("Building embed...")
-	embed := embeds.BuildNowEmbed(c.monitor)
+	embed := embeds.BuildNowEmbed(mon, c.settings.GuildLocation(i.GuildID))
 	log.Println("Embed built successfully")
 
 	// 画像データを取得
-	images := c.monitor.GetLatestImages()
+	images := mon.GetLatestImages()
 	if images != nil && len(images.LiveImage) > 0 && len(images.DiffImage) > 0 {
 		// 画像結合（Live + Diff）
 		combinedImage, err := embeds.CombineImages(images.LiveImage, images.DiffImage)
@@ -159,5 +177,6 @@ func (c *NowCommand) SlashDefinition() *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
 		Name:        c.Name(),
 		Description: c.Description(),
+		Options:     appendArtworkOption(nil, c.monitors),
 	}
 }
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"Koukyo_discord_bot/internal/notifications"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// outboxListLimit 一覧に表示する未送信通知の件数
+const outboxListLimit = 15
+
+// OutboxCommand 送信に失敗して保存されている通知の確認/再送/破棄（管理者のみ）
+type OutboxCommand struct {
+	notifier *notifications.Notifier
+}
+
+func NewOutboxCommand(notifier *notifications.Notifier) *OutboxCommand {
+	return &OutboxCommand{notifier: notifier}
+}
+
+func (c *OutboxCommand) Name() string { return "outbox" }
+func (c *OutboxCommand) Description() string {
+	return "送信に失敗した通知の確認/再送/破棄を行います（管理者のみ）"
+}
+
+func (c *OutboxCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	if !isAdminOrGold(s, m.GuildID, m.Author.ID) {
+		_, err := s.ChannelMessageSend(m.ChannelID, "❌ このコマンドは管理者のみ使用できます。")
+		return err
+	}
+	action := "show"
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+	}
+	content, embed := c.run(m.GuildID, action)
+	if embed != nil {
+		_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
+		return err
+	}
+	_, err := s.ChannelMessageSend(m.ChannelID, content)
+	return err
+}
+
+func (c *OutboxCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if !isAdminOrGold(s, i.GuildID, interactionUserID(i)) {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ このコマンドは管理者のみ使用できます。",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+	action := "show"
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "action" {
+			action = opt.StringValue()
+		}
+	}
+	// 再送は Discord 側の応答待ちで時間がかかることがあるため先に応答を保留する
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	}); err != nil {
+		return err
+	}
+	content, embed := c.run(i.GuildID, action)
+	edit := &discordgo.WebhookEdit{Content: &content}
+	if embed != nil {
+		edit.Embeds = &[]*discordgo.MessageEmbed{embed}
+	}
+	_, err := s.InteractionResponseEdit(i.Interaction, edit)
+	return err
+}
+
+func (c *OutboxCommand) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "action",
+				Description: "show: 一覧 / flush: 今すぐ再送 / clear: このサーバー分を破棄",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "show", Value: "show"},
+					{Name: "flush", Value: "flush"},
+					{Name: "clear", Value: "clear"},
+				},
+			},
+		},
+	}
+}
+
+// run action を実行し、テキストか Embed を返す
+func (c *OutboxCommand) run(guildID, action string) (string, *discordgo.MessageEmbed) {
+	outbox := c.notifier.Outbox()
+	if outbox == nil {
+		return "❌ 未送信通知の保存は無効です。", nil
+	}
+	switch action {
+	case "flush":
+		done := outbox.Flush()
+		rest := len(outbox.Entries(guildID))
+		return fmt.Sprintf("🔁 再送を実行しました（送信/破棄 %d件、このサーバーの残り %d件）。", done, rest), nil
+	case "clear":
+		removed := outbox.Clear(guildID)
+		return fmt.Sprintf("🗑️ このサーバー宛ての未送信通知を %d件 破棄しました。", removed), nil
+	default:
+		return "", buildOutboxEmbed(outbox.Entries(guildID), time.Now())
+	}
+}
+
+func buildOutboxEmbed(entries []notifications.OutboxEntry, now time.Time) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:     fmt.Sprintf("📮 未送信の通知（%d件）", len(entries)),
+		Color:     0x5865F2,
+		Timestamp: now.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Discord 復旧後に古い順で自動再送します",
+		},
+	}
+	if len(entries) == 0 {
+		embed.Description = "未送信の通知はありません。"
+		return embed
+	}
+	lines := make([]string, 0, min(len(entries), outboxListLimit)+1)
+	for _, e := range entries[:min(len(entries), outboxListLimit)] {
+		line := fmt.Sprintf("`#%d` **%s** <#%s> %s・試行%d回", e.ID, e.Kind, e.ChannelID, formatOutboxAge(now.Sub(e.CreatedAt)), e.Attempts)
+		if len(e.Files) > 0 {
+			line += fmt.Sprintf("・添付%d", len(e.Files))
+		}
+		if e.LastError != "" {
+			line += "\n　└ " + truncateLabel(e.LastError, 80)
+		}
+		lines = append(lines, line)
+	}
+	if len(entries) > outboxListLimit {
+		lines = append(lines, fmt.Sprintf("…他 %d件", len(entries)-outboxListLimit))
+	}
+	embed.Description = strings.Join(lines, "\n")
+	return embed
+}
+
+func formatOutboxAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "たった今"
+	case d < time.Hour:
+		return fmt.Sprintf("%d分前", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%d時間前", int(d.Hours()))
+	}
+}
@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/embeds"
+	"Koukyo_discord_bot/internal/monitor"
+	"Koukyo_discord_bot/internal/utils"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	pixelHistoryDefaultDays = 7
+	pixelHistoryMaxDays     = 30
+	// pixelHistoryMaxLines 埋め込みに並べるイベントの件数（新しい順）
+	pixelHistoryMaxLines = 20
+	pixelHistoryMapName  = "pixel_map.png"
+)
+
+// PixelHistoryCommand 帰属ジャーナルからピクセルごとの履歴、ユーザーごとの塗ったピクセルを表示
+type PixelHistoryCommand struct {
+	monitors *monitor.Set
+	settings *config.SettingsManager
+	dataDir  string
+}
+
+func NewPixelHistoryCommand(monitors *monitor.Set, settings *config.SettingsManager, dataDir string) *PixelHistoryCommand {
+	return &PixelHistoryCommand{monitors: monitors, settings: settings, dataDir: dataDir}
+}
+
+func (c *PixelHistoryCommand) Name() string { return "pixelhistory" }
+func (c *PixelHistoryCommand) Description() string {
+	return "ピクセルごとの荒らし/修復の履歴、またはユーザーが塗ったピクセルを表示"
+}
+
+func (c *PixelHistoryCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	_, err := s.ChannelMessageSend(m.ChannelID, "このコマンドはスラッシュコマンドで利用してください。")
+	return err
+}
+
+func (c *PixelHistoryCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	coords := ""
+	userID := ""
+	days := pixelHistoryDefaultDays
+	opts := i.ApplicationCommandData().Options
+	for _, opt := range opts {
+		switch opt.Name {
+		case "coords":
+			coords = strings.TrimSpace(opt.StringValue())
+		case "user":
+			userID = strings.TrimSpace(opt.StringValue())
+		case "days":
+			days = min(max(int(opt.IntValue()), 1), pixelHistoryMaxDays)
+		}
+	}
+	if coords == "" && userID == "" {
+		return respondUserListError(s, i, fmt.Errorf("coords か user のどちらかを指定してください"))
+	}
+	mon, err := resolveArtworkMonitor(c.monitors, artworkIDFromOptions(opts))
+	if err != nil {
+		return respondUserListError(s, i, err)
+	}
+	dataDir := artworkDataDirFor(c.monitors, mon, c.dataDir)
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	loc := c.settings.GuildLocation(i.GuildID)
+
+	var embed *discordgo.MessageEmbed
+	var file *discordgo.File
+	if coords != "" {
+		x, y, err := parsePixelCoords(coords)
+		if err != nil {
+			return respondUserListError(s, i, err)
+		}
+		embed, err = buildPixelTimelineEmbed(dataDir, x, y, since, days, loc)
+		if err != nil {
+			return respondUserListError(s, i, err)
+		}
+	} else {
+		embed, file, err = buildPainterPixelsMessage(dataDir, userID, since, days, loc)
+		if err != nil {
+			return respondUserListError(s, i, err)
+		}
+	}
+	embed.Title += artworkTitleSuffix(mon)
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Files:  buildOptionalFiles(file),
+		},
+	})
+}
+
+func (c *PixelHistoryCommand) SlashDefinition() *discordgo.ApplicationCommand {
+	minDays := 1.0
+	maxDays := float64(pixelHistoryMaxDays)
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: appendArtworkOption([]*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "coords",
+				Description: "ピクセル座標 (TlX-TlY-PxX-PxY 例: 1818-806-989-358)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "user",
+				Description: "wplace のユーザーID（塗ったピクセルを地図で表示）",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "days",
+				Description: fmt.Sprintf("遡る日数 (既定 %d日、最大 %d日)", pixelHistoryDefaultDays, pixelHistoryMaxDays),
+				Required:    false,
+				MinValue:    &minDays,
+				MaxValue:    maxDays,
+			},
+		}, c.monitors),
+	}
+}
+
+// parsePixelCoords 「TlX-TlY-PxX-PxY」を全体のピクセル座標に変換
+func parsePixelCoords(value string) (int, int, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 4 {
+		return 0, 0, fmt.Errorf("座標形式が正しくありません: TlX-TlY-PxX-PxY 例: 1818-806-989-358")
+	}
+	nums := make([]int, len(parts))
+	for idx, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("座標値が不正です。0以上の整数で指定してください")
+		}
+		nums[idx] = n
+	}
+	if nums[0] >= utils.WplaceTilesPerEdge || nums[1] >= utils.WplaceTilesPerEdge {
+		return 0, 0, fmt.Errorf("タイル座標が範囲外です: %d-%d 有効範囲: 0～%d", nums[0], nums[1], utils.WplaceTilesPerEdge-1)
+	}
+	if nums[2] >= utils.WplaceTileSize || nums[3] >= utils.WplaceTileSize {
+		return 0, 0, fmt.Errorf("ピクセル座標が範囲外です: %d-%d 有効範囲: 0～%d", nums[2], nums[3], utils.WplaceTileSize-1)
+	}
+	return nums[0]*utils.WplaceTileSize + nums[2], nums[1]*utils.WplaceTileSize + nums[3], nil
+}
+
+// buildPixelTimelineEmbed 1ピクセルの帰属の履歴（新しい順）
+func buildPixelTimelineEmbed(dataDir string, x, y int, since time.Time, days int, loc *time.Location) (*discordgo.MessageEmbed, error) {
+	events, err := activity.ReadPixelJournal(dataDir, since, func(ev activity.JournalEvent) bool {
+		return ev.X == x && ev.Y == y
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := loadPainterNames(dataDir)
+	lines := make([]string, 0, min(len(events), pixelHistoryMaxLines))
+	for idx := len(events) - 1; idx >= 0 && len(lines) < pixelHistoryMaxLines; idx-- {
+		ev := events[idx]
+		lines = append(lines, fmt.Sprintf("%s %s %s%s",
+			ev.At.In(loc).Format("01-02 15:04:05"), journalKindLabel(ev.Kind), painterLabel(names, ev.Painter), inferredMark(ev)))
+	}
+	value := strings.Join(lines, "\n")
+	if value == "" {
+		value = "該当なし"
+	}
+
+	coord := utils.CoordinateFromAbsolute(x, y)
+	now := time.Now()
+	return &discordgo.MessageEmbed{
+		Title:       "🧭 ピクセル履歴: " + utils.FormatHyphenCoords(coord),
+		URL:         utils.BuildWplaceHighDetailPixelURL(coord),
+		Description: fmt.Sprintf("過去%d日 / %d件 / 時刻: %s", days, len(events), zoneName(now, loc)),
+		Color:       0x3498DB,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: fmt.Sprintf("履歴 (新しい順、最大%d件)", pixelHistoryMaxLines), Value: value},
+		},
+		Footer:    &discordgo.MessageEmbedFooter{Text: "(推定) = 省電力推定による帰属"},
+		Timestamp: now.Format(time.RFC3339),
+	}, nil
+}
+
+// buildPainterPixelsMessage ユーザーが塗ったピクセルの集計・直近のイベントと地図画像
+func buildPainterPixelsMessage(dataDir, painterID string, since time.Time, days int, loc *time.Location) (*discordgo.MessageEmbed, *discordgo.File, error) {
+	events, err := activity.ReadPixelJournal(dataDir, since, func(ev activity.JournalEvent) bool {
+		return ev.Painter == painterID
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	names := loadPainterNames(dataDir)
+	displayName := painterLabel(names, painterID)
+	if len(events) == 0 {
+		return nil, nil, fmt.Errorf("過去%d日に %s の帰属はありません", days, displayName)
+	}
+
+	vandal, restore, inferred := 0, 0, 0
+	pixels := make(map[[2]int]bool)
+	for _, ev := range events {
+		if ev.Kind == activity.JournalKindRestore {
+			restore++
+		} else {
+			vandal++
+		}
+		if ev.Inferred {
+			inferred++
+		}
+		pixels[[2]int{ev.X, ev.Y}] = true
+	}
+	lines := make([]string, 0, min(len(events), pixelHistoryMaxLines))
+	for idx := len(events) - 1; idx >= 0 && len(lines) < pixelHistoryMaxLines; idx-- {
+		ev := events[idx]
+		lines = append(lines, fmt.Sprintf("%s %s %s%s",
+			ev.At.In(loc).Format("01-02 15:04:05"), journalKindLabel(ev.Kind),
+			utils.FormatHyphenCoords(utils.CoordinateFromAbsolute(ev.X, ev.Y)), inferredMark(ev)))
+	}
+
+	now := time.Now()
+	embed := &discordgo.MessageEmbed{
+		Title:       "🧭 塗ったピクセル: " + displayName,
+		Description: fmt.Sprintf("過去%d日 / 時刻: %s", days, zoneName(now, loc)),
+		Color:       0x3498DB,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "荒らし", Value: fmt.Sprintf("%d", vandal), Inline: true},
+			{Name: "修復", Value: fmt.Sprintf("%d", restore), Inline: true},
+			{Name: "ピクセル数", Value: fmt.Sprintf("%d (推定 %d件)", len(pixels), inferred), Inline: true},
+			{Name: fmt.Sprintf("直近のイベント (最大%d件)", pixelHistoryMaxLines), Value: strings.Join(lines, "\n")},
+		},
+		Footer:    &discordgo.MessageEmbedFooter{Text: "地図の色はピクセルごとの最後の帰属 (赤=荒らし / 緑=修復)"},
+		Timestamp: now.Format(time.RFC3339),
+	}
+
+	buf, err := embeds.BuildPixelMapPNG(fmt.Sprintf("Pixels by ID %s (last %d days)", painterID, days), events)
+	if err != nil {
+		return embed, nil, nil
+	}
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + pixelHistoryMapName}
+	return embed, &discordgo.File{Name: pixelHistoryMapName, ContentType: "image/png", Reader: buf}, nil
+}
+
+// loadPainterNames user_activity から ID→表示名を引く（読めなければ空）
+func loadPainterNames(dataDir string) map[string]string {
+	entries, err := activity.LoadUserActivityMap(dataDir)
+	if err != nil {
+		return nil
+	}
+	names := make(map[string]string, len(entries))
+	for id, entry := range entries {
+		if entry != nil {
+			names[id] = entry.Name
+		}
+	}
+	return names
+}
+
+// painterLabel 「名前#ID」。名前未取得（"ID:<id>" のまま）なら ID のみ
+func painterLabel(names map[string]string, id string) string {
+	name := names[id]
+	if name == "ID:"+id {
+		name = ""
+	}
+	return utils.FormatUserDisplayName(name, id)
+}
+
+func journalKindLabel(kind string) string {
+	if kind == activity.JournalKindRestore {
+		return "🛠️ 修復"
+	}
+	return "🚨 荒らし"
+}
+
+func inferredMark(ev activity.JournalEvent) string {
+	if ev.Inferred {
+		return " (推定)"
+	}
+	return ""
+}
@@ -0,0 +1,17 @@
+package commands
+
+import "testing"
+
+func TestParsePixelCoords(t *testing.T) {
+	t.Parallel()
+
+	x, y, err := parsePixelCoords("1818-806-989-358")
+	if err != nil || x != 1818989 || y != 806358 {
+		t.Fatalf("got (%d, %d, %v)", x, y, err)
+	}
+	for _, bad := range []string{"1818-806", "1818-806-1000-0", "2048-0-0-0", "a-b-c-d", "-1-0-0-0"} {
+		if _, _, err := parsePixelCoords(bad); err == nil {
+			t.Errorf("%q should be rejected", bad)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/monitor"
 	"fmt"
 	"math"
@@ -18,11 +19,12 @@ const (
 
 // PredictCommand 現在の修復速度から完全修復までの予測時間を表示
 type PredictCommand struct {
-	mon *monitor.Monitor
+	monitors *monitor.Set
+	settings *config.SettingsManager
 }
 
-func NewPredictCommand(mon *monitor.Monitor) *PredictCommand {
-	return &PredictCommand{mon: mon}
+func NewPredictCommand(monitors *monitor.Set, settings *config.SettingsManager) *PredictCommand {
+	return &PredictCommand{monitors: monitors, settings: settings}
 }
 
 func (c *PredictCommand) Name() string { return "predict" }
@@ -34,6 +36,7 @@ func (c *PredictCommand) Description() string {
 func (c *PredictCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
 	metric := "overall"
 	duration := predictDefaultDuration
+	artworkID, args := artworkIDFromArgs(args)
 	for _, a := range args {
 		if strings.HasPrefix(a, "metric=") {
 			metric = strings.TrimSpace(strings.TrimPrefix(a, "metric="))
@@ -50,7 +53,12 @@ func (c *PredictCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageC
 		}
 	}
 
-	embed, err := c.buildPredictionEmbed(metric, duration)
+	mon, err := resolveArtworkMonitor(c.monitors, artworkID)
+	if err != nil {
+		_, sendErr := s.ChannelMessageSend(m.ChannelID, err.Error())
+		return sendErr
+	}
+	embed, err := c.buildPredictionEmbed(mon, metric, duration, c.settings.GuildLocation(m.GuildID))
 	if err != nil {
 		_, sendErr := s.ChannelMessageSend(m.ChannelID, err.Error())
 		return sendErr
@@ -82,7 +90,18 @@ func (c *PredictCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.Interac
 		}
 	}
 
-	embed, err := c.buildPredictionEmbed(metric, duration)
+	opts := i.ApplicationCommandData().Options
+	mon, err := resolveArtworkMonitor(c.monitors, artworkIDFromOptions(opts))
+	if err != nil {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: err.Error(),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+	embed, err := c.buildPredictionEmbed(mon, metric, duration, c.settings.GuildLocation(i.GuildID))
 	if err != nil {
 		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -105,7 +124,7 @@ func (c *PredictCommand) SlashDefinition() *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
 		Name:        c.Name(),
 		Description: c.Description(),
-		Options: []*discordgo.ApplicationCommandOption{
+		Options: appendArtworkOption([]*discordgo.ApplicationCommandOption{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "metric",
@@ -130,34 +149,34 @@ func (c *PredictCommand) SlashDefinition() *discordgo.ApplicationCommand {
 					{Name: "24h", Value: "24h"},
 				},
 			},
-		},
+		}, c.monitors),
 	}
 }
 
-func (c *PredictCommand) buildPredictionEmbed(metric string, duration time.Duration) (*discordgo.MessageEmbed, error) {
-	if c.mon == nil {
+func (c *PredictCommand) buildPredictionEmbed(mon *monitor.Monitor, metric string, duration time.Duration, loc *time.Location) (*discordgo.MessageEmbed, error) {
+	if mon == nil {
 		return nil, fmt.Errorf("❌ predictでエラーが発生しました: 監視システムが初期化されていません。")
 	}
-	if !c.mon.State.HasData() {
+	if !mon.State.HasData() {
 		return nil, fmt.Errorf("❌ predictでエラーが発生しました: 監視データがまだ受信できていません。")
 	}
 
-	data := c.mon.State.GetLatestData()
+	data := mon.State.GetLatestData()
 	if data == nil {
 		return nil, fmt.Errorf("❌ predictでエラーが発生しました: 監視データが取得できませんでした。")
 	}
 
 	useWeighted := strings.EqualFold(strings.TrimSpace(metric), "weighted")
 	current, metricLabel, fallbackToOverall := predictMetricCurrent(data, useWeighted)
-	history := c.mon.State.GetDiffHistory(duration, useWeighted && !fallbackToOverall)
+	history := mon.State.GetDiffHistory(duration, useWeighted && !fallbackToOverall)
 	history = sanitizePredictHistory(history)
 
-	nowJST := time.Now().In(commandJST)
+	now := time.Now().In(loc)
 	embed := &discordgo.MessageEmbed{
-		Title:       "🔮 修復予測",
+		Title:       "🔮 修復予測" + artworkTitleSuffix(mon),
 		Description: fmt.Sprintf("現在の%sと直近データから、完全修復(0.00%%)までの時間を推定します。", metricLabel),
 		Color:       0x3498DB,
-		Timestamp:   nowJST.Format(time.RFC3339),
+		Timestamp:   now.Format(time.RFC3339),
 		Fields: []*discordgo.MessageEmbedField{
 			{
 				Name:   "現在値",
@@ -218,7 +237,7 @@ func (c *PredictCommand) buildPredictionEmbed(metric string, duration time.Durat
 	}
 
 	eta := time.Duration(etaSeconds * float64(time.Second))
-	finishAt := nowJST.Add(eta)
+	finishAt := now.Add(eta)
 	speedPerMinute := repairRatePerSec * 60
 
 	embed.Color = 0x2ECC71
@@ -234,7 +253,7 @@ func (c *PredictCommand) buildPredictionEmbed(metric string, duration time.Durat
 			Inline: true,
 		},
 		&discordgo.MessageEmbedField{
-			Name:   "推定完了時刻 (JST)",
+			Name:   fmt.Sprintf("推定完了時刻 (%s)", finishAt.Format("MST")),
 			Value:  finishAt.Format("2006-01-02 15:04:05"),
 			Inline: true,
 		},
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"Koukyo_discord_bot/internal/achievements"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/embeds"
+	"Koukyo_discord_bot/internal/utils"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// profileCardDays カードのスパークラインに載せる日数（今日を含む、JST）
+const profileCardDays = 30
+
+// buildProfileCardFile /me と /useractivity のカード画像。描画できなければ従来のアイコン画像を返す
+func buildProfileCardFile(dataDir string, entry userActivityEntry) *discordgo.File {
+	picture, _ := utils.DecodePictureData(entry.Picture)
+	card := embeds.ProfileCard{
+		Name:          utils.FormatUserDisplayName(entry.Name, entry.ID),
+		Alliance:      entry.Alliance,
+		Picture:       picture,
+		IconSeed:      entry.ID,
+		VandalCount:   entry.VandalCount,
+		RestoredCount: entry.RestoredCount,
+		Score:         entry.Score,
+		Daily:         profileCardDaily(entry, time.Now()),
+		DailyLabel:    fmt.Sprintf("Last %d days", profileCardDays),
+		Badges:        loadAchievementNames(dataDir, entry),
+	}
+	buf, err := embeds.BuildProfileCardPNG(card)
+	if err != nil {
+		log.Printf("Failed to render profile card for %s: %v", entry.ID, err)
+		return buildUserActivityImageFile(entry)
+	}
+	return &discordgo.File{
+		Name:        "user_card.png",
+		ContentType: "image/png",
+		Reader:      buf,
+	}
+}
+
+// profileCardDaily now（JST）までの日別アクション数（荒らし + 修復、古い順）
+func profileCardDaily(entry userActivityEntry, now time.Time) []int {
+	today := now.In(config.DefaultLocation())
+	out := make([]int, profileCardDays)
+	for i := range out {
+		key := today.AddDate(0, 0, i-profileCardDays+1).Format("2006-01-02")
+		out[i] = entry.DailyVandal[key] + entry.DailyRestored[key]
+	}
+	return out
+}
+
+// loadAchievementNames 取得済み実績の名前（新しい順）
+func loadAchievementNames(dataDir string, entry userActivityEntry) []string {
+	store, err := achievements.Load(filepath.Join(dataDir, achievements.FileName))
+	if err != nil {
+		return nil
+	}
+	user := store.GetByIdentity(strings.TrimSpace(entry.DiscordID), strings.TrimSpace(entry.ID))
+	if user == nil {
+		return nil
+	}
+	list := append([]achievements.Achievement(nil), user.Achievements...)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].AwardedAt > list[j].AwardedAt
+	})
+	names := make([]string, 0, len(list))
+	for _, a := range list {
+		names = append(names, a.Name)
+	}
+	return names
+}
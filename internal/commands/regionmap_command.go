@@ -21,12 +21,11 @@ import (
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
-	"golang.org/x/image/font/gofont/goregular"
-	"golang.org/x/image/font/opentype"
 	"golang.org/x/image/math/fixed"
 
 	"github.com/bwmarrin/discordgo"
 
+	"Koukyo_discord_bot/internal/embeds"
 	"Koukyo_discord_bot/internal/utils"
 	"Koukyo_discord_bot/internal/wplace"
 )
@@ -836,7 +835,7 @@ func strokeRect(img draw.Image, x1, y1, x2, y2 int, c color.NRGBA, width int) {
 }
 
 func drawCenteredText(img draw.Image, text string, x1, y1, x2, y2 int, highlight bool, size float64) {
-	face := resolveFontFace(size, basicfont.Face7x13)
+	face := embeds.ResolveFontFace(size, basicfont.Face7x13)
 	textWidth := font.MeasureString(face, text).Ceil()
 	textHeight := face.Metrics().Height.Ceil()
 	ascent := face.Metrics().Ascent.Ceil()
@@ -861,7 +860,7 @@ func drawCenteredText(img draw.Image, text string, x1, y1, x2, y2 int, highlight
 }
 
 func drawTitle(img draw.Image, text string, width, height int, size float64) {
-	face := resolveFontFace(size, basicfont.Face7x13)
+	face := embeds.ResolveFontFace(size, basicfont.Face7x13)
 	textWidth := font.MeasureString(face, text).Ceil()
 	ascent := face.Metrics().Ascent.Ceil()
 	x := (width - textWidth) / 2
@@ -879,46 +878,6 @@ func drawText(img draw.Image, text string, x, y int, c color.NRGBA, face font.Fa
 	d.DrawString(text)
 }
 
-func resolveFontFace(size float64, fallback font.Face) font.Face {
-	face := getGoFontFace(size)
-	if face != nil {
-		return face
-	}
-	return fallback
-}
-
-var (
-	gofontOnce      sync.Once
-	gofontFaceCache = make(map[float64]font.Face)
-	gofontErr       error
-	gofontMu        sync.Mutex
-	gofontData      *opentype.Font
-)
-
-func getGoFontFace(size float64) font.Face {
-	gofontOnce.Do(func() {
-		gofontData, gofontErr = opentype.Parse(goregular.TTF)
-	})
-	if gofontErr != nil || gofontData == nil {
-		return nil
-	}
-	gofontMu.Lock()
-	defer gofontMu.Unlock()
-	if face, ok := gofontFaceCache[size]; ok {
-		return face
-	}
-	face, err := opentype.NewFace(gofontData, &opentype.FaceOptions{
-		Size:    size,
-		DPI:     72,
-		Hinting: font.HintingFull,
-	})
-	if err != nil {
-		return nil
-	}
-	gofontFaceCache[size] = face
-	return face
-}
-
 func encodeRegionMap(img image.Image) ([]byte, string, string, error) {
 	var buf bytes.Buffer
 	if err := png.Encode(&buf, img); err == nil {
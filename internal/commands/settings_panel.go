@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -47,6 +49,36 @@ func (v *SettingsView) Components() []discordgo.MessageComponent {
 		metricStyle = discordgo.PrimaryButton
 	}
 
+	// メンテナンスボタン
+	maintenanceLabel := "メンテナンスを開始"
+	if mw := settings.Maintenance; mw != nil && mw.Active(time.Now()) {
+		maintenanceLabel = "メンテナンスを変更/終了"
+	}
+
+	// 配信モードボタン
+	deliveryLabel := "配信: 即時"
+	if settings.DigestEnabled() {
+		deliveryLabel = fmt.Sprintf("配信: ダイジェスト(%d分)", int(settings.DigestInterval().Minutes()))
+	}
+
+	// タイムゾーンボタン
+	timezoneLabel := "タイムゾーン: JST"
+	if settings.Timezone != "" {
+		timezoneLabel = truncateLabel("タイムゾーン: "+settings.Timezone, 80)
+	}
+
+	// 定期レポートボタン
+	report := settings.Report()
+	reportLabel := fmt.Sprintf("レポート: %s %s", report.CadenceLabel(), report.Time)
+
+	// インシデントスレッドボタン
+	threadLabel := "インシデントスレッド: OFF"
+	threadStyle := discordgo.SecondaryButton
+	if settings.IncidentThreadsEnabled {
+		threadLabel = "インシデントスレッド: ON"
+		threadStyle = discordgo.PrimaryButton
+	}
+
 	return []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
@@ -69,6 +101,11 @@ func (v *SettingsView) Components() []discordgo.MessageComponent {
 					Style:    discordgo.SecondaryButton,
 					CustomID: "settings_set_threshold",
 				},
+				discordgo.Button{
+					Label:    threadLabel,
+					Style:    threadStyle,
+					CustomID: "settings_toggle_threads",
+				},
 			},
 		},
 		discordgo.ActionsRow{
@@ -92,6 +129,45 @@ func (v *SettingsView) Components() []discordgo.MessageComponent {
 					Style:    discordgo.SecondaryButton,
 					CustomID: "settings_set_mention_role",
 				},
+				discordgo.Button{
+					Label:    "Tierラダーを編集",
+					Style:    discordgo.SecondaryButton,
+					CustomID: "settings_set_tier_ladder",
+				},
+				discordgo.Button{
+					Label:    "エスカレーションを編集",
+					Style:    discordgo.SecondaryButton,
+					CustomID: "settings_set_escalation",
+				},
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "静音時間を設定",
+					Style:    discordgo.SecondaryButton,
+					CustomID: "settings_set_quiet_hours",
+				},
+				discordgo.Button{
+					Label:    maintenanceLabel,
+					Style:    discordgo.SecondaryButton,
+					CustomID: "settings_set_maintenance",
+				},
+				discordgo.Button{
+					Label:    deliveryLabel,
+					Style:    discordgo.SecondaryButton,
+					CustomID: "settings_set_digest",
+				},
+				discordgo.Button{
+					Label:    timezoneLabel,
+					Style:    discordgo.SecondaryButton,
+					CustomID: "settings_set_timezone",
+				},
+				discordgo.Button{
+					Label:    reportLabel,
+					Style:    discordgo.SecondaryButton,
+					CustomID: "settings_set_report",
+				},
 			},
 		},
 	}
@@ -123,6 +199,8 @@ func HandleSettingsButtonInteraction(
 		handleToggleNotify(s, i, settings, notifier)
 	case "settings_toggle_metric":
 		handleToggleMetric(s, i, settings, notifier)
+	case "settings_toggle_threads":
+		handleToggleThreads(s, i, settings, notifier)
 	case "settings_set_threshold":
 		handleSetThreshold(s, i, settings)
 	case "settings_set_mention_threshold":
@@ -131,6 +209,20 @@ func HandleSettingsButtonInteraction(
 		handleSetChannel(s, i, settings)
 	case "settings_set_mention_role":
 		handleSetMentionRole(s, i, settings)
+	case "settings_set_tier_ladder":
+		handleSetTierLadder(s, i, settings)
+	case "settings_set_escalation":
+		handleSetEscalation(s, i, settings)
+	case "settings_set_quiet_hours":
+		handleSetQuietHours(s, i, settings)
+	case "settings_set_maintenance":
+		handleSetMaintenance(s, i, settings)
+	case "settings_set_digest":
+		handleSetDigest(s, i, settings)
+	case "settings_set_timezone":
+		handleSetTimezone(s, i, settings)
+	case "settings_set_report":
+		handleSetReport(s, i, settings)
 	}
 }
 
@@ -182,6 +274,28 @@ func handleToggleMetric(s *discordgo.Session, i *discordgo.InteractionCreate, se
 	})
 }
 
+// handleToggleThreads インシデントスレッドON/OFF切り替え
+func handleToggleThreads(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager, notifier *notifications.Notifier) {
+	settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+		gs.IncidentThreadsEnabled = !gs.IncidentThreadsEnabled
+	})
+
+	// 通知状態をリセット（進行中スレッドの追跡も破棄）
+	notifier.ResetState(i.GuildID)
+
+	// Embedを更新
+	embed := embeds.BuildSettingsEmbed(settings, i.GuildID)
+	view := NewSettingsView(settings, notifier, i.GuildID)
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: view.Components(),
+		},
+	})
+}
+
 // handleSetThreshold 通知閾値設定モーダル
 func handleSetThreshold(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager) {
 	currentSettings := settings.GetGuildSettings(i.GuildID)
@@ -303,6 +417,277 @@ func handleSetMentionRole(s *discordgo.Session, i *discordgo.InteractionCreate,
 	})
 }
 
+// handleSetTierLadder Tierラダー編集モーダル（1行1段）
+func handleSetTierLadder(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager) {
+	currentSettings := settings.GetGuildSettings(i.GuildID)
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "modal_set_tier_ladder",
+			Title:    "Tierラダーを編集",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "tier_ladder_input",
+							Label:       "閾値 | 名前 | 色 | ping/silent | ロール（空欄で10%刻み）",
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: "0.5 | 注意 | #F1C40F | silent\n15 | 警報 | #E74C3C | ping | <@&ロールID>",
+							Value:       config.FormatTierLadder(currentSettings.TierLadder),
+							Required:    false,
+							MaxLength:   4000,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleSetEscalation エスカレーション編集モーダル（1行1件）
+func handleSetEscalation(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager) {
+	currentSettings := settings.GetGuildSettings(i.GuildID)
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "modal_set_escalation",
+			Title:    "エスカレーションを編集",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "escalation_input",
+							Label:       "閾値 | 継続分数 | ロール（空欄で無効）",
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: "5 | 30 | <@&ロールID>",
+							Value:       config.FormatTierEscalations(currentSettings.TierEscalations),
+							Required:    false,
+							MaxLength:   2000,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleSetQuietHours 静音時間設定モーダル
+func handleSetQuietHours(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager) {
+	current := config.QuietHours{Mode: config.QuietModeSilent}
+	if q := settings.GetGuildSettings(i.GuildID).QuietHours; q != nil {
+		current = *q
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "modal_set_quiet_hours",
+			Title:    "静音時間を設定",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "quiet_start_input",
+							Label:       "開始時刻（HH:MM、空欄で無効）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "02:00",
+							Value:       current.Start,
+							Required:    false,
+							MaxLength:   5,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "quiet_end_input",
+							Label:       "終了時刻（HH:MM）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "07:00",
+							Value:       current.End,
+							Required:    false,
+							MaxLength:   5,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "quiet_mode_input",
+							Label:       "silent（メンションなし）/ mute（通知停止）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: config.QuietModeSilent,
+							Value:       current.Mode,
+							Required:    false,
+							MaxLength:   6,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleSetMaintenance メンテナンス期間設定モーダル
+func handleSetMaintenance(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager) {
+	reason := ""
+	if mw := settings.GetGuildSettings(i.GuildID).Maintenance; mw != nil && mw.Active(time.Now()) {
+		reason = mw.Reason
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "modal_set_maintenance",
+			Title:    "メンテナンス",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "maintenance_duration_input",
+							Label:       "今から何分間か（例: 90, 2h。空欄で終了）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "2h",
+							Required:    false,
+							MaxLength:   10,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:  "maintenance_reason_input",
+							Label:     "理由（任意）",
+							Style:     discordgo.TextInputShort,
+							Value:     reason,
+							Required:  false,
+							MaxLength: 100,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleSetDigest 配信モード（ダイジェスト間隔）設定モーダル
+func handleSetDigest(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager) {
+	current := ""
+	if gs := settings.GetGuildSettings(i.GuildID); gs.DigestEnabled() {
+		current = strconv.Itoa(int(gs.DigestInterval().Minutes()))
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "modal_set_digest",
+			Title:    "配信モード",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "digest_interval_input",
+							Label:       "ダイジェスト間隔（分、空欄で即時配信）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: strconv.Itoa(config.DefaultDigestIntervalMinutes),
+							Value:       current,
+							Required:    false,
+							MaxLength:   4,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleSetTimezone タイムゾーン設定モーダル
+func handleSetTimezone(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "modal_set_timezone",
+			Title:    "タイムゾーン",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "timezone_input",
+							Label:       "IANA 名または略称（空欄で JST）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "Europe/Paris / UTC / PST",
+							Value:       settings.GetGuildSettings(i.GuildID).Timezone,
+							Required:    false,
+							MaxLength:   64,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleSetReport 定期レポート設定モーダル
+func handleSetReport(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager) {
+	gs := settings.GetGuildSettings(i.GuildID)
+	current := gs.Report()
+	sections := ""
+	if gs.ReportSchedule != nil {
+		sections = strings.Join(gs.ReportSchedule.Sections, ", ")
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "modal_set_report",
+			Title:    "定期レポート",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "report_time_input",
+							Label:       "投稿時刻（HH:MM、サーバーのタイムゾーン）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: config.DefaultReportTime,
+							Value:       current.Time,
+							Required:    false,
+							MaxLength:   5,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "report_cadence_input",
+							Label:       "daily（毎日）/ weekly（月曜）/ monthly（1日）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: config.ReportCadenceDaily,
+							Value:       current.Cadence,
+							Required:    false,
+							MaxLength:   7,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "report_sections_input",
+							Label:       "項目（カンマ区切り、空欄で標準項目）",
+							Style:       discordgo.TextInputShort,
+							Placeholder: strings.Join(config.ReportSections, ", "),
+							Value:       sections,
+							Required:    false,
+							MaxLength:   100,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
 // HandleSettingsModalSubmit モーダル送信を処理
 func HandleSettingsModalSubmit(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager, notifier *notifications.Notifier) {
 	data := i.ModalSubmitData()
@@ -312,6 +697,20 @@ func HandleSettingsModalSubmit(s *discordgo.Session, i *discordgo.InteractionCre
 		handleModalSetThreshold(s, i, settings, notifier, data)
 	case "modal_set_mention_threshold":
 		handleModalSetMentionThreshold(s, i, settings, data)
+	case "modal_set_tier_ladder":
+		handleModalSetTierLadder(s, i, settings, notifier, data)
+	case "modal_set_escalation":
+		handleModalSetEscalation(s, i, settings, notifier, data)
+	case "modal_set_quiet_hours":
+		handleModalSetQuietHours(s, i, settings, data)
+	case "modal_set_maintenance":
+		handleModalSetMaintenance(s, i, settings, data)
+	case "modal_set_digest":
+		handleModalSetDigest(s, i, settings, data)
+	case "modal_set_timezone":
+		handleModalSetTimezone(s, i, settings, data)
+	case "modal_set_report":
+		handleModalSetReport(s, i, settings, data)
 	}
 }
 
@@ -371,6 +770,263 @@ func handleModalSetMentionThreshold(s *discordgo.Session, i *discordgo.Interacti
 	})
 }
 
+func handleModalSetTierLadder(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager, notifier *notifications.Notifier, data discordgo.ModalSubmitInteractionData) {
+	input := data.Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value
+	levels, err := config.ParseTierLadder(input)
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("❌ %v", err),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+		gs.TierLadder = levels
+	})
+
+	notifier.ResetState(i.GuildID)
+
+	content := "✅ Tierラダーをデフォルト（通知閾値から10%刻み）に戻しました。"
+	if len(levels) > 0 {
+		content = fmt.Sprintf("✅ Tierラダーを%d段で設定しました。", len(levels))
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func handleModalSetEscalation(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager, notifier *notifications.Notifier, data discordgo.ModalSubmitInteractionData) {
+	input := data.Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value
+	list, err := config.ParseTierEscalations(input)
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("❌ %v", err),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+		gs.TierEscalations = list
+	})
+
+	notifier.ResetState(i.GuildID)
+
+	content := "✅ エスカレーションを無効にしました。"
+	if len(list) > 0 {
+		content = fmt.Sprintf("✅ エスカレーションを%d件設定しました。", len(list))
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func handleModalSetQuietHours(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager, data discordgo.ModalSubmitInteractionData) {
+	start := strings.TrimSpace(modalTextValue(data, 0))
+	end := strings.TrimSpace(modalTextValue(data, 1))
+	mode := strings.ToLower(strings.TrimSpace(modalTextValue(data, 2)))
+
+	reply := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if start == "" {
+		settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+			gs.QuietHours = nil
+		})
+		reply("✅ 静音時間を無効にしました。")
+		return
+	}
+	if mode == "" {
+		mode = config.QuietModeSilent
+	}
+	if mode != config.QuietModeSilent && mode != config.QuietModeMute {
+		reply("❌ モードは silent か mute を指定してください。")
+		return
+	}
+	normStart, err := config.NormalizeClock(start)
+	if err != nil {
+		reply(fmt.Sprintf("❌ %v", err))
+		return
+	}
+	normEnd, err := config.NormalizeClock(end)
+	if err != nil {
+		reply(fmt.Sprintf("❌ %v", err))
+		return
+	}
+	if normStart == normEnd {
+		reply("❌ 開始と終了に同じ時刻は指定できません。")
+		return
+	}
+
+	quiet := &config.QuietHours{Start: normStart, End: normEnd, Mode: mode}
+	settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+		gs.QuietHours = quiet
+	})
+	reply(fmt.Sprintf("✅ 静音時間を %s に設定しました。", quiet.Label()))
+}
+
+func handleModalSetMaintenance(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager, data discordgo.ModalSubmitInteractionData) {
+	durationText := strings.TrimSpace(modalTextValue(data, 0))
+	reason := strings.TrimSpace(modalTextValue(data, 1))
+
+	reply := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	now := time.Now()
+	if durationText == "" {
+		settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+			if gs.Maintenance != nil && gs.Maintenance.Active(now) {
+				gs.Maintenance.End = now
+			}
+		})
+		reply("✅ メンテナンスを終了しました。まもなく期間中のまとめを投稿します。")
+		return
+	}
+	d, err := config.ParseMaintenanceDuration(durationText)
+	if err != nil {
+		reply(fmt.Sprintf("❌ %v", err))
+		return
+	}
+
+	window := &config.MaintenanceWindow{Start: now, End: now.Add(d), Reason: reason}
+	settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+		if gs.Maintenance != nil && gs.Maintenance.Active(now) {
+			window.Start = gs.Maintenance.Start // 延長時は開始時刻を引き継ぐ
+		}
+		gs.Maintenance = window
+	})
+	end := window.End.In(settings.GuildLocation(i.GuildID)).Format("01/02 15:04 MST")
+	reply(fmt.Sprintf("🛠️ メンテナンスを %s まで設定しました。差分通知・追加監視・進捗監視とこのサーバーのメンバーへの DM速報を止め、終了時にまとめを投稿します。", end))
+}
+
+func handleModalSetDigest(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager, data discordgo.ModalSubmitInteractionData) {
+	input := strings.TrimSuffix(strings.TrimSpace(modalTextValue(data, 0)), "分")
+
+	reply := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if input == "" {
+		settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+			gs.DeliveryMode = config.DeliveryImmediate
+			gs.DigestIntervalMinutes = 0
+		})
+		reply("✅ 即時配信に戻しました。未送信のダイジェストはまもなく投稿されます。")
+		return
+	}
+	minutes, err := strconv.Atoi(input)
+	if err != nil || minutes < 1 || minutes > 24*60 {
+		reply("❌ 間隔は 1〜1440 分で指定してください。")
+		return
+	}
+
+	settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+		gs.DeliveryMode = config.DeliveryDigest
+		gs.DigestIntervalMinutes = minutes
+	})
+	reply(fmt.Sprintf("✅ ダイジェスト配信（%d分ごと）にしました。修復完了・変化検知・メンション対象の Tier 上昇とエスカレーションは引き続き即時に送ります。", minutes))
+}
+
+func handleModalSetTimezone(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager, data discordgo.ModalSubmitInteractionData) {
+	reply := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	tz, err := config.ParseGuildTimezone(modalTextValue(data, 0))
+	if err != nil {
+		reply("❌ タイムゾーンを認識できませんでした。`Europe/Paris` のような IANA 名か、JST / UTC / PST / CET などで指定してください。")
+		return
+	}
+	settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+		gs.Timezone = tz
+	})
+	gs := settings.GetGuildSettings(i.GuildID)
+	now := time.Now()
+	reply(fmt.Sprintf("✅ タイムゾーンを %s にしました（現在 %s）。グラフ・タイムラプス・日次ランキングの投稿時刻・静音時間に反映します。日次集計の区切りは従来どおり JST です。",
+		gs.TimezoneLabel(now), now.In(gs.Location()).Format("01/02 15:04")))
+}
+
+func handleModalSetReport(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager, data discordgo.ModalSubmitInteractionData) {
+	reply := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	schedule, err := config.ParseReportSchedule(modalTextValue(data, 0), modalTextValue(data, 1), modalTextValue(data, 2))
+	if err != nil {
+		reply(fmt.Sprintf("❌ %v", err))
+		return
+	}
+	settings.UpdateGuildSetting(i.GuildID, func(gs *config.GuildSettings) {
+		gs.ReportSchedule = schedule
+	})
+	gs := settings.GetGuildSettings(i.GuildID)
+	reply(fmt.Sprintf("✅ 定期レポートを %s（%s）に設定しました。集計は JST 区切りで、指定時刻にまだ JST の期間が終わっていなければ終わりしだい投稿します。",
+		gs.Report().Label(), gs.TimezoneLabel(time.Now())))
+}
+
+// modalTextValue モーダルの index 番目の行のテキスト入力値
+func modalTextValue(data discordgo.ModalSubmitInteractionData, index int) string {
+	if index >= len(data.Components) {
+		return ""
+	}
+	row, ok := data.Components[index].(*discordgo.ActionsRow)
+	if !ok || len(row.Components) == 0 {
+		return ""
+	}
+	input, ok := row.Components[0].(*discordgo.TextInput)
+	if !ok {
+		return ""
+	}
+	return input.Value
+}
+
 // HandleSettingsSelectMenu セレクトメニュー選択を処理
 func HandleSettingsSelectMenu(s *discordgo.Session, i *discordgo.InteractionCreate, settings *config.SettingsManager) {
 	data := i.MessageComponentData()
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/embeds"
 	"Koukyo_discord_bot/internal/monitor"
 	"fmt"
@@ -9,16 +10,15 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-var timelapseJST = time.FixedZone("JST", 9*3600)
-
 // TimelapseCommand 閾値(>=30%→<=0.2%)の期間タイムラプス(GIF)を生成
 // ローカル保存せず、メモリ生成して送信
 type TimelapseCommand struct {
-	mon *monitor.Monitor
+	monitors *monitor.Set
+	settings *config.SettingsManager
 }
 
-func NewTimelapseCommand(mon *monitor.Monitor) *TimelapseCommand {
-	return &TimelapseCommand{mon: mon}
+func NewTimelapseCommand(monitors *monitor.Set, settings *config.SettingsManager) *TimelapseCommand {
+	return &TimelapseCommand{monitors: monitors, settings: settings}
 }
 
 func (c *TimelapseCommand) Name() string { return "timelapse" }
@@ -27,12 +27,29 @@ func (c *TimelapseCommand) Description() string {
 }
 
 func (c *TimelapseCommand) ExecuteText(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
-	return c.respondTimelapse(s, m.ChannelID)
+	artworkID, _ := artworkIDFromArgs(args)
+	mon, err := resolveArtworkMonitor(c.monitors, artworkID)
+	if err != nil {
+		_, sendErr := s.ChannelMessageSend(m.ChannelID, err.Error())
+		return sendErr
+	}
+	return c.respondTimelapse(s, m.ChannelID, mon, c.settings.GuildLocation(m.GuildID))
 }
 
 func (c *TimelapseCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	mon, err := resolveArtworkMonitor(c.monitors, artworkIDFromOptions(i.ApplicationCommandData().Options))
+	if err != nil {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: err.Error(),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
 	// 生成して即座に返す
-	if c.mon == nil || !c.mon.State.HasData() {
+	if mon == nil || !mon.State.HasData() {
 		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -42,7 +59,7 @@ func (c *TimelapseCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.Inter
 		})
 	}
 
-	frames := c.mon.State.GetLastTimelapseFrames()
+	frames := mon.State.GetLastTimelapseFrames()
 	if len(frames) == 0 {
 		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -64,12 +81,7 @@ func (c *TimelapseCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.Inter
 		})
 	}
 
-	embed := &discordgo.MessageEmbed{
-		Title:       "差分タイムラプス",
-		Description: fmt.Sprintf("フレーム数: %d / 開始: %s / 終了: %s (JST)", len(frames), frames[0].Timestamp.In(timelapseJST).Format("15:04:05"), frames[len(frames)-1].Timestamp.In(timelapseJST).Format("15:04:05")),
-		Color:       0x00AA88,
-		Timestamp:   time.Now().In(timelapseJST).Format(time.RFC3339),
-	}
+	embed := buildTimelapseEmbed(mon, frames, c.settings.GuildLocation(i.GuildID))
 
 	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -88,16 +100,17 @@ func (c *TimelapseCommand) SlashDefinition() *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
 		Name:        c.Name(),
 		Description: c.Description(),
+		Options:     appendArtworkOption(nil, c.monitors),
 	}
 }
 
-func (c *TimelapseCommand) respondTimelapse(s *discordgo.Session, channelID string) error {
-	if c.mon == nil || !c.mon.State.HasData() {
+func (c *TimelapseCommand) respondTimelapse(s *discordgo.Session, channelID string, mon *monitor.Monitor, loc *time.Location) error {
+	if mon == nil || !mon.State.HasData() {
 		_, err := s.ChannelMessageSend(channelID, "まだ監視データがありません。")
 		return err
 	}
 
-	frames := c.mon.State.GetLastTimelapseFrames()
+	frames := mon.State.GetLastTimelapseFrames()
 	if len(frames) == 0 {
 		_, err := s.ChannelMessageSend(channelID, "タイムラプス対象の期間が見つかりません。(30%→0.2%)")
 		return err
@@ -110,13 +123,7 @@ func (c *TimelapseCommand) respondTimelapse(s *discordgo.Session, channelID stri
 		return e
 	}
 
-	// Embed
-	embed := &discordgo.MessageEmbed{
-		Title:       "差分タイムラプス",
-		Description: fmt.Sprintf("フレーム数: %d / 開始: %s / 終了: %s (JST)", len(frames), frames[0].Timestamp.In(timelapseJST).Format("15:04:05"), frames[len(frames)-1].Timestamp.In(timelapseJST).Format("15:04:05")),
-		Color:       0x00AA88,
-		Timestamp:   time.Now().In(timelapseJST).Format(time.RFC3339),
-	}
+	embed := buildTimelapseEmbed(mon, frames, loc)
 
 	_, err = s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
 		Embeds: []*discordgo.MessageEmbed{embed},
@@ -128,3 +135,15 @@ func (c *TimelapseCommand) respondTimelapse(s *discordgo.Session, channelID stri
 	})
 	return err
 }
+
+// buildTimelapseEmbed 開始/終了時刻はサーバーのタイムゾーンで表示する
+func buildTimelapseEmbed(mon *monitor.Monitor, frames []monitor.TimelapseFrame, loc *time.Location) *discordgo.MessageEmbed {
+	start := frames[0].Timestamp.In(loc)
+	end := frames[len(frames)-1].Timestamp.In(loc)
+	return &discordgo.MessageEmbed{
+		Title:       "差分タイムラプス" + artworkTitleSuffix(mon),
+		Description: fmt.Sprintf("フレーム数: %d / 開始: %s / 終了: %s (%s)", len(frames), start.Format("15:04:05"), end.Format("15:04:05"), end.Format("MST")),
+		Color:       0x00AA88,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+}
@@ -1,5 +1,15 @@
 package commands
 
+import "fmt"
+
 func activityScore(restored, vandal int) int {
 	return restored - vandal
 }
+
+// formatEstimatedCount 件数と、そのうち抽出照会による推定分（「120 (うち ~40 px (est.))」）
+func formatEstimatedCount(count, estimated int) string {
+	if estimated <= 0 {
+		return fmt.Sprintf("%d", count)
+	}
+	return fmt.Sprintf("%d (うち ~%d px (est.))", count, estimated)
+}
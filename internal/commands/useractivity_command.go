@@ -3,11 +3,11 @@ package commands
 import (
 	"Koukyo_discord_bot/internal/achievements"
 	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/embeds"
 	"Koukyo_discord_bot/internal/utils"
 	"bytes"
-	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -26,11 +26,12 @@ const (
 )
 
 type UserActivityCommand struct {
-	dataDir string
+	dataDir  string
+	settings *config.SettingsManager
 }
 
-func NewUserActivityCommand(dataDir string) *UserActivityCommand {
-	return &UserActivityCommand{dataDir: dataDir}
+func NewUserActivityCommand(dataDir string, settings *config.SettingsManager) *UserActivityCommand {
+	return &UserActivityCommand{dataDir: dataDir, settings: settings}
 }
 
 func (c *UserActivityCommand) Name() string { return "useractivity" }
@@ -78,13 +79,14 @@ func (c *UserActivityCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.In
 	if page < 0 {
 		page = 0
 	}
+	loc := c.settings.GuildLocation(i.GuildID)
 
 	if userID != "" || discordID != "" {
 		entry, err := loadUserActivityByID(c.dataDir, userID, discordID)
 		if err != nil {
 			return respondUserListError(s, i, err)
 		}
-		embed, file := buildUserActivityDetailEmbedFromEntry(c.dataDir, inferUserKind(entry, kind), listType, entry)
+		embed, file := buildUserActivityDetailEmbedFromEntry(c.dataDir, inferUserKind(entry, kind), listType, entry, loc)
 		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -103,7 +105,7 @@ func (c *UserActivityCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.In
 			return respondUserListError(s, i, fmt.Errorf("該当ユーザーが見つかりません"))
 		}
 		if len(matches) == 1 {
-			embed, file := buildUserActivityDetailEmbedFromEntry(c.dataDir, inferUserKind(matches[0], kind), listType, matches[0])
+			embed, file := buildUserActivityDetailEmbedFromEntry(c.dataDir, inferUserKind(matches[0], kind), listType, matches[0], loc)
 			return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -131,7 +133,7 @@ func (c *UserActivityCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.In
 			return respondUserListError(s, i, fmt.Errorf("該当ユーザーが見つかりません"))
 		}
 		if len(matches) == 1 {
-			embed, file := buildUserActivityDetailEmbedFromEntry(c.dataDir, inferUserKind(matches[0], kind), listType, matches[0])
+			embed, file := buildUserActivityDetailEmbedFromEntry(c.dataDir, inferUserKind(matches[0], kind), listType, matches[0], loc)
 			return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -151,7 +153,7 @@ func (c *UserActivityCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.In
 	}
 
 	if mode == userActivityModeDetail {
-		embed, components, file, err := buildUserActivityDetailEmbed(c.dataDir, kind, listType, page)
+		embed, components, file, err := buildUserActivityDetailEmbed(c.dataDir, kind, listType, page, loc)
 		if err != nil {
 			return respondUserListError(s, i, err)
 		}
@@ -165,7 +167,7 @@ func (c *UserActivityCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.In
 		})
 	}
 
-	embed, components, err := buildUserListEmbed(c.dataDir, kind, mode, listType, page)
+	embed, components, err := buildUserListEmbed(c.dataDir, kind, mode, listType, page, loc)
 	if err != nil {
 		return respondUserListError(s, i, err)
 	}
@@ -249,7 +251,7 @@ func (c *UserActivityCommand) SlashDefinition() *discordgo.ApplicationCommand {
 	}
 }
 
-func HandleUserActivityPagination(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string) {
+func HandleUserActivityPagination(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string, loc *time.Location) {
 	customID := i.MessageComponentData().CustomID
 	if !strings.HasPrefix(customID, userActivityPrefix) {
 		return
@@ -268,7 +270,7 @@ func HandleUserActivityPagination(s *discordgo.Session, i *discordgo.Interaction
 		page = 0
 	}
 
-	embed, components, file, err := buildUserActivityDetailEmbed(dataDir, kind, listType, page)
+	embed, components, file, err := buildUserActivityDetailEmbed(dataDir, kind, listType, page, loc)
 	if err != nil {
 		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -290,7 +292,7 @@ func HandleUserActivityPagination(s *discordgo.Session, i *discordgo.Interaction
 	})
 }
 
-func HandleUserActivitySelect(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string) {
+func HandleUserActivitySelect(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string, loc *time.Location) {
 	customID := i.MessageComponentData().CustomID
 	if customID != userActivitySelectPrefix {
 		return
@@ -310,7 +312,7 @@ func HandleUserActivitySelect(s *discordgo.Session, i *discordgo.InteractionCrea
 		})
 		return
 	}
-	embed, file := buildUserActivityDetailEmbedFromEntry(dataDir, inferUserKind(entry, userListKindGrf), userListTypeScore, entry)
+	embed, file := buildUserActivityDetailEmbedFromEntry(dataDir, inferUserKind(entry, userListKindGrf), userListTypeScore, entry, loc)
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
 		Data: &discordgo.InteractionResponseData{
@@ -332,9 +334,18 @@ type userActivityEntry struct {
 	RestoredCount int
 	Score         int
 	LastSeen      time.Time
+	DailyVandal   map[string]int
+	DailyRestored map[string]int
+	// 抽出照会による推定分（VandalCount / RestoredCount に含まれる）
+	EstimatedVandal   int
+	EstimatedRestored int
+	SuspectedBot      *activity.BotSuspicion
+	Banned            bool
+	TimedOut          bool
+	StatusHistory     []activity.StatusChange
 }
 
-func buildUserActivityDetailEmbed(dataDir, kind, listType string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, *discordgo.File, error) {
+func buildUserActivityDetailEmbed(dataDir, kind, listType string, page int, loc *time.Location) (*discordgo.MessageEmbed, []discordgo.MessageComponent, *discordgo.File, error) {
 	entries, err := loadUserActivityEntries(dataDir, kind, listType)
 	if err != nil {
 		return nil, nil, nil, err
@@ -358,7 +369,7 @@ func buildUserActivityDetailEmbed(dataDir, kind, listType string, page int) (*di
 		page = len(entries) - 1
 	}
 	entry := entries[page]
-	embed, file := buildUserActivityDetailEmbedFromEntry(dataDir, kind, listType, entry)
+	embed, file := buildUserActivityDetailEmbedFromEntry(dataDir, kind, listType, entry, loc)
 	embed.Footer = &discordgo.MessageEmbedFooter{
 		Text: fmt.Sprintf("ページ %d / %d", page+1, len(entries)),
 	}
@@ -384,7 +395,7 @@ func buildUserActivityDetailEmbed(dataDir, kind, listType string, page int) (*di
 	return embed, components, file, nil
 }
 
-func buildUserActivityDetailEmbedFromEntry(dataDir, kind, listType string, entry userActivityEntry) (*discordgo.MessageEmbed, *discordgo.File) {
+func buildUserActivityDetailEmbedFromEntry(dataDir, kind, listType string, entry userActivityEntry, loc *time.Location) (*discordgo.MessageEmbed, *discordgo.File) {
 	name := utils.FormatUserDisplayName(entry.Name, entry.ID)
 	alliance := entry.Alliance
 	if alliance == "" {
@@ -400,8 +411,7 @@ func buildUserActivityDetailEmbedFromEntry(dataDir, kind, listType string, entry
 	}
 	lastSeenText := "-"
 	if !entry.LastSeen.IsZero() {
-		jst := time.FixedZone("JST", 9*3600)
-		lastSeenText = entry.LastSeen.In(jst).Format("2006-01-02 15:04:05")
+		lastSeenText = entry.LastSeen.In(loc).Format("2006-01-02 15:04:05 MST")
 	}
 	title := "🚨 荒らしユーザー詳細"
 	if kind == userListKindFix {
@@ -417,20 +427,34 @@ func buildUserActivityDetailEmbedFromEntry(dataDir, kind, listType string, entry
 			{Name: "Discord", Value: discordName, Inline: true},
 			{Name: "Discord ID", Value: discordID, Inline: true},
 			{Name: "アイコンseed", Value: entry.ID, Inline: true},
-			{Name: "荒らし数", Value: fmt.Sprintf("%d", entry.VandalCount), Inline: true},
-			{Name: "修復数", Value: fmt.Sprintf("%d", entry.RestoredCount), Inline: true},
+			{Name: "荒らし数", Value: formatEstimatedCount(entry.VandalCount, entry.EstimatedVandal), Inline: true},
+			{Name: "修復数", Value: formatEstimatedCount(entry.RestoredCount, entry.EstimatedRestored), Inline: true},
 			{Name: "スコア", Value: fmt.Sprintf("%d", score), Inline: true},
 			{Name: "最終観測", Value: lastSeenText, Inline: false},
 		},
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
+	if len(entry.StatusHistory) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "wplace の状態",
+			Value:  embeds.FormatUserStatus(entry.Banned, entry.TimedOut) + "\n" + embeds.FormatStatusHistory(entry.StatusHistory, loc, 5),
+			Inline: false,
+		})
+	}
+	if entry.SuspectedBot != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "🤖 bot の疑い",
+			Value:  embeds.FormatBotEvidence(entry.SuspectedBot.Evidence) + "\n" + embeds.FormatBotReview(entry.SuspectedBot, loc),
+			Inline: false,
+		})
+	}
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 		Name:   "実績",
-		Value:  buildUserAchievementSummary(dataDir, entry),
+		Value:  buildUserAchievementSummary(dataDir, entry, loc),
 		Inline: false,
 	})
 
-	file := buildUserActivityImageFile(entry)
+	file := buildProfileCardFile(dataDir, entry)
 	if file != nil {
 		embed.Image = &discordgo.MessageEmbedImage{
 			URL: "attachment://" + file.Name,
@@ -439,8 +463,8 @@ func buildUserActivityDetailEmbedFromEntry(dataDir, kind, listType string, entry
 	return embed, file
 }
 
-func buildUserAchievementSummary(dataDir string, entry userActivityEntry) string {
-	storePath := filepath.Join(dataDir, "achievements.json")
+func buildUserAchievementSummary(dataDir string, entry userActivityEntry, loc *time.Location) string {
+	storePath := filepath.Join(dataDir, achievements.FileName)
 	store, err := achievements.Load(storePath)
 	if err != nil {
 		return "取得に失敗しました。"
@@ -472,7 +496,7 @@ func buildUserAchievementSummary(dataDir string, entry userActivityEntry) string
 		line := "• " + a.Name
 		if a.AwardedAt != "" {
 			if t, parseErr := time.Parse(time.RFC3339, a.AwardedAt); parseErr == nil {
-				line += fmt.Sprintf(" (%s)", t.In(time.FixedZone("JST", 9*3600)).Format("2006-01-02"))
+				line += fmt.Sprintf(" (%s)", t.In(loc).Format("2006-01-02"))
 			}
 		}
 		if totalChars+len(line) > maxChars {
@@ -489,30 +513,33 @@ func buildUserAchievementSummary(dataDir string, entry userActivityEntry) string
 // activityToEntry は *activity.UserActivity を userActivityEntry に変換する共通ヘルパー。
 func activityToEntry(id string, e *activity.UserActivity) userActivityEntry {
 	return userActivityEntry{
-		ID:            id,
-		Name:          e.Name,
-		AllianceID:    e.AllianceID,
-		Alliance:      e.AllianceName,
-		Discord:       e.Discord,
-		DiscordID:     e.DiscordID,
-		Picture:       e.Picture,
-		VandalCount:   e.VandalCount,
-		RestoredCount: e.RestoredCount,
-		Score:         activityScore(e.RestoredCount, e.VandalCount),
-		LastSeen:      parseUserListTime(e.LastSeen),
+		ID:                id,
+		Name:              e.Name,
+		AllianceID:        e.AllianceID,
+		Alliance:          e.AllianceName,
+		Discord:           e.Discord,
+		DiscordID:         e.DiscordID,
+		Picture:           e.Picture,
+		VandalCount:       e.VandalCount,
+		RestoredCount:     e.RestoredCount,
+		Score:             activityScore(e.RestoredCount, e.VandalCount),
+		LastSeen:          parseUserListTime(e.LastSeen),
+		DailyVandal:       e.DailyVandalCounts,
+		DailyRestored:     e.DailyRestoredCounts,
+		EstimatedVandal:   e.EstimatedVandalCount,
+		EstimatedRestored: e.EstimatedRestoredCount,
+		SuspectedBot:      e.SuspectedBot,
+		Banned:            e.Banned,
+		TimedOut:          e.TimedOut,
+		StatusHistory:     e.StatusHistory,
 	}
 }
 
 func loadUserActivityEntries(dataDir, kind, listType string) ([]userActivityEntry, error) {
-	path := filepath.Join(dataDir, "user_activity.json")
-	data, err := os.ReadFile(path)
+	raw, err := activity.LoadUserActivityMap(dataDir)
 	if err != nil {
 		return nil, err
 	}
-	var raw map[string]*activity.UserActivity
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return nil, err
-	}
 
 	entries := make([]userActivityEntry, 0, len(raw))
 	for id, e := range raw {
@@ -529,27 +556,16 @@ func loadUserActivityEntries(dataDir, kind, listType string) ([]userActivityEntr
 }
 
 func loadUserActivityByDiscordName(dataDir, query string) ([]userActivityEntry, error) {
-	path := filepath.Join(dataDir, "user_activity.json")
-	data, err := os.ReadFile(path)
+	repo, err := activity.OpenRepository(dataDir)
 	if err != nil {
 		return nil, err
 	}
-	var raw map[string]*activity.UserActivity
-	if err := json.Unmarshal(data, &raw); err != nil {
+	raw, err := repo.FindUsersByDiscordName(query)
+	if err != nil {
 		return nil, err
 	}
-	queryLower := strings.ToLower(strings.TrimSpace(query))
-	if queryLower == "" {
-		return nil, nil
-	}
-	matches := make([]userActivityEntry, 0)
+	matches := make([]userActivityEntry, 0, len(raw))
 	for id, entry := range raw {
-		if entry.Discord == "" {
-			continue
-		}
-		if !strings.Contains(strings.ToLower(entry.Discord), queryLower) {
-			continue
-		}
 		matches = append(matches, activityToEntry(id, entry))
 	}
 	sort.Slice(matches, func(i, j int) bool {
@@ -562,27 +578,16 @@ func loadUserActivityByDiscordName(dataDir, query string) ([]userActivityEntry,
 }
 
 func loadUserActivityByName(dataDir, query string) ([]userActivityEntry, error) {
-	path := filepath.Join(dataDir, "user_activity.json")
-	data, err := os.ReadFile(path)
+	repo, err := activity.OpenRepository(dataDir)
 	if err != nil {
 		return nil, err
 	}
-	var raw map[string]*activity.UserActivity
-	if err := json.Unmarshal(data, &raw); err != nil {
+	raw, err := repo.FindUsersByName(query)
+	if err != nil {
 		return nil, err
 	}
-	queryLower := strings.ToLower(strings.TrimSpace(query))
-	if queryLower == "" {
-		return nil, nil
-	}
-	matches := make([]userActivityEntry, 0)
+	matches := make([]userActivityEntry, 0, len(raw))
 	for id, entry := range raw {
-		if entry.Name == "" {
-			continue
-		}
-		if !strings.Contains(strings.ToLower(entry.Name), queryLower) {
-			continue
-		}
 		matches = append(matches, activityToEntry(id, entry))
 	}
 	sort.Slice(matches, func(i, j int) bool {
@@ -595,25 +600,33 @@ func loadUserActivityByName(dataDir, query string) ([]userActivityEntry, error)
 }
 
 func loadUserActivityByID(dataDir, userID, discordID string) (userActivityEntry, error) {
-	path := filepath.Join(dataDir, "user_activity.json")
-	data, err := os.ReadFile(path)
+	repo, err := activity.OpenRepository(dataDir)
 	if err != nil {
 		return userActivityEntry{}, err
 	}
-	var raw map[string]*activity.UserActivity
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return userActivityEntry{}, err
-	}
-	normalizedDiscord := strings.TrimSpace(discordID)
 	normalizedUser := strings.TrimSpace(userID)
-	for id, entry := range raw {
-		if normalizedUser != "" && id == normalizedUser {
-			return activityToEntry(id, entry), nil
+	if normalizedUser != "" {
+		entry, err := repo.GetUser(normalizedUser)
+		if err != nil {
+			return userActivityEntry{}, err
 		}
-		if normalizedDiscord != "" && strings.EqualFold(entry.DiscordID, normalizedDiscord) {
-			return activityToEntry(id, entry), nil
+		if entry != nil {
+			return activityToEntry(normalizedUser, entry), nil
 		}
 	}
+	raw, err := repo.FindUsersByDiscordID(discordID)
+	if err != nil {
+		return userActivityEntry{}, err
+	}
+	// 同じ Discord ID に複数の wplace アカウントがあれば ID 順で先頭
+	ids := make([]string, 0, len(raw))
+	for id := range raw {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if len(ids) > 0 {
+		return activityToEntry(ids[0], raw[ids[0]]), nil
+	}
 	return userActivityEntry{}, fmt.Errorf("該当ユーザーが見つかりません")
 }
 
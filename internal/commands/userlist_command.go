@@ -2,11 +2,9 @@ package commands
 
 import (
 	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/utils"
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -30,16 +28,17 @@ const (
 )
 
 type UserListCommand struct {
-	kind    string
-	dataDir string
+	kind     string
+	dataDir  string
+	settings *config.SettingsManager
 }
 
-func NewFixUserCommand(dataDir string) *UserListCommand {
-	return &UserListCommand{kind: userListKindFix, dataDir: dataDir}
+func NewFixUserCommand(dataDir string, settings *config.SettingsManager) *UserListCommand {
+	return &UserListCommand{kind: userListKindFix, dataDir: dataDir, settings: settings}
 }
 
-func NewGrfUserCommand(dataDir string) *UserListCommand {
-	return &UserListCommand{kind: userListKindGrf, dataDir: dataDir}
+func NewGrfUserCommand(dataDir string, settings *config.SettingsManager) *UserListCommand {
+	return &UserListCommand{kind: userListKindGrf, dataDir: dataDir, settings: settings}
 }
 
 func (c *UserListCommand) Name() string {
@@ -69,7 +68,7 @@ func (c *UserListCommand) ExecuteText(s *discordgo.Session, m *discordgo.Message
 		}
 	}
 	page := 0
-	return sendUserListMessage(s, m.ChannelID, c.dataDir, c.kind, mode, listType, page)
+	return sendUserListMessage(s, m.ChannelID, c.dataDir, c.kind, mode, listType, page, c.settings.GuildLocation(m.GuildID))
 }
 
 func (c *UserListCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.InteractionCreate) error {
@@ -90,7 +89,7 @@ func (c *UserListCommand) ExecuteSlash(s *discordgo.Session, i *discordgo.Intera
 		page = 0
 	}
 
-	embed, components, err := buildUserListEmbed(c.dataDir, c.kind, mode, listType, page)
+	embed, components, err := buildUserListEmbed(c.dataDir, c.kind, mode, listType, page, c.settings.GuildLocation(i.GuildID))
 	if err != nil {
 		return respondUserListError(s, i, err)
 	}
@@ -140,7 +139,7 @@ func (c *UserListCommand) SlashDefinition() *discordgo.ApplicationCommand {
 	}
 }
 
-func HandleUserListPagination(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string) {
+func HandleUserListPagination(s *discordgo.Session, i *discordgo.InteractionCreate, dataDir string, loc *time.Location) {
 	customID := i.MessageComponentData().CustomID
 	if !strings.HasPrefix(customID, userListPrefix) {
 		return
@@ -165,7 +164,7 @@ func HandleUserListPagination(s *discordgo.Session, i *discordgo.InteractionCrea
 		page = 0
 	}
 
-	embed, components, err := buildUserListEmbed(dataDir, kind, mode, listType, page)
+	embed, components, err := buildUserListEmbed(dataDir, kind, mode, listType, page, loc)
 	if err != nil {
 		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -196,7 +195,7 @@ type userListEntry struct {
 	LastSeen   time.Time
 }
 
-func buildUserListEmbed(dataDir, kind, mode, listType string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+func buildUserListEmbed(dataDir, kind, mode, listType string, page int, loc *time.Location) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
 	entries, err := loadUserListEntries(dataDir, kind, listType)
 	if err != nil {
 		return nil, nil, err
@@ -241,7 +240,6 @@ func buildUserListEmbed(dataDir, kind, mode, listType string, page int) (*discor
 	}
 
 	lines := make([]string, 0)
-	jst := time.FixedZone("JST", 9*3600)
 	for i := start; i < end; i++ {
 		entry := entries[i]
 		name := utils.FormatUserDisplayName(entry.Name, entry.ID)
@@ -250,7 +248,7 @@ func buildUserListEmbed(dataDir, kind, mode, listType string, page int) (*discor
 		}
 		lastSeenText := "-"
 		if !entry.LastSeen.IsZero() {
-			lastSeenText = entry.LastSeen.In(jst).Format("2006-01-02 15:04")
+			lastSeenText = entry.LastSeen.In(loc).Format("2006-01-02 15:04")
 		}
 		lines = append(lines, fmt.Sprintf("%d. %s | %d | 最終 %s", i+1, name, entry.Count, lastSeenText))
 	}
@@ -317,15 +315,10 @@ func buildUserListEmbed(dataDir, kind, mode, listType string, page int) (*discor
 }
 
 func loadUserListEntries(dataDir, kind, listType string) ([]userListEntry, error) {
-	path := filepath.Join(dataDir, "user_activity.json")
-	data, err := os.ReadFile(path)
+	raw, err := activity.LoadUserActivityMap(dataDir)
 	if err != nil {
 		return nil, err
 	}
-	var raw map[string]*activity.UserActivity
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return nil, err
-	}
 
 	entries := make([]userListEntry, 0, len(raw))
 	for id, entry := range raw {
@@ -402,8 +395,8 @@ func respondUserListError(s *discordgo.Session, i *discordgo.InteractionCreate,
 	})
 }
 
-func sendUserListMessage(s *discordgo.Session, channelID, dataDir, kind, mode, listType string, page int) error {
-	embed, components, err := buildUserListEmbed(dataDir, kind, mode, listType, page)
+func sendUserListMessage(s *discordgo.Session, channelID, dataDir, kind, mode, listType string, page int, loc *time.Location) error {
+	embed, components, err := buildUserListEmbed(dataDir, kind, mode, listType, page, loc)
 	if err != nil {
 		_, e := s.ChannelMessageSend(channelID, "❌ エラー: "+err.Error())
 		return e
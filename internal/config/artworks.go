@@ -0,0 +1,136 @@
+package config
+
+import (
+	"Koukyo_discord_bot/internal/utils"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ArtworksFileName 監視アートワーク定義ファイル名（data/ 直下）
+	ArtworksFileName = "artworks.json"
+	// DefaultArtworkID 既定（皇居）アートワークのID
+	DefaultArtworkID = "koukyo"
+	// artworkDataDirName 2件目以降のアートワークの永続データを置くディレクトリ
+	artworkDataDirName = "artworks"
+)
+
+var artworkIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Artwork 監視対象アートワークの定義
+type Artwork struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	TileX            int    `json:"tile_x"`
+	TileY            int    `json:"tile_y"`
+	PixelX           int    `json:"pixel_x"`
+	PixelY           int    `json:"pixel_y"`
+	Width            int    `json:"width"`
+	Height           int    `json:"height"`
+	WebSocketURL     string `json:"websocket_url,omitempty"`     // 監視WebSocket（空ならスタンドアロン取得のみ）
+	PollURL          string `json:"poll_url,omitempty"`          // WS断時のHTTP poll先
+	Template         string `json:"template,omitempty"`          // data/template_img/ 配下のテンプレート（スタンドアロン用）
+	WeightedTemplate string `json:"weighted_template,omitempty"` // data/ 直下の加重差分用テンプレート
+}
+
+// DefaultArtwork 従来固定だった皇居エリアの定義
+func DefaultArtwork() Artwork {
+	return Artwork{
+		ID:               DefaultArtworkID,
+		Name:             "皇居",
+		TileX:            utils.MainMonitorTileX,
+		TileY:            utils.MainMonitorTileY,
+		PixelX:           utils.MainMonitorPixelX,
+		PixelY:           utils.MainMonitorPixelY,
+		Width:            utils.MainMonitorWidth,
+		Height:           utils.MainMonitorHeight,
+		Template:         "1818-806-989-358.png",
+		WeightedTemplate: "1818-806-989-358_kiku_only.webp",
+	}
+}
+
+// Origin 左上座標を "tileX-tileY-pixelX-pixelY" 形式で返す
+func (a Artwork) Origin() string {
+	return fmt.Sprintf("%d-%d-%d-%d", a.TileX, a.TileY, a.PixelX, a.PixelY)
+}
+
+// FullsizeString `/get fullsize:` 用の文字列を返す
+func (a Artwork) FullsizeString() string {
+	return utils.AreaFullsizeString(a.TileX, a.TileY, a.PixelX, a.PixelY, a.Width, a.Height)
+}
+
+// WplaceURL アートワーク中心の Wplace URL を返す
+func (a Artwork) WplaceURL() string {
+	return utils.BuildAreaWplaceURL(a.TileX, a.TileY, a.PixelX, a.PixelY, a.Width, a.Height)
+}
+
+// DisplayName 表示名（未設定ならID）
+func (a Artwork) DisplayName() string {
+	if strings.TrimSpace(a.Name) != "" {
+		return a.Name
+	}
+	return a.ID
+}
+
+// ArtworkDataDir アートワークごとの永続データ置き場を返す。
+// 先頭（primary）のアートワークは既存データとの互換のため baseDir をそのまま使う。
+func ArtworkDataDir(baseDir string, art Artwork, primary bool) string {
+	if primary {
+		return baseDir
+	}
+	return filepath.Join(baseDir, artworkDataDirName, art.ID)
+}
+
+// LoadArtworks artworks.json を読み込む。ファイルが無い場合は既定アートワーク1件を返す。
+func LoadArtworks(path string) ([]Artwork, error) {
+	var root struct {
+		Artworks []Artwork `json:"artworks"`
+	}
+	_, err := utils.ReadJSONFileWithBackup(path, &root)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Artwork{DefaultArtwork()}, nil
+		}
+		return nil, err
+	}
+	if len(root.Artworks) == 0 {
+		return []Artwork{DefaultArtwork()}, nil
+	}
+	return normalizeArtworks(root.Artworks)
+}
+
+func normalizeArtworks(list []Artwork) ([]Artwork, error) {
+	seen := make(map[string]struct{}, len(list))
+	out := make([]Artwork, 0, len(list))
+	for i, art := range list {
+		art.ID = strings.ToLower(strings.TrimSpace(art.ID))
+		art.Name = strings.TrimSpace(art.Name)
+		art.WebSocketURL = strings.TrimSpace(art.WebSocketURL)
+		art.PollURL = strings.TrimSpace(art.PollURL)
+		art.Template = strings.TrimSpace(art.Template)
+		art.WeightedTemplate = strings.TrimSpace(art.WeightedTemplate)
+		if !artworkIDPattern.MatchString(art.ID) {
+			return nil, fmt.Errorf("artworks[%d]: invalid id %q", i, art.ID)
+		}
+		if _, ok := seen[art.ID]; ok {
+			return nil, fmt.Errorf("artworks[%d]: duplicate id %q", i, art.ID)
+		}
+		seen[art.ID] = struct{}{}
+		if art.Width <= 0 || art.Height <= 0 {
+			return nil, fmt.Errorf("artwork %s: width/height must be positive", art.ID)
+		}
+		if art.TileX < 0 || art.TileY < 0 || art.PixelX < 0 || art.PixelY < 0 ||
+			art.PixelX >= utils.WplaceTileSize || art.PixelY >= utils.WplaceTileSize {
+			return nil, fmt.Errorf("artwork %s: origin out of range: %s", art.ID, art.Origin())
+		}
+		if art.Template == "" {
+			art.Template = art.Origin() + ".png"
+		}
+		out = append(out, art)
+	}
+	return out, nil
+}
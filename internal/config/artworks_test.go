@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadArtworksMissingFileReturnsDefault(t *testing.T) {
+	t.Parallel()
+
+	arts, err := LoadArtworks(filepath.Join(t.TempDir(), ArtworksFileName))
+	if err != nil {
+		t.Fatalf("LoadArtworks returned error: %v", err)
+	}
+	if len(arts) != 1 || arts[0].ID != DefaultArtworkID {
+		t.Fatalf("expected default artwork only, got %+v", arts)
+	}
+	if got := arts[0].Origin(); got != "1818-806-989-358" {
+		t.Fatalf("unexpected default origin: %s", got)
+	}
+}
+
+func TestLoadArtworksNormalizesEntries(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ArtworksFileName)
+	payload := `{"artworks":[
+		{"id":" Koukyo ","name":"皇居","tile_x":1818,"tile_y":806,"pixel_x":989,"pixel_y":358,"width":107,"height":142},
+		{"id":"fuji","tile_x":1817,"tile_y":805,"pixel_x":10,"pixel_y":20,"width":50,"height":40}
+	]}`
+	if err := os.WriteFile(path, []byte(payload), 0644); err != nil {
+		t.Fatalf("failed to write artworks: %v", err)
+	}
+
+	arts, err := LoadArtworks(path)
+	if err != nil {
+		t.Fatalf("LoadArtworks returned error: %v", err)
+	}
+	if len(arts) != 2 {
+		t.Fatalf("expected 2 artworks, got %d", len(arts))
+	}
+	if arts[0].ID != "koukyo" {
+		t.Fatalf("expected normalized id koukyo, got %q", arts[0].ID)
+	}
+	if arts[1].Template != "1817-805-10-20.png" {
+		t.Fatalf("expected template derived from origin, got %q", arts[1].Template)
+	}
+	if arts[1].DisplayName() != "fuji" {
+		t.Fatalf("expected display name fallback to id, got %q", arts[1].DisplayName())
+	}
+	if got := ArtworkDataDir("/data", arts[1], false); got != filepath.Join("/data", "artworks", "fuji") {
+		t.Fatalf("unexpected artwork data dir: %s", got)
+	}
+}
+
+func TestLoadArtworksRejectsInvalidEntries(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"duplicate": `{"artworks":[{"id":"a","width":1,"height":1},{"id":"a","width":1,"height":1}]}`,
+		"bad id":    `{"artworks":[{"id":"../x","width":1,"height":1}]}`,
+		"size":      `{"artworks":[{"id":"a","width":0,"height":1}]}`,
+		"origin":    `{"artworks":[{"id":"a","pixel_x":1000,"width":1,"height":1}]}`,
+	}
+	for name, payload := range cases {
+		path := filepath.Join(t.TempDir(), name+".json")
+		if err := os.WriteFile(path, []byte(payload), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := LoadArtworks(path); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// QuietModeSilent 静音時間中はメンションせずに通知する
+	QuietModeSilent = "silent"
+	// QuietModeMute 静音時間中は通知せず、終了時にまとめを投稿する
+	QuietModeMute = "mute"
+)
+
+// QuietHours 毎日の静音時間（サーバーのタイムゾーン。Start > End なら日付をまたぐ）
+type QuietHours struct {
+	Start string `json:"start"` // "HH:MM"
+	End   string `json:"end"`   // "HH:MM"
+	Mode  string `json:"mode"`  // QuietModeSilent / QuietModeMute
+}
+
+// MaintenanceWindow 一時的なメンテナンス期間（通知は止めてログと記録のみ）
+type MaintenanceWindow struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// Active t が静音時間内か
+func (q QuietHours) Active(t time.Time, loc *time.Location) bool {
+	start, err1 := ParseClock(q.Start)
+	end, err2 := ParseClock(q.End)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// Label 「02:00〜07:00 (メンションなし)」形式
+func (q QuietHours) Label() string {
+	mode := "メンションなし"
+	if q.Mode == QuietModeMute {
+		mode = "通知停止"
+	}
+	return fmt.Sprintf("%s〜%s (%s)", q.Start, q.End, mode)
+}
+
+// Active t がメンテナンス期間内か
+func (w MaintenanceWindow) Active(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// ParseClock "HH:MM" を0時からの分に変換する
+func ParseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	if !ok || err1 != nil || err2 != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("時刻 %q は HH:MM 形式で指定してください", s)
+	}
+	return h*60 + m, nil
+}
+
+// NormalizeClock "2:0" → "02:00"
+func NormalizeClock(s string) (string, error) {
+	v, err := ParseClock(s)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%02d:%02d", v/60, v%60), nil
+}
+
+// ParseMaintenanceDuration "90"（分）/ "2h" / "30m" を期間に変換する（最大7日）
+func ParseMaintenanceDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		minutes, convErr := strconv.Atoi(strings.TrimSuffix(s, "分"))
+		if convErr != nil {
+			return 0, fmt.Errorf("期間 %q は分数または 2h / 30m 形式で指定してください", s)
+		}
+		d = time.Duration(minutes) * time.Minute
+	}
+	if d <= 0 || d > 7*24*time.Hour {
+		return 0, fmt.Errorf("期間は1分〜7日で指定してください")
+	}
+	return d, nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursActiveAcrossMidnight(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*3600)
+	q := QuietHours{Start: "23:30", End: "07:00", Mode: QuietModeSilent}
+	cases := []struct {
+		clock string
+		want  bool
+	}{{"23:29", false}, {"23:30", true}, {"02:00", true}, {"06:59", true}, {"07:00", false}, {"12:00", false}}
+	for _, tc := range cases {
+		at, _ := time.ParseInLocation("15:04", tc.clock, jst)
+		if got := q.Active(at, jst); got != tc.want {
+			t.Errorf("Active(%s) = %v, want %v", tc.clock, got, tc.want)
+		}
+	}
+
+	day := QuietHours{Start: "02:00", End: "07:00"}
+	at, _ := time.ParseInLocation("15:04", "01:59", jst)
+	if day.Active(at, jst) {
+		t.Fatalf("01:59 should be outside 02:00-07:00")
+	}
+	if (QuietHours{Start: "bad", End: "07:00"}).Active(at, jst) {
+		t.Fatalf("invalid clock must never be active")
+	}
+}
+
+func TestParseMaintenanceDuration(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]time.Duration{"90": 90 * time.Minute, "2h": 2 * time.Hour, "30分": 30 * time.Minute} {
+		got, err := ParseMaintenanceDuration(input)
+		if err != nil || got != want {
+			t.Errorf("ParseMaintenanceDuration(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"0", "-5m", "8d", "abc", "200h"} {
+		if _, err := ParseMaintenanceDuration(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// ReportCadenceDaily 毎日、前日分を投稿する
+	ReportCadenceDaily = "daily"
+	// ReportCadenceWeekly 毎週月曜、前週（月〜日）分を投稿する
+	ReportCadenceWeekly = "weekly"
+	// ReportCadenceMonthly 毎月1日、前月分を投稿する
+	ReportCadenceMonthly = "monthly"
+
+	// DefaultReportTime 定期レポートの投稿時刻の既定値（サーバーのタイムゾーン）
+	DefaultReportTime = "00:00"
+)
+
+// 定期レポートに含める項目
+const (
+	ReportSectionSummary   = "summary"
+	ReportSectionIncidents = "incidents"
+	ReportSectionVandal    = "vandal"
+	ReportSectionRestore   = "restore"
+	ReportSectionActivity  = "activity"
+	ReportSectionAlliance  = "alliance"
+	ReportSectionPeak      = "peak"
+	// ReportSectionLeaderboard 順位変動付きのリーダーボード（既定では含めない）
+	ReportSectionLeaderboard = "leaderboard"
+)
+
+// ReportSections 定期レポートの項目（表示順）
+var ReportSections = []string{
+	ReportSectionSummary,
+	ReportSectionIncidents,
+	ReportSectionVandal,
+	ReportSectionRestore,
+	ReportSectionActivity,
+	ReportSectionAlliance,
+	ReportSectionPeak,
+	ReportSectionLeaderboard,
+}
+
+// defaultReportSections Sections が空のときの項目
+var defaultReportSections = ReportSections[:len(ReportSections)-1]
+
+// ReportSchedule 定期レポート（日次ランキング）の投稿設定
+type ReportSchedule struct {
+	Time     string   `json:"time,omitempty"`     // "HH:MM"（サーバーのタイムゾーン。空なら 00:00）
+	Cadence  string   `json:"cadence,omitempty"`  // ReportCadence*（空なら daily）
+	Sections []string `json:"sections,omitempty"` // ReportSection*（空なら leaderboard 以外の全項目）
+}
+
+// Report 定期レポートの設定（未設定の項目は既定値で埋める）
+func (gs GuildSettings) Report() ReportSchedule {
+	var rs ReportSchedule
+	if gs.ReportSchedule != nil {
+		rs = *gs.ReportSchedule
+	}
+	if _, err := ParseClock(rs.Time); err != nil {
+		rs.Time = DefaultReportTime
+	}
+	switch rs.Cadence {
+	case ReportCadenceWeekly, ReportCadenceMonthly:
+	default:
+		rs.Cadence = ReportCadenceDaily
+	}
+	if len(rs.Sections) == 0 {
+		rs.Sections = defaultReportSections
+	}
+	return rs
+}
+
+// Minute 投稿時刻（0時からの分）
+func (rs ReportSchedule) Minute() int {
+	v, err := ParseClock(rs.Time)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// Includes 項目を含むか（GuildSettings.Report() で既定値を埋めてから使う）
+func (rs ReportSchedule) Includes(section string) bool {
+	return slices.Contains(rs.Sections, section)
+}
+
+// CadenceLabel 「毎日」「毎週月曜」「毎月1日」
+func (rs ReportSchedule) CadenceLabel() string {
+	switch rs.Cadence {
+	case ReportCadenceWeekly:
+		return "毎週月曜"
+	case ReportCadenceMonthly:
+		return "毎月1日"
+	default:
+		return "毎日"
+	}
+}
+
+// Label 「毎週月曜 09:00 (summary, vandal)」形式
+func (rs ReportSchedule) Label() string {
+	sections := "標準項目"
+	if len(rs.Sections) > 0 && !slices.Equal(rs.Sections, defaultReportSections) {
+		sections = strings.Join(rs.Sections, ", ")
+	}
+	return fmt.Sprintf("%s %s (%s)", rs.CadenceLabel(), rs.Time, sections)
+}
+
+// ParseReportSchedule 設定パネルの入力から定期レポート設定を作る。
+// すべて既定値（毎日 00:00・標準項目）なら nil を返す。
+func ParseReportSchedule(timeInput, cadenceInput, sectionsInput string) (*ReportSchedule, error) {
+	rs := ReportSchedule{Time: DefaultReportTime, Cadence: ReportCadenceDaily}
+	if s := strings.TrimSpace(timeInput); s != "" {
+		clock, err := NormalizeClock(s)
+		if err != nil {
+			return nil, err
+		}
+		rs.Time = clock
+	}
+	switch cadence := strings.ToLower(strings.TrimSpace(cadenceInput)); cadence {
+	case "", ReportCadenceDaily:
+	case ReportCadenceWeekly, ReportCadenceMonthly:
+		rs.Cadence = cadence
+	default:
+		return nil, fmt.Errorf("頻度は daily / weekly / monthly のいずれかで指定してください")
+	}
+
+	seen := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(strings.ToLower(sectionsInput), func(r rune) bool {
+		return r == ',' || r == '、' || r == ' ' || r == '\n'
+	}) {
+		if !slices.Contains(ReportSections, field) {
+			return nil, fmt.Errorf("項目 %q は %s から選んでください", field, strings.Join(ReportSections, " / "))
+		}
+		seen[field] = true
+	}
+	for _, section := range ReportSections {
+		if seen[section] {
+			rs.Sections = append(rs.Sections, section)
+		}
+	}
+	if slices.Equal(rs.Sections, defaultReportSections) {
+		rs.Sections = nil
+	}
+
+	if rs.Time == DefaultReportTime && rs.Cadence == ReportCadenceDaily && len(rs.Sections) == 0 {
+		return nil, nil
+	}
+	return &rs, nil
+}
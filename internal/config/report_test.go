@@ -0,0 +1,50 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReportSchedule(t *testing.T) {
+	t.Parallel()
+
+	rs, err := ParseReportSchedule(" 9:30 ", "Weekly", "vandal, summary、peak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ReportSchedule{Time: "09:30", Cadence: ReportCadenceWeekly, Sections: []string{ReportSectionSummary, ReportSectionVandal, ReportSectionPeak}}
+	if !reflect.DeepEqual(rs, want) {
+		t.Fatalf("got %+v, want %+v", rs, want)
+	}
+
+	// リーダーボードは明示したときだけ含める
+	if rs, err := ParseReportSchedule("", "", "summary, leaderboard"); err != nil || !reflect.DeepEqual(rs.Sections, []string{ReportSectionSummary, ReportSectionLeaderboard}) {
+		t.Fatalf("leaderboard section: %+v, %v", rs, err)
+	}
+
+	// 既定値だけなら nil（設定なし）
+	if rs, err := ParseReportSchedule("", "daily", "summary incidents vandal restore activity alliance peak"); err != nil || rs != nil {
+		t.Fatalf("defaults should be nil, got %+v, %v", rs, err)
+	}
+	for _, in := range [][3]string{{"25:00", "", ""}, {"", "yearly", ""}, {"", "", "summary, chart"}} {
+		if _, err := ParseReportSchedule(in[0], in[1], in[2]); err == nil {
+			t.Errorf("expected error for %q", in)
+		}
+	}
+}
+
+func TestGuildSettingsReportDefaults(t *testing.T) {
+	t.Parallel()
+
+	rs := (GuildSettings{}).Report()
+	if rs.Time != DefaultReportTime || rs.Cadence != ReportCadenceDaily || !rs.Includes(ReportSectionPeak) || rs.Includes(ReportSectionLeaderboard) {
+		t.Fatalf("unexpected defaults: %+v", rs)
+	}
+	rs = (GuildSettings{ReportSchedule: &ReportSchedule{Time: "bad", Cadence: "monthly", Sections: []string{ReportSectionVandal}}}).Report()
+	if rs.Minute() != 0 || rs.Cadence != ReportCadenceMonthly || rs.Includes(ReportSectionSummary) || !rs.Includes(ReportSectionVandal) {
+		t.Fatalf("unexpected schedule: %+v", rs)
+	}
+	if got := rs.Label(); got != "毎月1日 00:00 (vandal)" {
+		t.Fatalf("label = %q", got)
+	}
+}
@@ -18,12 +18,50 @@ type GuildSettings struct {
 	NotificationFixChannel    *string `json:"notification_fix_channel,omitempty"`    // 修復ユーザー通知チャンネル
 	AchievementChannel        *string `json:"achievement_channel,omitempty"`         // 実績通知チャンネル
 	ProgressChannel           *string `json:"progress_channel,omitempty"`            // 進捗通知チャンネル
+	ModerationChannel         *string `json:"moderation_channel,omitempty"`          // モデレーション通知チャンネル（bot の疑い）
 	AutoNotifyEnabled         bool    `json:"auto_notify_enabled"`                   // 自動通知ON/OFF
 	ProgressNotifyEnabled     bool    `json:"progress_notify_enabled"`               // 進捗通知ON/OFF
 	NotificationThreshold     float64 `json:"notification_threshold"`                // 通知閾値（%）
 	MentionRole               *string `json:"mention_role,omitempty"`                // メンションロールID
 	MentionThreshold          float64 `json:"mention_threshold"`                     // メンション閾値（%）
 	NotificationMetric        string  `json:"notification_metric"`                   // 通知指標: "overall" or "weighted"
+	IncidentThreadsEnabled    bool    `json:"incident_threads_enabled"`              // インシデントごとにスレッドへまとめる
+
+	TierLadder      []TierLevel      `json:"tier_ladder,omitempty"`      // 独自Tier（空なら通知閾値から10%刻み）
+	TierEscalations []TierEscalation `json:"tier_escalations,omitempty"` // Tier継続時のエスカレーション
+
+	QuietHours  *QuietHours        `json:"quiet_hours,omitempty"` // 毎日の静音時間
+	Maintenance *MaintenanceWindow `json:"maintenance,omitempty"` // メンテナンス期間
+
+	DeliveryMode          string `json:"delivery_mode,omitempty"`           // 配信モード: "immediate" or "digest"
+	DigestIntervalMinutes int    `json:"digest_interval_minutes,omitempty"` // ダイジェストの投稿間隔（分）
+
+	Timezone string `json:"timezone,omitempty"` // 表示・日次投稿のタイムゾーン（IANA 名。空なら JST）
+
+	ReportSchedule *ReportSchedule `json:"report_schedule,omitempty"` // 定期レポートの投稿時刻・頻度・項目（nil なら毎日 00:00・全項目）
+}
+
+const (
+	// DeliveryImmediate 通知をその都度送る
+	DeliveryImmediate = "immediate"
+	// DeliveryDigest 重要でない通知をまとめて N 分ごとに送る
+	DeliveryDigest = "digest"
+	// DefaultDigestIntervalMinutes ダイジェスト間隔の既定値
+	DefaultDigestIntervalMinutes = 15
+)
+
+// DigestEnabled ダイジェスト配信か
+func (gs GuildSettings) DigestEnabled() bool {
+	return gs.DeliveryMode == DeliveryDigest
+}
+
+// DigestInterval ダイジェストの投稿間隔
+func (gs GuildSettings) DigestInterval() time.Duration {
+	minutes := gs.DigestIntervalMinutes
+	if minutes <= 0 {
+		minutes = DefaultDigestIntervalMinutes
+	}
+	return time.Duration(minutes) * time.Minute
 }
 
 // DefaultGuildSettings デフォルト設定
@@ -263,7 +301,19 @@ func normalizeGuildSettings(settings GuildSettings) GuildSettings {
 	normalized.NotificationFixChannel = settings.NotificationFixChannel
 	normalized.AchievementChannel = settings.AchievementChannel
 	normalized.ProgressChannel = settings.ProgressChannel
+	normalized.ModerationChannel = settings.ModerationChannel
 	normalized.MentionRole = settings.MentionRole
+	normalized.IncidentThreadsEnabled = settings.IncidentThreadsEnabled
+	normalized.TierLadder = settings.TierLadder
+	normalized.TierEscalations = settings.TierEscalations
+	normalized.QuietHours = settings.QuietHours
+	normalized.Maintenance = settings.Maintenance
+	normalized.Timezone = settings.Timezone
+	normalized.ReportSchedule = settings.ReportSchedule
+	if settings.DeliveryMode == DeliveryDigest {
+		normalized.DeliveryMode = DeliveryDigest
+		normalized.DigestIntervalMinutes = settings.DigestIntervalMinutes
+	}
 
 	if settings.AutoNotifyEnabled || !looksLikeLegacyNotificationSettings(settings) {
 		normalized.AutoNotifyEnabled = settings.AutoNotifyEnabled
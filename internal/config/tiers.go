@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxTierLevels Tierラダーの最大段数
+const MaxTierLevels = 10
+
+// MaxTierEscalations エスカレーション設定の最大件数
+const MaxTierEscalations = 5
+
+// TierLevel ギルド独自の Tier 段階
+type TierLevel struct {
+	Threshold    float64  `json:"threshold"`               // この値（%）以上で該当
+	Name         string   `json:"name,omitempty"`          // 表示名（空なら「x%以上」）
+	Color        int      `json:"color,omitempty"`         // Embed色（0ならデフォルト配色）
+	MentionRoles []string `json:"mention_roles,omitempty"` // 上昇時にメンションするロール
+	Ping         bool     `json:"ping"`                    // メンションするか
+}
+
+// TierEscalation 一定以上の Tier が続いたときの追加メンション
+type TierEscalation struct {
+	MinThreshold float64  `json:"min_threshold"` // この値（%）以上の Tier が
+	AfterMinutes int      `json:"after_minutes"` // この分数続いたら
+	RoleIDs      []string `json:"role_ids"`      // このロールへ通知
+}
+
+var roleIDPattern = regexp.MustCompile(`^(?:<@&)?(\d{5,20})>?$`)
+
+// DisplayName 表示名（未設定なら閾値から生成）
+func (l TierLevel) DisplayName() string {
+	if l.Name != "" {
+		return l.Name
+	}
+	return FormatPercent(l.Threshold) + "以上"
+}
+
+// FormatPercent 0.5 → "0.5%"、10 → "10%"
+func FormatPercent(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64) + "%"
+}
+
+// ParseTierLadder 設定パネルの入力（1行1段: 閾値 | 名前 | 色 | ping/silent | ロール...）を解釈する。
+// 空入力は nil（デフォルトの10%刻み）を返す。
+func ParseTierLadder(text string) ([]TierLevel, error) {
+	var levels []TierLevel
+	for n, line := range nonEmptyLines(text) {
+		fields := splitFields(line)
+		threshold, err := parseThreshold(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%d行目: %w", n+1, err)
+		}
+		level := TierLevel{Threshold: threshold}
+		if len(fields) > 1 {
+			level.Name = fields[1]
+		}
+		if len(fields) > 2 && fields[2] != "" {
+			color, err := strconv.ParseInt(strings.TrimPrefix(fields[2], "#"), 16, 32)
+			if err != nil || color < 0 || color > 0xFFFFFF {
+				return nil, fmt.Errorf("%d行目: 色 %q は #RRGGBB 形式で指定してください", n+1, fields[2])
+			}
+			level.Color = int(color)
+		}
+		if len(fields) > 3 {
+			switch strings.ToLower(fields[3]) {
+			case "ping", "on":
+				level.Ping = true
+			case "", "silent", "off":
+			default:
+				return nil, fmt.Errorf("%d行目: ping/silent を指定してください（%q）", n+1, fields[3])
+			}
+		}
+		if len(fields) > 4 {
+			roles, err := parseRoleIDs(fields[4])
+			if err != nil {
+				return nil, fmt.Errorf("%d行目: %w", n+1, err)
+			}
+			level.MentionRoles = roles
+		}
+		levels = append(levels, level)
+	}
+	if len(levels) > MaxTierLevels {
+		return nil, fmt.Errorf("Tierは最大%d段までです", MaxTierLevels)
+	}
+	slices.SortFunc(levels, func(a, b TierLevel) int {
+		switch {
+		case a.Threshold < b.Threshold:
+			return -1
+		case a.Threshold > b.Threshold:
+			return 1
+		}
+		return 0
+	})
+	for i := 1; i < len(levels); i++ {
+		if levels[i].Threshold == levels[i-1].Threshold {
+			return nil, fmt.Errorf("閾値 %s が重複しています", FormatPercent(levels[i].Threshold))
+		}
+	}
+	return levels, nil
+}
+
+// FormatTierLadder ParseTierLadder の入力形式に戻す（モーダルの初期値用）
+func FormatTierLadder(levels []TierLevel) string {
+	lines := make([]string, 0, len(levels))
+	for _, l := range levels {
+		ping := "silent"
+		if l.Ping {
+			ping = "ping"
+		}
+		color := ""
+		if l.Color != 0 {
+			color = fmt.Sprintf("#%06X", l.Color)
+		}
+		line := fmt.Sprintf("%s | %s | %s | %s", strconv.FormatFloat(l.Threshold, 'f', -1, 64), l.Name, color, ping)
+		if len(l.MentionRoles) > 0 {
+			line += " | " + formatRoleIDs(l.MentionRoles)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseTierEscalations 設定パネルの入力（1行1件: 閾値 | 分 | ロール...）を解釈する
+func ParseTierEscalations(text string) ([]TierEscalation, error) {
+	var out []TierEscalation
+	for n, line := range nonEmptyLines(text) {
+		fields := splitFields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%d行目: 「閾値 | 分 | ロール」の形式で指定してください", n+1)
+		}
+		threshold, err := parseThreshold(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%d行目: %w", n+1, err)
+		}
+		minutes, err := strconv.Atoi(strings.TrimSuffix(fields[1], "分"))
+		if err != nil || minutes <= 0 || minutes > 24*60 {
+			return nil, fmt.Errorf("%d行目: 分数 %q は 1〜1440 で指定してください", n+1, fields[1])
+		}
+		roles, err := parseRoleIDs(fields[2])
+		if err != nil || len(roles) == 0 {
+			return nil, fmt.Errorf("%d行目: エスカレーション先のロールを指定してください", n+1)
+		}
+		out = append(out, TierEscalation{MinThreshold: threshold, AfterMinutes: minutes, RoleIDs: roles})
+	}
+	if len(out) > MaxTierEscalations {
+		return nil, fmt.Errorf("エスカレーションは最大%d件までです", MaxTierEscalations)
+	}
+	return out, nil
+}
+
+// FormatTierEscalations ParseTierEscalations の入力形式に戻す
+func FormatTierEscalations(list []TierEscalation) string {
+	lines := make([]string, 0, len(list))
+	for _, e := range list {
+		lines = append(lines, fmt.Sprintf("%s | %d | %s", strconv.FormatFloat(e.MinThreshold, 'f', -1, 64), e.AfterMinutes, formatRoleIDs(e.RoleIDs)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func nonEmptyLines(text string) []string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func splitFields(line string) []string {
+	fields := strings.Split(line, "|")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+func parseThreshold(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || v <= 0 || v > 100 {
+		return 0, fmt.Errorf("閾値 %q は 0より大きく100以下の数値で指定してください", s)
+	}
+	return v, nil
+}
+
+func parseRoleIDs(s string) ([]string, error) {
+	var roles []string
+	for _, token := range strings.Fields(s) {
+		m := roleIDPattern.FindStringSubmatch(token)
+		if m == nil {
+			return nil, fmt.Errorf("ロール %q は <@&ID> かIDで指定してください", token)
+		}
+		if !slices.Contains(roles, m[1]) {
+			roles = append(roles, m[1])
+		}
+	}
+	return roles, nil
+}
+
+func formatRoleIDs(ids []string) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, "<@&"+id+">")
+	}
+	return strings.Join(parts, " ")
+}
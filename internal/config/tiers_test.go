@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTierLadderSortsAndRoundTrips(t *testing.T) {
+	t.Parallel()
+
+	input := "15 | 警報 | #E74C3C | ping | <@&123456789> 987654321\n\n0.5 | 注意\n2%"
+	levels, err := ParseTierLadder(input)
+	if err != nil {
+		t.Fatalf("ParseTierLadder returned error: %v", err)
+	}
+	want := []TierLevel{
+		{Threshold: 0.5, Name: "注意"},
+		{Threshold: 2},
+		{Threshold: 15, Name: "警報", Color: 0xE74C3C, Ping: true, MentionRoles: []string{"123456789", "987654321"}},
+	}
+	if !reflect.DeepEqual(levels, want) {
+		t.Fatalf("unexpected levels:\n got %+v\nwant %+v", levels, want)
+	}
+	if levels[1].DisplayName() != "2%以上" {
+		t.Fatalf("unexpected default name: %q", levels[1].DisplayName())
+	}
+
+	again, err := ParseTierLadder(FormatTierLadder(levels))
+	if err != nil || !reflect.DeepEqual(again, want) {
+		t.Fatalf("round trip mismatch: %+v err=%v", again, err)
+	}
+}
+
+func TestParseTierLadderRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		"0 | ゼロ",
+		"5 | a\n5 | b",
+		"5 | a | red",
+		"5 | a | | loud",
+		"5 | a | | ping | @everyone",
+	} {
+		if _, err := ParseTierLadder(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+	if levels, err := ParseTierLadder("  \n"); err != nil || levels != nil {
+		t.Fatalf("empty input should reset ladder, got %+v err=%v", levels, err)
+	}
+}
+
+func TestParseTierEscalations(t *testing.T) {
+	t.Parallel()
+
+	list, err := ParseTierEscalations("5 | 30分 | <@&111111>\n40 | 10 | 222222 <@&333333>")
+	if err != nil {
+		t.Fatalf("ParseTierEscalations returned error: %v", err)
+	}
+	want := []TierEscalation{
+		{MinThreshold: 5, AfterMinutes: 30, RoleIDs: []string{"111111"}},
+		{MinThreshold: 40, AfterMinutes: 10, RoleIDs: []string{"222222", "333333"}},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Fatalf("unexpected escalations: %+v", list)
+	}
+	if _, err := ParseTierEscalations("5 | 30"); err == nil {
+		t.Fatalf("expected error when role is missing")
+	}
+}
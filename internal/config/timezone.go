@@ -0,0 +1,76 @@
+package config
+
+import (
+	"Koukyo_discord_bot/internal/utils"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimezone サーバーのタイムゾーンの既定値
+const DefaultTimezone = "Asia/Tokyo"
+
+var (
+	// defaultLocation 既定の表示タイムゾーン。日次集計など保存データの日付キーもこの基準のまま
+	defaultLocation = time.FixedZone("JST", 9*3600)
+	locationCache   sync.Map // IANA 名 -> *time.Location
+)
+
+// DefaultLocation 既定のタイムゾーン（JST）
+func DefaultLocation() *time.Location {
+	return defaultLocation
+}
+
+// ParseGuildTimezone 入力（"JST"、"UTC"、"Europe/Paris" など）を保存用の IANA 名へ正規化する。
+// 空欄は既定値（JST）を表す空文字を返す。
+func ParseGuildTimezone(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", nil
+	}
+	loc, err := utils.ParseTimezone(input)
+	if err != nil || loc.String() == "Local" {
+		return "", fmt.Errorf("unknown timezone %q", input)
+	}
+	if loc.String() == DefaultTimezone {
+		return "", nil
+	}
+	return loc.String(), nil
+}
+
+func loadLocation(name string) *time.Location {
+	if name == "" || name == DefaultTimezone {
+		return defaultLocation
+	}
+	if cached, ok := locationCache.Load(name); ok {
+		return cached.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return defaultLocation
+	}
+	locationCache.Store(name, loc)
+	return loc
+}
+
+// Location サーバーのタイムゾーン（未設定なら JST）
+func (gs GuildSettings) Location() *time.Location {
+	return loadLocation(gs.Timezone)
+}
+
+// TimezoneLabel 設定パネル向けの表示（例: "Europe/Paris (CET)"）
+func (gs GuildSettings) TimezoneLabel(now time.Time) string {
+	if gs.Timezone == "" {
+		return DefaultTimezone + " (JST)"
+	}
+	return fmt.Sprintf("%s (%s)", gs.Timezone, now.In(gs.Location()).Format("MST"))
+}
+
+// GuildLocation サーバーのタイムゾーン（sm が nil なら既定値）
+func (sm *SettingsManager) GuildLocation(guildID string) *time.Location {
+	if sm == nil || guildID == "" {
+		return defaultLocation
+	}
+	return sm.GetGuildSettings(guildID).Location()
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGuildTimezone(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]string{"": "", "JST": "", "Asia/Tokyo": "", "utc": "UTC", " Europe/Paris ": "Europe/Paris", "PST": "America/Los_Angeles"} {
+		got, err := ParseGuildTimezone(input)
+		if err != nil || got != want {
+			t.Errorf("ParseGuildTimezone(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"Mars/Olympus", "Local"} {
+		if _, err := ParseGuildTimezone(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestGuildSettingsLocation(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 1, 1, 15, 0, 0, 0, time.UTC)
+	if got := (GuildSettings{}).Location(); at.In(got).Hour() != 0 {
+		t.Fatalf("default location should be JST, got %s", at.In(got))
+	}
+	paris := GuildSettings{Timezone: "Europe/Paris"}
+	if got := at.In(paris.Location()).Format("15:04 MST"); got != "16:00 CET" {
+		t.Fatalf("paris = %s", got)
+	}
+	if got := paris.TimezoneLabel(at); got != "Europe/Paris (CET)" {
+		t.Fatalf("label = %q", got)
+	}
+	// 壊れた値は既定値へフォールバックする
+	if got := (GuildSettings{Timezone: "Bad/Zone"}).Location(); got != DefaultLocation() {
+		t.Fatalf("invalid timezone should fall back to default, got %s", got)
+	}
+}
@@ -0,0 +1,126 @@
+package embeds
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// allianceSeriesDays 同盟詳細に載せる日別推移の日数（今日を含む、JST）
+const allianceSeriesDays = 7
+
+// allianceSummary 「🚨 13 / 🛠️ 1 / スコア -12」
+func allianceSummary(vandal, restored int) string {
+	return fmt.Sprintf("🚨 %d / 🛠️ %d / スコア %d", vandal, restored, restored-vandal)
+}
+
+// pageBounds page を丸め、ページ内の範囲と最終ページを返す
+func pageBounds(total, page, pageSize int) (int, int, int, int) {
+	maxPage := 0
+	if total > 0 {
+		maxPage = (total - 1) / pageSize
+	}
+	page = min(max(page, 0), maxPage)
+	start := page * pageSize
+	return page, start, min(start+pageSize, total), maxPage
+}
+
+// BuildAllianceListEmbed 同盟一覧（荒らし数の多い順）の1ページ。page は 0 始まりで、範囲外は丸めた値を返す
+func BuildAllianceListEmbed(stats []activity.AllianceStats, page, pageSize int) (*discordgo.MessageEmbed, int, int) {
+	page, start, end, maxPage := pageBounds(len(stats), page, pageSize)
+	lines := make([]string, 0, end-start)
+	for i, a := range stats[start:end] {
+		lines = append(lines, fmt.Sprintf("%d. %s | %s | メンバー %d人 (アクティブ %d人)",
+			start+i+1, a.DisplayName(), allianceSummary(a.VandalCount, a.RestoredCount), len(a.Members), a.ActiveMembers))
+	}
+	value := strings.Join(lines, "\n")
+	if value == "" {
+		value = "該当なし"
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "🏴 同盟一覧",
+		Description: fmt.Sprintf("所属同盟のあるユーザーを同盟ごとに合算 | %d同盟", len(stats)),
+		Color:       0x9B59B6,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: fmt.Sprintf("ページ %d / %d", page+1, maxPage+1), Value: value},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("荒らし数の多い順 | アクティブ = 直近%d日に活動", activity.AllianceActiveDays),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, page, maxPage
+}
+
+// BuildAllianceDetailEmbed 同盟の合計・日別推移とメンバー一覧の1ページ
+func BuildAllianceDetailEmbed(a activity.AllianceStats, page, pageSize int, now time.Time, loc *time.Location) (*discordgo.MessageEmbed, int, int) {
+	page, start, end, maxPage := pageBounds(len(a.Members), page, pageSize)
+	members := make([]string, 0, end-start)
+	for i, m := range a.Members[start:end] {
+		mark := ""
+		if m.Active {
+			mark = " 🟢"
+		}
+		members = append(members, fmt.Sprintf("%d. %s | 🚨 %d / 🛠️ %d%s",
+			start+i+1, utils.FormatUserDisplayName(m.Name, m.ID), m.VandalCount, m.RestoredCount, mark))
+	}
+	memberText := strings.Join(members, "\n")
+	if memberText == "" {
+		memberText = "該当なし"
+	}
+
+	firstSeen := a.FirstSeen
+	if firstSeen == "" {
+		firstSeen = "-"
+	}
+	lastSeen := "-"
+	if !a.LastSeen.IsZero() {
+		lastSeen = a.LastSeen.In(loc).Format("2006-01-02 15:04:05 MST")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: "🏴 同盟詳細: " + a.DisplayName(),
+		Color: 0x9B59B6,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "荒らし数", Value: fmt.Sprintf("%d", a.VandalCount), Inline: true},
+			{Name: "修復数", Value: fmt.Sprintf("%d", a.RestoredCount), Inline: true},
+			{Name: "スコア", Value: fmt.Sprintf("%d", a.Score()), Inline: true},
+			{Name: "メンバー", Value: fmt.Sprintf("%d人 (アクティブ %d人)", len(a.Members), a.ActiveMembers), Inline: true},
+			{Name: "初観測日 (JST)", Value: firstSeen, Inline: true},
+			{Name: "最終観測", Value: lastSeen, Inline: true},
+			{Name: fmt.Sprintf("直近%d日の推移 (JST)", allianceSeriesDays), Value: formatAllianceSeries(a, now)},
+			{Name: fmt.Sprintf("メンバー (ページ %d / %d)", page+1, maxPage+1), Value: memberText},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("荒らし数の多い順 | 🟢 = 直近%d日に活動", activity.AllianceActiveDays),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, page, maxPage
+}
+
+// formatAllianceSeries 「03-13 🚨 5 / 🛠️ 1 (1人)」形式の日別推移（古い順）
+func formatAllianceSeries(a activity.AllianceStats, now time.Time) string {
+	today := now.In(config.DefaultLocation())
+	lines := make([]string, 0, allianceSeriesDays)
+	for i := allianceSeriesDays - 1; i >= 0; i-- {
+		key := today.AddDate(0, 0, -i).Format("2006-01-02")
+		lines = append(lines, fmt.Sprintf("%s 🚨 %d / 🛠️ %d (%d人)", key[5:], a.DailyVandal[key], a.DailyRestored[key], a.DailyMembers[key]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatAllianceRanking 定期レポートの同盟ランキング（上位 limit 件）
+func FormatAllianceRanking(entries []activity.AlliancePeriodEntry, limit int) string {
+	if len(entries) == 0 {
+		return "該当なし"
+	}
+	lines := make([]string, 0, min(len(entries), limit))
+	for i, e := range entries[:min(len(entries), limit)] {
+		lines = append(lines, fmt.Sprintf("%d. %s | 🚨 %d / 🛠️ %d (%d人)", i+1, e.DisplayName(), e.VandalCount, e.RestoredCount, e.Members))
+	}
+	return strings.Join(lines, "\n")
+}
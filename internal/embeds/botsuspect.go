@@ -0,0 +1,71 @@
+package embeds
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// BotReviewPrefix 確認ボタンの CustomID「botreview:<verdict>:<userID>:<artworkID>」の接頭辞
+const BotReviewPrefix = "botreview:"
+
+// FormatBotEvidence bot の疑いの根拠（1行1件）
+func FormatBotEvidence(evidence []activity.BotEvidence) string {
+	lines := make([]string, 0, len(evidence))
+	for _, ev := range evidence {
+		lines = append(lines, fmt.Sprintf("- %s: %s", botEvidenceLabel(ev.Kind), ev.Detail))
+	}
+	if len(lines) == 0 {
+		return "-"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatBotReview 確認結果（未確認なら「未確認」）
+func FormatBotReview(s *activity.BotSuspicion, loc *time.Location) string {
+	switch s.Review {
+	case activity.BotReviewConfirmed, activity.BotReviewDismissed:
+		label := "🤖 bot と確認"
+		if s.Review == activity.BotReviewDismissed {
+			label = "✅ 誤検知"
+		}
+		if at, err := time.Parse(time.RFC3339Nano, s.ReviewedAt); err == nil {
+			return fmt.Sprintf("%s（%s / %s）", label, s.ReviewedBy, at.In(loc).Format("2006-01-02 15:04"))
+		}
+		return fmt.Sprintf("%s（%s）", label, s.ReviewedBy)
+	}
+	return "⏳ 未確認"
+}
+
+// BotReviewComponents 確認/誤検知ボタン
+func BotReviewComponents(artworkID, userID string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "bot と確認",
+				Style:    discordgo.DangerButton,
+				CustomID: BotReviewPrefix + activity.BotReviewConfirmed + ":" + userID + ":" + artworkID,
+			},
+			discordgo.Button{
+				Label:    "誤検知",
+				Style:    discordgo.SecondaryButton,
+				CustomID: BotReviewPrefix + activity.BotReviewDismissed + ":" + userID + ":" + artworkID,
+			},
+		}},
+	}
+}
+
+func botEvidenceLabel(kind string) string {
+	switch kind {
+	case activity.BotEvidenceCadence:
+		return "一定間隔"
+	case activity.BotEvidenceScanline:
+		return "走査線順"
+	case activity.BotEvidenceChargeRate:
+		return "回復速度超過"
+	}
+	return kind
+}
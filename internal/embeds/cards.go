@@ -0,0 +1,348 @@
+package embeds
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/utils"
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	profileCardWidth  = 800
+	profileCardHeight = 320
+	profileAvatarSize = 160
+	podiumWidth       = 800
+	podiumAvatarSize  = 56
+	// podiumListLimit 表彰台の下に並べる4位以下の件数
+	podiumListLimit = 6
+)
+
+var (
+	cardBackground = color.NRGBA{43, 45, 49, 255}
+	cardPanel      = color.NRGBA{56, 58, 64, 255}
+	cardText       = color.NRGBA{242, 243, 245, 255}
+	cardSubText    = color.NRGBA{181, 186, 193, 255}
+	cardAccent     = color.NRGBA{88, 101, 242, 255}
+	cardVandal     = color.NRGBA{237, 66, 69, 255}
+	cardRestore    = color.NRGBA{87, 242, 135, 255}
+	podiumColors   = [3]color.NRGBA{{241, 196, 15, 255}, {189, 195, 199, 255}, {205, 127, 50, 255}}
+)
+
+// ProfileCard プロフィールカード画像の内容
+type ProfileCard struct {
+	Name          string // 表示名（"name#id" など）
+	Alliance      string
+	Picture       []byte // wplace のアイコン画像（PNG/JPEG/WebP）。nil なら IconSeed の Identicon
+	IconSeed      string
+	VandalCount   int
+	RestoredCount int
+	Score         int
+	Daily         []int    // 日別のアクション数（古い順）
+	DailyLabel    string   // スパークラインの見出し（例: "Last 30 days"）
+	Badges        []string // 取得済み実績の名前
+}
+
+// PodiumEntry 表彰台画像の1行
+type PodiumEntry struct {
+	ID       string // Identicon の seed
+	Name     string
+	Alliance string
+	Count    int
+	Rank     int // 0 なら並び順
+}
+
+// PodiumEntriesFromLeaderboard リーダーボードの上位を表彰台用に変換する
+func PodiumEntriesFromLeaderboard(lb activity.Leaderboard) []PodiumEntry {
+	limit := min(len(lb.Entries), 3+podiumListLimit)
+	out := make([]PodiumEntry, 0, limit)
+	for _, entry := range lb.Entries[:limit] {
+		out = append(out, PodiumEntry{
+			ID:       entry.ID,
+			Name:     utils.FormatUserDisplayName(entry.Name, entry.ID),
+			Alliance: entry.Alliance,
+			Count:    entry.Count,
+			Rank:     entry.Rank,
+		})
+	}
+	return out
+}
+
+func (e PodiumEntry) rankOr(position int) int {
+	if e.Rank > 0 {
+		return e.Rank
+	}
+	return position
+}
+
+// BuildProfileCardPNG アイコン・同盟・件数・日別アクティビティ・実績バッジをまとめたカード画像を生成
+func BuildProfileCardPNG(card ProfileCard) (*bytes.Buffer, error) {
+	img := image.NewRGBA(image.Rect(0, 0, profileCardWidth, profileCardHeight))
+	fillCardRect(img, img.Bounds(), cardBackground)
+	fillCardRect(img, image.Rect(0, 0, 8, profileCardHeight), cardAccent)
+
+	avatarRect := image.Rect(32, 32, 32+profileAvatarSize, 32+profileAvatarSize)
+	fillCardRect(img, avatarRect.Inset(-3), cardPanel)
+	if avatar := cardAvatar(card.Picture, card.IconSeed); avatar != nil {
+		xdraw.NearestNeighbor.Scale(img, avatarRect, avatar, avatar.Bounds(), xdraw.Over, nil)
+	}
+
+	left := avatarRect.Max.X + 32
+	right := profileCardWidth - 32
+	nameFace := ResolveFontFace(30, basicfont.Face7x13)
+	subFace := ResolveFontFace(18, basicfont.Face7x13)
+	labelFace := ResolveFontFace(13, basicfont.Face7x13)
+	valueFace := ResolveFontFace(24, basicfont.Face7x13)
+
+	drawFaceText(img, fitText(nameFace, card.Name, right-left), left, 64, cardText, nameFace)
+	alliance := card.Alliance
+	if alliance == "" {
+		alliance = "-"
+	}
+	drawFaceText(img, fitText(subFace, "Alliance: "+alliance, right-left), left, 94, cardSubText, subFace)
+
+	stats := []struct {
+		label string
+		value int
+		c     color.NRGBA
+	}{
+		{"VANDAL", card.VandalCount, cardVandal},
+		{"RESTORED", card.RestoredCount, cardRestore},
+		{"SCORE", card.Score, cardText},
+	}
+	const statGap = 12
+	statWidth := (right - left - statGap*(len(stats)-1)) / len(stats)
+	for i, stat := range stats {
+		x := left + i*(statWidth+statGap)
+		fillCardRect(img, image.Rect(x, 112, x+statWidth, 168), cardPanel)
+		drawFaceText(img, stat.label, x+12, 130, cardSubText, labelFace)
+		drawFaceText(img, fmt.Sprintf("%d", stat.value), x+12, 160, stat.c, valueFace)
+	}
+
+	label := card.DailyLabel
+	if label == "" {
+		label = "Daily activity"
+	}
+	total, peak := 0, 0
+	for _, v := range card.Daily {
+		total += v
+		peak = max(peak, v)
+	}
+	drawFaceText(img, fmt.Sprintf("%s  total %d / max %d", label, total, peak), left, 194, cardSubText, labelFace)
+	plot := image.Rect(left, 202, right, 250)
+	fillCardRect(img, plot, cardPanel)
+	drawSparkline(img, plot.Inset(4), card.Daily, cardAccent)
+
+	drawBadges(img, card.Badges, 32, 268, right)
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// BuildLeaderboardPodiumPNG 上位3人を表彰台、4位以下を一覧で描いたランキング画像を生成
+func BuildLeaderboardPodiumPNG(title, subtitle string, entries []PodiumEntry) (*bytes.Buffer, error) {
+	rest := entries[min(len(entries), 3):min(len(entries), 3+podiumListLimit)]
+	const (
+		podiumBase = 350
+		rowHeight  = 26
+	)
+	height := podiumBase + 24
+	if len(rest) > 0 {
+		height += (len(rest)+1)/2*rowHeight + 8
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, podiumWidth, height))
+	fillCardRect(img, img.Bounds(), cardBackground)
+
+	titleFace := ResolveFontFace(24, basicfont.Face7x13)
+	subFace := ResolveFontFace(14, basicfont.Face7x13)
+	rankFace := ResolveFontFace(36, basicfont.Face7x13)
+	nameFace := ResolveFontFace(14, basicfont.Face7x13)
+	countFace := ResolveFontFace(18, basicfont.Face7x13)
+	rowFace := ResolveFontFace(15, basicfont.Face7x13)
+
+	drawCenteredFaceText(img, fitText(titleFace, title, podiumWidth-40), podiumWidth/2, 40, cardText, titleFace)
+	drawCenteredFaceText(img, fitText(subFace, subtitle, podiumWidth-40), podiumWidth/2, 64, cardSubText, subFace)
+
+	if len(entries) == 0 {
+		drawCenteredFaceText(img, "No entries", podiumWidth/2, podiumBase/2+40, cardSubText, countFace)
+	}
+
+	// 左から 2位・1位・3位
+	const blockWidth = 200
+	centers := [3]int{podiumWidth / 2, podiumWidth/2 - 220, podiumWidth/2 + 220}
+	heights := [3]int{150, 110, 80}
+	for rank := 0; rank < min(len(entries), 3); rank++ {
+		entry := entries[rank]
+		cx := centers[rank]
+		top := podiumBase - heights[rank]
+		fillCardRect(img, image.Rect(cx-blockWidth/2, top, cx+blockWidth/2, podiumBase), podiumColors[rank])
+
+		avatarTop := top - podiumAvatarSize - 56
+		avatarRect := image.Rect(cx-podiumAvatarSize/2, avatarTop, cx+podiumAvatarSize/2, avatarTop+podiumAvatarSize)
+		fillCardRect(img, avatarRect.Inset(-2), podiumColors[rank])
+		if avatar := cardAvatar(nil, entry.ID); avatar != nil {
+			xdraw.NearestNeighbor.Scale(img, avatarRect, avatar, avatar.Bounds(), xdraw.Over, nil)
+		}
+		drawCenteredFaceText(img, fitText(nameFace, entry.Name, blockWidth), cx, avatarRect.Max.Y+20, cardText, nameFace)
+		if entry.Alliance != "" {
+			drawCenteredFaceText(img, fitText(nameFace, entry.Alliance, blockWidth), cx, avatarRect.Max.Y+38, cardSubText, nameFace)
+		}
+		drawCenteredFaceText(img, fmt.Sprintf("%d", entry.rankOr(rank+1)), cx, top+44, cardBackground, rankFace)
+		drawCenteredFaceText(img, fmt.Sprintf("%d", entry.Count), cx, top+70, cardBackground, countFace)
+	}
+	fillCardRect(img, image.Rect(24, podiumBase, podiumWidth-24, podiumBase+4), cardPanel)
+
+	colWidth := (podiumWidth - 48) / 2
+	for i, entry := range rest {
+		x := 24 + (i%2)*colWidth
+		y := podiumBase + 24 + (i/2)*rowHeight
+		display := entry.Name
+		if entry.Alliance != "" {
+			display = fmt.Sprintf("%s (%s)", display, entry.Alliance)
+		}
+		count := fmt.Sprintf("%d", entry.Count)
+		countWidth := font.MeasureString(rowFace, count).Ceil()
+		rankText := fmt.Sprintf("%d.", entry.rankOr(i+4))
+		drawFaceText(img, rankText, x+8, y+16, cardSubText, rowFace)
+		drawFaceText(img, fitText(rowFace, display, colWidth-countWidth-64), x+40, y+16, cardText, rowFace)
+		drawFaceText(img, count, x+colWidth-countWidth-16, y+16, podiumColors[0], rowFace)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// cardAvatar wplace のアイコン画像を優先し、読めなければ seed の Identicon を使う
+func cardAvatar(picture []byte, seed string) image.Image {
+	if len(picture) > 0 {
+		if img, _, err := image.Decode(bytes.NewReader(picture)); err == nil {
+			return img
+		}
+	}
+	if seed == "" {
+		return nil
+	}
+	data, err := utils.BuildIdenticonPNG(seed, profileAvatarSize)
+	if err != nil {
+		return nil
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+// drawSparkline 値の推移を塗りつぶし付きの折れ線で描く（最大値で正規化）
+func drawSparkline(img *image.RGBA, rect image.Rectangle, values []int, c color.NRGBA) {
+	if len(values) == 0 || rect.Dx() <= 0 {
+		return
+	}
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	if peak == 0 {
+		fillCardRect(img, image.Rect(rect.Min.X, rect.Max.Y-2, rect.Max.X, rect.Max.Y), c)
+		return
+	}
+	area := color.NRGBA{c.R, c.G, c.B, 80}
+	for x := rect.Min.X; x < rect.Max.X; x++ {
+		v := float64(values[0])
+		if len(values) > 1 {
+			pos := float64(x-rect.Min.X) / float64(rect.Dx()-1) * float64(len(values)-1)
+			i := min(int(pos), len(values)-2)
+			frac := pos - float64(i)
+			v = float64(values[i])*(1-frac) + float64(values[i+1])*frac
+		}
+		y := rect.Max.Y - int(v/float64(peak)*float64(rect.Dy()-2)) - 2
+		fillCardRect(img, image.Rect(x, y+2, x+1, rect.Max.Y), area)
+		fillCardRect(img, image.Rect(x, y, x+1, y+2), c)
+	}
+}
+
+// drawBadges 実績名を横並びのバッジで描く。入りきらない分は "+N"
+func drawBadges(img *image.RGBA, badges []string, x, y, right int) {
+	face := ResolveFontFace(14, basicfont.Face7x13)
+	if len(badges) == 0 {
+		drawFaceText(img, "No achievements yet", x, y+19, cardSubText, face)
+		return
+	}
+	const (
+		height  = 28
+		padding = 12
+		gap     = 8
+	)
+	for i, badge := range badges {
+		width := font.MeasureString(face, badge).Ceil() + padding*2
+		if x+width > right-56 && i < len(badges)-1 || x+width > right {
+			drawFaceText(img, fmt.Sprintf("+%d", len(badges)-i), x+4, y+19, cardSubText, face)
+			return
+		}
+		fillCardRect(img, image.Rect(x, y, x+width, y+height), badgeColor(badge))
+		drawFaceText(img, badge, x+padding, y+19, cardBackground, face)
+		x += width + gap
+	}
+}
+
+// badgeColor 実績名ごとに固定の明るい色
+func badgeColor(name string) color.NRGBA {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	sum := h.Sum32()
+	return color.NRGBA{uint8(150 + sum%100), uint8(150 + (sum>>8)%100), uint8(150 + (sum>>16)%100), 255}
+}
+
+// fitText 幅に収まらない文字列を "..." で切り詰める
+func fitText(face font.Face, text string, maxWidth int) string {
+	if font.MeasureString(face, text).Ceil() <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "..."
+		if font.MeasureString(face, candidate).Ceil() <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func fillCardRect(img draw.Image, rect image.Rectangle, c color.NRGBA) {
+	draw.Draw(img, rect, &image.Uniform{C: c}, image.Point{}, draw.Over)
+}
+
+func drawFaceText(img draw.Image, text string, x, y int, c color.NRGBA, face font.Face) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+func drawCenteredFaceText(img draw.Image, text string, cx, y int, c color.NRGBA, face font.Face) {
+	width := font.MeasureString(face, text).Ceil()
+	drawFaceText(img, text, cx-width/2, y, c, face)
+}
@@ -0,0 +1,92 @@
+package embeds
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestBuildProfileCardUsesPicture(t *testing.T) {
+	t.Parallel()
+
+	picture := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range picture.Pix {
+		if i%4 == 0 || i%4 == 3 {
+			picture.Pix[i] = 255
+		}
+	}
+	var raw bytes.Buffer
+	if err := png.Encode(&raw, picture); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := BuildProfileCardPNG(ProfileCard{
+		Name:     "tester#1",
+		Picture:  raw.Bytes(),
+		IconSeed: "1",
+		Daily:    []int{0, 3, 1},
+		Badges:   []string{"First Steps", strings.Repeat("Long Badge ", 20)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != profileCardWidth || img.Bounds().Dy() != profileCardHeight {
+		t.Fatalf("unexpected size: %v", img.Bounds())
+	}
+	// アバター領域の中心は渡した画像（赤）で塗られる
+	center := 32 + profileAvatarSize/2
+	if got := color.RGBAModel.Convert(img.At(center, center)).(color.RGBA); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Fatalf("avatar pixel = %v, want red", got)
+	}
+}
+
+func TestBuildLeaderboardPodiumHeight(t *testing.T) {
+	t.Parallel()
+
+	entries := make([]PodiumEntry, 12)
+	for i := range entries {
+		entries[i] = PodiumEntry{ID: string(rune('a' + i)), Name: "user", Count: 12 - i}
+	}
+	cases := []struct {
+		n, height int
+	}{
+		{0, 374},
+		{3, 374},
+		{5, 374 + 26 + 8},
+		{12, 374 + 3*26 + 8},
+	}
+	for _, c := range cases {
+		buf, err := BuildLeaderboardPodiumPNG("Restore", "Last 7 days", entries[:c.n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := png.DecodeConfig(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Width != podiumWidth || cfg.Height != c.height {
+			t.Errorf("%d entries: size %dx%d, want %dx%d", c.n, cfg.Width, cfg.Height, podiumWidth, c.height)
+		}
+	}
+}
+
+func TestFitText(t *testing.T) {
+	t.Parallel()
+
+	face := basicfont.Face7x13
+	if got := fitText(face, "short", 100); got != "short" {
+		t.Fatalf("fitText kept = %q", got)
+	}
+	if got := fitText(face, "a very long display name", 70); got != "a very..." {
+		t.Fatalf("fitText truncated = %q", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"Koukyo_discord_bot/internal/version"
 	"fmt"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -178,22 +179,17 @@ func BuildConvertPixelEmbed(tileX, tileY, pixelX, pixelY int) *discordgo.Message
 	return embed
 }
 
-// BuildNowEmbed now コマンド用の埋め込みを作成
-func BuildNowEmbed(mon *monitor.Monitor) *discordgo.MessageEmbed {
+// BuildNowEmbed now コマンド用の埋め込みを作成（現在時刻は loc で表示）
+func BuildNowEmbed(mon *monitor.Monitor, loc *time.Location) *discordgo.MessageEmbed {
 	now := time.Now()
-
-	// JSTに変換（タイムゾーンデータがない場合はUTC+9で代用）
-	jstLoc, err := time.LoadLocation("Asia/Tokyo")
-	if err != nil {
-		// タイムゾーンデータがない場合はUTC+9を使用
-		jstLoc = time.FixedZone("JST", 9*60*60)
-	}
-	jstTime := now.In(jstLoc)
+	localTime := now.In(loc)
+	art := mon.Artwork()
+	title := nowEmbedTitle(art)
 
 	// モニターがnilまたはデータがない場合
 	if mon == nil || !mon.State.HasData() {
 		embed := &discordgo.MessageEmbed{
-			Title:       "🏯 Wplace 監視情報",
+			Title:       title,
 			Description: "**現在の監視状況**",
 			Color:       0x3498DB, // Blue
 			Fields: []*discordgo.MessageEmbedField{
@@ -204,7 +200,7 @@ func BuildNowEmbed(mon *monitor.Monitor) *discordgo.MessageEmbed {
 				},
 				{
 					Name:   "🎯 監視対象",
-					Value:  "• 皇居エリア\n• 菊の紋章\n• 背景領域",
+					Value:  nowEmbedTargetSummary(art),
 					Inline: true,
 				},
 				{
@@ -213,8 +209,8 @@ func BuildNowEmbed(mon *monitor.Monitor) *discordgo.MessageEmbed {
 					Inline: true,
 				},
 				{
-					Name:   "⏰ 現在時刻 (JST)",
-					Value:  jstTime.Format("2006-01-02 15:04:05"),
+					Name:   fmt.Sprintf("⏰ 現在時刻 (%s)", localTime.Format("MST")),
+					Value:  localTime.Format("2006-01-02 15:04:05"),
 					Inline: false,
 				},
 				{
@@ -228,7 +224,7 @@ func BuildNowEmbed(mon *monitor.Monitor) *discordgo.MessageEmbed {
 				Text: "監視システム起動中...",
 			},
 		}
-		appendMainMonitorMapField(embed)
+		appendArtworkMapField(embed, art)
 		return embed
 	}
 
@@ -236,7 +232,7 @@ func BuildNowEmbed(mon *monitor.Monitor) *discordgo.MessageEmbed {
 	data := mon.GetLatestData()
 	if data == nil {
 		embed := &discordgo.MessageEmbed{
-			Title:       "🏯 Wplace 監視情報",
+			Title:       title,
 			Description: "**現在の監視状況**",
 			Color:       0x3498DB,
 			Fields: []*discordgo.MessageEmbedField{
@@ -248,7 +244,7 @@ func BuildNowEmbed(mon *monitor.Monitor) *discordgo.MessageEmbed {
 			},
 			Timestamp: now.UTC().Format(time.RFC3339),
 		}
-		appendMainMonitorMapField(embed)
+		appendArtworkMapField(embed, art)
 		return embed
 	}
 
@@ -283,7 +279,7 @@ func BuildNowEmbed(mon *monitor.Monitor) *discordgo.MessageEmbed {
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "🏯 Wplace 監視情報",
+		Title:       title,
 		Description: "**現在の監視状況**",
 		Color:       color,
 		Fields: []*discordgo.MessageEmbedField{
@@ -327,18 +323,32 @@ func BuildNowEmbed(mon *monitor.Monitor) *discordgo.MessageEmbed {
 			Inline: false,
 		})
 	}
-	appendMainMonitorMapField(embed)
+	appendArtworkMapField(embed, art)
 
 	return embed
 }
 
-func appendMainMonitorMapField(embed *discordgo.MessageEmbed) {
+func nowEmbedTitle(art config.Artwork) string {
+	if art.ID == config.DefaultArtworkID {
+		return "🏯 Wplace 監視情報"
+	}
+	return fmt.Sprintf("🏯 Wplace 監視情報 - %s", art.DisplayName())
+}
+
+func nowEmbedTargetSummary(art config.Artwork) string {
+	if art.ID == config.DefaultArtworkID {
+		return "• 皇居エリア\n• 菊の紋章\n• 背景領域"
+	}
+	return fmt.Sprintf("• %s\n• `%s` (%dx%d)", art.DisplayName(), art.Origin(), art.Width, art.Height)
+}
+
+func appendArtworkMapField(embed *discordgo.MessageEmbed, art config.Artwork) {
 	if embed == nil {
 		return
 	}
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 		Name:   "Wplace.live",
-		Value:  fmt.Sprintf("[地図で見る](%s)\n`/get fullsize:%s`", utils.BuildMainMonitorWplaceURL(), utils.MainMonitorFullsizeString()),
+		Value:  fmt.Sprintf("[地図で見る](%s)\n`/get fullsize:%s`", art.WplaceURL(), art.FullsizeString()),
 		Inline: false,
 	})
 }
@@ -351,6 +361,9 @@ func getConnectionStatus(mon *monitor.Monitor) string {
 	if mon.IsConnected() {
 		return "✅ WebSocketサーバーに接続中"
 	}
+	if source := mon.ActiveSourceName(); source != "" {
+		return fmt.Sprintf("🔁 フォールバック取得中（%s）", source)
+	}
 	return "⚠️ 接続試行中..."
 }
 
@@ -469,10 +482,61 @@ func BuildSettingsEmbed(settings *config.SettingsManager, guildID string) *disco
 	if guildSettings.MentionRole != nil {
 		roleText = fmt.Sprintf("<@&%s>", *guildSettings.MentionRole)
 	}
+	ladderText := fmt.Sprintf("デフォルト（%.0f%%以上を10%%刻み）", guildSettings.NotificationThreshold)
+	if len(guildSettings.TierLadder) > 0 {
+		lines := make([]string, 0, len(guildSettings.TierLadder))
+		for _, level := range guildSettings.TierLadder {
+			line := fmt.Sprintf("%s: %s", config.FormatPercent(level.Threshold), level.DisplayName())
+			if level.Ping && len(level.MentionRoles) > 0 {
+				line += " 🔔"
+				for _, id := range level.MentionRoles {
+					line += fmt.Sprintf(" <@&%s>", id)
+				}
+			}
+			lines = append(lines, line)
+		}
+		ladderText = strings.Join(lines, "\n")
+	}
+	escalationText := "(なし)"
+	if len(guildSettings.TierEscalations) > 0 {
+		lines := make([]string, 0, len(guildSettings.TierEscalations))
+		for _, esc := range guildSettings.TierEscalations {
+			line := fmt.Sprintf("%s以上が%d分継続 →", config.FormatPercent(esc.MinThreshold), esc.AfterMinutes)
+			for _, id := range esc.RoleIDs {
+				line += fmt.Sprintf(" <@&%s>", id)
+			}
+			lines = append(lines, line)
+		}
+		escalationText = strings.Join(lines, "\n")
+	}
+	quietText := "(なし)"
+	if guildSettings.QuietHours != nil {
+		quietText = guildSettings.QuietHours.Label()
+	}
+	maintenanceText := "(なし)"
+	if mw := guildSettings.Maintenance; mw != nil && mw.Active(time.Now()) {
+		maintenanceText = fmt.Sprintf("🛠️ %s まで", mw.End.In(guildSettings.Location()).Format("01/02 15:04"))
+		if mw.Reason != "" {
+			maintenanceText += "（" + mw.Reason + "）"
+		}
+	}
+	deliveryText := "即時"
+	if guildSettings.DigestEnabled() {
+		deliveryText = fmt.Sprintf("📰 ダイジェスト（%d分ごと）", int(guildSettings.DigestInterval().Minutes()))
+	}
+	timezoneText := guildSettings.TimezoneLabel(time.Now())
+	threadStatus := "❌ OFF"
+	if guildSettings.IncidentThreadsEnabled {
+		threadStatus = "✅ ON"
+	}
 	achievementChannelText := "(未設定)"
 	if guildSettings.AchievementChannel != nil {
 		achievementChannelText = fmt.Sprintf("<#%s>", *guildSettings.AchievementChannel)
 	}
+	moderationChannelText := "(未設定)"
+	if guildSettings.ModerationChannel != nil {
+		moderationChannelText = fmt.Sprintf("<#%s>", *guildSettings.ModerationChannel)
+	}
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "⚙️ Bot設定パネル",
@@ -514,6 +578,51 @@ func BuildSettingsEmbed(settings *config.SettingsManager, guildID string) *disco
 				Value:  achievementChannelText,
 				Inline: true,
 			},
+			{
+				Name:   "モデレーション通知チャンネル",
+				Value:  moderationChannelText,
+				Inline: true,
+			},
+			{
+				Name:   "インシデントスレッド",
+				Value:  threadStatus,
+				Inline: true,
+			},
+			{
+				Name:   "Tierラダー",
+				Value:  ladderText,
+				Inline: false,
+			},
+			{
+				Name:   "エスカレーション",
+				Value:  escalationText,
+				Inline: false,
+			},
+			{
+				Name:   "静音時間",
+				Value:  quietText,
+				Inline: true,
+			},
+			{
+				Name:   "メンテナンス",
+				Value:  maintenanceText,
+				Inline: true,
+			},
+			{
+				Name:   "配信モード",
+				Value:  deliveryText,
+				Inline: true,
+			},
+			{
+				Name:   "タイムゾーン",
+				Value:  timezoneText,
+				Inline: true,
+			},
+			{
+				Name:   "定期レポート",
+				Value:  guildSettings.Report().Label(),
+				Inline: true,
+			},
 		},
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: "ボタンをクリックして設定を変更できます",
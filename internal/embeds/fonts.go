@@ -0,0 +1,50 @@
+package embeds
+
+import (
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+var (
+	gofontOnce      sync.Once
+	gofontFaceCache = make(map[float64]font.Face)
+	gofontErr       error
+	gofontMu        sync.Mutex
+	gofontData      *opentype.Font
+)
+
+// ResolveFontFace 指定サイズの Go フォント。読み込めなければ fallback を返す
+func ResolveFontFace(size float64, fallback font.Face) font.Face {
+	face := getGoFontFace(size)
+	if face != nil {
+		return face
+	}
+	return fallback
+}
+
+func getGoFontFace(size float64) font.Face {
+	gofontOnce.Do(func() {
+		gofontData, gofontErr = opentype.Parse(goregular.TTF)
+	})
+	if gofontErr != nil || gofontData == nil {
+		return nil
+	}
+	gofontMu.Lock()
+	defer gofontMu.Unlock()
+	if face, ok := gofontFaceCache[size]; ok {
+		return face
+	}
+	face, err := opentype.NewFace(gofontData, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil
+	}
+	gofontFaceCache[size] = face
+	return face
+}
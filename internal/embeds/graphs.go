@@ -20,8 +20,12 @@ var graphJST = time.FixedZone("JST", 9*3600)
 
 // BuildDiffGraphPNG 差分履歴から簡易折れ線グラフPNGを生成
 // history: 時系列の差分率, titleは埋め込み側で使う
+// loc: 時刻軸のタイムゾーン（nil なら JST）
 
-func BuildDiffGraphPNG(history []monitor.DiffRecord) (*bytes.Buffer, error) {
+func BuildDiffGraphPNG(history []monitor.DiffRecord, loc *time.Location) (*bytes.Buffer, error) {
+	if loc == nil {
+		loc = graphJST
+	}
 	// 画像サイズ
 	const width = 800
 	const height = 400
@@ -123,11 +127,11 @@ func BuildDiffGraphPNG(history []monitor.DiffRecord) (*bytes.Buffer, error) {
 	for i := 0; i <= nXTicks; i++ {
 		t := tMin.Add(time.Duration(float64(tMax.Sub(tMin)) * float64(i) / float64(nXTicks)))
 		x := plotRect.Min.X + int(float64(plotRect.Dx())*float64(i)/float64(nXTicks))
-		drawText(img, x-18, plotRect.Max.Y+12, formatGraphTickTimeJST(t), tickColor)
+		drawText(img, x-18, plotRect.Max.Y+12, formatGraphTickTime(t, loc), tickColor)
 	}
 
 	// 軸ラベル
-	drawText(img, plotRect.Min.X+(plotRect.Dx()/2)-30, plotRect.Max.Y+34, "Time (JST)", color.RGBA{40, 40, 80, 255})
+	drawText(img, plotRect.Min.X+(plotRect.Dx()/2)-30, plotRect.Max.Y+34, "Time ("+tMax.In(loc).Format("MST")+")", color.RGBA{40, 40, 80, 255})
 	// 縦軸ラベル（ASCIIのみで可読性優先）
 	yLabel := "Diff %"
 	for i := 0; i < len(yLabel); i++ {
@@ -364,6 +368,6 @@ func drawText(img *image.RGBA, x, y int, s string, c color.RGBA) {
 	d.DrawString(s)
 }
 
-func formatGraphTickTimeJST(t time.Time) string {
-	return t.In(graphJST).Format("15:04")
+func formatGraphTickTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("15:04")
 }
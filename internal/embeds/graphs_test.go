@@ -7,14 +7,17 @@ import (
 	"time"
 )
 
-func TestFormatGraphTickTimeJST(t *testing.T) {
+func TestFormatGraphTickTime(t *testing.T) {
 	t.Parallel()
 
 	utc := time.Date(2026, 2, 20, 0, 15, 0, 0, time.UTC)
-	got := formatGraphTickTimeJST(utc)
+	got := formatGraphTickTime(utc, graphJST)
 	if got != "09:15" {
 		t.Fatalf("unexpected JST tick label: got=%s want=09:15", got)
 	}
+	if got := formatGraphTickTime(utc, time.UTC); got != "00:15" {
+		t.Fatalf("unexpected UTC tick label: got=%s want=00:15", got)
+	}
 }
 
 func TestSanitizeDiffHistoryFiltersAndSorts(t *testing.T) {
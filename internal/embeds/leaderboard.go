@@ -0,0 +1,156 @@
+package embeds
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/utils"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// leaderboardDigestLimit 定期投稿で種別ごとに表示する件数
+const leaderboardDigestLimit = 10
+
+// LeaderboardKindLabel 種別の表示名
+func LeaderboardKindLabel(kind string) string {
+	switch kind {
+	case activity.LeaderboardVandal:
+		return "🚨 荒らし"
+	case activity.LeaderboardRestore:
+		return "🛠️ 修復"
+	default:
+		return "🧮 総合 (修復 - 荒らし)"
+	}
+}
+
+// LeaderboardPeriodLabel 期間の表示（例: "直近7日 (2026-03-01〜2026-03-07 JST)"）
+func LeaderboardPeriodLabel(lb activity.Leaderboard) string {
+	switch lb.Period {
+	case activity.LeaderboardAllTime:
+		return fmt.Sprintf("通算 (%s まで JST)", lb.To)
+	case activity.LeaderboardMonth:
+		return fmt.Sprintf("直近30日 (%s〜%s JST)", lb.From, lb.To)
+	default:
+		return fmt.Sprintf("直近7日 (%s〜%s JST)", lb.From, lb.To)
+	}
+}
+
+// BuildLeaderboardPodium リーダーボード上位の表彰台画像（Go フォントで描くため見出しは英語）
+func BuildLeaderboardPodium(lb activity.Leaderboard) (*bytes.Buffer, error) {
+	title := "Activity (restore - vandal)"
+	switch lb.Kind {
+	case activity.LeaderboardVandal:
+		title = "Vandal"
+	case activity.LeaderboardRestore:
+		title = "Restore"
+	}
+	var subtitle string
+	switch lb.Period {
+	case activity.LeaderboardAllTime:
+		subtitle = fmt.Sprintf("All time until %s (JST)", lb.To)
+	case activity.LeaderboardMonth:
+		subtitle = fmt.Sprintf("Last 30 days: %s - %s (JST)", lb.From, lb.To)
+	default:
+		subtitle = fmt.Sprintf("Last 7 days: %s - %s (JST)", lb.From, lb.To)
+	}
+	return BuildLeaderboardPodiumPNG(title, subtitle, PodiumEntriesFromLeaderboard(lb))
+}
+
+// leaderboardComparison 順位変動の比較対象
+func leaderboardComparison(period string) string {
+	switch period {
+	case activity.LeaderboardAllTime:
+		return "7日前時点の通算"
+	case activity.LeaderboardMonth:
+		return "その前の30日"
+	default:
+		return "その前の7日"
+	}
+}
+
+// leaderboardMovement 順位変動の表示（🆕 / ⬆️2 / ⬇️1 / ➖）
+func leaderboardMovement(entry activity.LeaderboardEntry) string {
+	delta, ok := entry.RankChange()
+	switch {
+	case !ok:
+		return "🆕"
+	case delta > 0:
+		return fmt.Sprintf("⬆️%d", delta)
+	case delta < 0:
+		return fmt.Sprintf("⬇️%d", -delta)
+	default:
+		return "➖"
+	}
+}
+
+// FormatLeaderboardLines 「1. ⬆️2 name (同盟) | 12」形式の行
+func FormatLeaderboardLines(entries []activity.LeaderboardEntry) string {
+	if len(entries) == 0 {
+		return "該当なし"
+	}
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		display := utils.FormatUserDisplayName(entry.Name, entry.ID)
+		if entry.Alliance != "" {
+			display = fmt.Sprintf("%s (%s)", display, entry.Alliance)
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s %s | %d", entry.Rank, leaderboardMovement(entry), display, entry.Count))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BuildLeaderboardEmbed /leaderboard の1ページ分。page は 0 始まりで、範囲外は丸めた値を返す
+func BuildLeaderboardEmbed(lb activity.Leaderboard, page, pageSize int) (*discordgo.MessageEmbed, int, int) {
+	total := len(lb.Entries)
+	maxPage := 0
+	if total > 0 {
+		maxPage = (total - 1) / pageSize
+	}
+	page = min(max(page, 0), maxPage)
+	start := page * pageSize
+	end := min(start+pageSize, total)
+
+	return &discordgo.MessageEmbed{
+		Title:       "🏆 リーダーボード: " + LeaderboardKindLabel(lb.Kind),
+		Description: fmt.Sprintf("%s | %d人", LeaderboardPeriodLabel(lb), total),
+		Color:       0xF1C40F,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:  fmt.Sprintf("ページ %d / %d", page+1, maxPage+1),
+				Value: FormatLeaderboardLines(lb.Entries[start:end]),
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("順位変動は%sとの比較 | 🆕 = 新規ランクイン", leaderboardComparison(lb.Period)),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, page, maxPage
+}
+
+// BuildLeaderboardDigestEmbed 定期レポートに添えるリーダーボード（同じ期間の種別ごとの上位）
+func BuildLeaderboardDigestEmbed(boards []activity.Leaderboard) *discordgo.MessageEmbed {
+	if len(boards) == 0 {
+		return nil
+	}
+	fields := make([]*discordgo.MessageEmbedField, 0, len(boards))
+	for _, lb := range boards {
+		entries := lb.Entries[:min(len(lb.Entries), leaderboardDigestLimit)]
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  LeaderboardKindLabel(lb.Kind),
+			Value: FormatLeaderboardLines(entries),
+		})
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "🏆 リーダーボード",
+		Description: LeaderboardPeriodLabel(boards[0]),
+		Color:       0xF1C40F,
+		Fields:      fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("順位変動は%sとの比較 | 🆕 = 新規ランクイン", leaderboardComparison(boards[0].Period)),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
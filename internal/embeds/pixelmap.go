@@ -0,0 +1,89 @@
+package embeds
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	pixelMapWidth     = 800
+	pixelMapMaxHeight = 560
+	pixelMapHeader    = 64
+	pixelMapFooter    = 36
+	// pixelMapMaxScale 1ピクセルを描く最大の大きさ（数ピクセルしか無いときに巨大なマスにしない）
+	pixelMapMaxScale = 24.0
+)
+
+// BuildPixelMapPNG ジャーナルのイベントをピクセルごとの最後の帰属で色分けした地図画像を生成（荒らし=赤、修復=緑）
+func BuildPixelMapPNG(title string, events []activity.JournalEvent) (*bytes.Buffer, error) {
+	type pixel struct{ x, y int }
+	latest := make(map[pixel]string)
+	minX, minY, maxX, maxY := math.MaxInt, math.MaxInt, math.MinInt, math.MinInt
+	for _, ev := range events {
+		latest[pixel{ev.X, ev.Y}] = ev.Kind
+		minX, minY = min(minX, ev.X), min(minY, ev.Y)
+		maxX, maxY = max(maxX, ev.X), max(maxY, ev.Y)
+	}
+
+	mapWidth := pixelMapWidth - 40
+	height := pixelMapHeader + pixelMapFooter + 120
+	scale := 1.0
+	pad := 0
+	if len(latest) > 0 {
+		pad = max(2, (max(maxX-minX, maxY-minY)+1)/20)
+		spanX := float64(maxX - minX + 1 + pad*2)
+		spanY := float64(maxY - minY + 1 + pad*2)
+		scale = min(float64(mapWidth)/spanX, float64(pixelMapMaxHeight)/spanY, pixelMapMaxScale)
+		height = pixelMapHeader + pixelMapFooter + int(math.Ceil(spanY*scale))
+		mapWidth = int(math.Ceil(spanX * scale))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, pixelMapWidth, height))
+	fillCardRect(img, img.Bounds(), cardBackground)
+
+	titleFace := ResolveFontFace(20, basicfont.Face7x13)
+	subFace := ResolveFontFace(13, basicfont.Face7x13)
+	drawFaceText(img, fitText(titleFace, title, pixelMapWidth-40), 20, 30, cardText, titleFace)
+
+	left := (pixelMapWidth - mapWidth) / 2
+	area := image.Rect(left, pixelMapHeader, left+mapWidth, height-pixelMapFooter)
+	fillCardRect(img, area, cardPanel)
+	if len(latest) == 0 {
+		drawCenteredFaceText(img, "No pixels", pixelMapWidth/2, area.Min.Y+area.Dy()/2+5, cardSubText, subFace)
+	} else {
+		drawFaceText(img, fmt.Sprintf("x %d..%d / y %d..%d", minX, maxX, minY, maxY), 20, 52, cardSubText, subFace)
+	}
+
+	vandal, restore := 0, 0
+	size := max(1, int(math.Ceil(scale)))
+	for px, kind := range latest {
+		c := cardVandal
+		if kind == activity.JournalKindRestore {
+			c = cardRestore
+			restore++
+		} else {
+			vandal++
+		}
+		x := area.Min.X + int(float64(px.x-minX+pad)*scale)
+		y := area.Min.Y + int(float64(px.y-minY+pad)*scale)
+		fillCardRect(img, image.Rect(x, y, x+size, y+size), c)
+	}
+
+	legendY := height - pixelMapFooter + 24
+	fillCardRect(img, image.Rect(20, legendY-10, 30, legendY), cardVandal)
+	drawFaceText(img, fmt.Sprintf("vandal %d", vandal), 36, legendY, cardText, subFace)
+	fillCardRect(img, image.Rect(160, legendY-10, 170, legendY), cardRestore)
+	drawFaceText(img, fmt.Sprintf("restore %d", restore), 176, legendY, cardText, subFace)
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
@@ -0,0 +1,38 @@
+package embeds
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestBuildPixelMapUsesLatestKind(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	buf, err := BuildPixelMapPNG("test", []activity.JournalEvent{
+		{X: 100, Y: 200, Kind: activity.JournalKindVandal, At: at},
+		{X: 100, Y: 200, Kind: activity.JournalKindRestore, At: at.Add(time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1ピクセルだけなら最大倍率で中央（pad=2 の位置）に描かれる
+	left := (pixelMapWidth - int(5*pixelMapMaxScale)) / 2
+	x := left + int(2*pixelMapMaxScale) + 1
+	y := pixelMapHeader + int(2*pixelMapMaxScale) + 1
+	want := color.RGBA{cardRestore.R, cardRestore.G, cardRestore.B, 255}
+	if got := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA); got != want {
+		t.Fatalf("pixel = %v, want restore color", got)
+	}
+
+	if _, err := BuildPixelMapPNG("empty", nil); err != nil {
+		t.Fatalf("empty map: %v", err)
+	}
+}
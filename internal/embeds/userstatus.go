@@ -0,0 +1,41 @@
+package embeds
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FormatUserStatus wplace 上の状態（BAN / タイムアウト / 通常）
+func FormatUserStatus(banned, timedOut bool) string {
+	switch {
+	case banned && timedOut:
+		return "⛔ BAN・⏳ タイムアウト"
+	case banned:
+		return "⛔ BAN"
+	case timedOut:
+		return "⏳ タイムアウト"
+	}
+	return "✅ 通常"
+}
+
+// FormatStatusHistory 状態変化の履歴（新しい順に最大 limit 件）
+func FormatStatusHistory(history []activity.StatusChange, loc *time.Location, limit int) string {
+	if len(history) == 0 {
+		return "-"
+	}
+	lines := make([]string, 0, min(len(history), limit))
+	for idx := len(history) - 1; idx >= 0 && len(lines) < limit; idx-- {
+		change := history[idx]
+		at := change.At
+		if parsed, err := time.Parse(time.RFC3339Nano, change.At); err == nil {
+			at = parsed.In(loc).Format("2006-01-02 15:04")
+		}
+		lines = append(lines, fmt.Sprintf("%s → %s", at, FormatUserStatus(change.Banned, change.TimedOut)))
+	}
+	if rest := len(history) - len(lines); rest > 0 {
+		lines = append(lines, fmt.Sprintf("…ほか %d 件", rest))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,255 @@
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/png"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Koukyo_discord_bot/internal/achievements"
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/monitor"
+	"Koukyo_discord_bot/internal/notifications"
+	"Koukyo_discord_bot/internal/utils"
+	"Koukyo_discord_bot/internal/wplace"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func e2eScenario() *Scenario {
+	vandal := &activity.PaintedBy{ID: 101, Name: "vandal"}
+	fixer := &activity.PaintedBy{ID: 202, Name: "fixer", DiscordID: "555"}
+	// 24px（12%）: 小規模差分の上限を超えて通知閾値 10% の Tier に入る
+	pixels := func(p *activity.PaintedBy) []ScriptedPixel {
+		out := make([]ScriptedPixel, 0, 24)
+		for i := 0; i < 24; i++ {
+			out = append(out, ScriptedPixel{X: i % 12, Y: 1 + i/12, Painter: p})
+		}
+		return out
+	}
+	return &Scenario{
+		TileX: 1818, TileY: 806, PixelX: 989, PixelY: 358,
+		Width: 20, Height: 10,
+		IntervalMS: 400,
+		Steps: []ScenarioStep{
+			{Paint: pixels(vandal)},
+			// 通知ループ（1秒ごと）が荒らされた状態を確実に見るまで待つ
+			{Restore: pixels(fixer), DelayMS: 3000},
+		},
+	}
+}
+
+// fakeDiscord Discord REST API の代わりに送信内容を記録する http.RoundTripper
+type fakeDiscord struct {
+	mu       sync.Mutex
+	nextID   int
+	messages map[string][]string // チャンネルID -> 送信した本文（JSON / multipart）
+}
+
+func (f *fakeDiscord) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/v"+discordgo.APIVersion+"/"), "/")
+	channelID := ""
+	if len(parts) >= 2 && parts[0] == "channels" {
+		channelID = parts[1]
+	}
+
+	f.mu.Lock()
+	f.nextID++
+	id := strconv.Itoa(f.nextID)
+	if req.Method == http.MethodPost && len(parts) == 3 && parts[2] == "messages" {
+		f.messages[channelID] = append(f.messages[channelID], string(body))
+	}
+	f.mu.Unlock()
+
+	resp := map[string]any{"id": id, "channel_id": channelID}
+	if strings.HasSuffix(req.URL.Path, "/threads") {
+		resp = map[string]any{"id": "thread-" + id, "parent_id": channelID, "type": discordgo.ChannelTypeGuildPublicThread}
+	}
+	payload, _ := json.Marshal(resp)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(payload)),
+		Request:    req,
+	}, nil
+}
+
+// sent channelID へ送った本文のうち substr を含むものがあるか
+func (f *fakeDiscord) sent(channelID, substr string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, body := range f.messages[channelID] {
+		if strings.Contains(body, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestPipelineEndToEnd 監視WS → Monitor → Tracker（ピクセルAPI）→ Notifier（Discord 送信）→ 実績判定 をローカルで通す
+func TestPipelineEndToEnd(t *testing.T) {
+	sc := e2eScenario()
+	if err := sc.Validate(); err != nil {
+		t.Fatalf("scenario invalid: %v", err)
+	}
+	srv := NewServer("", sc)
+	if err := srv.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	defer srv.Close()
+	utils.SetWplaceBackendURL(srv.URL())
+	defer utils.SetWplaceBackendURL("")
+
+	tracker := activity.NewTracker(activity.Config{
+		TopLeftTileX: sc.TileX, TopLeftTileY: sc.TileY,
+		TopLeftPixelX: sc.PixelX, TopLeftPixelY: sc.PixelY,
+		Width: sc.Width, Height: sc.Height,
+	}, nil, t.TempDir())
+
+	art := config.Artwork{
+		ID: "e2e", TileX: sc.TileX, TileY: sc.TileY, PixelX: sc.PixelX, PixelY: sc.PixelY,
+		Width: sc.Width, Height: sc.Height, WebSocketURL: srv.WebSocketURL(),
+	}
+	mon := monitor.NewArtworkMonitor(art, "")
+	mon.SetActivityTracker(tracker)
+
+	discord := &fakeDiscord{messages: make(map[string][]string)}
+	session, err := discordgo.New("Bot e2e")
+	if err != nil {
+		t.Fatalf("discordgo.New returned error: %v", err)
+	}
+	session.Client = &http.Client{Transport: discord}
+	if err := session.State.GuildAdd(&discordgo.Guild{ID: "g1"}); err != nil {
+		t.Fatalf("GuildAdd returned error: %v", err)
+	}
+	settings := config.NewSettingsManager(filepath.Join(t.TempDir(), "settings.json"))
+	defer settings.Close()
+	notifyChannel, vandalChannel := "c-notify", "c-vandal"
+	settings.UpdateGuildSetting("g1", func(gs *config.GuildSettings) {
+		gs.AutoNotifyEnabled = true
+		gs.NotificationChannel = &notifyChannel
+		gs.NotificationVandalChannel = &vandalChannel
+	})
+	// dataDir を空にして通知状態・インシデントをファイルへ書かない（ループはテスト後も残るため）
+	notifier := notifications.NewNotifier(session, mon, settings, "")
+
+	var mu sync.Mutex
+	notified := make(map[string]activity.UserActivity)
+	tracker.SetNewUserCallback(func(kind string, user activity.UserActivity) {
+		mu.Lock()
+		notified[kind] = user
+		mu.Unlock()
+		notifier.NotifyNewUser(kind, user)
+	})
+	notifier.StartArtworkMonitoring()
+	tracker.Start()
+	defer tracker.Stop()
+	if err := mon.Start(); err != nil {
+		t.Fatalf("monitor Start returned error: %v", err)
+	}
+	defer mon.Stop()
+
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		mu.Lock()
+		_, gotVandal := notified["vandal"]
+		_, gotFix := notified["fix"]
+		mu.Unlock()
+		tierSent := discord.sent(notifyChannel, "Wplace 荒らし検知")
+		completedSent := discord.sent(notifyChannel, "Wplace 修復完了")
+		vandalSent := discord.sent(vandalChannel, "新規荒らしユーザー検知")
+		if gotVandal && gotFix && tierSent && completedSent && vandalSent {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out: tracker=%+v tier=%v completed=%v new_vandal=%v", notified, tierSent, completedSent, vandalSent)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if got := mon.ActiveSourceName(); got != "websocket" {
+		t.Fatalf("expected websocket source, got %q", got)
+	}
+	if data := mon.GetLatestData(); data == nil || data.DiffPixels != 0 || data.TotalPixels != sc.Width*sc.Height {
+		t.Fatalf("unexpected final monitor data: %+v", data)
+	}
+
+	mu.Lock()
+	vandal, fixer := notified["vandal"], notified["fix"]
+	mu.Unlock()
+	if vandal.ID != "101" || vandal.VandalCount != 24 {
+		t.Fatalf("unexpected vandal activity: %+v", vandal)
+	}
+	if fixer.ID != "202" || fixer.RestoredCount != 24 || fixer.DiscordID != "555" {
+		t.Fatalf("unexpected fixer activity: %+v", fixer)
+	}
+
+	five := 5
+	rules := &achievements.RuleSet{Version: 1, Rules: []achievements.Rule{
+		{ID: "restore_5", Name: "Restore 5", Conditions: achievements.RuleConditions{RestoredCountGTE: &five}},
+	}}
+	awards := achievements.Evaluate(achievements.UserSnapshot{
+		WplaceID:      fixer.ID,
+		RestoredCount: fixer.RestoredCount,
+		ActivityScore: fixer.ActivityScore,
+	}, rules)
+	if len(awards) != 1 || awards[0].ID != "restore_5" {
+		t.Fatalf("unexpected achievements: %+v", awards)
+	}
+}
+
+func TestTileAndHealthEndpoints(t *testing.T) {
+	tiles := t.TempDir()
+	srv := NewServer(tiles, nil)
+	if err := srv.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	defer srv.Close()
+	utils.SetWplaceBackendURL(srv.URL())
+	defer utils.SetWplaceBackendURL("")
+
+	srv.SetTile(1818, 806, []byte("custom-tile"))
+	data, err := wplace.DownloadTileNoCache(context.Background(), nil, 1818, 806)
+	if err != nil || string(data) != "custom-tile" {
+		t.Fatalf("unexpected tile: %q err=%v", data, err)
+	}
+	blank, err := wplace.DownloadTileNoCache(context.Background(), nil, 1, 2)
+	if err != nil {
+		t.Fatalf("blank tile download failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(blank))
+	if err != nil || img.Bounds().Dx() != utils.WplaceTileSize {
+		t.Fatalf("unexpected blank tile: err=%v", err)
+	}
+
+	srv.SetHealth(true, false)
+	resp, err := http.Get(srv.URL() + "/health")
+	if err != nil {
+		t.Fatalf("health request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var health struct {
+		Up       bool `json:"up"`
+		Database bool `json:"database"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	if !health.Up || health.Database {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+}
@@ -0,0 +1,108 @@
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/utils"
+)
+
+// defaultStepInterval シナリオで間隔を省略したときのステップ間隔
+const defaultStepInterval = 2 * time.Second
+
+// Scenario 監視 WebSocket が配信する差分シナリオとピクセル API の応答表
+type Scenario struct {
+	// 監視領域（config.Artwork と同じ意味）。差分画像はこのサイズで生成する。
+	TileX  int `json:"tile_x"`
+	TileY  int `json:"tile_y"`
+	PixelX int `json:"pixel_x"`
+	PixelY int `json:"pixel_y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	IntervalMS int  `json:"interval_ms,omitempty"` // ステップ間隔の既定値
+	Loop       bool `json:"loop,omitempty"`        // 最後まで配信したら差分を空に戻して繰り返す
+
+	// Painters 起動時点のピクセル→塗った人の対応（座標は監視領域の左上からの相対）
+	Painters []ScriptedPixel `json:"painters,omitempty"`
+	// DefaultPainter 対応表に無いピクセルへの応答（nil なら未塗装扱い）
+	DefaultPainter *activity.PaintedBy `json:"default_painter,omitempty"`
+
+	Steps []ScenarioStep `json:"steps"`
+}
+
+// ScenarioStep 1回分の差分変化。Paint は差分に加え、Restore は差分から除く。
+// 指定した painter はそのピクセルのピクセル API 応答に反映される。
+type ScenarioStep struct {
+	DelayMS int             `json:"delay_ms,omitempty"`
+	Paint   []ScriptedPixel `json:"paint,omitempty"`
+	Restore []ScriptedPixel `json:"restore,omitempty"`
+}
+
+// ScriptedPixel 監視領域の左上からの相対座標と、そのピクセルを塗った人
+type ScriptedPixel struct {
+	X       int                 `json:"x"`
+	Y       int                 `json:"y"`
+	Painter *activity.PaintedBy `json:"painter,omitempty"`
+}
+
+// LoadScenario JSON のシナリオファイルを読み込む
+func LoadScenario(path string) (*Scenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sc Scenario
+	if err := json.Unmarshal(raw, &sc); err != nil {
+		return nil, fmt.Errorf("parse scenario %s: %w", path, err)
+	}
+	if err := sc.Validate(); err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	return &sc, nil
+}
+
+// Validate 監視領域と各ピクセル座標を検証する
+func (sc *Scenario) Validate() error {
+	if sc.Width <= 0 || sc.Height <= 0 {
+		return fmt.Errorf("width/height must be positive")
+	}
+	check := func(where string, pixels []ScriptedPixel) error {
+		for _, px := range pixels {
+			if px.X < 0 || px.Y < 0 || px.X >= sc.Width || px.Y >= sc.Height {
+				return fmt.Errorf("%s: pixel (%d,%d) is outside %dx%d", where, px.X, px.Y, sc.Width, sc.Height)
+			}
+		}
+		return nil
+	}
+	if err := check("painters", sc.Painters); err != nil {
+		return err
+	}
+	for i, step := range sc.Steps {
+		if err := check(fmt.Sprintf("steps[%d].paint", i), step.Paint); err != nil {
+			return err
+		}
+		if err := check(fmt.Sprintf("steps[%d].restore", i), step.Restore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sc *Scenario) stepDelay(step ScenarioStep) time.Duration {
+	if step.DelayMS > 0 {
+		return time.Duration(step.DelayMS) * time.Millisecond
+	}
+	if sc.IntervalMS > 0 {
+		return time.Duration(sc.IntervalMS) * time.Millisecond
+	}
+	return defaultStepInterval
+}
+
+// absolute 相対座標を wplace の絶対ピクセル座標へ変換する
+func (sc *Scenario) absolute(px ScriptedPixel) (int, int) {
+	return sc.TileX*utils.WplaceTileSize + sc.PixelX + px.X, sc.TileY*utils.WplaceTileSize + sc.PixelY + px.Y
+}
@@ -0,0 +1,374 @@
+// Package emulator ネットワークの無い環境で監視パイプライン全体を動かすための
+// wplace バックエンドと監視 WebSocket のローカル代替サーバー。
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+// frameTypeDiff 監視 WebSocket の差分画像フレーム種別（monitor.handleBinaryMessage と同じ）
+const frameTypeDiff = 3
+
+// Server wplace バックエンド（タイル / ピクセル / ヘルス）と監視 WebSocket を模したHTTPサーバー。
+//
+//	GET /tile/{x}/{y}.png, /files/s0/tiles/{x}/{y}.png  tilesDir/{x}/{y}.png（無ければ透明タイル）
+//	GET /s0/pixel/{tileX}/{tileY}?x=&y=                 シナリオの塗った人の対応表
+//	GET /health                                         SetHealth で切り替え
+//	GET /ws                                             シナリオの差分を接続ごとに先頭から配信
+type Server struct {
+	tilesDir string
+	scenario *Scenario
+
+	mu         sync.RWMutex
+	painters   map[string]activity.PaintedBy
+	tiles      map[string][]byte
+	healthUp   bool
+	healthDB   bool
+	startedAt  time.Time
+	blankTile  []byte
+	listener   net.Listener
+	httpServer *http.Server
+
+	upgrader websocket.Upgrader
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewServer tilesDir（空なら常に透明タイル）とシナリオ（nil なら差分配信なし）からサーバーを作成する
+func NewServer(tilesDir string, scenario *Scenario) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		tilesDir:  tilesDir,
+		scenario:  scenario,
+		painters:  make(map[string]activity.PaintedBy),
+		tiles:     make(map[string][]byte),
+		healthUp:  true,
+		healthDB:  true,
+		startedAt: time.Now(),
+		upgrader:  websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	if scenario != nil {
+		for _, px := range scenario.Painters {
+			if px.Painter == nil {
+				continue
+			}
+			x, y := scenario.absolute(px)
+			s.painters[painterKey(x, y)] = *px.Painter
+		}
+	}
+	return s
+}
+
+// Handler ルーティング済みの http.Handler（httptest などへ直接渡す用）
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tile/{x}/{file}", s.handleTile)
+	mux.HandleFunc("GET /files/s0/tiles/{x}/{file}", s.handleTile)
+	mux.HandleFunc("GET /s0/pixel/{tileX}/{tileY}", s.handlePixel)
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /ws", s.handleWebSocket)
+	return mux
+}
+
+// Start addr（例: 127.0.0.1:0）で待ち受けを開始する
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	s.mu.Lock()
+	s.listener = ln
+	s.httpServer = srv
+	s.mu.Unlock()
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("emulator: serve failed: %v", err)
+		}
+	}()
+	log.Printf("emulator: listening on %s", ln.Addr())
+	return nil
+}
+
+// URL バックエンドのベースURL（utils.SetWplaceBackendURL / WPLACE_BACKEND_URL に渡す）
+func (s *Server) URL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.listener == nil {
+		return ""
+	}
+	return "http://" + s.listener.Addr().String()
+}
+
+// WebSocketURL 監視 WebSocket のURL（config.Artwork.WebSocketURL / WEBSOCKET_URL に渡す）
+func (s *Server) WebSocketURL() string {
+	base := s.URL()
+	if base == "" {
+		return ""
+	}
+	return "ws" + strings.TrimPrefix(base, "http") + "/ws"
+}
+
+// Close 待ち受けと配信中の WebSocket を停止する
+func (s *Server) Close() error {
+	s.cancel()
+	s.mu.RLock()
+	srv := s.httpServer
+	s.mu.RUnlock()
+	if srv == nil {
+		return nil
+	}
+	return srv.Close()
+}
+
+// SetTile タイル画像を差し替える（tilesDir より優先）
+func (s *Server) SetTile(tileX, tileY int, data []byte) {
+	s.mu.Lock()
+	s.tiles[tileKey(tileX, tileY)] = append([]byte(nil), data...)
+	s.mu.Unlock()
+}
+
+// SetPainter 絶対ピクセル座標のピクセル API 応答を設定する
+func (s *Server) SetPainter(absX, absY int, painter activity.PaintedBy) {
+	s.mu.Lock()
+	s.painters[painterKey(absX, absY)] = painter
+	s.mu.Unlock()
+}
+
+// SetHealth /health の応答（up / database）を切り替える
+func (s *Server) SetHealth(up, database bool) {
+	s.mu.Lock()
+	s.healthUp = up
+	s.healthDB = database
+	s.mu.Unlock()
+}
+
+func (s *Server) handleTile(w http.ResponseWriter, r *http.Request) {
+	tileX, errX := strconv.Atoi(r.PathValue("x"))
+	tileY, errY := strconv.Atoi(strings.TrimSuffix(r.PathValue("file"), ".png"))
+	if errX != nil || errY != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.RLock()
+	data := s.tiles[tileKey(tileX, tileY)]
+	s.mu.RUnlock()
+	if data == nil && s.tilesDir != "" {
+		if raw, err := os.ReadFile(filepath.Join(s.tilesDir, strconv.Itoa(tileX), strconv.Itoa(tileY)+".png")); err == nil {
+			data = raw
+		}
+	}
+	if data == nil {
+		var err error
+		if data, err = s.blankTilePNG(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data) //nolint:errcheck
+}
+
+func (s *Server) blankTilePNG() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.blankTile != nil {
+		return s.blankTile, nil
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, utils.WplaceTileSize, utils.WplaceTileSize))); err != nil {
+		return nil, err
+	}
+	s.blankTile = buf.Bytes()
+	return s.blankTile, nil
+}
+
+func (s *Server) handlePixel(w http.ResponseWriter, r *http.Request) {
+	tileX, err1 := strconv.Atoi(r.PathValue("tileX"))
+	tileY, err2 := strconv.Atoi(r.PathValue("tileY"))
+	pixelX, err3 := strconv.Atoi(r.URL.Query().Get("x"))
+	pixelY, err4 := strconv.Atoi(r.URL.Query().Get("y"))
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		http.Error(w, "invalid pixel coordinate", http.StatusBadRequest)
+		return
+	}
+	absX := tileX*utils.WplaceTileSize + pixelX
+	absY := tileY*utils.WplaceTileSize + pixelY
+
+	s.mu.RLock()
+	painter, ok := s.painters[painterKey(absX, absY)]
+	s.mu.RUnlock()
+	if !ok && s.scenario != nil && s.scenario.DefaultPainter != nil {
+		painter, ok = *s.scenario.DefaultPainter, true
+	}
+	resp := activity.PixelAPIResponse{}
+	if ok {
+		resp.PaintedBy = &painter
+	} else {
+		resp.PaintedBy = &activity.PaintedBy{}
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	up, db, startedAt := s.healthUp, s.healthDB, s.startedAt
+	s.mu.RUnlock()
+	writeJSON(w, map[string]interface{}{
+		"up":       up,
+		"database": db,
+		"uptime":   time.Since(startedAt).Round(time.Second).String(),
+	})
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("emulator: websocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	// クライアントからの keepalive を読み捨てつつ切断を検知する
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if s.scenario == nil {
+		<-ctx.Done()
+		return
+	}
+	if err := s.playScenario(ctx, conn); err != nil && ctx.Err() == nil {
+		log.Printf("emulator: scenario playback stopped: %v", err)
+	}
+}
+
+// playScenario 差分の初期状態（空）を送り、ステップごとに差分データと差分画像を送る
+func (s *Server) playScenario(ctx context.Context, conn *websocket.Conn) error {
+	sc := s.scenario
+	diff := make(map[[2]int]bool)
+	if err := s.sendDiff(conn, diff); err != nil {
+		return err
+	}
+	for {
+		for _, step := range sc.Steps {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(sc.stepDelay(step)):
+			}
+			s.applyStep(step, diff)
+			if err := s.sendDiff(conn, diff); err != nil {
+				return err
+			}
+		}
+		if !sc.Loop {
+			<-ctx.Done()
+			return nil
+		}
+		diff = make(map[[2]int]bool)
+	}
+}
+
+func (s *Server) applyStep(step ScenarioStep, diff map[[2]int]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, px := range step.Paint {
+		diff[[2]int{px.X, px.Y}] = true
+		if px.Painter != nil {
+			x, y := s.scenario.absolute(px)
+			s.painters[painterKey(x, y)] = *px.Painter
+		}
+	}
+	for _, px := range step.Restore {
+		delete(diff, [2]int{px.X, px.Y})
+		if px.Painter != nil {
+			x, y := s.scenario.absolute(px)
+			s.painters[painterKey(x, y)] = *px.Painter
+		}
+	}
+}
+
+func (s *Server) sendDiff(conn *websocket.Conn, diff map[[2]int]bool) error {
+	sc := s.scenario
+	total := sc.Width * sc.Height
+	percentage := float64(len(diff)) * 100 / float64(total)
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":            "update",
+		"diff_percentage": percentage,
+		"diff_pixels":     len(diff),
+		"total_pixels":    total,
+	})
+	if err != nil {
+		return err
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+		return err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, sc.Width, sc.Height))
+	for p := range diff {
+		img.SetNRGBA(p[0], p[1], color.NRGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.BinaryMessage, binaryFrame(frameTypeDiff, buf.Bytes()))
+}
+
+// binaryFrame 5バイトヘッダー（type_id + リトルエンディアンのペイロード長）を付ける
+func binaryFrame(typeID byte, payload []byte) []byte {
+	frame := make([]byte, 5+len(payload))
+	frame[0] = typeID
+	binary.LittleEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+	return frame
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("emulator: write response failed: %v", err)
+	}
+}
+
+func painterKey(absX, absY int) string {
+	return fmt.Sprintf("%d,%d", absX, absY)
+}
+
+func tileKey(tileX, tileY int) string {
+	return fmt.Sprintf("%d-%d", tileX, tileY)
+}
@@ -16,6 +16,7 @@ type Handler struct {
 	prefix   string
 	botInfo  *models.BotInfo
 	monitor  *monitor.Monitor
+	monitors *monitor.Set
 	settings *config.SettingsManager
 	notifier *notifications.Notifier
 	limiter  *utils.RateLimiter // これを追加
@@ -26,8 +27,9 @@ type Handler struct {
 	lastReadyAt      time.Time
 }
 
-func NewHandler(prefix string, botInfo *models.BotInfo, mon *monitor.Monitor, settingsManager *config.SettingsManager, notifier *notifications.Notifier, limiter *utils.RateLimiter, activityLimiter *utils.RateLimiter, dataDir string) *Handler { // limiter 引数を追加
+func NewHandler(prefix string, botInfo *models.BotInfo, monitors *monitor.Set, settingsManager *config.SettingsManager, notifier *notifications.Notifier, limiter *utils.RateLimiter, activityLimiter *utils.RateLimiter, dataDir string) *Handler { // limiter 引数を追加
 	registry := commands.NewRegistry()
+	mon := monitors.Primary()
 
 	// すべてのコマンドを配列で一元管理
 	var commandsList []commands.Command
@@ -36,31 +38,37 @@ func NewHandler(prefix string, botInfo *models.BotInfo, mon *monitor.Monitor, se
 		commands.NewInfoCommand(botInfo),
 		commands.NewExplanationCommand(),
 		commands.NewStatusCommand(botInfo, notifier),
-		commands.NewNowCommand(mon),
+		commands.NewNowCommand(monitors, settingsManager),
 		commands.NewTimeCommand(),
 		commands.NewConvertCommand(),
 		commands.NewProxyCommand(),
 		commands.NewProxyDeleteCommand(),
-		commands.NewMeCommand(dataDir, activityLimiter),
-		commands.NewAchievementsCommand(dataDir),
+		commands.NewMeCommand(dataDir, activityLimiter, settingsManager),
+		commands.NewAchievementsCommand(dataDir, settingsManager),
 		commands.NewSettingsCommand(settingsManager, notifier), // settingsManager を渡す
 		commands.NewNotificationCommand(settingsManager),
 		commands.NewProgressChannelCommand(settingsManager),
 		commands.NewAchievementChannelCommand(settingsManager),
+		commands.NewModerationChannelCommand(settingsManager),
 		commands.NewDMCommand(settingsManager),
 		commands.NewGetCommand(limiter), // limiter を渡すように変更
 		commands.NewPaintCommand(notifier),
+		commands.NewOutboxCommand(notifier),
 		commands.NewRegionMapCommand(),
-		commands.NewUserActivityCommand(dataDir),
-		commands.NewFixUserCommand(dataDir),
-		commands.NewGrfUserCommand(dataDir),
+		commands.NewUserActivityCommand(dataDir, settingsManager),
+		commands.NewFixUserCommand(dataDir, settingsManager),
+		commands.NewGrfUserCommand(dataDir, settingsManager),
+		commands.NewLeaderboardCommand(dataDir),
+		commands.NewAllianceCommand(dataDir, settingsManager),
 	)
 	if mon != nil {
 		commandsList = append(commandsList,
-			commands.NewGraphCommand(mon, dataDir),
-			commands.NewPredictCommand(mon),
-			commands.NewTimelapseCommand(mon),
+			commands.NewGraphCommand(monitors, settingsManager, dataDir),
+			commands.NewPredictCommand(monitors, settingsManager),
+			commands.NewTimelapseCommand(monitors, settingsManager),
 			commands.NewHeatmapCommand(mon),
+			commands.NewIncidentsCommand(monitors, settingsManager, dataDir),
+			commands.NewPixelHistoryCommand(monitors, settingsManager, dataDir),
 		)
 	}
 	// HelpCommandは最後に追加し、registryを渡す
@@ -77,6 +85,7 @@ func NewHandler(prefix string, botInfo *models.BotInfo, mon *monitor.Monitor, se
 		prefix:   prefix,
 		botInfo:  botInfo,
 		monitor:  mon,
+		monitors: monitors,
 		settings: settingsManager, // settingsManager を使用
 		notifier: notifier,
 		limiter:  limiter, // これを追加
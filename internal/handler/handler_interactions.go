@@ -75,19 +75,43 @@ func (h *Handler) handleMessageComponent(s *discordgo.Session, i *discordgo.Inte
 		{
 			match: func(id string) bool { return strings.HasPrefix(id, "userlist:") },
 			handle: func() {
-				commands.HandleUserListPagination(s, i, h.dataDir)
+				commands.HandleUserListPagination(s, i, h.dataDir, h.settings.GuildLocation(i.GuildID))
+			},
+		},
+		{
+			match: func(id string) bool { return strings.HasPrefix(id, "leaderboard:") },
+			handle: func() {
+				commands.HandleLeaderboardPagination(s, i, h.dataDir)
 			},
 		},
 		{
 			match: func(id string) bool { return strings.HasPrefix(id, "useractivity:") },
 			handle: func() {
-				commands.HandleUserActivityPagination(s, i, h.dataDir)
+				commands.HandleUserActivityPagination(s, i, h.dataDir, h.settings.GuildLocation(i.GuildID))
 			},
 		},
 		{
 			match: func(id string) bool { return id == "useractivity_select" },
 			handle: func() {
-				commands.HandleUserActivitySelect(s, i, h.dataDir)
+				commands.HandleUserActivitySelect(s, i, h.dataDir, h.settings.GuildLocation(i.GuildID))
+			},
+		},
+		{
+			match: func(id string) bool { return strings.HasPrefix(id, "alliance_list:") },
+			handle: func() {
+				commands.HandleAllianceListPagination(s, i, h.dataDir)
+			},
+		},
+		{
+			match: func(id string) bool { return strings.HasPrefix(id, "alliance:") },
+			handle: func() {
+				commands.HandleAlliancePagination(s, i, h.dataDir, h.settings.GuildLocation(i.GuildID))
+			},
+		},
+		{
+			match: func(id string) bool { return id == "alliance_select" },
+			handle: func() {
+				commands.HandleAllianceSelect(s, i, h.dataDir, h.settings.GuildLocation(i.GuildID))
 			},
 		},
 		{
@@ -108,6 +132,12 @@ func (h *Handler) handleMessageComponent(s *discordgo.Session, i *discordgo.Inte
 				commands.HandleRegionMapConfirm(s, i, h.limiter)
 			},
 		},
+		{
+			match: func(id string) bool { return strings.HasPrefix(id, "botreview:") },
+			handle: func() {
+				commands.HandleBotReviewButton(s, i, h.monitors, h.settings.GuildLocation(i.GuildID))
+			},
+		},
 		{
 			match: func(id string) bool { return strings.HasPrefix(id, "explanation_page:") },
 			handle: func() {
@@ -72,7 +72,7 @@ func (h *Handler) SendStartupNotification(s *discordgo.Session) {
 
 		// 現在の監視情報を送信（データがある場合）
 		if h.monitor != nil && h.monitor.State.HasData() {
-			nowEmbed := embeds.BuildNowEmbed(h.monitor)
+			nowEmbed := embeds.BuildNowEmbed(h.monitor, h.settings.GuildLocation(guildID))
 			images := h.monitor.GetLatestImages()
 			if images != nil && len(images.LiveImage) > 0 && len(images.DiffImage) > 0 {
 				combinedImage, err2 := embeds.CombineImages(images.LiveImage, images.DiffImage)
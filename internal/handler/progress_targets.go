@@ -6,5 +6,5 @@ func (h *Handler) handleProgressTargetManual(s *discordgo.Session, m *discordgo.
 	if h.notifier == nil {
 		return false
 	}
-	return h.notifier.HandleProgressTargetManual(m.ChannelID, targetID)
+	return h.notifier.HandleProgressTargetManual(m.GuildID, m.ChannelID, targetID)
 }
@@ -8,5 +8,5 @@ func (h *Handler) handleWatchTargetManual(s *discordgo.Session, m *discordgo.Mes
 	if h.notifier == nil {
 		return false
 	}
-	return h.notifier.HandleWatchTargetManual(m.ChannelID, targetID)
+	return h.notifier.HandleWatchTargetManual(m.GuildID, m.ChannelID, targetID)
 }
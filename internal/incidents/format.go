@@ -0,0 +1,60 @@
+package incidents
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"Koukyo_discord_bot/internal/utils"
+)
+
+// FormatDuration 継続時間を「44分」「1時間3分」形式で表す
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%d秒", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%d分", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%d時間%d分", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// SummaryLine 一覧用の1行表示（#12 21:03–21:47 (44分) ピーク 12.30%）
+func SummaryLine(inc *Incident, now time.Time, loc *time.Location) string {
+	end := "進行中"
+	if !inc.Open() {
+		end = inc.EndedAt.In(loc).Format("15:04")
+	}
+	line := fmt.Sprintf("#%d %s–%s (%s) ピーク %.2f%%",
+		inc.ID,
+		inc.StartedAt.In(loc).Format("01/02 15:04"),
+		end,
+		FormatDuration(inc.Duration(now)),
+		inc.PeakPercentage,
+	)
+	if len(inc.Vandals) > 0 || len(inc.Fixers) > 0 {
+		line += fmt.Sprintf(" | 荒らし %d人 / 修復 %d人", len(inc.Vandals), len(inc.Fixers))
+	}
+	return line
+}
+
+// ParticipantLines 上位 limit 名を「名前#ID (同盟) | Npx」で並べる
+func ParticipantLines(list []Participant, limit int) string {
+	if len(list) == 0 {
+		return "なし"
+	}
+	lines := make([]string, 0, min(limit, len(list))+1)
+	for i, p := range list {
+		if i >= limit {
+			lines = append(lines, fmt.Sprintf("…他 %d人", len(list)-limit))
+			break
+		}
+		display := utils.FormatUserDisplayName(p.Name, p.ID)
+		if p.Alliance != "" {
+			display = fmt.Sprintf("%s (%s)", display, p.Alliance)
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s | %dpx", i+1, display, p.Pixels))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,114 @@
+// Package incidents 差分が 0% から離れて戻るまでを1件のインシデントとして記録する。
+package incidents
+
+import (
+	"sort"
+	"time"
+
+	"Koukyo_discord_bot/internal/activity"
+)
+
+// Participant インシデント期間中に活動したユーザー
+type Participant struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Alliance string `json:"alliance,omitempty"`
+	Pixels   int    `json:"pixels"`
+}
+
+// Incident 差分発生から 0% 復帰までの1エピソード
+type Incident struct {
+	ID              int           `json:"id"`
+	Artwork         string        `json:"artwork,omitempty"`
+	StartedAt       time.Time     `json:"started_at"`
+	EndedAt         time.Time     `json:"ended_at,omitzero"`
+	PeakPercentage  float64       `json:"peak_percentage"`
+	PeakWeighted    *float64      `json:"peak_weighted,omitempty"`
+	PeakDiffPixels  int           `json:"peak_diff_pixels"`
+	PeakAt          time.Time     `json:"peak_at"`
+	TotalPixels     int           `json:"total_pixels"`
+	PeakLiveImage   string        `json:"peak_live_image,omitempty"`
+	PeakDiffImage   string        `json:"peak_diff_image,omitempty"`
+	Vandals         []Participant `json:"vandals,omitempty"`
+	Fixers          []Participant `json:"fixers,omitempty"`
+	ObservedSamples int           `json:"observed_samples"`
+}
+
+// Open 進行中か
+func (inc *Incident) Open() bool {
+	return inc.EndedAt.IsZero()
+}
+
+// Duration 継続時間（進行中は now までの経過）
+func (inc *Incident) Duration(now time.Time) time.Duration {
+	end := inc.EndedAt
+	if end.IsZero() {
+		end = now
+	}
+	if end.Before(inc.StartedAt) {
+		return 0
+	}
+	return end.Sub(inc.StartedAt)
+}
+
+// TimeToRecovery ピークから 0% 復帰までの時間（進行中は 0）
+func (inc *Incident) TimeToRecovery() time.Duration {
+	if inc.EndedAt.IsZero() || inc.PeakAt.IsZero() || inc.EndedAt.Before(inc.PeakAt) {
+		return 0
+	}
+	return inc.EndedAt.Sub(inc.PeakAt)
+}
+
+// VandalPixels 荒らしピクセル合計
+func (inc *Incident) VandalPixels() int {
+	return sumPixels(inc.Vandals)
+}
+
+// FixerPixels 修復ピクセル合計
+func (inc *Incident) FixerPixels() int {
+	return sumPixels(inc.Fixers)
+}
+
+func (inc *Incident) clone() *Incident {
+	cp := *inc
+	if inc.PeakWeighted != nil {
+		w := *inc.PeakWeighted
+		cp.PeakWeighted = &w
+	}
+	cp.Vandals = append([]Participant(nil), inc.Vandals...)
+	cp.Fixers = append([]Participant(nil), inc.Fixers...)
+	return &cp
+}
+
+func sumPixels(list []Participant) int {
+	total := 0
+	for _, p := range list {
+		total += p.Pixels
+	}
+	return total
+}
+
+// participantsSince baseline からの増分で荒らし/修復参加者を求める（ピクセル数の多い順）
+func participantsSince(baseline, current map[string]activity.ActivityCount) (vandals, fixers []Participant) {
+	for id, cur := range current {
+		base := baseline[id]
+		if d := cur.Vandal - base.Vandal; d > 0 {
+			vandals = append(vandals, Participant{ID: id, Name: cur.Name, Alliance: cur.Alliance, Pixels: d})
+		}
+		if d := cur.Restored - base.Restored; d > 0 {
+			fixers = append(fixers, Participant{ID: id, Name: cur.Name, Alliance: cur.Alliance, Pixels: d})
+		}
+	}
+	sortParticipants(vandals)
+	sortParticipants(fixers)
+	return vandals, fixers
+}
+
+func sortParticipants(list []Participant) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Pixels != list[j].Pixels {
+			return list[i].Pixels > list[j].Pixels
+		}
+		return list[i].ID < list[j].ID
+	})
+}
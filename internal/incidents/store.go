@@ -0,0 +1,300 @@
+package incidents
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/utils"
+)
+
+const (
+	// FileName インシデント履歴ファイル名（アートワークのデータディレクトリ直下）
+	FileName = "incidents.json"
+	// ImageDirName ピーク画像の保存ディレクトリ名
+	ImageDirName = "incidents"
+	// maxClosedIncidents 保持する終了済みインシデント数（超過分は画像ごと削除）
+	maxClosedIncidents = 500
+	// participantRefreshInterval 進行中インシデントの参加者更新・保存間隔
+	participantRefreshInterval = 30 * time.Second
+	// zeroEpsilon 0%判定の許容幅（通知と同じ）
+	zeroEpsilon = 0.005
+)
+
+// Observation 監視ループ1回分の観測値
+type Observation struct {
+	Artwork     string
+	Percentage  float64
+	Weighted    *float64
+	DiffPixels  int
+	TotalPixels int
+	LiveImage   []byte
+	DiffImage   []byte
+}
+
+// ActivitySource ユーザー別の累計荒らし/修復数を返す（Monitor.GetActivityCounts）
+type ActivitySource func() map[string]activity.ActivityCount
+
+type storeFile struct {
+	NextID    int                               `json:"next_id"`
+	Open      *Incident                         `json:"open,omitempty"`
+	Baseline  map[string]activity.ActivityCount `json:"baseline,omitempty"`
+	Incidents []*Incident                       `json:"incidents"`
+}
+
+// Store インシデントの記録と永続化
+type Store struct {
+	dir      string
+	activity ActivitySource
+
+	mu          sync.Mutex
+	nextID      int
+	open        *Incident
+	baseline    map[string]activity.ActivityCount
+	closed      []*Incident // 古い順
+	lastRefresh time.Time
+}
+
+// NewStore dir の incidents.json から復元した Store を作成する（dir が空ならメモリのみ）
+func NewStore(dir string, source ActivitySource) *Store {
+	s := &Store{dir: dir, activity: source, nextID: 1}
+	if dir == "" {
+		return s
+	}
+	data, err := load(dir)
+	if err != nil {
+		log.Printf("incidents: load failed dir=%s err=%v", dir, err)
+		return s
+	}
+	s.nextID = max(data.NextID, 1)
+	s.open = data.Open
+	s.baseline = data.Baseline
+	s.closed = data.Incidents
+	return s
+}
+
+// Load dir の履歴を読み取り専用で読み込む（新しい順、進行中があれば先頭）
+func Load(dir string) ([]*Incident, error) {
+	data, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Incident, 0, len(data.Incidents)+1)
+	if data.Open != nil {
+		out = append(out, data.Open)
+	}
+	for i := len(data.Incidents) - 1; i >= 0; i-- {
+		out = append(out, data.Incidents[i])
+	}
+	return out, nil
+}
+
+func load(dir string) (*storeFile, error) {
+	var data storeFile
+	_, err := utils.ReadJSONFileWithBackup(filepath.Join(dir, FileName), &data)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &storeFile{NextID: 1}, nil
+		}
+		return nil, err
+	}
+	return &data, nil
+}
+
+// ImagePath ピーク画像ファイル名をフルパスに変換する
+func ImagePath(dir, name string) string {
+	if name == "" {
+		return ""
+	}
+	return filepath.Join(dir, ImageDirName, filepath.Base(name))
+}
+
+// Observe 観測値を反映する。0%から離れたら開始、0%に戻ったら終了したインシデントを返す。
+func (s *Store) Observe(now time.Time, obs Observation) (opened, closed *Incident) {
+	if s == nil {
+		return nil, nil
+	}
+	isZero := obs.DiffPixels == 0 || obs.Percentage < zeroEpsilon
+
+	s.mu.Lock()
+	switch {
+	case s.open == nil && !isZero:
+		s.open = &Incident{ID: s.nextID, Artwork: obs.Artwork, StartedAt: now}
+		s.nextID++
+		s.baseline = s.snapshotActivity()
+		s.updatePeakLocked(now, obs)
+		s.lastRefresh = now
+		opened = s.open.clone()
+		s.saveLocked()
+	case s.open != nil && isZero:
+		inc := s.open
+		inc.EndedAt = now
+		inc.ObservedSamples++
+		s.refreshParticipantsLocked()
+		s.closed = append(s.closed, inc)
+		s.pruneLocked()
+		s.open, s.baseline = nil, nil
+		closed = inc.clone()
+		s.saveLocked()
+	case s.open != nil:
+		peakChanged := s.updatePeakLocked(now, obs)
+		refresh := now.Sub(s.lastRefresh) >= participantRefreshInterval
+		if refresh {
+			s.lastRefresh = now
+			s.refreshParticipantsLocked()
+		}
+		// ピークが変わったら画像と一緒に保存し、再起動してもピーク画像を失わない
+		if refresh || peakChanged {
+			s.saveLocked()
+		}
+	}
+	s.mu.Unlock()
+	return opened, closed
+}
+
+// Current 進行中のインシデント（無ければ nil）
+func (s *Store) Current() *Incident {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.open == nil {
+		return nil
+	}
+	return s.open.clone()
+}
+
+// LastClosed 最後に終了したインシデント（無ければ nil）
+func (s *Store) LastClosed() *Incident {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.closed) == 0 {
+		return nil
+	}
+	return s.closed[len(s.closed)-1].clone()
+}
+
+// StartedBetween [from, to) に開始したインシデント（進行中を含む、古い順）
+func (s *Store) StartedBetween(from, to time.Time) []*Incident {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*Incident
+	for _, inc := range s.closed {
+		if !inc.StartedAt.Before(from) && inc.StartedAt.Before(to) {
+			out = append(out, inc.clone())
+		}
+	}
+	if s.open != nil && !s.open.StartedAt.Before(from) && s.open.StartedAt.Before(to) {
+		out = append(out, s.open.clone())
+	}
+	return out
+}
+
+// updatePeakLocked 観測値を進行中インシデントに反映し、ピークが更新されたら true を返す
+func (s *Store) updatePeakLocked(now time.Time, obs Observation) bool {
+	inc := s.open
+	inc.ObservedSamples++
+	if obs.TotalPixels > 0 {
+		inc.TotalPixels = obs.TotalPixels
+	}
+	if obs.Weighted != nil && (inc.PeakWeighted == nil || *obs.Weighted > *inc.PeakWeighted) {
+		w := *obs.Weighted
+		inc.PeakWeighted = &w
+	}
+	if !inc.PeakAt.IsZero() && obs.Percentage <= inc.PeakPercentage {
+		return false
+	}
+	inc.PeakPercentage = obs.Percentage
+	inc.PeakDiffPixels = obs.DiffPixels
+	inc.PeakAt = now
+	if len(obs.DiffImage) > 0 {
+		s.writePeakImagesLocked(inc, obs.LiveImage, obs.DiffImage)
+	}
+	return true
+}
+
+func (s *Store) snapshotActivity() map[string]activity.ActivityCount {
+	if s.activity == nil {
+		return nil
+	}
+	return s.activity()
+}
+
+func (s *Store) refreshParticipantsLocked() {
+	if s.open == nil || s.activity == nil {
+		return
+	}
+	s.open.Vandals, s.open.Fixers = participantsSince(s.baseline, s.activity())
+}
+
+// writePeakImagesLocked ピーク時点の画像を incidents/{id}_live.png / {id}_diff.png に保存する
+// （ピーク更新のたびに上書き。書き込みに失敗したら前のピーク画像を残す）
+func (s *Store) writePeakImagesLocked(inc *Incident, live, diff []byte) {
+	if s.dir == "" {
+		return
+	}
+	imageDir := filepath.Join(s.dir, ImageDirName)
+	write := func(current *string, suffix string, data []byte) {
+		if len(data) == 0 {
+			*current = ""
+			return
+		}
+		name := fmt.Sprintf("%d_%s.png", inc.ID, suffix)
+		if err := utils.WriteFileAtomic(filepath.Join(imageDir, name), data); err != nil {
+			log.Printf("incidents: write %s failed: %v", name, err)
+			return
+		}
+		*current = name
+	}
+	write(&inc.PeakLiveImage, "live", live)
+	write(&inc.PeakDiffImage, "diff", diff)
+}
+
+func (s *Store) pruneLocked() {
+	if len(s.closed) <= maxClosedIncidents {
+		return
+	}
+	drop := s.closed[:len(s.closed)-maxClosedIncidents]
+	for _, inc := range drop {
+		for _, name := range []string{inc.PeakLiveImage, inc.PeakDiffImage} {
+			if path := ImagePath(s.dir, name); path != "" {
+				if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+					log.Printf("incidents: remove %s failed: %v", path, err)
+				}
+			}
+		}
+	}
+	s.closed = append([]*Incident(nil), s.closed[len(drop):]...)
+}
+
+func (s *Store) saveLocked() {
+	if s.dir == "" {
+		return
+	}
+	payload, err := json.MarshalIndent(storeFile{
+		NextID:    s.nextID,
+		Open:      s.open,
+		Baseline:  s.baseline,
+		Incidents: s.closed,
+	}, "", "  ")
+	if err != nil {
+		log.Printf("incidents: marshal failed: %v", err)
+		return
+	}
+	if err := utils.WriteFileAtomic(filepath.Join(s.dir, FileName), payload); err != nil {
+		log.Printf("incidents: save failed: %v", err)
+	}
+}
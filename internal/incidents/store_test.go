@@ -0,0 +1,125 @@
+package incidents
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"Koukyo_discord_bot/internal/activity"
+)
+
+func TestStoreOpensTracksPeakAndCloses(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	counts := map[string]activity.ActivityCount{
+		"1": {Name: "vandal", Vandal: 10},
+		"2": {Name: "fixer", Restored: 3},
+	}
+	store := NewStore(dir, func() map[string]activity.ActivityCount {
+		out := make(map[string]activity.ActivityCount, len(counts))
+		for k, v := range counts {
+			out[k] = v
+		}
+		return out
+	})
+
+	base := time.Date(2026, 1, 2, 21, 3, 0, 0, time.UTC)
+	if opened, closed := store.Observe(base, Observation{Percentage: 0, DiffPixels: 0}); opened != nil || closed != nil {
+		t.Fatalf("zero diff must not open an incident")
+	}
+	opened, _ := store.Observe(base, Observation{Artwork: "koukyo", Percentage: 1.5, DiffPixels: 15, TotalPixels: 1000, DiffImage: []byte("d1")})
+	if opened == nil || opened.ID != 1 || !opened.Open() {
+		t.Fatalf("expected incident #1 to open, got %+v", opened)
+	}
+
+	counts["1"] = activity.ActivityCount{Name: "vandal", Vandal: 40}
+	store.Observe(base.Add(10*time.Minute), Observation{Percentage: 4.0, DiffPixels: 40, LiveImage: []byte("live"), DiffImage: []byte("peak")})
+	store.Observe(base.Add(20*time.Minute), Observation{Percentage: 2.0, DiffPixels: 20, DiffImage: []byte("d3")})
+	counts["2"] = activity.ActivityCount{Name: "fixer", Restored: 43}
+
+	_, closed := store.Observe(base.Add(44*time.Minute), Observation{Percentage: 0, DiffPixels: 0})
+	if closed == nil || closed.ID != 1 || closed.Open() {
+		t.Fatalf("expected incident #1 to close, got %+v", closed)
+	}
+	if closed.PeakPercentage != 4.0 || closed.PeakDiffPixels != 40 || !closed.PeakAt.Equal(base.Add(10*time.Minute)) {
+		t.Fatalf("unexpected peak: %+v", closed)
+	}
+	if closed.Duration(time.Time{}) != 44*time.Minute || closed.TimeToRecovery() != 34*time.Minute {
+		t.Fatalf("unexpected duration=%s recovery=%s", closed.Duration(time.Time{}), closed.TimeToRecovery())
+	}
+	if len(closed.Vandals) != 1 || closed.Vandals[0].ID != "1" || closed.Vandals[0].Pixels != 30 {
+		t.Fatalf("unexpected vandals: %+v", closed.Vandals)
+	}
+	if len(closed.Fixers) != 1 || closed.Fixers[0].ID != "2" || closed.Fixers[0].Pixels != 40 {
+		t.Fatalf("unexpected fixers: %+v", closed.Fixers)
+	}
+	data, err := os.ReadFile(ImagePath(dir, closed.PeakDiffImage))
+	if err != nil || string(data) != "peak" {
+		t.Fatalf("peak diff image not saved: %q err=%v", data, err)
+	}
+
+	next, _ := store.Observe(base.Add(time.Hour), Observation{Percentage: 0.5, DiffPixels: 5})
+	if next == nil || next.ID != 2 {
+		t.Fatalf("expected incident #2 to open, got %+v", next)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].ID != 2 || !loaded[0].Open() || loaded[1].ID != 1 {
+		t.Fatalf("unexpected persisted incidents: %+v", loaded)
+	}
+
+	restored := NewStore(dir, nil)
+	if cur := restored.Current(); cur == nil || cur.ID != 2 {
+		t.Fatalf("open incident not restored: %+v", cur)
+	}
+	if last := restored.LastClosed(); last == nil || last.ID != 1 {
+		t.Fatalf("closed incident not restored: %+v", last)
+	}
+}
+
+func TestStartedBetweenIncludesOpenIncident(t *testing.T) {
+	t.Parallel()
+	store := NewStore("", nil)
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	store.Observe(day.Add(-time.Hour), Observation{Percentage: 1, DiffPixels: 1})
+	store.Observe(day.Add(-30*time.Minute), Observation{Percentage: 0, DiffPixels: 0})
+	store.Observe(day.Add(time.Hour), Observation{Percentage: 1, DiffPixels: 1})
+	store.Observe(day.Add(2*time.Hour), Observation{Percentage: 0, DiffPixels: 0})
+	store.Observe(day.Add(3*time.Hour), Observation{Percentage: 1, DiffPixels: 1})
+
+	got := store.StartedBetween(day, day.Add(24*time.Hour))
+	if len(got) != 2 || got[0].ID != 2 || got[1].ID != 3 || !got[1].Open() {
+		t.Fatalf("unexpected incidents for the day: %+v", got)
+	}
+}
+
+func TestStoreKeepsPeakImagesOfOpenIncidentAcrossRestart(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 2, 21, 3, 0, 0, time.UTC)
+
+	store := NewStore(dir, nil)
+	store.Observe(base, Observation{Percentage: 1.0, DiffPixels: 10, LiveImage: []byte("live1"), DiffImage: []byte("diff1")})
+	store.Observe(base.Add(time.Second), Observation{Percentage: 3.0, DiffPixels: 30, LiveImage: []byte("live2"), DiffImage: []byte("peak")})
+	store.Observe(base.Add(2*time.Second), Observation{Percentage: 2.0, DiffPixels: 20, LiveImage: []byte("live3"), DiffImage: []byte("diff3")})
+
+	// 終了前に再起動しても、ピーク画像は保存済みで履歴からも参照できる
+	restored := NewStore(dir, nil)
+	cur := restored.Current()
+	if cur == nil || cur.PeakPercentage != 3.0 || cur.PeakDiffImage == "" {
+		t.Fatalf("open incident peak not persisted: %+v", cur)
+	}
+	_, closed := restored.Observe(base.Add(time.Minute), Observation{Percentage: 0, DiffPixels: 0})
+	if closed == nil {
+		t.Fatalf("expected incident to close")
+	}
+	for name, want := range map[string]string{closed.PeakDiffImage: "peak", closed.PeakLiveImage: "live2"} {
+		data, err := os.ReadFile(ImagePath(dir, name))
+		if err != nil || string(data) != want {
+			t.Fatalf("peak image %q = %q err=%v, want %q", name, data, err, want)
+		}
+	}
+}
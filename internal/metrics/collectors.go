@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/monitor"
+	"Koukyo_discord_bot/internal/notifications"
+	"Koukyo_discord_bot/internal/utils"
+	"Koukyo_discord_bot/internal/wplace"
+)
+
+// BuildInfoCollector バージョン情報
+func BuildInfoCollector(version string) Collector {
+	return func() []Family {
+		return []Family{{
+			Name:    "koukyo_build",
+			Type:    Info,
+			Help:    "Bot build information.",
+			Samples: []Sample{{Labels: []Label{{"version", version}}, Value: 1}},
+		}}
+	}
+}
+
+// MonitorCollector WS接続・取得元・最新差分率（アートワーク別）
+func MonitorCollector(set *monitor.Set) Collector {
+	return func() []Family {
+		connected := Family{Name: "koukyo_monitor_ws_connected", Type: Gauge, Help: "1 if the monitor WebSocket is connected."}
+		idle := Family{Name: "koukyo_monitor_idle_seconds", Type: Gauge, Help: "Seconds since the last monitor message (absent until the first message)."}
+		running := Family{Name: "koukyo_monitor_source_running", Type: Gauge, Help: "1 if the monitor source is running."}
+		healthy := Family{Name: "koukyo_monitor_source_healthy", Type: Gauge, Help: "1 if the monitor source reports healthy."}
+		active := Family{Name: "koukyo_monitor_source_active", Type: Gauge, Help: "1 for the source currently feeding monitor state."}
+		diff := Family{Name: "koukyo_monitor_diff_percentage", Type: Gauge, Help: "Latest diff percentage."}
+		weighted := Family{Name: "koukyo_monitor_weighted_diff_percentage", Type: Gauge, Help: "Latest weighted (chrysanthemum) diff percentage."}
+		diffPixels := Family{Name: "koukyo_monitor_diff_pixels", Type: Gauge, Help: "Latest diff pixel count."}
+		powerSave := Family{Name: "koukyo_monitor_power_save", Type: Gauge, Help: "1 if power-save mode is active."}
+
+		now := time.Now()
+		for _, mon := range set.All() {
+			art := []Label{{"artwork", mon.Artwork().ID}}
+			connected.Samples = append(connected.Samples, Sample{Labels: art, Value: BoolValue(mon.IsConnected())})
+			if last := mon.LastMessageAt(); !last.IsZero() {
+				idle.Samples = append(idle.Samples, Sample{Labels: art, Value: now.Sub(last).Seconds()})
+			}
+			for _, st := range mon.SourceStatuses() {
+				labels := []Label{{"artwork", mon.Artwork().ID}, {"source", st.Name}}
+				running.Samples = append(running.Samples, Sample{Labels: labels, Value: BoolValue(st.Running)})
+				healthy.Samples = append(healthy.Samples, Sample{Labels: labels, Value: BoolValue(st.Healthy)})
+				active.Samples = append(active.Samples, Sample{Labels: labels, Value: BoolValue(st.Active)})
+			}
+			powerSave.Samples = append(powerSave.Samples, Sample{Labels: art, Value: BoolValue(mon.State.IsPowerSaveMode())})
+			data := mon.GetLatestData()
+			if data == nil {
+				continue
+			}
+			diff.Samples = append(diff.Samples, Sample{Labels: art, Value: data.DiffPercentage})
+			diffPixels.Samples = append(diffPixels.Samples, Sample{Labels: art, Value: float64(data.DiffPixels)})
+			if data.WeightedDiffPercentage != nil {
+				weighted.Samples = append(weighted.Samples, Sample{Labels: art, Value: *data.WeightedDiffPercentage})
+			}
+		}
+		return []Family{connected, idle, running, healthy, active, diff, weighted, diffPixels, powerSave}
+	}
+}
+
+// NotifierCollector ディスパッチキュー滞留数・ドロップ数・スタンドアロン状態
+func NotifierCollector(notifiers []*notifications.Notifier) Collector {
+	return func() []Family {
+		depth := Family{Name: "koukyo_notifier_queue_depth", Type: Gauge, Help: "Pending notifications in the dispatch queue."}
+		dropped := Family{Name: "koukyo_notifier_dropped", Type: Counter, Help: "Notifications dropped because the dispatch queue was full."}
+		standalone := Family{Name: "koukyo_notifier_standalone_active", Type: Gauge, Help: "1 while the standalone fallback is active."}
+		for _, n := range notifiers {
+			if n == nil {
+				continue
+			}
+			id := n.ArtworkID()
+			high, low := n.DispatchQueueDepth()
+			droppedHigh, droppedLow := n.GetDroppedNotificationStats()
+			depth.Samples = append(depth.Samples,
+				Sample{Labels: []Label{{"artwork", id}, {"priority", "high"}}, Value: float64(high)},
+				Sample{Labels: []Label{{"artwork", id}, {"priority", "low"}}, Value: float64(low)},
+			)
+			dropped.Samples = append(dropped.Samples,
+				Sample{Labels: []Label{{"artwork", id}, {"priority", "high"}}, Value: float64(droppedHigh)},
+				Sample{Labels: []Label{{"artwork", id}, {"priority", "low"}}, Value: float64(droppedLow)},
+			)
+			standalone.Samples = append(standalone.Samples, Sample{Labels: []Label{{"artwork", id}}, Value: BoolValue(n.IsStandaloneActive())})
+		}
+		return []Family{depth, dropped, standalone}
+	}
+}
+
+// RateLimiterCollector ホスト別の待ち行列と待ち時間（limiters のキーは limiter ラベル）
+func RateLimiterCollector(limiters map[string]*utils.RateLimiter) Collector {
+	names := make([]string, 0, len(limiters))
+	for name := range limiters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return func() []Family {
+		queued := Family{Name: "koukyo_ratelimiter_queue_length", Type: Gauge, Help: "Requests waiting in the per-host rate limiter queue."}
+		requests := Family{Name: "koukyo_ratelimiter_requests", Type: Counter, Help: "Requests executed through the rate limiter."}
+		wait := Family{Name: "koukyo_ratelimiter_wait_seconds", Type: Counter, Help: "Cumulative time requests spent queued before execution."}
+		lastWait := Family{Name: "koukyo_ratelimiter_last_wait_seconds", Type: Gauge, Help: "Queue wait of the most recently executed request."}
+		for _, name := range names {
+			for _, st := range limiters[name].Stats() {
+				labels := []Label{{"limiter", name}, {"host", st.Host}}
+				queued.Samples = append(queued.Samples, Sample{Labels: labels, Value: float64(st.Queued)})
+				requests.Samples = append(requests.Samples, Sample{Labels: labels, Value: float64(st.Processed)})
+				wait.Samples = append(wait.Samples, Sample{Labels: labels, Value: st.WaitTotal.Seconds()})
+				lastWait.Samples = append(lastWait.Samples, Sample{Labels: labels, Value: st.LastWait.Seconds()})
+			}
+		}
+		return []Family{queued, requests, wait, lastWait}
+	}
+}
+
+// TrackerCollector ピクセル照会キュー・保留数・429バックオフ（trackers のキーはアートワークID）
+func TrackerCollector(trackers map[string]*activity.Tracker) Collector {
+	ids := make([]string, 0, len(trackers))
+	for id := range trackers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return func() []Family {
+		queued := Family{Name: "koukyo_tracker_queue_length", Type: Gauge, Help: "Pixels queued for painter lookup."}
+		pending := Family{Name: "koukyo_tracker_pending_pixels", Type: Gauge, Help: "Pixels queued or being looked up."}
+		current := Family{Name: "koukyo_tracker_current_diff_pixels", Type: Gauge, Help: "Diff pixels known to the activity tracker."}
+		backoff := Family{Name: "koukyo_tracker_backoff_active", Type: Gauge, Help: "1 while painter lookups are backing off after HTTP 429."}
+		remaining := Family{Name: "koukyo_tracker_backoff_remaining_seconds", Type: Gauge, Help: "Remaining 429 backoff time."}
+		sampling := Family{Name: "koukyo_tracker_sampling_unprobed_pixels", Type: Gauge, Help: "Pixels credited by sampling estimate and not yet looked up."}
+		for _, id := range ids {
+			st := trackers[id].Stats()
+			labels := []Label{{"artwork", id}}
+			queued.Samples = append(queued.Samples, Sample{Labels: labels, Value: float64(st.Queued)})
+			pending.Samples = append(pending.Samples, Sample{Labels: labels, Value: float64(st.Pending)})
+			current.Samples = append(current.Samples, Sample{Labels: labels, Value: float64(st.CurrentDiff)})
+			backoff.Samples = append(backoff.Samples, Sample{Labels: labels, Value: BoolValue(st.BackoffActive)})
+			remaining.Samples = append(remaining.Samples, Sample{Labels: labels, Value: st.BackoffRemaining.Seconds()})
+			sampling.Samples = append(sampling.Samples, Sample{Labels: labels, Value: float64(st.SamplingPixels)})
+		}
+		return []Family{queued, pending, current, backoff, remaining, sampling}
+	}
+}
+
+// TileCacheCollector タイルキャッシュのヒット/ミスと保持件数
+func TileCacheCollector() Collector {
+	return func() []Family {
+		hits, misses, entries := wplace.TileCacheStats()
+		return []Family{
+			{Name: "koukyo_tile_cache_hits", Type: Counter, Help: "Tile cache hits.", Samples: []Sample{{Value: float64(hits)}}},
+			{Name: "koukyo_tile_cache_misses", Type: Counter, Help: "Tile cache misses.", Samples: []Sample{{Value: float64(misses)}}},
+			{Name: "koukyo_tile_cache_entries", Type: Gauge, Help: "Tiles currently cached.", Samples: []Sample{{Value: float64(entries)}}},
+		}
+	}
+}
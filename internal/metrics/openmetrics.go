@@ -0,0 +1,179 @@
+// Package metrics Bot 内部状態を OpenMetrics（Prometheus 互換）テキスト形式で公開する。
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType OpenMetrics テキスト形式の Content-Type
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Type メトリクスの種類
+type Type string
+
+const (
+	Gauge   Type = "gauge"
+	Counter Type = "counter"
+	Info    Type = "info"
+)
+
+// Label ラベル（出力順を保つためスライスで持つ）
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample 1系列の値
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Family 同名メトリクスの集合。Counter は出力時に _total、Info は _info が付く。
+type Family struct {
+	Name    string
+	Type    Type
+	Help    string
+	Samples []Sample
+}
+
+// Collector スクレイプのたびに呼ばれ、現在値を返す
+type Collector func() []Family
+
+// Registry Collector の登録先
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+}
+
+// NewRegistry 空の Registry を作成
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register Collector を追加する
+func (r *Registry) Register(c Collector) {
+	if c == nil {
+		return
+	}
+	r.mu.Lock()
+	r.collectors = append(r.collectors, c)
+	r.mu.Unlock()
+}
+
+// Gather 全 Collector を実行し、同名のファミリーをまとめて名前順に返す
+func (r *Registry) Gather() []Family {
+	r.mu.Lock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	merged := make(map[string]*Family)
+	for _, collect := range collectors {
+		for _, fam := range collect() {
+			if existing, ok := merged[fam.Name]; ok {
+				existing.Samples = append(existing.Samples, fam.Samples...)
+				continue
+			}
+			f := fam
+			merged[f.Name] = &f
+		}
+	}
+	out := make([]Family, 0, len(merged))
+	for _, f := range merged {
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Write OpenMetrics テキスト形式で書き出す（末尾に # EOF）
+func (r *Registry) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, fam := range r.Gather() {
+		if len(fam.Samples) == 0 {
+			continue
+		}
+		bw.WriteString("# TYPE " + fam.Name + " " + string(fam.Type) + "\n")
+		if fam.Help != "" {
+			bw.WriteString("# HELP " + fam.Name + " " + escapeHelp(fam.Help) + "\n")
+		}
+		sampleName := fam.Name
+		switch fam.Type {
+		case Counter:
+			sampleName += "_total"
+		case Info:
+			sampleName += "_info"
+		}
+		for _, s := range fam.Samples {
+			bw.WriteString(sampleName)
+			writeLabels(bw, s.Labels)
+			bw.WriteString(" " + formatValue(s.Value) + "\n")
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// Handler /metrics 用の http.Handler
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if err := r.Write(w); err != nil {
+			log.Printf("metrics: write failed: %v", err)
+		}
+	})
+}
+
+func writeLabels(w *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	w.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(l.Name + `="` + escapeLabelValue(l.Value) + `"`)
+	}
+	w.WriteByte('}')
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(v string) string {
+	return helpEscaper.Replace(v)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// BoolValue bool を 0/1 に変換する
+func BoolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
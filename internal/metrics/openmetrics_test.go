@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"Koukyo_discord_bot/internal/utils"
+)
+
+func TestRegistryWritesOpenMetricsText(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	reg.Register(func() []Family {
+		return []Family{
+			{Name: "koukyo_b", Type: Counter, Help: "B help.", Samples: []Sample{
+				{Labels: []Label{{"artwork", "koukyo"}, {"priority", "high"}}, Value: 3},
+			}},
+			{Name: "koukyo_empty", Type: Gauge, Help: "Skipped when empty."},
+		}
+	})
+	reg.Register(func() []Family {
+		return []Family{
+			{Name: "koukyo_a", Type: Gauge, Help: "A help.", Samples: []Sample{{Labels: []Label{{"artwork", `q"uo\te`}}, Value: 1.5}}},
+			{Name: "koukyo_b", Type: Counter, Samples: []Sample{{Labels: []Label{{"artwork", "other"}, {"priority", "low"}}, Value: 0}}},
+		}
+	})
+
+	var buf bytes.Buffer
+	if err := reg.Write(&buf); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	want := strings.Join([]string{
+		"# TYPE koukyo_a gauge",
+		"# HELP koukyo_a A help.",
+		`koukyo_a{artwork="q\"uo\\te"} 1.5`,
+		"# TYPE koukyo_b counter",
+		"# HELP koukyo_b B help.",
+		`koukyo_b_total{artwork="koukyo",priority="high"} 3`,
+		`koukyo_b_total{artwork="other",priority="low"} 0`,
+		"# EOF",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRateLimiterCollectorReportsHostStats(t *testing.T) {
+	t.Parallel()
+
+	limiter := utils.NewRateLimiter(50)
+	defer limiter.Close()
+	if _, err := limiter.Do(context.Background(), "backend.wplace.live", func() (interface{}, error) { return nil, nil }); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+
+	families := RateLimiterCollector(map[string]*utils.RateLimiter{"activity": limiter})()
+	var requests *Family
+	for i := range families {
+		if families[i].Name == "koukyo_ratelimiter_requests" {
+			requests = &families[i]
+		}
+	}
+	if requests == nil || len(requests.Samples) != 1 {
+		t.Fatalf("requests family missing: %+v", families)
+	}
+	s := requests.Samples[0]
+	if s.Value != 1 || s.Labels[0].Value != "activity" || s.Labels[1].Value != "backend.wplace.live" {
+		t.Fatalf("unexpected sample: %+v", s)
+	}
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Serve addr で /metrics を公開する。停止は返り値の Close で行う。
+func Serve(addr string, reg *Registry) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", reg.Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("metrics: server stopped: %v", err)
+		}
+	}()
+	log.Printf("Metrics endpoint listening on http://%s/metrics", addr)
+	return srv
+}
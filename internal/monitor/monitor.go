@@ -9,11 +9,13 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
 
 	"github.com/gorilla/websocket"
 )
@@ -31,28 +33,32 @@ func monitorDebugf(format string, args ...interface{}) {
 
 // Monitor WebSocket監視クライアント
 type Monitor struct {
-	URL                string
-	State              *MonitorState
-	conn               *websocket.Conn
-	ctx                context.Context
-	cancel             context.CancelFunc
-	connected          bool
-	mu                 sync.RWMutex
-	writeMu            sync.Mutex
-	reconnectMu        sync.Mutex
-	lastIdleReconnect  time.Time
-	tracker            *activity.Tracker
-	lastMu             sync.Mutex
-	lastMsgAt          time.Time
-	wsUnavailableSince time.Time
-	reconnectAttempts  int
-	reconnectBackoff   time.Duration
-	pollURL            string
-	pollClient         *http.Client
-	pollBaseInterval   time.Duration
-	pollMu             sync.Mutex
-	pollAttempts       int
-	pollNextAttemptAt  time.Time
+	URL               string
+	State             *MonitorState
+	artwork           config.Artwork
+	conn              *websocket.Conn
+	ctx               context.Context
+	cancel            context.CancelFunc
+	connected         bool
+	mu                sync.RWMutex
+	writeMu           sync.Mutex
+	reconnectMu       sync.Mutex
+	lastIdleReconnect time.Time
+	tracker           *activity.Tracker
+	lastMu            sync.Mutex
+	lastMsgAt         time.Time
+	wsSink            *SourceSink
+	reconnectAttempts int
+	reconnectBackoff  time.Duration
+	pollURL           string
+	pollClient        *http.Client
+	pollBaseInterval  time.Duration
+	sourcesMu         sync.Mutex
+	sources           []*sourceEntry
+	sourcesStarted    bool
+	recorder          *Recorder
+	replayPath        string
+	replaySpeed       float64
 }
 
 type monitorTextPayload struct {
@@ -114,17 +120,31 @@ func (p *monitorTextPayload) toMonitorData() *MonitorData {
 	return data
 }
 
-// NewMonitor 新しいMonitorを作成
+// NewMonitor 新しいMonitorを作成（既定アートワーク）
 func NewMonitor(url string) *Monitor {
+	art := config.DefaultArtwork()
+	art.WebSocketURL = url
+	art.PollURL = strings.TrimSpace(os.Getenv("MONITOR_POLL_URL"))
+	return NewArtworkMonitor(art, "")
+}
+
+// NewArtworkMonitor アートワーク定義からMonitorを作成する。
+// stateDir を指定すると監視状態をそのディレクトリへ永続化し、起動時に復元する。
+func NewArtworkMonitor(art config.Artwork, stateDir string) *Monitor {
 	ctx, cancel := context.WithCancel(context.Background())
-	pollURL := strings.TrimSpace(os.Getenv("MONITOR_POLL_URL"))
+	pollURL := strings.TrimSpace(art.PollURL)
 	forceStandalone := os.Getenv("MONITOR_FORCE_STANDALONE") == "1"
 	if forceStandalone {
-		log.Println("⚠️ MONITOR_FORCE_STANDALONE enabled: starting in standalone mode without WebSocket")
+		log.Printf("⚠️ MONITOR_FORCE_STANDALONE enabled: artwork=%s starting in standalone mode without WebSocket", art.ID)
+	}
+	snapshotPath := ""
+	if stateDir != "" {
+		snapshotPath = filepath.Join(stateDir, StateSnapshotFileName)
 	}
 	return &Monitor{
-		URL:              url,
-		State:            NewMonitorState(),
+		URL:              art.WebSocketURL,
+		State:            NewMonitorState(snapshotPath),
+		artwork:          art,
 		ctx:              ctx,
 		cancel:           cancel,
 		reconnectBackoff: 2 * time.Second,
@@ -134,28 +154,35 @@ func NewMonitor(url string) *Monitor {
 	}
 }
 
+// Artwork 監視対象アートワークの定義を返す
+func (m *Monitor) Artwork() config.Artwork {
+	if m == nil {
+		return config.DefaultArtwork()
+	}
+	return m.artwork
+}
+
 func (m *Monitor) SetActivityTracker(tracker *activity.Tracker) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.tracker = tracker
 }
 
-// EnqueueDiffImageToTracker forwards a diff PNG to the activity tracker.
-// Skipped when tracker is nil or power-save mode is active (mirrors WebSocket path).
-func (m *Monitor) EnqueueDiffImageToTracker(diffPNG []byte) {
-	if m == nil || len(diffPNG) == 0 {
-		return
+func (m *Monitor) GetCurrentDiffPainterCounts(limit int) []activity.PainterPixelCount {
+	if m == nil {
+		return nil
 	}
 	m.mu.RLock()
 	tracker := m.tracker
 	m.mu.RUnlock()
-	if tracker == nil || m.State.IsPowerSaveMode() {
-		return
+	if tracker == nil {
+		return nil
 	}
-	tracker.EnqueueDiffImage(diffPNG)
+	return tracker.GetCurrentDiffPainterCounts(limit)
 }
 
-func (m *Monitor) GetCurrentDiffPainterCounts(limit int) []activity.PainterPixelCount {
+// GetActivityCounts ユーザー別の累計荒らし/修復数（Tracker 未設定なら nil）
+func (m *Monitor) GetActivityCounts() map[string]activity.ActivityCount {
 	if m == nil {
 		return nil
 	}
@@ -165,7 +192,21 @@ func (m *Monitor) GetCurrentDiffPainterCounts(limit int) []activity.PainterPixel
 	if tracker == nil {
 		return nil
 	}
-	return tracker.GetCurrentDiffPainterCounts(limit)
+	return tracker.ActivityCounts()
+}
+
+// ReviewBotSuspicion bot の疑いにモデレーターの確認結果を付ける（Tracker 未設定ならエラー）
+func (m *Monitor) ReviewBotSuspicion(userID, verdict, reviewer string) (activity.UserActivity, error) {
+	if m == nil {
+		return activity.UserActivity{}, fmt.Errorf("monitor is not available")
+	}
+	m.mu.RLock()
+	tracker := m.tracker
+	m.mu.RUnlock()
+	if tracker == nil {
+		return activity.UserActivity{}, fmt.Errorf("activity tracker is not available")
+	}
+	return tracker.ReviewBotSuspicion(userID, verdict, reviewer)
 }
 
 // Connect WebSocketサーバーに接続
@@ -202,22 +243,42 @@ func (m *Monitor) Connect() error {
 	return nil
 }
 
-// Start 監視を開始
+// Start 監視を開始。組み込みの取得元（再生 / WebSocket / HTTPポーリング）を
+// AddSource 済みの取得元より高い優先度で登録し、フェイルオーバー監視を始める。
 func (m *Monitor) Start() error {
-	if err := m.Connect(); err != nil {
-		log.Printf("Initial WebSocket connect failed: %v; starting in degraded mode", err)
-		m.markWSUnavailable(time.Now())
+	m.mu.RLock()
+	replaying := m.replayPath != ""
+	m.mu.RUnlock()
+
+	var builtin []SourceConfig
+	switch {
+	case replaying:
+		// キャプチャ再生中は WebSocket/フォールバックへ接続しない。
+		builtin = append(builtin, SourceConfig{Source: &replaySource{m: m}})
+	case os.Getenv("MONITOR_FORCE_STANDALONE") == "1":
+		// 組み込みの取得元を使わず、下位（スタンドアロン取得など）のみで運用する。
+	default:
+		if m.URL != "" {
+			builtin = append(builtin, SourceConfig{Source: &webSocketSource{m: m}})
+		} else {
+			log.Printf("Monitor %s: no WebSocket URL; relying on fallback sources", m.artwork.ID)
+		}
+		if m.pollURL != "" {
+			builtin = append(builtin, SourceConfig{Source: &pollSource{m: m}, ActivateAfter: pollActivateAfter})
+		} else {
+			log.Println("Monitor polling fallback disabled (MONITOR_POLL_URL is empty)")
+		}
 	}
 
-	go m.runLoop("receiveLoop", m.receiveLoop)
-	go m.runLoop("pingLoop", m.pingLoop)
-	go m.runLoop("keepaliveLoop", m.keepaliveLoop)
-	go m.runLoop("idleWatchLoop", m.idleWatchLoop)
-	if m.pollURL != "" {
-		go m.runLoop("pollFallbackLoop", m.pollFallbackLoop)
-	} else {
-		log.Println("Monitor polling fallback disabled (MONITOR_POLL_URL is empty)")
+	m.sourcesMu.Lock()
+	entries := make([]*sourceEntry, 0, len(builtin)+len(m.sources))
+	for _, cfg := range builtin {
+		entries = append(entries, m.newSourceEntry(cfg))
 	}
+	m.sources = append(entries, m.sources...)
+	m.sourcesMu.Unlock()
+
+	m.startSources()
 	return nil
 }
 
@@ -321,7 +382,8 @@ func (m *Monitor) receiveLoop() {
 			m.lastMsgAt = time.Now()
 			m.lastMu.Unlock()
 
-			if err := m.handleMessage(messageType, message); err != nil {
+			m.recordFrame(messageType, message)
+			if err := m.handleMessage(m.webSocketSink(), messageType, message); err != nil {
 				log.Printf("Message handling error: %v", err)
 			}
 		}
@@ -445,114 +507,25 @@ func (m *Monitor) forceReconnectIfIdle() {
 	monitorDebugf("WebSocket idle >60s: forced reconnect triggered")
 }
 
+func (m *Monitor) webSocketSink() *SourceSink {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.wsSink
+}
+
 func (m *Monitor) markWSUnavailable(now time.Time) {
-	m.lastMu.Lock()
-	if m.wsUnavailableSince.IsZero() {
-		m.wsUnavailableSince = now
-	}
-	m.lastMu.Unlock()
+	m.webSocketSink().Unavailable(now)
 }
 
 func (m *Monitor) markWSHealthy(now time.Time) {
+	m.webSocketSink().Healthy()
 	m.lastMu.Lock()
-	m.wsUnavailableSince = time.Time{}
 	m.lastMsgAt = now
 	m.lastMu.Unlock()
 }
 
-func (m *Monitor) getWSUnavailableSince() time.Time {
-	m.lastMu.Lock()
-	defer m.lastMu.Unlock()
-	return m.wsUnavailableSince
-}
-
-// IsWSUnavailableFor reports whether WebSocket has been unavailable for at least d.
-func (m *Monitor) IsWSUnavailableFor(d time.Duration) bool {
-	if d <= 0 {
-		return false
-	}
-	since := m.getWSUnavailableSince()
-	if since.IsZero() {
-		return false
-	}
-	return time.Since(since) >= d
-}
-
-func (m *Monitor) shouldPollFallback(now time.Time) bool {
-	since := m.getWSUnavailableSince()
-	if since.IsZero() {
-		m.resetPollBackoff()
-		return false
-	}
-	return now.Sub(since) >= 1*time.Minute
-}
-
-func (m *Monitor) resetPollBackoff() {
-	m.pollMu.Lock()
-	m.pollAttempts = 0
-	m.pollNextAttemptAt = time.Time{}
-	m.pollMu.Unlock()
-}
-
-func (m *Monitor) nextPollDelayLocked() time.Duration {
-	attempt := m.pollAttempts
-	if attempt > 5 {
-		attempt = 5
-	}
-	delay := m.pollBaseInterval * time.Duration(1<<uint(attempt))
-	if delay > 5*time.Minute {
-		delay = 5 * time.Minute
-	}
-	return delay
-}
-
-func (m *Monitor) pollFallbackLoop() {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-m.ctx.Done():
-			return
-		case <-ticker.C:
-			now := time.Now()
-			if !m.shouldPollFallback(now) {
-				continue
-			}
-
-			m.pollMu.Lock()
-			if !m.pollNextAttemptAt.IsZero() && now.Before(m.pollNextAttemptAt) {
-				m.pollMu.Unlock()
-				continue
-			}
-			m.pollMu.Unlock()
-
-			data, err := m.fetchPolledData()
-			if err != nil {
-				m.pollMu.Lock()
-				delay := m.nextPollDelayLocked()
-				m.pollNextAttemptAt = time.Now().Add(delay)
-				if m.pollAttempts < 5 {
-					m.pollAttempts++
-				}
-				m.pollMu.Unlock()
-				log.Printf("Monitor poll fallback failed: %v", err)
-				continue
-			}
-
-			wasPowerSave := m.State.IsPowerSaveMode()
-			m.State.UpdateData(data)
-			m.armPowerSaveInferenceOnResume(wasPowerSave, data)
-			m.pollMu.Lock()
-			m.pollAttempts = 0
-			m.pollNextAttemptAt = time.Now().Add(m.pollBaseInterval)
-			m.pollMu.Unlock()
-		}
-	}
-}
-
-func (m *Monitor) fetchPolledData() (*MonitorData, error) {
-	req, err := http.NewRequestWithContext(m.ctx, http.MethodGet, m.pollURL, nil)
+func (m *Monitor) fetchPolledData(ctx context.Context) (*MonitorData, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.pollURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -595,18 +568,19 @@ func parsePolledMonitorData(body []byte) (*MonitorData, error) {
 }
 
 // handleMessage メッセージを処理
-func (m *Monitor) handleMessage(messageType int, message []byte) error {
+// sink は受信元の取得元（nil の場合は優先度判定を行わず直接反映する）。
+func (m *Monitor) handleMessage(sink *SourceSink, messageType int, message []byte) error {
 	switch messageType {
 	case websocket.TextMessage:
-		return m.handleTextMessage(message)
+		return m.handleTextMessage(sink, message)
 	case websocket.BinaryMessage:
-		return m.handleBinaryMessage(message)
+		return m.handleBinaryMessage(sink, message)
 	}
 	return nil
 }
 
 // handleTextMessage JSONメッセージを処理
-func (m *Monitor) handleTextMessage(message []byte) error {
+func (m *Monitor) handleTextMessage(sink *SourceSink, message []byte) error {
 	var payload monitorTextPayload
 	if err := json.Unmarshal(message, &payload); err != nil {
 		return err
@@ -624,9 +598,11 @@ func (m *Monitor) handleTextMessage(message []byte) error {
 
 	if payload.hasMonitoringData() {
 		data := payload.toMonitorData()
-		wasPowerSave := m.State.IsPowerSaveMode()
-		m.State.UpdateData(data)
-		m.armPowerSaveInferenceOnResume(wasPowerSave, data)
+		if sink != nil {
+			sink.UpdateData(data)
+		} else {
+			m.applyData(data)
+		}
 		monitorDebugf("Updated: Diff=%.2f%%, Weighted=%.2f%%",
 			data.DiffPercentage,
 			getWeightedValue(data.WeightedDiffPercentage))
@@ -635,6 +611,47 @@ func (m *Monitor) handleTextMessage(message []byte) error {
 	return nil
 }
 
+// applyData 監視データを MonitorState へ反映する（全取得元の共通経路）
+func (m *Monitor) applyData(data *MonitorData) {
+	wasPowerSave := m.State.IsPowerSaveMode()
+	m.State.UpdateData(data)
+	m.armPowerSaveInferenceOnResume(wasPowerSave, data)
+}
+
+// applyImages ライブ/差分画像を現在の画像へマージして反映する（全取得元の共通経路）。
+// 差分画像は省電力モードでなければ活動トラッカーにも渡す。
+func (m *Monitor) applyImages(live, diff []byte) {
+	m.mu.RLock()
+	tracker := m.tracker
+	m.mu.RUnlock()
+
+	var current ImageData
+	m.State.mu.RLock()
+	if m.State.LatestImages != nil {
+		current = *m.State.LatestImages
+	}
+	powerSave := m.State.PowerSaveMode
+	m.State.mu.RUnlock()
+
+	if len(live) > 0 {
+		current.LiveImage = live
+	}
+	if len(diff) > 0 {
+		current.DiffImage = diff
+		if tracker != nil && !powerSave {
+			tracker.EnqueueDiffImage(diff)
+		} else if tracker != nil {
+			monitorDebugf("activity tracker skipped: power_save_mode=true")
+		}
+	}
+	current.Timestamp = time.Now()
+	m.State.UpdateImages(&ImageData{
+		LiveImage: append([]byte(nil), current.LiveImage...),
+		DiffImage: append([]byte(nil), current.DiffImage...),
+		Timestamp: current.Timestamp,
+	})
+}
+
 func (m *Monitor) armPowerSaveInferenceOnResume(wasPowerSave bool, data *MonitorData) {
 	if data == nil || !wasPowerSave || m.State.IsPowerSaveMode() {
 		return
@@ -649,7 +666,7 @@ func (m *Monitor) armPowerSaveInferenceOnResume(wasPowerSave bool, data *Monitor
 }
 
 // handleBinaryMessage バイナリメッセージ（画像）を処理
-func (m *Monitor) handleBinaryMessage(message []byte) error {
+func (m *Monitor) handleBinaryMessage(sink *SourceSink, message []byte) error {
 	// ヘッダーサイズ: 5バイト (type_id: 1バイト + payload_size: 4バイト)
 	headerSize := 5
 	if len(message) < headerSize {
@@ -667,70 +684,36 @@ func (m *Monitor) handleBinaryMessage(message []byte) error {
 
 	monitorDebugf("Received binary data: %d bytes, type_id=%d, payload_size=%d", len(message), typeID, payloadLen)
 
-	m.mu.RLock()
-	tracker := m.tracker
-	m.mu.RUnlock()
-
 	// payloadのコピーを作成（元のバッファが上書きされるのを防ぐ）
 	payloadCopy := make([]byte, len(payload))
 	copy(payloadCopy, payload)
-
-	var current ImageData
-	m.State.mu.RLock()
-	if m.State.LatestImages != nil {
-		current = *m.State.LatestImages
+	// 先頭に余分な00バイトがある場合は削除
+	if len(payloadCopy) > 0 && payloadCopy[0] == 0x00 {
+		payloadCopy = payloadCopy[1:]
 	}
-	powerSave := m.State.PowerSaveMode
-	m.State.mu.RUnlock()
 
-	updated := false
-	now := time.Now()
+	var live, diff []byte
 	switch typeID {
 	case 2: // Live image
-		// 先頭に余分な00バイトがある場合は削除
-		if len(payloadCopy) > 0 && payloadCopy[0] == 0x00 {
-			payloadCopy = payloadCopy[1:]
-		}
-		current.LiveImage = payloadCopy
-		current.Timestamp = now
-		updated = true
-		// 最初の16バイトをログに出力してフォーマットを確認
-		if len(payloadCopy) >= 16 {
-			monitorDebugf("Stored live image: %d bytes, header: %X", len(payloadCopy), payloadCopy[:16])
-		} else {
-			monitorDebugf("Stored live image: %d bytes", len(payloadCopy))
-		}
+		live = payloadCopy
 	case 3: // Diff image
-		// 先頭に余分な00バイトがある場合は削除
-		if len(payloadCopy) > 0 && payloadCopy[0] == 0x00 {
-			payloadCopy = payloadCopy[1:]
-		}
-		current.DiffImage = payloadCopy
-		current.Timestamp = now
-		updated = true
-		if tracker != nil && !powerSave {
-			tracker.EnqueueDiffImage(payloadCopy)
-		} else if tracker != nil {
-			monitorDebugf("activity tracker skipped: power_save_mode=true")
-		}
-		// 最初の16バイトをログに出力してフォーマットを確認
-		if len(payloadCopy) >= 16 {
-			monitorDebugf("Stored diff image: %d bytes, header: %X", len(payloadCopy), payloadCopy[:16])
-		} else {
-			monitorDebugf("Stored diff image: %d bytes", len(payloadCopy))
-		}
+		diff = payloadCopy
 	default:
 		log.Printf("Unknown binary type_id: %d", typeID)
+		return nil
 	}
-	if updated {
-		imagesCopy := &ImageData{
-			LiveImage: append([]byte(nil), current.LiveImage...),
-			DiffImage: append([]byte(nil), current.DiffImage...),
-			Timestamp: current.Timestamp,
-		}
-		m.State.UpdateImages(imagesCopy)
+	// 最初の16バイトをログに出力してフォーマットを確認
+	if len(payloadCopy) >= 16 {
+		monitorDebugf("Stored image type_id=%d: %d bytes, header: %X", typeID, len(payloadCopy), payloadCopy[:16])
+	} else {
+		monitorDebugf("Stored image type_id=%d: %d bytes", typeID, len(payloadCopy))
 	}
 
+	if sink != nil {
+		sink.UpdateImages(live, diff)
+	} else {
+		m.applyImages(live, diff)
+	}
 	return nil
 }
 
@@ -786,7 +769,19 @@ func (m *Monitor) Stop() {
 	if m.conn != nil {
 		m.conn.Close()
 	}
+	rec := m.recorder
+	m.recorder = nil
 	m.mu.Unlock()
+	if err := rec.Close(); err != nil {
+		log.Printf("Failed to close monitor recorder: %v", err)
+	}
+}
+
+// LastMessageAt 監視データを最後に受信した時刻（未受信/切断直後はゼロ値）
+func (m *Monitor) LastMessageAt() time.Time {
+	m.lastMu.Lock()
+	defer m.lastMu.Unlock()
+	return m.lastMsgAt
 }
 
 // IsConnected 接続状態を確認
@@ -5,7 +5,7 @@ import "testing"
 func TestHandleTextMessageUpdatePayload(t *testing.T) {
 	t.Parallel()
 
-	m := &Monitor{State: NewMonitorState()}
+	m := &Monitor{State: NewMonitorState("")}
 	msg := []byte(`{
 		"type":"update",
 		"diff_percentage":12.5,
@@ -18,7 +18,7 @@ func TestHandleTextMessageUpdatePayload(t *testing.T) {
 		"total_pixels":300
 	}`)
 
-	if err := m.handleTextMessage(msg); err != nil {
+	if err := m.handleTextMessage(nil, msg); err != nil {
 		t.Fatalf("handleTextMessage returned error: %v", err)
 	}
 
@@ -46,10 +46,10 @@ func TestHandleTextMessageUpdatePayload(t *testing.T) {
 func TestHandleTextMessageMetadataPayload(t *testing.T) {
 	t.Parallel()
 
-	m := &Monitor{State: NewMonitorState()}
+	m := &Monitor{State: NewMonitorState("")}
 	msg := []byte(`{"type":"metadata","total_pixels":12345}`)
 
-	if err := m.handleTextMessage(msg); err != nil {
+	if err := m.handleTextMessage(nil, msg); err != nil {
 		t.Fatalf("handleTextMessage returned error: %v", err)
 	}
 
@@ -68,10 +68,10 @@ func TestHandleTextMessageMetadataPayload(t *testing.T) {
 func TestHandleTextMessageErrorPayload(t *testing.T) {
 	t.Parallel()
 
-	m := &Monitor{State: NewMonitorState()}
+	m := &Monitor{State: NewMonitorState("")}
 	msg := []byte(`{"type":"error","message":"server down"}`)
 
-	if err := m.handleTextMessage(msg); err != nil {
+	if err := m.handleTextMessage(nil, msg); err != nil {
 		t.Fatalf("handleTextMessage returned error: %v", err)
 	}
 	if data := m.State.GetLatestData(); data != nil {
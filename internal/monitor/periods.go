@@ -0,0 +1,54 @@
+package monitor
+
+import "time"
+
+// dailySummaryRetentionDays 日次サマリを保持する日数（月次レポートで前月分を集計できる長さ）
+const dailySummaryRetentionDays = 40
+
+var jstLocation = time.FixedZone("JST", 9*3600)
+
+// PeakCapture 期間中で差分率が最大だったときの画像
+type PeakCapture struct {
+	Diff      float64
+	At        time.Time
+	LiveImage []byte
+	DiffImage []byte
+}
+
+// DayPeriodKey t を含む JST の日のキー（"2006-01-02"。日次サマリと同じ）
+func DayPeriodKey(t time.Time) string {
+	return t.In(jstLocation).Format("2006-01-02")
+}
+
+// WeekPeriodKey t を含む JST の週（月曜始まり）のキー（"week:" + 月曜の日付）
+func WeekPeriodKey(t time.Time) string {
+	jst := t.In(jstLocation)
+	offset := (int(jst.Weekday()) + 6) % 7
+	monday := time.Date(jst.Year(), jst.Month(), jst.Day()-offset, 0, 0, 0, 0, jstLocation)
+	return "week:" + monday.Format("2006-01-02")
+}
+
+// MonthPeriodKey t を含む JST の月のキー（"month:2006-01"）
+func MonthPeriodKey(t time.Time) string {
+	return "month:" + t.In(jstLocation).Format("2006-01")
+}
+
+// peakPeriodKeys t が属する日・週・月のキー
+func peakPeriodKeys(t time.Time) []string {
+	return []string{DayPeriodKey(t), WeekPeriodKey(t), MonthPeriodKey(t)}
+}
+
+// retainedPeakKeys t の時点で保持するピークのキー（現在と直前の日・週・月）。
+// 直前の期間はレポート投稿まで残しておく。
+func retainedPeakKeys(t time.Time) map[string]bool {
+	jst := t.In(jstLocation)
+	day := time.Date(jst.Year(), jst.Month(), jst.Day(), 0, 0, 0, 0, jstLocation)
+	keys := make(map[string]bool, 6)
+	for _, key := range peakPeriodKeys(day) {
+		keys[key] = true
+	}
+	keys[DayPeriodKey(day.AddDate(0, 0, -1))] = true
+	keys[WeekPeriodKey(day.AddDate(0, 0, -7))] = true
+	keys[MonthPeriodKey(time.Date(jst.Year(), jst.Month()-1, 1, 0, 0, 0, 0, jstLocation))] = true
+	return keys
+}
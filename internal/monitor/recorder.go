@@ -0,0 +1,172 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// captureFilePrefix キャプチャファイル名の接頭辞
+	captureFilePrefix = "monitor_capture"
+	// captureFlushInterval バッファをディスクへ書き出す間隔
+	captureFlushInterval = 5 * time.Second
+)
+
+// CaptureFrame キャプチャファイルの1レコード（JSON Lines）。
+// テキストは受信した JSON をそのまま、バイナリは 5 バイトヘッダー込みのフレームを保持する。
+type CaptureFrame struct {
+	At     time.Time       `json:"at"`
+	Type   int             `json:"type"`
+	Text   json.RawMessage `json:"text,omitempty"`
+	Binary []byte          `json:"binary,omitempty"`
+}
+
+// Recorder 監視 WebSocket の受信フレームをキャプチャファイルへ記録する
+type Recorder struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	w       *bufio.Writer
+	frames  int
+	lastErr error
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewRecorder dir 配下にタイムスタンプ付きのキャプチャファイルを作成する
+func NewRecorder(dir, artworkID string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("%s_%s_%s.jsonl", captureFilePrefix, artworkID, time.Now().UTC().Format("20060102-150405"))
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		path: path,
+		file: f,
+		w:    bufio.NewWriterSize(f, 256*1024),
+		done: make(chan struct{}),
+	}
+	go r.flushLoop()
+	return r, nil
+}
+
+// Path キャプチャファイルのパス
+func (r *Recorder) Path() string {
+	if r == nil {
+		return ""
+	}
+	return r.path
+}
+
+// Record 受信フレームを1件記録する。記録失敗は監視を止めないようログのみ。
+func (r *Recorder) Record(messageType int, message []byte) {
+	if r == nil {
+		return
+	}
+	frame := CaptureFrame{At: time.Now(), Type: messageType}
+	switch messageType {
+	case websocket.TextMessage:
+		if !json.Valid(message) {
+			// 不正な JSON も再現できるよう文字列として保持する
+			quoted, _ := json.Marshal(string(message))
+			frame.Text = quoted
+		} else {
+			frame.Text = append(json.RawMessage(nil), message...)
+		}
+	case websocket.BinaryMessage:
+		frame.Binary = append([]byte(nil), message...)
+	default:
+		return
+	}
+	line, err := json.Marshal(frame)
+	if err != nil {
+		log.Printf("monitor recorder: marshal failed: %v", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.w == nil {
+		return
+	}
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		if r.lastErr == nil {
+			log.Printf("monitor recorder: write failed path=%s err=%v", r.path, err)
+		}
+		r.lastErr = err
+		return
+	}
+	r.lastErr = nil
+	r.frames++
+}
+
+// Close バッファを書き出してファイルを閉じる
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	var err error
+	r.once.Do(func() {
+		close(r.done)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if flushErr := r.w.Flush(); flushErr != nil {
+			err = flushErr
+		}
+		if closeErr := r.file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		r.w = nil
+		log.Printf("monitor recorder: closed path=%s frames=%d", r.path, r.frames)
+	})
+	return err
+}
+
+func (r *Recorder) flushLoop() {
+	ticker := time.NewTicker(captureFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.mu.Lock()
+			if r.w != nil {
+				if err := r.w.Flush(); err != nil {
+					log.Printf("monitor recorder: flush failed path=%s err=%v", r.path, err)
+				}
+			}
+			r.mu.Unlock()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// decode キャプチャレコードを WebSocket の受信フレームに戻す
+func (f CaptureFrame) decode() (int, []byte, error) {
+	switch f.Type {
+	case websocket.TextMessage:
+		if len(f.Text) > 0 && f.Text[0] == '"' {
+			var raw string
+			if err := json.Unmarshal(f.Text, &raw); err != nil {
+				return 0, nil, err
+			}
+			return f.Type, []byte(raw), nil
+		}
+		return f.Type, []byte(f.Text), nil
+	case websocket.BinaryMessage:
+		return f.Type, f.Binary, nil
+	}
+	return 0, nil, fmt.Errorf("unknown capture frame type: %d", f.Type)
+}
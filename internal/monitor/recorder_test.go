@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func binaryFrame(typeID byte, payload []byte) []byte {
+	frame := make([]byte, 5+len(payload))
+	frame[0] = typeID
+	binary.LittleEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+	return frame
+}
+
+func TestRecorderReplayRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	rec, err := NewRecorder(t.TempDir(), "koukyo")
+	if err != nil {
+		t.Fatalf("NewRecorder returned error: %v", err)
+	}
+	rec.Record(websocket.TextMessage, []byte(`{"type":"update","diff_percentage":12.5,"diff_pixels":30,"total_pixels":240}`))
+	rec.Record(websocket.BinaryMessage, binaryFrame(2, []byte{0x00, 0xAA, 0xBB}))
+	rec.Record(websocket.BinaryMessage, binaryFrame(3, []byte{0xCC, 0xDD}))
+	rec.Record(websocket.TextMessage, []byte(`not json`))
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if _, err := os.Stat(rec.Path()); err != nil {
+		t.Fatalf("capture file missing: %v", err)
+	}
+
+	m := &Monitor{State: NewMonitorState("")}
+	defer m.State.StopHeatmapWorker()
+	count, err := m.Replay(context.Background(), rec.Path(), 0)
+	if err != nil {
+		t.Fatalf("Replay returned error: %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("expected 4 replayed frames, got %d", count)
+	}
+
+	data := m.State.GetLatestData()
+	if data == nil || data.DiffPercentage != 12.5 || data.DiffPixels != 30 {
+		t.Fatalf("unexpected replayed data: %+v", data)
+	}
+	images := m.GetLatestImages()
+	if images == nil || string(images.LiveImage) != "\xAA\xBB" || string(images.DiffImage) != "\xCC\xDD" {
+		t.Fatalf("unexpected replayed images: %+v", images)
+	}
+}
+
+func TestReplayStopsOnContextCancel(t *testing.T) {
+	t.Parallel()
+
+	rec, err := NewRecorder(t.TempDir(), "koukyo")
+	if err != nil {
+		t.Fatalf("NewRecorder returned error: %v", err)
+	}
+	rec.Record(websocket.TextMessage, []byte(`{"type":"update","diff_percentage":1}`))
+	rec.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m := &Monitor{State: NewMonitorState("")}
+	defer m.State.StopHeatmapWorker()
+	if _, err := m.Replay(ctx, rec.Path(), 1); err == nil {
+		t.Fatalf("expected cancellation error")
+	}
+}
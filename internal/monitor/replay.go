@@ -0,0 +1,166 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// replayMaxGap 記録の空白（監視停止中など）を再生時に詰める上限
+const replayMaxGap = 5 * time.Minute
+
+// CaptureReader キャプチャファイルを先頭から順に読む
+type CaptureReader struct {
+	file    *os.File
+	scanner *bufio.Scanner
+	line    int
+}
+
+// OpenCapture キャプチャファイルを開く
+func OpenCapture(path string) (*CaptureReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(f)
+	// 画像フレームは base64 で数百KBになるため上限を広げる
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	return &CaptureReader{file: f, scanner: scanner}, nil
+}
+
+// Next 次のフレームを返す。末尾では io.EOF。
+func (r *CaptureReader) Next() (CaptureFrame, error) {
+	for r.scanner.Scan() {
+		r.line++
+		raw := r.scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+		var frame CaptureFrame
+		if err := json.Unmarshal(raw, &frame); err != nil {
+			return CaptureFrame{}, fmt.Errorf("capture line %d: %w", r.line, err)
+		}
+		return frame, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return CaptureFrame{}, err
+	}
+	return CaptureFrame{}, io.EOF
+}
+
+// Close ファイルを閉じる
+func (r *CaptureReader) Close() error {
+	return r.file.Close()
+}
+
+// SetRecorder 受信フレームの記録先を設定する（nil で停止）
+func (m *Monitor) SetRecorder(rec *Recorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recorder = rec
+}
+
+// SetReplay WebSocket の代わりにキャプチャファイルを再生する。
+// speed は再生倍率（1 で実時間、0 以下は待機なしで流し込む）。Start より前に呼ぶ。
+func (m *Monitor) SetReplay(path string, speed float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replayPath = path
+	m.replaySpeed = speed
+}
+
+func (m *Monitor) recordFrame(messageType int, message []byte) {
+	m.mu.RLock()
+	rec := m.recorder
+	m.mu.RUnlock()
+	rec.Record(messageType, message)
+}
+
+// Replay キャプチャファイルを handleMessage に流し込む。
+// 受信時刻の間隔を speed 倍で再現し、ctx のキャンセルで中断する。
+func (m *Monitor) Replay(ctx context.Context, path string, speed float64) (int, error) {
+	return m.replay(ctx, nil, path, speed)
+}
+
+func (m *Monitor) replay(ctx context.Context, sink *SourceSink, path string, speed float64) (int, error) {
+	reader, err := OpenCapture(path)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	var prevAt time.Time
+	count := 0
+	for {
+		frame, err := reader.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+
+		if speed > 0 && !prevAt.IsZero() && frame.At.After(prevAt) {
+			gap := frame.At.Sub(prevAt)
+			if gap > replayMaxGap {
+				gap = replayMaxGap
+			}
+			select {
+			case <-ctx.Done():
+				return count, ctx.Err()
+			case <-time.After(time.Duration(float64(gap) / speed)):
+			}
+		} else {
+			select {
+			case <-ctx.Done():
+				return count, ctx.Err()
+			default:
+			}
+		}
+		prevAt = frame.At
+
+		messageType, message, err := frame.decode()
+		if err != nil {
+			log.Printf("monitor replay: skip frame line=%d err=%v", reader.line, err)
+			continue
+		}
+		sink.Healthy()
+		m.lastMu.Lock()
+		m.lastMsgAt = time.Now()
+		m.lastMu.Unlock()
+		if err := m.handleMessage(sink, messageType, message); err != nil {
+			log.Printf("monitor replay: message handling error line=%d err=%v", reader.line, err)
+		}
+		count++
+	}
+}
+
+// replaySource キャプチャファイルを再生する取得元（完了後は最終状態を保持して待機する）
+type replaySource struct {
+	m *Monitor
+}
+
+func (s *replaySource) Name() string { return "replay" }
+
+func (s *replaySource) Run(ctx context.Context, sink *SourceSink) error {
+	m := s.m
+	m.mu.RLock()
+	path, speed := m.replayPath, m.replaySpeed
+	m.mu.RUnlock()
+
+	log.Printf("Monitor %s: replaying capture %s (speed=%.2f)", m.artwork.ID, path, speed)
+	sink.Healthy()
+	count, err := m.replay(ctx, sink, path, speed)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Monitor %s: replay stopped after %d frames: %v", m.artwork.ID, count, err)
+	} else {
+		log.Printf("Monitor %s: replay finished (%d frames)", m.artwork.ID, count)
+	}
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"strings"
+	"sync"
+)
+
+// Set 複数アートワークの Monitor を登録順に保持する。
+// 先頭に登録された Monitor を primary として扱う。
+type Set struct {
+	mu       sync.RWMutex
+	monitors []*Monitor
+	byID     map[string]*Monitor
+}
+
+// NewSet 空のSetを作成
+func NewSet() *Set {
+	return &Set{byID: make(map[string]*Monitor)}
+}
+
+// Add Monitorを登録する（同じIDは後勝ちせず無視）
+func (s *Set) Add(m *Monitor) {
+	if s == nil || m == nil {
+		return
+	}
+	id := m.Artwork().ID
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.byID[id]; exists {
+		return
+	}
+	s.monitors = append(s.monitors, m)
+	s.byID[id] = m
+}
+
+// Get IDでMonitorを取得（空IDはprimary）
+func (s *Set) Get(id string) (*Monitor, bool) {
+	if s == nil {
+		return nil, false
+	}
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		m := s.Primary()
+		return m, m != nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, ok := s.byID[id]
+	return m, ok
+}
+
+// Primary 先頭のMonitorを返す
+func (s *Set) Primary() *Monitor {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.monitors) == 0 {
+		return nil
+	}
+	return s.monitors[0]
+}
+
+// All 登録順のMonitor一覧を返す
+func (s *Set) All() []*Monitor {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*Monitor(nil), s.monitors...)
+}
+
+// Len 登録数
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.monitors)
+}
@@ -0,0 +1,293 @@
+package monitor
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// sourceSuperviseInterval フェイルオーバー判定の間隔
+const sourceSuperviseInterval = 1 * time.Second
+
+// Source 監視データ（MonitorData とライブ/差分画像）の取得元。
+// Run は ctx が終了するまでブロックし、取得結果と健全性を sink へ報告する。
+type Source interface {
+	Name() string
+	Run(ctx context.Context, sink *SourceSink) error
+}
+
+// SourceConfig Monitor に登録する取得元と起動条件。登録順が優先度（先頭が最優先）。
+type SourceConfig struct {
+	Source Source
+	// ActivateAfter 上位の取得元がすべてこの時間以上停止していたら起動する。
+	// 0 の場合は常時起動（WebSocket など自前で再接続する取得元向け）。
+	ActivateAfter time.Duration
+}
+
+// sourceEntry 登録済み取得元の実行状態
+type sourceEntry struct {
+	cfg  SourceConfig
+	sink *SourceSink
+
+	running          bool
+	gen              int
+	cancel           context.CancelFunc
+	unavailableSince time.Time
+	healthyOnce      bool
+}
+
+func (e *sourceEntry) alwaysOn() bool {
+	return e.cfg.ActivateAfter <= 0
+}
+
+// SourceSink 取得元から Monitor への報告窓口。
+// 上位の取得元が健全な間、下位からの更新は MonitorState に反映されない。
+type SourceSink struct {
+	m     *Monitor
+	entry *sourceEntry
+}
+
+// Healthy 取得元が正常にデータを取得できていることを報告する
+func (s *SourceSink) Healthy() {
+	if s == nil {
+		return
+	}
+	s.m.sourcesMu.Lock()
+	s.entry.unavailableSince = time.Time{}
+	s.entry.healthyOnce = true
+	s.m.sourcesMu.Unlock()
+}
+
+// Unavailable 取得元が停止/失敗していることを報告する（停止開始時刻は保持）
+func (s *SourceSink) Unavailable(now time.Time) {
+	if s == nil {
+		return
+	}
+	s.m.sourcesMu.Lock()
+	if s.entry.unavailableSince.IsZero() {
+		s.entry.unavailableSince = now
+	}
+	s.m.sourcesMu.Unlock()
+}
+
+// UnavailableFor 取得元が d 以上停止しているか
+func (s *SourceSink) UnavailableFor(d time.Duration) bool {
+	if s == nil || d <= 0 {
+		return false
+	}
+	s.m.sourcesMu.Lock()
+	since := s.entry.unavailableSince
+	s.m.sourcesMu.Unlock()
+	return !since.IsZero() && time.Since(since) >= d
+}
+
+// Authoritative この取得元の更新が現在 MonitorState に反映されるか
+func (s *SourceSink) Authoritative() bool {
+	if s == nil {
+		return true
+	}
+	s.m.sourcesMu.Lock()
+	defer s.m.sourcesMu.Unlock()
+	return s.m.authoritativeLocked(s.entry)
+}
+
+// UpdateData 監視データを反映する
+func (s *SourceSink) UpdateData(data *MonitorData) {
+	if data == nil || !s.Authoritative() {
+		return
+	}
+	s.m.applyData(data)
+}
+
+// UpdateImages ライブ/差分画像を反映する（nil の画像は前回値を維持）
+func (s *SourceSink) UpdateImages(live, diff []byte) {
+	if (len(live) == 0 && len(diff) == 0) || !s.Authoritative() {
+		return
+	}
+	s.m.applyImages(live, diff)
+}
+
+// AddSource 取得元を末尾（最低優先度）に追加する。Start 後の追加も可。
+func (m *Monitor) AddSource(cfg SourceConfig) {
+	if m == nil || cfg.Source == nil {
+		return
+	}
+	m.sourcesMu.Lock()
+	entry := m.newSourceEntry(cfg)
+	m.sources = append(m.sources, entry)
+	started := m.sourcesStarted
+	if started && entry.alwaysOn() {
+		m.startSourceLocked(entry)
+	}
+	m.sourcesMu.Unlock()
+}
+
+func (m *Monitor) newSourceEntry(cfg SourceConfig) *sourceEntry {
+	entry := &sourceEntry{cfg: cfg}
+	entry.sink = &SourceSink{m: m, entry: entry}
+	return entry
+}
+
+// ActiveSourceName 現在 MonitorState を更新している取得元の名前（無ければ空）
+func (m *Monitor) ActiveSourceName() string {
+	if m == nil {
+		return ""
+	}
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	for _, e := range m.sources {
+		if e.running && e.healthyOnce && e.unavailableSince.IsZero() {
+			return e.cfg.Source.Name()
+		}
+	}
+	return ""
+}
+
+// authoritativeLocked 上位に健全な取得元が無ければ true。sourcesMu を保持して呼ぶ。
+func (m *Monitor) authoritativeLocked(target *sourceEntry) bool {
+	for _, e := range m.sources {
+		if e == target {
+			return true
+		}
+		if e.running && e.unavailableSince.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Monitor) startSources() {
+	m.sourcesMu.Lock()
+	m.sourcesStarted = true
+	for _, e := range m.sources {
+		if e.alwaysOn() {
+			m.startSourceLocked(e)
+		}
+	}
+	m.sourcesMu.Unlock()
+	go m.runLoop("sourceSupervisor", m.superviseSources)
+}
+
+// startSourceLocked sourcesMu を保持して呼ぶ
+func (m *Monitor) startSourceLocked(e *sourceEntry) {
+	if e.running {
+		return
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	e.gen++
+	gen := e.gen
+	e.running = true
+	e.cancel = cancel
+	e.healthyOnce = false
+	if !e.alwaysOn() {
+		// オンデマンドの取得元は最初の成功までは停止扱い
+		e.unavailableSince = time.Now()
+	}
+	name := e.cfg.Source.Name()
+	sink := e.sink
+	log.Printf("Monitor %s: source %s started", m.artwork.ID, name)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("PANIC in source %s: %v", name, r)
+			}
+			m.sourcesMu.Lock()
+			if e.gen == gen {
+				e.running = false
+				e.cancel = nil
+			}
+			m.sourcesMu.Unlock()
+		}()
+		if err := e.cfg.Source.Run(ctx, sink); err != nil && ctx.Err() == nil {
+			log.Printf("Monitor %s: source %s exited: %v", m.artwork.ID, name, err)
+		}
+	}()
+}
+
+// superviseSources オンデマンド取得元の起動/停止を優先度順に判定する
+func (m *Monitor) superviseSources() {
+	ticker := time.NewTicker(sourceSuperviseInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.superviseOnce(time.Now())
+		}
+	}
+}
+
+func (m *Monitor) superviseOnce(now time.Time) {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	for i, e := range m.sources {
+		if e.alwaysOn() {
+			if !e.running {
+				m.startSourceLocked(e)
+			}
+			continue
+		}
+		upstreamDown := true
+		upstreamHealthy := false
+		for _, up := range m.sources[:i] {
+			if up.running && up.unavailableSince.IsZero() {
+				upstreamHealthy = true
+				upstreamDown = false
+				break
+			}
+			if up.running && now.Sub(up.unavailableSince) < e.cfg.ActivateAfter {
+				upstreamDown = false
+			}
+		}
+		switch {
+		case !e.running && upstreamDown:
+			m.startSourceLocked(e)
+		case e.running && upstreamHealthy:
+			log.Printf("Monitor %s: source %s stopped (higher priority source recovered)", m.artwork.ID, e.cfg.Source.Name())
+			e.cancel()
+			e.running = false
+			e.cancel = nil
+		}
+	}
+}
+
+// SourceStatus 取得元の稼働状況（メトリクス/診断用）
+type SourceStatus struct {
+	Name    string
+	Running bool
+	Healthy bool
+	Active  bool // 現在 MonitorState を更新している取得元
+}
+
+// SourceStatuses 登録済み取得元の稼働状況を優先度順に返す
+func (m *Monitor) SourceStatuses() []SourceStatus {
+	if m == nil {
+		return nil
+	}
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	out := make([]SourceStatus, 0, len(m.sources))
+	activeFound := false
+	for _, e := range m.sources {
+		healthy := e.running && e.healthyOnce && e.unavailableSince.IsZero()
+		st := SourceStatus{Name: e.cfg.Source.Name(), Running: e.running, Healthy: healthy}
+		if healthy && !activeFound {
+			st.Active = true
+			activeFound = true
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// sourcesState テスト/診断用に各取得元の稼働状況を返す
+func (m *Monitor) sourcesState() map[string]bool {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	out := make(map[string]bool, len(m.sources))
+	for _, e := range m.sources {
+		out[e.cfg.Source.Name()] = e.running
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// pollActivateAfter 上位（WebSocket）がこの時間以上停止したらHTTPポーリングを起動する
+	pollActivateAfter = 1 * time.Minute
+	// pollMaxInterval ポーリング失敗時のバックオフ上限
+	pollMaxInterval = 5 * time.Minute
+)
+
+// webSocketSource 監視サーバーの WebSocket から受信する常時起動の取得元。
+// 再接続は receiveLoop が自前で行う。
+type webSocketSource struct {
+	m *Monitor
+}
+
+func (s *webSocketSource) Name() string { return "websocket" }
+
+func (s *webSocketSource) Run(ctx context.Context, sink *SourceSink) error {
+	m := s.m
+	m.mu.Lock()
+	m.wsSink = sink
+	m.mu.Unlock()
+
+	if err := m.Connect(); err != nil {
+		log.Printf("Initial WebSocket connect failed: %v; starting in degraded mode", err)
+		m.markWSUnavailable(time.Now())
+	}
+
+	go m.runLoop("pingLoop", m.pingLoop)
+	go m.runLoop("keepaliveLoop", m.keepaliveLoop)
+	go m.runLoop("idleWatchLoop", m.idleWatchLoop)
+	m.runLoop("receiveLoop", m.receiveLoop)
+	<-ctx.Done()
+	return nil
+}
+
+// pollSource MONITOR_POLL_URL の JSON を定期取得するオンデマンドの取得元
+type pollSource struct {
+	m *Monitor
+}
+
+func (s *pollSource) Name() string { return "poll" }
+
+func (s *pollSource) Run(ctx context.Context, sink *SourceSink) error {
+	attempts := 0
+	for {
+		delay := s.m.pollBaseInterval
+		data, err := s.m.fetchPolledData(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			sink.Unavailable(time.Now())
+			log.Printf("Monitor poll fallback failed: %v", err)
+			delay = pollBackoffDelay(s.m.pollBaseInterval, attempts)
+			if attempts < 5 {
+				attempts++
+			}
+		} else {
+			attempts = 0
+			sink.Healthy()
+			sink.UpdateData(data)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+func pollBackoffDelay(base time.Duration, attempt int) time.Duration {
+	if attempt > 5 {
+		attempt = 5
+	}
+	delay := base * time.Duration(1<<uint(attempt))
+	if delay > pollMaxInterval {
+		delay = pollMaxInterval
+	}
+	return delay
+}
@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	name    string
+	started chan *SourceSink
+	stopped chan struct{}
+}
+
+func newFakeSource(name string) *fakeSource {
+	return &fakeSource{name: name, started: make(chan *SourceSink, 4), stopped: make(chan struct{}, 4)}
+}
+
+func (f *fakeSource) Name() string { return f.name }
+
+func (f *fakeSource) Run(ctx context.Context, sink *SourceSink) error {
+	f.started <- sink
+	<-ctx.Done()
+	f.stopped <- struct{}{}
+	return nil
+}
+
+func waitStarted(t *testing.T, f *fakeSource) *SourceSink {
+	t.Helper()
+	select {
+	case sink := <-f.started:
+		return sink
+	case <-time.After(2 * time.Second):
+		t.Fatalf("source %s did not start", f.name)
+	}
+	return nil
+}
+
+func newSourceTestMonitor() *Monitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Monitor{State: NewMonitorState(""), ctx: ctx, cancel: cancel}
+}
+
+func TestSourceFailoverByPriority(t *testing.T) {
+	t.Parallel()
+
+	m := newSourceTestMonitor()
+	defer m.cancel()
+	defer m.State.StopHeatmapWorker()
+
+	primary := newFakeSource("primary")
+	fallback := newFakeSource("fallback")
+	m.AddSource(SourceConfig{Source: primary})
+	m.AddSource(SourceConfig{Source: fallback, ActivateAfter: time.Minute})
+
+	now := time.Now()
+	m.superviseOnce(now)
+	primarySink := waitStarted(t, primary)
+	primarySink.Healthy()
+	if state := m.sourcesState(); !state["primary"] || state["fallback"] {
+		t.Fatalf("unexpected sources state: %+v", state)
+	}
+
+	// 上位の停止が ActivateAfter 未満ならフォールバックは起動しない
+	primarySink.Unavailable(now)
+	m.superviseOnce(now.Add(30 * time.Second))
+	if m.sourcesState()["fallback"] {
+		t.Fatal("fallback started before ActivateAfter elapsed")
+	}
+
+	m.superviseOnce(now.Add(time.Minute))
+	fallbackSink := waitStarted(t, fallback)
+	if m.ActiveSourceName() != "" {
+		t.Fatalf("expected no active source before fallback succeeds, got %q", m.ActiveSourceName())
+	}
+	fallbackSink.UpdateData(&MonitorData{Type: "fallback", DiffPercentage: 3, DiffPixels: 6, TotalPixels: 200})
+	fallbackSink.Healthy()
+	if got := m.ActiveSourceName(); got != "fallback" {
+		t.Fatalf("expected fallback to be active, got %q", got)
+	}
+	if data := m.State.GetLatestData(); data == nil || data.Type != "fallback" {
+		t.Fatalf("fallback update not applied: %+v", data)
+	}
+
+	// 上位が復帰したら下位の更新は反映されず、下位は停止する
+	primarySink.Healthy()
+	fallbackSink.UpdateData(&MonitorData{Type: "stale", DiffPercentage: 9, DiffPixels: 18, TotalPixels: 200})
+	if data := m.State.GetLatestData(); data == nil || data.Type != "fallback" {
+		t.Fatalf("non-authoritative update applied: %+v", data)
+	}
+	m.superviseOnce(now.Add(2 * time.Minute))
+	select {
+	case <-fallback.stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("fallback was not stopped after primary recovered")
+	}
+	if got := m.ActiveSourceName(); got != "primary" {
+		t.Fatalf("expected primary to be active, got %q", got)
+	}
+}
+
+func TestSourceWithoutUpstreamStartsImmediately(t *testing.T) {
+	t.Parallel()
+
+	m := newSourceTestMonitor()
+	defer m.cancel()
+	defer m.State.StopHeatmapWorker()
+
+	only := newFakeSource("standalone")
+	m.AddSource(SourceConfig{Source: only, ActivateAfter: time.Minute})
+	m.superviseOnce(time.Now())
+	sink := waitStarted(t, only)
+
+	sink.UpdateImages([]byte{0x01}, nil)
+	sink.UpdateImages(nil, []byte{0x02})
+	images := m.GetLatestImages()
+	if images == nil || string(images.LiveImage) != "\x01" || string(images.DiffImage) != "\x02" {
+		t.Fatalf("images were not merged: %+v", images)
+	}
+}
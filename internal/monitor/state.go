@@ -5,6 +5,7 @@ import (
 	"container/ring"
 	"context"
 	"image/png"
+	"log"
 	"math"
 	"sort"
 	"sync"
@@ -67,18 +68,18 @@ type MonitorState struct {
 	HeatmapCounts  []uint32
 	HeatmapSourceW int
 	HeatmapSourceH int
-	// Daily peak tracking (JST)
-	DailyPeakDate      string
-	DailyPeakDiff      float64
-	DailyPeakAt        time.Time
-	DailyPeakLiveImage []byte
-	DailyPeakDiffImage []byte
+	// Peak tracking per JST day/week/month (current and previous period of each)
+	PeriodPeaks map[string]PeakCapture
+	peakDate    string
 	// Daily diff summary tracking (JST)
-	DailySummaries map[string]DailySummary
-	heatmapQueue        chan []byte
-	heatmapStopOnce     sync.Once
-	heatmapCancelFunc   context.CancelFunc
-	mu                  sync.RWMutex
+	DailySummaries    map[string]DailySummary
+	heatmapQueue      chan []byte
+	heatmapStopOnce   sync.Once
+	heatmapCancelFunc context.CancelFunc
+	// Snapshot persistence (空パスならメモリのみ)
+	snapshotPath  string
+	snapshotDirty bool
+	mu            sync.RWMutex
 }
 
 // DiffRecord 差分履歴のレコード
@@ -118,25 +119,37 @@ type ReferencePixels struct {
 	Background    int
 }
 
-// NewMonitorState 新しい監視状態を作成
-func NewMonitorState() *MonitorState {
+// NewMonitorState 新しい監視状態を作成する。
+// snapshotPath を指定した場合は既存スナップショットから復元し、定期的にチェックポイントを書き出す。
+func NewMonitorState(snapshotPath string) *MonitorState {
 	ctx, cancel := context.WithCancel(context.Background())
 	ms := &MonitorState{
-		DiffHistory:       ring.New(historyLimit),
+		DiffHistory:         ring.New(historyLimit),
 		WeightedDiffHistory: ring.New(historyLimit),
-		PowerSaveMode:     false,
-		heatmapQueue:      make(chan []byte, 1),
-		heatmapCancelFunc: cancel,
-		DailySummaries:    make(map[string]DailySummary),
+		PowerSaveMode:       false,
+		heatmapQueue:        make(chan []byte, 1),
+		heatmapCancelFunc:   cancel,
+		DailySummaries:      make(map[string]DailySummary),
+		PeriodPeaks:         make(map[string]PeakCapture),
+		snapshotPath:        snapshotPath,
+	}
+	if snapshotPath != "" {
+		if err := ms.restoreSnapshot(time.Now()); err != nil {
+			log.Printf("monitor state: restore failed path=%s err=%v", snapshotPath, err)
+		}
+		ms.startSnapshotWorker(ctx)
 	}
 	ms.startHeatmapWorker(ctx)
 	return ms
 }
 
-// StopHeatmapWorker ヒートマップワーカーを停止する（Monitor.Stop から呼ぶ）
+// StopHeatmapWorker ヒートマップワーカーとチェックポイントを停止し、最終スナップショットを書き出す（Monitor.Stop から呼ぶ）
 func (ms *MonitorState) StopHeatmapWorker() {
 	ms.heatmapStopOnce.Do(func() {
 		ms.heatmapCancelFunc()
+		if err := ms.SaveSnapshot(); err != nil {
+			log.Printf("monitor state: final checkpoint failed path=%s err=%v", ms.snapshotPath, err)
+		}
 	})
 }
 
@@ -147,6 +160,7 @@ func (ms *MonitorState) UpdateData(data *MonitorData) {
 
 	data.Timestamp = time.Now()
 	ms.LatestData = data
+	ms.snapshotDirty = true
 	ms.updateDailySummaryLocked(data)
 
 	// 基準ピクセル数の更新
@@ -233,30 +247,15 @@ func (ms *MonitorState) UpdateData(data *MonitorData) {
 func (ms *MonitorState) UpdateImages(images *ImageData) {
 	ms.mu.Lock()
 	ms.LatestImages = images
+	ms.snapshotDirty = true
 
-	// Daily peak tracking (JST)
+	// Peak tracking (JST day/week/month)
 	if images != nil && len(images.DiffImage) > 0 && ms.LatestData != nil {
-		jst := time.FixedZone("JST", 9*3600)
 		ts := ms.LatestData.Timestamp
 		if ts.IsZero() {
 			ts = time.Now()
 		}
-		dateKey := ts.In(jst).Format("2006-01-02")
-		if dateKey != ms.DailyPeakDate {
-			ms.DailyPeakDate = dateKey
-			ms.DailyPeakDiff = 0
-			ms.DailyPeakAt = time.Time{}
-			ms.DailyPeakLiveImage = nil
-			ms.DailyPeakDiffImage = nil
-		}
-		if ms.DailyPeakDiffImage == nil || ms.LatestData.DiffPercentage >= ms.DailyPeakDiff {
-			liveCopy := append([]byte(nil), images.LiveImage...)
-			diffCopy := append([]byte(nil), images.DiffImage...)
-			ms.DailyPeakLiveImage = liveCopy
-			ms.DailyPeakDiffImage = diffCopy
-			ms.DailyPeakDiff = ms.LatestData.DiffPercentage
-			ms.DailyPeakAt = ts
-		}
+		ms.updatePeriodPeaksLocked(ts, ms.LatestData.DiffPercentage, images)
 	}
 
 	// タイムラプス中で、diff画像があり、一定間隔ごとにフレームを追加
@@ -332,6 +331,7 @@ func (ms *MonitorState) updateHeatmap(diffImage []byte) error {
 
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
+	ms.snapshotDirty = true
 
 	if ms.HeatmapGridW == 0 || ms.HeatmapGridW*ms.HeatmapGridH == 0 || w != ms.HeatmapSourceW || h != ms.HeatmapSourceH {
 		gridW := w
@@ -535,25 +535,56 @@ func (ms *MonitorState) GetHeatmapSnapshot() (counts []uint32, gridW, gridH, src
 
 // GetDailyPeakDiffImage returns the diff image captured at the daily peak (JST).
 func (ms *MonitorState) GetDailyPeakDiffImage(dateKey string) (img []byte, peakAt time.Time, peakValue float64, ok bool) {
-	ms.mu.RLock()
-	defer ms.mu.RUnlock()
-	if ms.DailyPeakDate != dateKey || len(ms.DailyPeakDiffImage) == 0 {
-		return nil, time.Time{}, 0, false
-	}
-	cp := append([]byte(nil), ms.DailyPeakDiffImage...)
-	return cp, ms.DailyPeakAt, ms.DailyPeakDiff, true
+	_, img, peakAt, peakValue, ok = ms.GetPeakImages(dateKey)
+	return img, peakAt, peakValue, ok
 }
 
 // GetDailyPeakImages returns live+diff images captured at the daily peak (JST).
 func (ms *MonitorState) GetDailyPeakImages(dateKey string) (live []byte, diff []byte, peakAt time.Time, peakValue float64, ok bool) {
+	return ms.GetPeakImages(dateKey)
+}
+
+// GetPeakImages returns live+diff images captured at the peak of the period
+// (key from DayPeriodKey / WeekPeriodKey / MonthPeriodKey).
+func (ms *MonitorState) GetPeakImages(periodKey string) (live []byte, diff []byte, peakAt time.Time, peakValue float64, ok bool) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
-	if ms.DailyPeakDate != dateKey || len(ms.DailyPeakDiffImage) == 0 {
+	peak, found := ms.PeriodPeaks[periodKey]
+	if !found || len(peak.DiffImage) == 0 {
 		return nil, nil, time.Time{}, 0, false
 	}
-	liveCopy := append([]byte(nil), ms.DailyPeakLiveImage...)
-	diffCopy := append([]byte(nil), ms.DailyPeakDiffImage...)
-	return liveCopy, diffCopy, ms.DailyPeakAt, ms.DailyPeakDiff, true
+	liveCopy := append([]byte(nil), peak.LiveImage...)
+	diffCopy := append([]byte(nil), peak.DiffImage...)
+	return liveCopy, diffCopy, peak.At, peak.Diff, true
+}
+
+// updatePeriodPeaksLocked assumes ms.mu is already locked.
+func (ms *MonitorState) updatePeriodPeaksLocked(ts time.Time, diff float64, images *ImageData) {
+	if day := DayPeriodKey(ts); day != ms.peakDate {
+		ms.peakDate = day
+		retained := retainedPeakKeys(ts)
+		for key := range ms.PeriodPeaks {
+			if !retained[key] {
+				delete(ms.PeriodPeaks, key)
+			}
+		}
+	}
+	var capture *PeakCapture
+	for _, key := range peakPeriodKeys(ts) {
+		if peak, ok := ms.PeriodPeaks[key]; ok && len(peak.DiffImage) > 0 && diff < peak.Diff {
+			continue
+		}
+		if capture == nil {
+			// 日・週・月で同じ画像を共有する（書き換えはしない）
+			capture = &PeakCapture{
+				Diff:      diff,
+				At:        ts,
+				LiveImage: append([]byte(nil), images.LiveImage...),
+				DiffImage: append([]byte(nil), images.DiffImage...),
+			}
+		}
+		ms.PeriodPeaks[key] = *capture
+	}
 }
 
 // GetDailySummary returns aggregate diff summary for the given JST date key.
@@ -564,6 +595,18 @@ func (ms *MonitorState) GetDailySummary(dateKey string) (summary DailySummary, o
 	return summary, ok
 }
 
+// GetDailySummaryDates returns the JST date keys that have a summary, newest first.
+func (ms *MonitorState) GetDailySummaryDates() []string {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	dates := make([]string, 0, len(ms.DailySummaries))
+	for date := range ms.DailySummaries {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	return dates
+}
+
 func (ms *MonitorState) updateDailySummaryLocked(data *MonitorData) {
 	if data == nil || data.Timestamp.IsZero() {
 		return
@@ -579,8 +622,8 @@ func (ms *MonitorState) updateDailySummaryLocked(data *MonitorData) {
 	}
 	ms.DailySummaries[dateKey] = summary
 
-	// Keep memory bounded: preserve only recent 7 JST days.
-	cutoff := data.Timestamp.In(jst).AddDate(0, 0, -7)
+	// Keep memory bounded: preserve only recent JST days needed for monthly reports.
+	cutoff := data.Timestamp.In(jst).AddDate(0, 0, -dailySummaryRetentionDays)
 	for key := range ms.DailySummaries {
 		day, err := time.ParseInLocation("2006-01-02", key, jst)
 		if err != nil || day.Before(cutoff) {
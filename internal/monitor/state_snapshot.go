@@ -0,0 +1,291 @@
+package monitor
+
+import (
+	"container/ring"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"Koukyo_discord_bot/internal/utils"
+)
+
+const (
+	// StateSnapshotFileName 監視状態スナップショットのファイル名（アートワークのデータディレクトリ直下）
+	StateSnapshotFileName = "monitor_state.json"
+	// stateSnapshotVersion スナップショット形式のバージョン
+	stateSnapshotVersion = 1
+	// stateSnapshotInterval 定期チェックポイントの間隔
+	stateSnapshotInterval = 1 * time.Minute
+	// stateSnapshotRetention 差分履歴・日次データを復元する最大期間
+	stateSnapshotRetention = 7 * 24 * time.Hour
+)
+
+// stateSnapshot 再起動をまたいで保持する MonitorState の永続化形式
+type stateSnapshot struct {
+	Version             int                     `json:"version"`
+	SavedAt             time.Time               `json:"saved_at"`
+	DiffHistory         []snapshotDiffRecord    `json:"diff_history"`
+	WeightedDiffHistory []snapshotDiffRecord    `json:"weighted_diff_history"`
+	DailySummaries      map[string]DailySummary `json:"daily_summaries,omitempty"`
+	Heatmap             *snapshotHeatmap        `json:"heatmap,omitempty"`
+	PeriodPeaks         map[string]snapshotPeak `json:"period_peaks,omitempty"`
+	DailyPeak           *snapshotDailyPeak      `json:"daily_peak,omitempty"` // 旧形式（読み込みのみ）
+	LastTimelapseFrames []snapshotTimelapse     `json:"last_timelapse_frames,omitempty"`
+}
+
+type snapshotDiffRecord struct {
+	Timestamp  time.Time `json:"t"`
+	Percentage float64   `json:"p"`
+}
+
+type snapshotHeatmap struct {
+	GridW   int      `json:"grid_w"`
+	GridH   int      `json:"grid_h"`
+	SourceW int      `json:"source_w"`
+	SourceH int      `json:"source_h"`
+	Counts  []uint32 `json:"counts"`
+}
+
+type snapshotDailyPeak struct {
+	Date      string    `json:"date"`
+	Diff      float64   `json:"diff"`
+	At        time.Time `json:"at"`
+	LiveImage []byte    `json:"live_image,omitempty"`
+	DiffImage []byte    `json:"diff_image,omitempty"`
+}
+
+type snapshotPeak struct {
+	Diff      float64   `json:"diff"`
+	At        time.Time `json:"at"`
+	LiveImage []byte    `json:"live_image,omitempty"`
+	DiffImage []byte    `json:"diff_image,omitempty"`
+}
+
+type snapshotTimelapse struct {
+	Timestamp time.Time `json:"timestamp"`
+	DiffPNG   []byte    `json:"diff_png"`
+	LivePNG   []byte    `json:"live_png,omitempty"`
+}
+
+// SaveSnapshot 現在の監視状態をスナップショットファイルへ書き出す（変更が無ければ何もしない）
+func (ms *MonitorState) SaveSnapshot() error {
+	if ms == nil || ms.snapshotPath == "" {
+		return nil
+	}
+	ms.mu.Lock()
+	if !ms.snapshotDirty {
+		ms.mu.Unlock()
+		return nil
+	}
+	snap := ms.buildSnapshotLocked(time.Now())
+	ms.snapshotDirty = false
+	ms.mu.Unlock()
+
+	payload, err := json.Marshal(snap)
+	if err != nil {
+		ms.markSnapshotDirty()
+		return fmt.Errorf("marshal monitor state: %w", err)
+	}
+	if err := utils.WriteFileAtomic(ms.snapshotPath, payload); err != nil {
+		ms.markSnapshotDirty()
+		return fmt.Errorf("write monitor state: %w", err)
+	}
+	return nil
+}
+
+func (ms *MonitorState) markSnapshotDirty() {
+	ms.mu.Lock()
+	ms.snapshotDirty = true
+	ms.mu.Unlock()
+}
+
+func (ms *MonitorState) startSnapshotWorker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(stateSnapshotInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := ms.SaveSnapshot(); err != nil {
+					log.Printf("monitor state: checkpoint failed path=%s err=%v", ms.snapshotPath, err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// buildSnapshotLocked assumes ms.mu is already locked.
+func (ms *MonitorState) buildSnapshotLocked(now time.Time) *stateSnapshot {
+	snap := &stateSnapshot{
+		Version:             stateSnapshotVersion,
+		SavedAt:             now,
+		DiffHistory:         toSnapshotRecords(ms.collectDiffHistoryLocked(false)),
+		WeightedDiffHistory: toSnapshotRecords(ms.collectDiffHistoryLocked(true)),
+	}
+	if len(ms.DailySummaries) > 0 {
+		snap.DailySummaries = make(map[string]DailySummary, len(ms.DailySummaries))
+		for k, v := range ms.DailySummaries {
+			snap.DailySummaries[k] = v
+		}
+	}
+	if len(ms.HeatmapCounts) > 0 && ms.HeatmapGridW > 0 && ms.HeatmapGridH > 0 {
+		snap.Heatmap = &snapshotHeatmap{
+			GridW:   ms.HeatmapGridW,
+			GridH:   ms.HeatmapGridH,
+			SourceW: ms.HeatmapSourceW,
+			SourceH: ms.HeatmapSourceH,
+			Counts:  append([]uint32(nil), ms.HeatmapCounts...),
+		}
+	}
+	for key, peak := range ms.PeriodPeaks {
+		if len(peak.DiffImage) == 0 {
+			continue
+		}
+		if snap.PeriodPeaks == nil {
+			snap.PeriodPeaks = make(map[string]snapshotPeak, len(ms.PeriodPeaks))
+		}
+		snap.PeriodPeaks[key] = snapshotPeak{Diff: peak.Diff, At: peak.At, LiveImage: peak.LiveImage, DiffImage: peak.DiffImage}
+	}
+	for _, frame := range ms.LastTimelapseFrames {
+		snap.LastTimelapseFrames = append(snap.LastTimelapseFrames, snapshotTimelapse{
+			Timestamp: frame.Timestamp,
+			DiffPNG:   frame.DiffPNG,
+			LivePNG:   frame.LivePNG,
+		})
+	}
+	return snap
+}
+
+// collectDiffHistoryLocked assumes ms.mu is already locked.
+func (ms *MonitorState) collectDiffHistoryLocked(weighted bool) []DiffRecord {
+	src := ms.DiffHistory
+	if weighted {
+		src = ms.WeightedDiffHistory
+	}
+	if src == nil {
+		return nil
+	}
+	out := make([]DiffRecord, 0, src.Len())
+	src.Do(func(p interface{}) {
+		if p == nil {
+			return
+		}
+		if r := p.(DiffRecord); !r.Timestamp.IsZero() {
+			out = append(out, r)
+		}
+	})
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Timestamp.Before(out[j].Timestamp)
+	})
+	return out
+}
+
+func toSnapshotRecords(records []DiffRecord) []snapshotDiffRecord {
+	out := make([]snapshotDiffRecord, 0, len(records))
+	for _, r := range records {
+		out = append(out, snapshotDiffRecord{Timestamp: r.Timestamp, Percentage: r.Percentage})
+	}
+	return out
+}
+
+// restoreSnapshot スナップショットを読み込み、保持期間内のデータだけを復元する
+func (ms *MonitorState) restoreSnapshot(now time.Time) error {
+	var snap stateSnapshot
+	source, err := utils.ReadJSONFileWithBackup(ms.snapshotPath, &snap)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if snap.Version != stateSnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	cutoff := now.Add(-stateSnapshotRetention)
+	jst := time.FixedZone("JST", 9*3600)
+	cutoffDay := now.In(jst).AddDate(0, 0, -dailySummaryRetentionDays).Format("2006-01-02")
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.DiffHistoryCount = restoreHistoryRing(ms.DiffHistory, snap.DiffHistory, cutoff)
+	ms.WeightedHistoryCount = restoreHistoryRing(ms.WeightedDiffHistory, snap.WeightedDiffHistory, cutoff)
+	// 書き込み位置を最後の復元レコードの次へ進める
+	ms.DiffHistory = ms.DiffHistory.Move(ms.DiffHistoryCount % historyLimit)
+	ms.WeightedDiffHistory = ms.WeightedDiffHistory.Move(ms.WeightedHistoryCount % historyLimit)
+
+	for key, summary := range snap.DailySummaries {
+		if key < cutoffDay {
+			continue
+		}
+		ms.DailySummaries[key] = summary
+	}
+
+	if h := snap.Heatmap; h != nil && h.GridW > 0 && h.GridH > 0 && len(h.Counts) == h.GridW*h.GridH {
+		ms.HeatmapGridW = h.GridW
+		ms.HeatmapGridH = h.GridH
+		ms.HeatmapSourceW = h.SourceW
+		ms.HeatmapSourceH = h.SourceH
+		ms.HeatmapCounts = h.Counts
+	}
+
+	retained := retainedPeakKeys(now)
+	for key, p := range snap.PeriodPeaks {
+		if retained[key] && len(p.DiffImage) > 0 {
+			ms.PeriodPeaks[key] = PeakCapture{Diff: p.Diff, At: p.At, LiveImage: p.LiveImage, DiffImage: p.DiffImage}
+		}
+	}
+	if p := snap.DailyPeak; p != nil && snap.PeriodPeaks == nil && retained[p.Date] && len(p.DiffImage) > 0 {
+		ms.PeriodPeaks[p.Date] = PeakCapture{Diff: p.Diff, At: p.At, LiveImage: p.LiveImage, DiffImage: p.DiffImage}
+	}
+	ms.peakDate = DayPeriodKey(now)
+
+	frames := snap.LastTimelapseFrames
+	if len(frames) > timelapseFrameLimit {
+		frames = frames[len(frames)-timelapseFrameLimit:]
+	}
+	for _, f := range frames {
+		if f.Timestamp.IsZero() || len(f.DiffPNG) == 0 {
+			continue
+		}
+		ms.LastTimelapseFrames = append(ms.LastTimelapseFrames, TimelapseFrame{
+			Timestamp: f.Timestamp,
+			DiffPNG:   f.DiffPNG,
+			LivePNG:   f.LivePNG,
+		})
+	}
+	// TimelapseCompletedAt は復元しない（再起動のたびに自動投稿が再送されるのを防ぐ）。
+
+	log.Printf("monitor state: restored from %s (saved_at=%s history=%d weighted=%d days=%d)",
+		filepath.Base(source), snap.SavedAt.Format(time.RFC3339), ms.DiffHistoryCount, ms.WeightedHistoryCount, len(ms.DailySummaries))
+	return nil
+}
+
+// restoreHistoryRing 保持期間内のレコードを古い順に ring へ書き込み、件数を返す
+func restoreHistoryRing(r *ring.Ring, records []snapshotDiffRecord, cutoff time.Time) int {
+	kept := make([]snapshotDiffRecord, 0, len(records))
+	for _, rec := range records {
+		if rec.Timestamp.IsZero() || rec.Timestamp.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, rec)
+	}
+	if len(kept) > historyLimit {
+		kept = kept[len(kept)-historyLimit:]
+	}
+	cur := r
+	for _, rec := range kept {
+		cur.Value = DiffRecord{Timestamp: rec.Timestamp, Percentage: rec.Percentage}
+		cur = cur.Next()
+	}
+	return len(kept)
+}
@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Koukyo_discord_bot/internal/utils"
+)
+
+func TestMonitorStateSnapshotRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), StateSnapshotFileName)
+	ms := NewMonitorState(path)
+	weighted := 12.5
+	ms.UpdateData(&MonitorData{DiffPercentage: 40, WeightedDiffPercentage: &weighted, TotalPixels: 100})
+	ms.UpdateData(&MonitorData{DiffPercentage: 10, WeightedDiffPercentage: &weighted, TotalPixels: 100})
+	ms.mu.Lock()
+	ms.HeatmapGridW, ms.HeatmapGridH = 2, 1
+	ms.HeatmapSourceW, ms.HeatmapSourceH = 2, 1
+	ms.HeatmapCounts = []uint32{3, 5}
+	ms.LastTimelapseFrames = []TimelapseFrame{{Timestamp: time.Now(), DiffPNG: []byte{1}, LivePNG: []byte{2}}}
+	ms.mu.Unlock()
+	ms.StopHeatmapWorker()
+
+	restored := NewMonitorState(path)
+	defer restored.StopHeatmapWorker()
+
+	history := restored.GetDiffHistory(0, false)
+	if len(history) != 2 || history[0].Percentage != 40 || history[1].Percentage != 10 {
+		t.Fatalf("unexpected restored history: %+v", history)
+	}
+	if got := restored.GetDiffHistoryCount(); got != 2 {
+		t.Fatalf("expected history count 2, got %d", got)
+	}
+	if len(restored.GetDiffHistory(0, true)) != 2 {
+		t.Fatalf("expected weighted history to be restored")
+	}
+	dateKey := time.Now().In(time.FixedZone("JST", 9*3600)).Format("2006-01-02")
+	summary, ok := restored.GetDailySummary(dateKey)
+	if !ok || summary.Overall.Max != 40 || summary.Overall.Count != 2 {
+		t.Fatalf("unexpected restored daily summary: ok=%v %+v", ok, summary)
+	}
+	counts, gw, gh, _, _ := restored.GetHeatmapSnapshot()
+	if gw != 2 || gh != 1 || len(counts) != 2 || counts[1] != 5 {
+		t.Fatalf("unexpected restored heatmap: %v %dx%d", counts, gw, gh)
+	}
+	if len(restored.GetLastTimelapseFrames()) != 1 {
+		t.Fatalf("expected timelapse frames to be restored")
+	}
+	if restored.GetTimelapseCompletedAt() != nil {
+		t.Fatalf("timelapse completion must not be restored")
+	}
+
+	// 新しいレコードは復元済み履歴の後ろに追記される
+	restored.UpdateData(&MonitorData{DiffPercentage: 5})
+	history = restored.GetDiffHistory(0, false)
+	if len(history) != 3 || history[2].Percentage != 5 {
+		t.Fatalf("expected appended record after restore, got %+v", history)
+	}
+}
+
+func TestMonitorStateSnapshotRetentionAndBackup(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), StateSnapshotFileName)
+	now := time.Now()
+	payload := []byte(`{"version":1,"diff_history":[` +
+		`{"t":"` + now.Add(-8*24*time.Hour).Format(time.RFC3339) + `","p":50},` +
+		`{"t":"` + now.Add(-time.Hour).Format(time.RFC3339) + `","p":7}],` +
+		`"daily_summaries":{"2000-01-01":{"Overall":{"Max":1,"Count":1}}}}`)
+	if err := os.WriteFile(utils.BackupPath(path), payload, 0644); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{broken"), 0644); err != nil {
+		t.Fatalf("failed to write primary: %v", err)
+	}
+
+	ms := NewMonitorState(path)
+	defer ms.StopHeatmapWorker()
+
+	history := ms.GetDiffHistory(0, false)
+	if len(history) != 1 || history[0].Percentage != 7 {
+		t.Fatalf("expected only in-retention record from backup, got %+v", history)
+	}
+	if _, ok := ms.GetDailySummary("2000-01-01"); ok {
+		t.Fatalf("expired daily summary must not be restored")
+	}
+}
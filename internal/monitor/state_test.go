@@ -9,7 +9,7 @@ import (
 func TestGetDiffHistorySkipsZeroTimestamp(t *testing.T) {
 	t.Parallel()
 
-	ms := NewMonitorState()
+	ms := NewMonitorState("")
 	ms.DiffHistory = ring.New(4)
 
 	now := time.Now()
@@ -41,3 +41,39 @@ func TestGetDiffHistorySkipsZeroTimestamp(t *testing.T) {
 		}
 	}
 }
+
+func TestPeriodPeaksKeepPreviousPeriod(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMonitorState("")
+	defer ms.StopHeatmapWorker()
+
+	// 2026-03-01 は日曜（JST）
+	sunday := time.Date(2026, 3, 1, 10, 0, 0, 0, jstLocation)
+	ms.updatePeriodPeaksLocked(sunday, 30, &ImageData{DiffImage: []byte{1}})
+	ms.updatePeriodPeaksLocked(sunday.Add(time.Hour), 20, &ImageData{DiffImage: []byte{2}})
+	monday := sunday.Add(15 * time.Hour) // 翌日 01:00
+	ms.updatePeriodPeaksLocked(monday, 5, &ImageData{DiffImage: []byte{3}})
+
+	for key, want := range map[string]byte{
+		"2026-03-01":      1,
+		"2026-03-02":      3,
+		"week:2026-02-23": 1,
+		"week:2026-03-02": 3,
+		"month:2026-03":   1,
+	} {
+		_, diff, _, _, ok := ms.GetPeakImages(key)
+		if !ok || diff[0] != want {
+			t.Errorf("peak %s = %v, %v; want %d", key, diff, ok, want)
+		}
+	}
+
+	// 2日後には前々日分が消える
+	ms.updatePeriodPeaksLocked(monday.AddDate(0, 0, 2), 1, &ImageData{DiffImage: []byte{4}})
+	if _, _, _, _, ok := ms.GetPeakImages("2026-03-01"); ok {
+		t.Fatal("old daily peak should be pruned")
+	}
+	if _, _, _, _, ok := ms.GetPeakImages("week:2026-02-23"); !ok {
+		t.Fatal("previous week peak should be kept")
+	}
+}
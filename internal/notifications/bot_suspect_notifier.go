@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/embeds"
+	"Koukyo_discord_bot/internal/utils"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// BotSuspectNotifier 自動描画（bot/スクリプト）の疑いをモデレーション通知チャンネルへ送る（確認/誤検知ボタン付き）
+type BotSuspectNotifier struct {
+	session   *discordgo.Session
+	settings  *config.SettingsManager
+	artworkID string
+	deliver   deliverFunc // ギルドの配信モード（nil なら即時送信）
+	post      postFunc
+}
+
+func NewBotSuspectNotifier(session *discordgo.Session, settings *config.SettingsManager, artworkID string, deliver deliverFunc, post postFunc) *BotSuspectNotifier {
+	return &BotSuspectNotifier{
+		session:   session,
+		settings:  settings,
+		artworkID: artworkID,
+		deliver:   deliver,
+		post:      post,
+	}
+}
+
+func (n *BotSuspectNotifier) Notify(user activity.UserActivity) {
+	if user.SuspectedBot == nil {
+		return
+	}
+	for _, guild := range n.session.State.Guilds {
+		gs := n.settings.GetGuildSettings(guild.ID)
+		if gs.ModerationChannel == nil {
+			continue
+		}
+		channelID := *gs.ModerationChannel
+		guildID := guild.ID
+		send := func() {
+			embed, file := buildUserNotifyEmbed("🤖 bot の疑い", user, user.VandalCount >= user.RestoredCount)
+			embed.Color = 0x9B59B6
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  "根拠",
+				Value: embeds.FormatBotEvidence(user.SuspectedBot.Evidence),
+			})
+			embed.Footer = &discordgo.MessageEmbedFooter{Text: "自動判定のため誤検知があります。確認して結果を記録してください"}
+			msg := &discordgo.MessageSend{
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: embeds.BotReviewComponents(n.artworkID, user.ID),
+			}
+			if file != nil {
+				msg.Files = []*discordgo.File{file}
+			}
+			if _, err := n.post(guildID, channelID, outboxKindBotSuspect, msg); err != nil {
+				log.Printf("Failed to send suspected bot notification to guild %s: %v", guildID, err)
+			}
+		}
+		if n.deliver == nil {
+			send()
+			continue
+		}
+		n.deliver(guildID, gs, digestItem{
+			kind: outboxKindBotSuspect,
+			line: fmt.Sprintf("🤖 bot の疑い: %s", utils.FormatUserDisplayName(user.Name, user.ID)),
+		}, send)
+	}
+}
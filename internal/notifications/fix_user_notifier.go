@@ -3,6 +3,8 @@ package notifications
 import (
 	"Koukyo_discord_bot/internal/activity"
 	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/utils"
+	"fmt"
 	"log"
 
 	"github.com/bwmarrin/discordgo"
@@ -11,12 +13,16 @@ import (
 type FixUserNotifier struct {
 	session  *discordgo.Session
 	settings *config.SettingsManager
+	deliver  deliverFunc // ギルドの配信モード（nil なら即時送信）
+	post     postFunc
 }
 
-func NewFixUserNotifier(session *discordgo.Session, settings *config.SettingsManager) *FixUserNotifier {
+func NewFixUserNotifier(session *discordgo.Session, settings *config.SettingsManager, deliver deliverFunc, post postFunc) *FixUserNotifier {
 	return &FixUserNotifier{
 		session:  session,
 		settings: settings,
+		deliver:  deliver,
+		post:     post,
 	}
 }
 
@@ -27,19 +33,25 @@ func (n *FixUserNotifier) Notify(user activity.UserActivity) {
 			continue
 		}
 		channelID := *gs.NotificationFixChannel
-		embed, file := buildUserNotifyEmbed("🛠️ 新規修復ユーザー検知", user, false)
-		embed.Color = 0x2ECC71
-		if file != nil {
-			if _, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-				Embeds: []*discordgo.MessageEmbed{embed},
-				Files:  []*discordgo.File{file},
-			}); err != nil {
-				log.Printf("Failed to send fix user notification to guild %s: %v", guild.ID, err)
+		guildID := guild.ID
+		send := func() {
+			embed, file := buildUserNotifyEmbed("🛠️ 新規修復ユーザー検知", user, false)
+			embed.Color = 0x2ECC71
+			msg := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+			if file != nil {
+				msg.Files = []*discordgo.File{file}
+			}
+			if _, err := n.post(guildID, channelID, outboxKindFixUser, msg); err != nil {
+				log.Printf("Failed to send fix user notification to guild %s: %v", guildID, err)
 			}
-			continue
 		}
-		if _, err := n.session.ChannelMessageSendEmbed(channelID, embed); err != nil {
-			log.Printf("Failed to send fix user notification to guild %s: %v", guild.ID, err)
+		if n.deliver == nil {
+			send()
+			continue
 		}
+		n.deliver(guildID, gs, digestItem{
+			kind: outboxKindFixUser,
+			line: fmt.Sprintf("🛠️ 新規修復: %s", utils.FormatUserDisplayName(user.Name, user.ID)),
+		}, send)
 	}
 }
@@ -4,8 +4,11 @@ import (
 	"Koukyo_discord_bot/internal/activity"
 	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/embeds"
+	"Koukyo_discord_bot/internal/incidents"
 	"Koukyo_discord_bot/internal/monitor"
 	"Koukyo_discord_bot/internal/utils"
+	"Koukyo_discord_bot/internal/webhooks"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -29,6 +32,8 @@ type NotificationState struct {
 	LargeDiffActive           bool
 	SmallDiffLastContent      string
 	SmallDiffNextUpdate       time.Time
+	thread                    *incidentThread   // インシデントスレッド（IncidentThreadsEnabled 時のみ）
+	escalations               []escalationState // TierEscalations と同じ並び
 }
 
 // Notifier 通知システム
@@ -48,9 +53,12 @@ type Notifier struct {
 	dispatchLowQueued        map[string]bool
 	dispatchLowQueue         chan string
 	dataDir                  string
-	lastDailyReportDate      string
+	reportMu                 sync.Mutex
+	reportSent               map[string]string // サーバーごとに最後に投稿した定期レポートの期間キー（チェックポイントに保存する）
 	vandalUserNotifier       *VandalUserNotifier
 	fixUserNotifier          *FixUserNotifier
+	botSuspectNotifier       *BotSuspectNotifier
+	userStatusNotifier       *UserStatusNotifier
 	watchTargetsState        *watchTargetsRuntime
 	progressTargetsState     *progressTargetsRuntime
 	droppedHighPriority      uint64
@@ -75,32 +83,54 @@ type Notifier struct {
 	achievementBaselineReady bool
 	dmUserStatesMu           sync.Mutex
 	dmUserStates             map[string]*dmUserState
+	dmMembers                map[string]dmMembership // "guildID/userID" -> メンテナンス判定用のメンバー確認結果（dmUserStatesMu で保護）
+	incidents                *incidents.Store
+	mutedMu                  sync.Mutex
+	muted                    map[string]*mutedWindow // 静音時間/メンテナンスで止めている通知の記録
+	digestMu                 sync.Mutex
+	digests                  map[string]*digestBuffer // ダイジェスト配信の未送信分
+	webhooks                 *webhooks.Dispatcher     // 外部 Webhook（未設定なら nil）
+	outbox                   *Outbox                  // 送信失敗した通知の保存と再送（未設定なら nil）
+	dispatchOverflowMu       sync.Mutex
+	dispatchOverflow         []dispatchFunc     // 高優先度キューが満杯のときの退避先（順番を保って戻す）
+	dispatchSpill            chan dispatchFunc  // 退避先も溢れた送信処理（送らずに outbox へ保存する）
+	stateSaveRequests        chan chan struct{} // 終了時の状態保存要求（監視ループが処理する）
+	nextStateCheckpoint      time.Time
+	lastStatePayload         []byte
+	secondary                bool // 2件目以降のアートワーク用（サーバー全体の処理を行わない）
 }
 
 // NewNotifier 通知システムを作成
 func NewNotifier(session *discordgo.Session, mon *monitor.Monitor, settings *config.SettingsManager, dataDir string) *Notifier {
-	return &Notifier{
+	n := &Notifier{
 		session:              session,
 		monitor:              mon,
 		settings:             settings,
 		states:               make(map[string]*NotificationState),
 		dispatchHigh:         make(chan dispatchFunc, 256),
+		dispatchSpill:        make(chan dispatchFunc, dispatchOverflowLimit),
 		dispatchLowPending:   make(map[string]dispatchFunc),
 		dispatchLowQueued:    make(map[string]bool),
 		dispatchLowQueue:     make(chan string, 2048),
 		dataDir:              dataDir,
-		vandalUserNotifier:   NewVandalUserNotifier(session, settings),
-		fixUserNotifier:      NewFixUserNotifier(session, settings),
 		watchTargetsState:    newWatchTargetsRuntime(dataDir),
 		progressTargetsState: newProgressTargetsRuntime(dataDir),
 		dmUserStates:         make(map[string]*dmUserState),
-	}
+		stateSaveRequests:    make(chan chan struct{}),
+	}
+	n.restoreState(time.Now())
+	n.vandalUserNotifier = NewVandalUserNotifier(session, settings, n.deliverHigh, n.sendChannelMessage)
+	n.fixUserNotifier = NewFixUserNotifier(session, settings, n.deliverHigh, n.sendChannelMessage)
+	n.botSuspectNotifier = NewBotSuspectNotifier(session, settings, mon.Artwork().ID, n.deliverHigh, n.sendChannelMessage)
+	n.userStatusNotifier = NewUserStatusNotifier(session, settings, n.deliverHigh, n.sendChannelMessage)
+	return n
 }
 
 func (n *Notifier) startDispatchWorker() {
 	if n == nil {
 		return
 	}
+	go n.runDispatchSpill()
 	go func() {
 		for {
 			// High priority (FIFO, no coalescing).
@@ -109,6 +139,7 @@ func (n *Notifier) startDispatchWorker() {
 				if fn != nil {
 					fn()
 				}
+				n.refillDispatchHigh()
 				continue
 			default:
 			}
@@ -119,6 +150,7 @@ func (n *Notifier) startDispatchWorker() {
 				if fn != nil {
 					fn()
 				}
+				n.refillDispatchHigh()
 			case key := <-n.dispatchLowQueue:
 				n.dispatchLowMu.Lock()
 				fn := n.dispatchLowPending[key]
@@ -133,19 +165,73 @@ func (n *Notifier) startDispatchWorker() {
 	}()
 }
 
-func (n *Notifier) enqueueHigh(fn dispatchFunc) {
+// EnqueueHigh コマンド等から高優先度の送信処理を投入する
+func (n *Notifier) EnqueueHigh(fn func()) {
+	n.enqueueHigh("コマンド", fn)
+}
+
+// enqueueHigh 高優先度キューへ投入する。kind は溢れた時のログに出す通知の種類。
+// 退避先も満杯なら、送信処理をその場で送らずに outbox へ保存させる（outbox が無ければドロップ）。
+func (n *Notifier) enqueueHigh(kind string, fn dispatchFunc) {
 	if n == nil || fn == nil {
 		return
 	}
-	select {
-	case n.dispatchHigh <- fn:
-	default:
-		// Drop if overloaded; do not block the monitoring loop.
-		n.metricsMu.Lock()
-		n.droppedHighPriority++
-		dropped := n.droppedHighPriority
-		n.metricsMu.Unlock()
-		log.Printf("⚠️ dispatch: high queue full, dropping notification (total dropped: %d)", dropped)
+	n.dispatchOverflowMu.Lock()
+	defer n.dispatchOverflowMu.Unlock()
+	// 退避中の通知があれば順番を保つため後ろに並べる
+	if len(n.dispatchOverflow) == 0 {
+		select {
+		case n.dispatchHigh <- fn:
+			return
+		default:
+		}
+	}
+	if len(n.dispatchOverflow) < dispatchOverflowLimit {
+		n.dispatchOverflow = append(n.dispatchOverflow, fn)
+		return
+	}
+	if n.outbox != nil && n.dispatchSpill != nil {
+		select {
+		case n.dispatchSpill <- fn:
+			log.Printf("⚠️ dispatch: high queue full, saving notification kind=%s to outbox", kind)
+			return
+		default:
+		}
+	}
+	// Drop if overloaded; do not block the monitoring loop.
+	n.metricsMu.Lock()
+	n.droppedHighPriority++
+	dropped := n.droppedHighPriority
+	n.metricsMu.Unlock()
+	log.Printf("⚠️ dispatch: high queue full, dropping notification kind=%s (total dropped: %d)", kind, dropped)
+}
+
+// runDispatchSpill 溢れた送信処理を outbox を保留にした状態で実行する。
+// 実行中はこのアートワークの通知が Discord へ直接送られず outbox に積まれ、再送ループが古い順に送る。
+func (n *Notifier) runDispatchSpill() {
+	if n.dispatchSpill == nil {
+		return
+	}
+	artwork := n.artwork().ID
+	for fn := range n.dispatchSpill {
+		n.outbox.hold(artwork)
+		fn()
+		n.outbox.release(artwork)
+	}
+}
+
+// refillDispatchHigh 退避していた高優先度の送信処理を空いた分だけキューへ戻す
+func (n *Notifier) refillDispatchHigh() {
+	n.dispatchOverflowMu.Lock()
+	defer n.dispatchOverflowMu.Unlock()
+	for len(n.dispatchOverflow) > 0 {
+		select {
+		case n.dispatchHigh <- n.dispatchOverflow[0]:
+			n.dispatchOverflow[0] = nil
+			n.dispatchOverflow = n.dispatchOverflow[1:]
+		default:
+			return
+		}
 	}
 }
 
@@ -184,6 +270,26 @@ func (n *Notifier) GetDroppedNotificationStats() (high, low uint64) {
 	return n.droppedHighPriority, n.droppedLowPriority
 }
 
+// DispatchQueueDepth 高/低優先度ディスパッチキューの滞留数を取得
+func (n *Notifier) DispatchQueueDepth() (high, low int) {
+	n.dispatchOverflowMu.Lock()
+	overflow := len(n.dispatchOverflow)
+	n.dispatchOverflowMu.Unlock()
+	return len(n.dispatchHigh) + overflow, len(n.dispatchLowQueue)
+}
+
+// IsStandaloneActive スタンドアロンフォールバック中か
+func (n *Notifier) IsStandaloneActive() bool {
+	n.standaloneMu.Lock()
+	defer n.standaloneMu.Unlock()
+	return n.standaloneActive
+}
+
+// ArtworkID 通知対象アートワークのID
+func (n *Notifier) ArtworkID() string {
+	return n.artwork().ID
+}
+
 // getState サーバーの通知状態を取得
 func (n *Notifier) getState(guildID string) *NotificationState {
 	n.mu.Lock()
@@ -206,14 +312,20 @@ func (n *Notifier) getState(guildID string) *NotificationState {
 // While within this limit, we keep a single text message and edit it to reduce spam.
 const smallDiffPixelLimit = 10
 
+// dispatchOverflowLimit 高優先度キュー満杯時に退避しておける件数（超過分はドロップ）
+const dispatchOverflowLimit = 1024
+
 const smallDiffMinUpdateInterval = 5 * time.Second
 
 const diffUserSummaryTopN = 5
 
-func (n *Notifier) upsertSmallDiffMessage(channelID string, state *NotificationState, content string, force bool) {
-	if n == nil || n.session == nil || state == nil || channelID == "" {
+func (n *Notifier) upsertSmallDiffMessage(guildID string, settings config.GuildSettings, state *NotificationState, content string, force bool) {
+	if n == nil || n.session == nil || state == nil || settings.NotificationChannel == nil {
 		return
 	}
+	channelID := *settings.NotificationChannel
+	summary, _, _ := strings.Cut(content, "\n")
+	content = n.artworkPrefix() + content
 	now := time.Now()
 	if !force {
 		// Avoid hammering Discord with edits every tick; keep the loop responsive.
@@ -238,7 +350,7 @@ func (n *Notifier) upsertSmallDiffMessage(channelID string, state *NotificationS
 
 	// Coalesce small-diff updates per guild+channel: keep only the latest edit.
 	key := fmt.Sprintf("small:%s:%s", channelID, guildKeyFromState(state))
-	n.enqueueLow(key, func() {
+	n.deliverLow(guildID, settings, key, digestItem{kind: "小規模差分", line: summary}, func() {
 		state.mu.Lock()
 		msgID := state.SmallDiffMessageID
 		msgCh := state.SmallDiffMessageChannelID
@@ -320,7 +432,7 @@ func (n *Notifier) buildSmallDiffCoordinateLines(limit int) []string {
 		return nil
 	}
 
-	lines, err := smallDiffCoordinateLines(diffImage, limit)
+	lines, err := smallDiffCoordinateLinesAt(artworkOrigin(n.artwork()), diffImage, limit)
 	if err != nil {
 		log.Printf("small_diff: failed to build coordinate lines: %v", err)
 		return nil
@@ -354,11 +466,26 @@ func (n *Notifier) CheckAndNotify(guildID string) {
 	// 通知指標の値を取得
 	diffValue := getDiffValue(data, settings.NotificationMetric)
 	isZero := isZeroDiff(diffValue)
-	currentTier := calculateTier(diffValue, settings.NotificationThreshold)
+	ladder := newTierLadder(settings)
+	currentTier := ladder.tier(diffValue)
 	state := n.getState(guildID)
 
+	// 外部 Webhook へ状態遷移を送る（Discord 通知を止めている間も送る）
+	n.emitDiffWebhooks(guildID, state, settings, data, ladder, diffValue, currentTier, isZero)
+
+	// 0. 静音時間/メンテナンス中は記録のみ
+	now := time.Now()
+	gate, reason := notificationGate(settings, now)
+	if gate == gateMuted {
+		n.recordMutedCheck(guildID, reason, state, settings, diffValue, currentTier, isZero, now)
+		return
+	}
+
+	// Tier継続によるエスカレーション（小規模差分でも判定する）
+	n.checkEscalations(guildID, state, settings, ladder, currentTier, diffValue, isZero, gate == gateSilent, now)
+
 	// 1. 小規模差分（Small Diff）の処理
-	if n.handleSmallDiff(state, settings, data, diffValue, currentTier, isZero) {
+	if n.handleSmallDiff(guildID, state, settings, data, diffValue, currentTier, isZero) {
 		return
 	}
 
@@ -374,6 +501,7 @@ func (n *Notifier) CheckAndNotify(guildID string) {
 
 // handleSmallDiff 小規模差分の処理。trueを返した場合は後続処理をスキップする。
 func (n *Notifier) handleSmallDiff(
+	guildID string,
 	state *NotificationState,
 	settings config.GuildSettings,
 	data *monitor.MonitorData,
@@ -396,7 +524,7 @@ func (n *Notifier) handleSmallDiff(
 		if lines := n.buildSmallDiffCoordinateLines(smallDiffPixelLimit); len(lines) > 0 {
 			content += "\n" + strings.Join(lines, "\n")
 		}
-		n.upsertSmallDiffMessage(*settings.NotificationChannel, state, content, false)
+		n.upsertSmallDiffMessage(guildID, settings, state, content, false)
 		state.SmallDiffActive = true
 		state.LastTier = currentTier
 		state.MentionTriggered = diffValue >= settings.MentionThreshold
@@ -427,7 +555,12 @@ func (n *Notifier) handleLargeDiffTransition(
 		state.mu.Unlock()
 
 		if transitionedFromSmall {
-			n.sendLargeDiffTransitionSnapshot(guildID, settings, data, diffValue)
+			n.deliverHigh(guildID, settings, digestItem{
+				kind: "変化検知",
+				line: fmt.Sprintf("🟡 差分が%dpxを超えました（%.2f%%・%dpx）", smallDiffPixelLimit, diffValue, data.DiffPixels),
+			}, func() {
+				n.sendLargeDiffTransitionSnapshot(guildID, settings, data, diffValue)
+			})
 		}
 	}
 }
@@ -442,29 +575,47 @@ func (n *Notifier) handleStandardNotification(
 	currentTier Tier,
 	isZero bool,
 ) {
+	ladder := newTierLadder(settings)
+	metricLabel := "差分率"
+	if settings.NotificationMetric == "weighted" {
+		metricLabel = "加重差分率"
+	}
+
+	// 検知と修復完了はダイジェスト配信でも即時（高優先度キューで順序を保つ）
 	if state.WasZeroDiff && !isZero {
-		n.sendZeroRecoveryNotification(guildID, settings, data, diffValue)
+		n.deliverHigh(guildID, settings, digestItem{critical: true}, func() {
+			n.sendZeroRecoveryNotification(guildID, settings, data, diffValue)
+		})
 	}
 
 	if !state.WasZeroDiff && isZero {
 		if state.SmallDiffActive && !state.LargeDiffActive {
-			metricLabel := "差分率"
-			if settings.NotificationMetric == "weighted" {
-				metricLabel = "加重差分率"
-			}
 			content := fmt.Sprintf("✅ 【Wplace速報】修復完了！ %s: 0.00%% # Pixel Perfect!", metricLabel)
-			n.upsertSmallDiffMessage(*settings.NotificationChannel, state, content, true)
+			n.upsertSmallDiffMessage(guildID, settings, state, content, true)
 			return
 		} else {
-			n.sendZeroCompletionNotification(guildID, settings, data)
+			n.deliverHigh(guildID, settings, digestItem{critical: true}, func() {
+				n.sendZeroCompletionNotification(guildID, settings, data)
+			})
 		}
 	}
 
 	if !isZero && currentTier != state.LastTier {
 		if currentTier > state.LastTier {
-			n.sendNotification(guildID, settings, data, currentTier, diffValue)
+			n.deliverHigh(guildID, settings, digestItem{
+				kind:     "Tier上昇",
+				line:     fmt.Sprintf("🚨 %sが%sに増加（%.2f%%）", metricLabel, ladder.label(currentTier), diffValue),
+				critical: ladder.critical(currentTier, diffValue, settings),
+			}, func() {
+				n.sendNotification(guildID, settings, data, currentTier, diffValue)
+			})
 		} else {
-			n.sendDecreaseNotification(guildID, settings, data, currentTier, diffValue)
+			n.deliverHigh(guildID, settings, digestItem{
+				kind: "Tier減少",
+				line: fmt.Sprintf("📉 %sが%sまで減少（%.2f%%）", metricLabel, ladder.label(currentTier), diffValue),
+			}, func() {
+				n.sendDecreaseNotification(guildID, settings, data, currentTier, diffValue)
+			})
 		}
 	}
 }
@@ -547,7 +698,7 @@ func (n *Notifier) sendLargeDiffTransitionSnapshot(
 		Inline: false,
 	})
 	appendCurrentDiffUserSummaryField(n, embed)
-	appendMainMonitorMapField(embed)
+	n.appendArtworkMapField(embed)
 
 	var files []*discordgo.File
 	images := n.monitor.GetLatestImages()
@@ -567,11 +718,11 @@ func (n *Notifier) sendLargeDiffTransitionSnapshot(
 		}
 	}
 
-	if _, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-		Content: message,
+	if err := n.sendEpisodeMessage(guildID, settings, outboxKindSnapshot, &discordgo.MessageSend{
+		Content: n.artworkPrefix() + message,
 		Embeds:  []*discordgo.MessageEmbed{embed},
 		Files:   files,
-	}); err != nil {
+	}, fmt.Sprintf("🟡 %s %.2f%%", metricLabel, diffValue)); err != nil {
 		log.Printf("Failed to send transition snapshot to channel %s: %v", channelID, err)
 	} else {
 		log.Printf("Transition snapshot sent to guild %s: %.2f%%", guildID, diffValue)
@@ -588,9 +739,10 @@ func (n *Notifier) sendNotification(
 ) {
 	channelID := *settings.NotificationChannel
 
+	ladder := newTierLadder(settings)
 	mentionStr := ""
-	if diffValue >= settings.MentionThreshold && settings.MentionRole != nil {
-		mentionStr = fmt.Sprintf("<@&%s> ", *settings.MentionRole)
+	if mentionsAllowed(settings) {
+		mentionStr = ladder.mention(tier, diffValue, settings)
 	}
 
 	metricLabel := "差分率"
@@ -598,31 +750,7 @@ func (n *Notifier) sendNotification(
 		metricLabel = "加重差分率"
 	}
 
-	var tierDesc string
-	switch tier {
-	case Tier100:
-		tierDesc = "100%に急増!!"
-	case Tier90:
-		tierDesc = "90%台に増加"
-	case Tier80:
-		tierDesc = "80%台に増加"
-	case Tier70:
-		tierDesc = "70%台に増加"
-	case Tier60:
-		tierDesc = "60%台に増加"
-	case Tier50:
-		tierDesc = "50%以上に急増"
-	case Tier40:
-		tierDesc = "40%台に増加"
-	case Tier30:
-		tierDesc = "30%台に増加"
-	case Tier20:
-		tierDesc = "20%台に増加"
-	case Tier10:
-		tierDesc = "10%台に増加"
-	default:
-		tierDesc = "変動"
-	}
+	tierDesc := ladder.increaseDesc(tier)
 
 	message := fmt.Sprintf(
 		"%s【Wplace速報】 🚨 %sが%sしました！[現在%.2f%%]",
@@ -635,7 +763,7 @@ func (n *Notifier) sendNotification(
 	embed := &discordgo.MessageEmbed{
 		Title:       "🏯 Wplace 荒らし検知",
 		Description: fmt.Sprintf("現在の%s: **%.2f%%**", metricLabel, diffValue),
-		Color:       getTierColor(tier),
+		Color:       ladder.color(tier),
 		Fields: []*discordgo.MessageEmbedField{
 			{
 				Name:   "📊 差分率 (全体)",
@@ -676,7 +804,7 @@ func (n *Notifier) sendNotification(
 		Inline: false,
 	})
 	appendCurrentDiffUserSummaryField(n, embed)
-	appendMainMonitorMapField(embed)
+	n.appendArtworkMapField(embed)
 
 	var files []*discordgo.File
 	images := n.monitor.GetLatestImages()
@@ -696,11 +824,11 @@ func (n *Notifier) sendNotification(
 		}
 	}
 
-	_, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-		Content: message,
+	err := n.sendEpisodeMessage(guildID, settings, outboxKindTierUp, &discordgo.MessageSend{
+		Content: n.artworkPrefix() + message,
 		Embeds:  []*discordgo.MessageEmbed{embed},
 		Files:   files,
-	})
+	}, fmt.Sprintf("🚨 %s %.2f%%（%s）", metricLabel, diffValue, ladder.label(tier)))
 
 	if err != nil {
 		log.Printf("Failed to send notification to channel %s: %v", channelID, err)
@@ -724,7 +852,8 @@ func (n *Notifier) sendDecreaseNotification(
 		metricLabel = "加重差分率"
 	}
 
-	tierLabel := tierRangeLabel(tier, settings.NotificationThreshold)
+	ladder := newTierLadder(settings)
+	tierLabel := ladder.label(tier)
 	message := fmt.Sprintf(
 		"【Wplace速報】 %sが%sまで減少しました。[現在%.2f%%]",
 		metricLabel,
@@ -735,7 +864,7 @@ func (n *Notifier) sendDecreaseNotification(
 	embed := &discordgo.MessageEmbed{
 		Title:       "🏯 Wplace 差分減少",
 		Description: fmt.Sprintf("現在の%s: **%.2f%%**", metricLabel, diffValue),
-		Color:       getTierColor(tier),
+		Color:       ladder.color(tier),
 		Fields: []*discordgo.MessageEmbedField{
 			{
 				Name:   "📊 差分率 (全体)",
@@ -776,7 +905,7 @@ func (n *Notifier) sendDecreaseNotification(
 		Inline: false,
 	})
 	appendCurrentDiffUserSummaryField(n, embed)
-	appendMainMonitorMapField(embed)
+	n.appendArtworkMapField(embed)
 
 	var files []*discordgo.File
 	images := n.monitor.GetLatestImages()
@@ -796,11 +925,11 @@ func (n *Notifier) sendDecreaseNotification(
 		}
 	}
 
-	_, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-		Content: message,
+	err := n.sendEpisodeMessage(guildID, settings, outboxKindTierDown, &discordgo.MessageSend{
+		Content: n.artworkPrefix() + message,
 		Embeds:  []*discordgo.MessageEmbed{embed},
 		Files:   files,
-	})
+	}, fmt.Sprintf("📉 %s %.2f%%（%s）", metricLabel, diffValue, tierLabel))
 
 	if err != nil {
 		log.Printf("Failed to send decrease notification to channel %s: %v", channelID, err)
@@ -869,7 +998,7 @@ func (n *Notifier) sendZeroRecoveryNotification(
 		Inline: false,
 	})
 	appendCurrentDiffUserSummaryField(n, embed)
-	appendMainMonitorMapField(embed)
+	n.appendArtworkMapField(embed)
 
 	var files []*discordgo.File
 	images := n.monitor.GetLatestImages()
@@ -889,11 +1018,11 @@ func (n *Notifier) sendZeroRecoveryNotification(
 		}
 	}
 
-	_, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-		Content: message,
+	err := n.sendEpisodeMessage(guildID, settings, outboxKindDetected, &discordgo.MessageSend{
+		Content: n.artworkPrefix() + message,
 		Embeds:  []*discordgo.MessageEmbed{embed},
 		Files:   files,
-	})
+	}, fmt.Sprintf("🟢 %s %.2f%%", metricLabel, diffValue))
 
 	if err != nil {
 		log.Printf("Failed to send zero recovery notification to channel %s: %v", channelID, err)
@@ -953,7 +1082,8 @@ func (n *Notifier) sendZeroCompletionNotification(
 		Inline: false,
 	})
 	appendCurrentDiffUserSummaryField(n, embed)
-	appendMainMonitorMapField(embed)
+	n.appendClosedIncidentField(embed)
+	n.appendArtworkMapField(embed)
 
 	var files []*discordgo.File
 	images := n.monitor.GetLatestImages()
@@ -973,15 +1103,18 @@ func (n *Notifier) sendZeroCompletionNotification(
 		}
 	}
 
-	_, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-		Content: message,
+	err := n.sendEpisodeMessage(guildID, settings, outboxKindCompleted, &discordgo.MessageSend{
+		Content: n.artworkPrefix() + message,
 		Embeds:  []*discordgo.MessageEmbed{embed},
 		Files:   files,
-	})
+	}, "✅ 修復完了")
 
-	if err != nil {
+	switch {
+	case errors.Is(err, errOutboxQueued):
+		log.Printf("Zero completion notification for guild %s deferred: %v", guildID, err)
+	case err != nil:
 		log.Printf("Failed to send zero completion notification to channel %s: %v", channelID, err)
-	} else {
+	default:
 		log.Printf("Zero completion notification sent to guild %s", guildID)
 	}
 }
@@ -1006,7 +1139,7 @@ func appendCurrentDiffUserSummaryField(n *Notifier, embed *discordgo.MessageEmbe
 	lines := make([]string, 0, limit+1)
 	for i := 0; i < limit; i++ {
 		item := all[i]
-		lines = append(lines, fmt.Sprintf("%s | %dpx", utils.FormatUserDisplayName(item.Name, item.UserID), item.Pixels))
+		lines = append(lines, fmt.Sprintf("%s | %s", utils.FormatUserDisplayName(item.Name, item.UserID), formatPainterPixels(item)))
 	}
 	if len(all) > limit {
 		lines = append(lines, fmt.Sprintf("...ほか%d人", len(all)-limit))
@@ -1019,6 +1152,17 @@ func appendCurrentDiffUserSummaryField(n *Notifier, embed *discordgo.MessageEmbe
 	})
 }
 
+// formatPainterPixels 差分内のピクセル数。推定分があれば95%信頼区間も付ける（「~120 px (est., 95% CI 98–141)」）
+func formatPainterPixels(item activity.PainterPixelCount) string {
+	if item.Estimated <= 0 {
+		return fmt.Sprintf("%dpx", item.Pixels)
+	}
+	if item.High > item.Low {
+		return fmt.Sprintf("~%d px (est., 95%% CI %d–%d)", item.Pixels, item.Low, item.High)
+	}
+	return fmt.Sprintf("~%d px (est.)", item.Pixels)
+}
+
 // ResetState サーバーの通知状態をリセット
 func (n *Notifier) ResetState(guildID string) {
 	n.mu.Lock()
@@ -1029,13 +1173,32 @@ func (n *Notifier) ResetState(guildID string) {
 func (n *Notifier) NotifyNewUser(kind string, user activity.UserActivity) {
 	switch kind {
 	case "vandal":
+		n.emitWebhook(webhooks.EventUserVandal, "", userWebhookData(user))
 		if n.vandalUserNotifier != nil {
 			n.vandalUserNotifier.Notify(user)
 		}
+		n.notifyNewVandalInThreads(user)
 	case "fix":
+		n.emitWebhook(webhooks.EventUserFix, "", userWebhookData(user))
 		if n.fixUserNotifier != nil {
 			n.fixUserNotifier.Notify(user)
 		}
+	case activity.NotifyKindBot:
+		data := userWebhookData(user)
+		if user.SuspectedBot != nil {
+			for _, ev := range user.SuspectedBot.Evidence {
+				data.BotEvidence = append(data.BotEvidence, webhooks.BotEvidenceData{Kind: ev.Kind, Detail: ev.Detail})
+			}
+		}
+		n.emitWebhook(webhooks.EventUserSuspectedBot, "", data)
+		if n.botSuspectNotifier != nil {
+			n.botSuspectNotifier.Notify(user)
+		}
+	case activity.NotifyKindRestricted:
+		n.emitWebhook(webhooks.EventUserRestricted, "", userWebhookData(user))
+		if n.userStatusNotifier != nil {
+			n.userStatusNotifier.Notify(user)
+		}
 	}
 }
 
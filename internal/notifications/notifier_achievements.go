@@ -3,10 +3,9 @@ package notifications
 import (
 	"Koukyo_discord_bot/internal/achievements"
 	"Koukyo_discord_bot/internal/activity"
-	"errors"
+	"Koukyo_discord_bot/internal/webhooks"
 	"fmt"
 	"log"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -58,17 +57,16 @@ func (n *Notifier) evaluateAchievementsAndNotify() {
 		return
 	}
 
-	activityPath := filepath.Join(n.dataDir, "user_activity.json")
-	entries, err := readUserActivityWithRetry(activityPath, 3, 100*time.Millisecond)
+	entries, err := readUserActivityWithRetry(n.dataDir, 3, 100*time.Millisecond)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return
-		}
 		log.Printf("achievement eval: failed to load user activity: %v", err)
 		return
 	}
+	if len(entries) == 0 {
+		return
+	}
 
-	storePath := filepath.Join(n.dataDir, "achievements.json")
+	storePath := filepath.Join(n.dataDir, achievements.FileName)
 	store, err := achievements.Load(storePath)
 	if err != nil {
 		log.Printf("achievement eval: failed to load store: %v", err)
@@ -127,6 +125,13 @@ func (n *Notifier) evaluateAchievementsAndNotify() {
 	}
 
 	for _, notice := range pendingNotices {
+		n.emitWebhook(webhooks.EventAchievementUnlocked, "", webhooks.AchievementData{
+			Achievement: notice.AchievementName,
+			DiscordID:   notice.DiscordID,
+			DiscordName: notice.DiscordName,
+			WplaceID:    notice.WplaceID,
+			WplaceName:  notice.WplaceName,
+		})
 		userDisplay := buildAchievementUserDisplay(notice)
 		for _, guild := range n.session.State.Guilds {
 			n.NotifyAchievement(guild.ID, userDisplay, notice.AchievementName)
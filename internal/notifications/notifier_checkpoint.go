@@ -0,0 +1,298 @@
+package notifications
+
+import (
+	"Koukyo_discord_bot/internal/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// NotifierStateFileName 通知状態のチェックポイント（アートワークごとのデータディレクトリに保存）
+	NotifierStateFileName = "notifier_state.json"
+
+	notifierStateVersion            = 1
+	notifierStateCheckpointInterval = 10 * time.Second
+	// notifierStateMaxAge これより古いチェックポイントは状況が変わっているとみなして使わない
+	notifierStateMaxAge = 24 * time.Hour
+	saveStateTimeout    = 3 * time.Second
+)
+
+// notifierStateFile 再起動をまたいで引き継ぐ通知状態
+type notifierStateFile struct {
+	Version         int                                             `json:"version"`
+	SavedAt         time.Time                                       `json:"saved_at"`
+	Guilds          map[string]guildStateSnapshot                   `json:"guilds,omitempty"`
+	DMUsers         map[string]dmUserSnapshot                       `json:"dm_users,omitempty"`
+	WatchTargets    map[string]map[string]targetStateSnapshot       `json:"watch_targets,omitempty"`
+	ProgressTargets map[string]map[string]progressNotificationState `json:"progress_targets,omitempty"`
+	ReportSent      map[string]string                               `json:"report_sent,omitempty"`
+}
+
+type guildStateSnapshot struct {
+	LastTier                  Tier                 `json:"last_tier"`
+	MentionTriggered          bool                 `json:"mention_triggered,omitempty"`
+	WasZeroDiff               bool                 `json:"was_zero_diff"`
+	SmallDiffMessageID        string               `json:"small_diff_message_id,omitempty"`
+	SmallDiffMessageChannelID string               `json:"small_diff_message_channel_id,omitempty"`
+	SmallDiffActive           bool                 `json:"small_diff_active,omitempty"`
+	LargeDiffActive           bool                 `json:"large_diff_active,omitempty"`
+	SmallDiffLastContent      string               `json:"small_diff_last_content,omitempty"`
+	Thread                    *incidentThread      `json:"thread,omitempty"`
+	Escalations               []escalationSnapshot `json:"escalations,omitempty"`
+}
+
+type escalationSnapshot struct {
+	Since     time.Time `json:"since"`
+	Escalated bool      `json:"escalated,omitempty"`
+}
+
+type dmUserSnapshot struct {
+	LastTier   Tier      `json:"last_tier"`
+	WasZero    bool      `json:"was_zero"`
+	LastNotify time.Time `json:"last_notify"`
+}
+
+type targetStateSnapshot struct {
+	LastTier    Tier `json:"last_tier"`
+	WasZeroDiff bool `json:"was_zero_diff"`
+}
+
+func (n *Notifier) stateFilePath() string {
+	if n.dataDir == "" {
+		return ""
+	}
+	return filepath.Join(n.dataDir, NotifierStateFileName)
+}
+
+// snapshotState 現在の通知状態を書き出し用にコピーする
+func (n *Notifier) snapshotState() notifierStateFile {
+	file := notifierStateFile{Version: notifierStateVersion}
+
+	n.mu.RLock()
+	if len(n.states) > 0 {
+		file.Guilds = make(map[string]guildStateSnapshot, len(n.states))
+	}
+	for guildID, state := range n.states {
+		state.mu.Lock()
+		snap := guildStateSnapshot{
+			LastTier:                  state.LastTier,
+			MentionTriggered:          state.MentionTriggered,
+			WasZeroDiff:               state.WasZeroDiff,
+			SmallDiffMessageID:        state.SmallDiffMessageID,
+			SmallDiffMessageChannelID: state.SmallDiffMessageChannelID,
+			SmallDiffActive:           state.SmallDiffActive,
+			LargeDiffActive:           state.LargeDiffActive,
+			SmallDiffLastContent:      state.SmallDiffLastContent,
+		}
+		if state.thread != nil {
+			thread := *state.thread
+			snap.Thread = &thread
+		}
+		for _, esc := range state.escalations {
+			snap.Escalations = append(snap.Escalations, escalationSnapshot{Since: esc.since, Escalated: esc.escalated})
+		}
+		state.mu.Unlock()
+		file.Guilds[guildID] = snap
+	}
+	n.mu.RUnlock()
+
+	n.dmUserStatesMu.Lock()
+	if len(n.dmUserStates) > 0 {
+		file.DMUsers = make(map[string]dmUserSnapshot, len(n.dmUserStates))
+	}
+	for userID, s := range n.dmUserStates {
+		s.mu.Lock()
+		file.DMUsers[userID] = dmUserSnapshot{LastTier: s.lastTier, WasZero: s.wasZero, LastNotify: s.lastNotify}
+		s.mu.Unlock()
+	}
+	n.dmUserStatesMu.Unlock()
+
+	if w := n.watchTargetsState; w != nil {
+		w.mu.Lock()
+		for targetID, st := range w.statuses {
+			if len(st.GuildStates) == 0 {
+				continue
+			}
+			if file.WatchTargets == nil {
+				file.WatchTargets = make(map[string]map[string]targetStateSnapshot)
+			}
+			guilds := make(map[string]targetStateSnapshot, len(st.GuildStates))
+			for guildID, gs := range st.GuildStates {
+				guilds[guildID] = targetStateSnapshot{LastTier: gs.LastTier, WasZeroDiff: gs.WasZeroDiff}
+			}
+			file.WatchTargets[targetID] = guilds
+		}
+		w.mu.Unlock()
+	}
+
+	if w := n.progressTargetsState; w != nil {
+		w.mu.Lock()
+		for targetID, st := range w.statuses {
+			if len(st.GuildStates) == 0 {
+				continue
+			}
+			if file.ProgressTargets == nil {
+				file.ProgressTargets = make(map[string]map[string]progressNotificationState)
+			}
+			guilds := make(map[string]progressNotificationState, len(st.GuildStates))
+			for guildID, gs := range st.GuildStates {
+				guilds[guildID] = *gs
+			}
+			file.ProgressTargets[targetID] = guilds
+		}
+		w.mu.Unlock()
+	}
+
+	n.reportMu.Lock()
+	if len(n.reportSent) > 0 {
+		file.ReportSent = make(map[string]string, len(n.reportSent))
+		for guildID, key := range n.reportSent {
+			file.ReportSent[guildID] = key
+		}
+	}
+	n.reportMu.Unlock()
+	return file
+}
+
+// checkpointState 通知状態をファイルへ保存する。
+// force でなければ一定間隔ごと、かつ内容が変わったときだけ書き込む。
+func (n *Notifier) checkpointState(now time.Time, force bool) {
+	path := n.stateFilePath()
+	if path == "" {
+		return
+	}
+	if !force && now.Before(n.nextStateCheckpoint) {
+		return
+	}
+	n.nextStateCheckpoint = now.Add(notifierStateCheckpointInterval)
+
+	file := n.snapshotState()
+	// 比較用には保存時刻を含めない
+	body, err := json.Marshal(file)
+	if err != nil {
+		log.Printf("Failed to encode notifier state (artwork=%s): %v", n.artwork().ID, err)
+		return
+	}
+	if !force && bytes.Equal(body, n.lastStatePayload) {
+		return
+	}
+	file.SavedAt = now
+	payload, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode notifier state (artwork=%s): %v", n.artwork().ID, err)
+		return
+	}
+	if err := utils.WriteFileAtomic(path, payload); err != nil {
+		log.Printf("Failed to save notifier state (artwork=%s): %v", n.artwork().ID, err)
+		return
+	}
+	n.lastStatePayload = body
+}
+
+// restoreState 前回保存した通知状態を読み込む（NewNotifier から呼ぶ）
+func (n *Notifier) restoreState(now time.Time) {
+	path := n.stateFilePath()
+	if path == "" {
+		return
+	}
+	var file notifierStateFile
+	if _, err := utils.ReadJSONFileWithBackup(path, &file); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Failed to load notifier state %s: %v", path, err)
+		}
+		return
+	}
+	if file.Version != notifierStateVersion {
+		log.Printf("Ignoring notifier state %s: unsupported version %d", path, file.Version)
+		return
+	}
+	// 定期レポートの投稿済み期間は古くても使う（停止中に迎えた投稿時刻の分を再起動後に送るため）
+	if len(file.ReportSent) > 0 {
+		n.reportMu.Lock()
+		n.reportSent = file.ReportSent
+		n.reportMu.Unlock()
+	}
+	if now.Sub(file.SavedAt) > notifierStateMaxAge {
+		log.Printf("Ignoring notifier state %s: saved at %s", path, file.SavedAt.Format(time.RFC3339))
+		return
+	}
+
+	n.mu.Lock()
+	for guildID, snap := range file.Guilds {
+		state := &NotificationState{
+			LastTier:                  snap.LastTier,
+			MentionTriggered:          snap.MentionTriggered,
+			WasZeroDiff:               snap.WasZeroDiff,
+			SmallDiffMessageID:        snap.SmallDiffMessageID,
+			SmallDiffMessageChannelID: snap.SmallDiffMessageChannelID,
+			SmallDiffActive:           snap.SmallDiffActive,
+			LargeDiffActive:           snap.LargeDiffActive,
+			SmallDiffLastContent:      snap.SmallDiffLastContent,
+			thread:                    snap.Thread,
+		}
+		for _, esc := range snap.Escalations {
+			state.escalations = append(state.escalations, escalationState{since: esc.Since, escalated: esc.Escalated})
+		}
+		n.states[guildID] = state
+	}
+	n.mu.Unlock()
+
+	n.dmUserStatesMu.Lock()
+	for userID, snap := range file.DMUsers {
+		n.dmUserStates[userID] = &dmUserState{lastTier: snap.LastTier, wasZero: snap.WasZero, lastNotify: snap.LastNotify}
+	}
+	n.dmUserStatesMu.Unlock()
+
+	if w := n.watchTargetsState; w != nil {
+		w.mu.Lock()
+		for targetID, guilds := range file.WatchTargets {
+			st := &watchTargetStatus{GuildStates: make(map[string]*NotificationState, len(guilds))}
+			for guildID, snap := range guilds {
+				st.GuildStates[guildID] = &NotificationState{LastTier: snap.LastTier, WasZeroDiff: snap.WasZeroDiff}
+			}
+			w.statuses[targetID] = st
+		}
+		w.mu.Unlock()
+	}
+
+	if w := n.progressTargetsState; w != nil {
+		w.mu.Lock()
+		for targetID, guilds := range file.ProgressTargets {
+			st := &progressTargetStatus{GuildStates: make(map[string]*progressNotificationState, len(guilds))}
+			for guildID, snap := range guilds {
+				gs := snap
+				st.GuildStates[guildID] = &gs
+			}
+			w.statuses[targetID] = st
+		}
+		w.mu.Unlock()
+	}
+
+	log.Printf("Notifier state restored (artwork=%s guilds=%d dm_users=%d saved_at=%s)",
+		n.artwork().ID, len(file.Guilds), len(file.DMUsers), file.SavedAt.Format(time.RFC3339))
+}
+
+// SaveState 通知状態を今すぐ保存する（終了時に呼ぶ）。
+// 監視ループ側で書き出すため、ループが動いていなければ何もしない。
+func (n *Notifier) SaveState() {
+	if n == nil || n.stateSaveRequests == nil {
+		return
+	}
+	done := make(chan struct{})
+	select {
+	case n.stateSaveRequests <- done:
+	case <-time.After(saveStateTimeout):
+		log.Printf("Notifier state save skipped (artwork=%s): monitoring loop busy", n.artwork().ID)
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(saveStateTimeout):
+		log.Printf("Notifier state save timed out (artwork=%s)", n.artwork().ID)
+	}
+}
@@ -0,0 +1,97 @@
+package notifications
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newCheckpointTestNotifier(dir string) *Notifier {
+	return &Notifier{
+		dataDir:              dir,
+		states:               make(map[string]*NotificationState),
+		dmUserStates:         make(map[string]*dmUserState),
+		watchTargetsState:    newWatchTargetsRuntime(dir),
+		progressTargetsState: newProgressTargetsRuntime(dir),
+	}
+}
+
+func TestNotifierStateCheckpointRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-5 * time.Minute)
+
+	n := newCheckpointTestNotifier(dir)
+	n.states["g1"] = &NotificationState{
+		LastTier:                  Tier(2),
+		WasZeroDiff:               false,
+		SmallDiffMessageID:        "m1",
+		SmallDiffMessageChannelID: "c1",
+		SmallDiffActive:           true,
+		SmallDiffLastContent:      "🟡 差分 3px",
+		thread:                    &incidentThread{ChannelID: "c1", StarterID: "s1", ThreadID: "t1", StartedAt: started},
+		escalations:               []escalationState{{since: started, escalated: true}},
+	}
+	n.dmUserStates["u1"] = &dmUserState{lastTier: Tier(1), lastNotify: started}
+	n.watchTargetsState.evaluateAndUpdateGuild("w1", "g1", 25, 10)
+	n.progressTargetsState.evaluateProgress("p1", "g1", 42)
+	n.checkpointState(now, false)
+
+	restored := newCheckpointTestNotifier(dir)
+	restored.restoreState(now.Add(time.Minute))
+
+	st := restored.states["g1"]
+	if st == nil || st.LastTier != Tier(2) || st.WasZeroDiff || st.SmallDiffMessageID != "m1" || !st.SmallDiffActive || st.SmallDiffLastContent != "🟡 差分 3px" {
+		t.Fatalf("guild state not restored: %+v", st)
+	}
+	if st.thread == nil || st.thread.ThreadID != "t1" || !st.thread.StartedAt.Equal(started) {
+		t.Fatalf("thread not restored: %+v", st.thread)
+	}
+	if len(st.escalations) != 1 || !st.escalations[0].escalated || !st.escalations[0].since.Equal(started) {
+		t.Fatalf("escalations not restored: %+v", st.escalations)
+	}
+	if dm := restored.dmUserStates["u1"]; dm == nil || dm.lastTier != Tier(1) || dm.wasZero || !dm.lastNotify.Equal(started) {
+		t.Fatalf("dm state not restored: %+v", dm)
+	}
+	// 復元後に同じ値を評価しても検知/増加を再通知しない
+	if ev := restored.watchTargetsState.evaluateAndUpdateGuild("w1", "g1", 25, 10); ev.sendRecover || ev.sendIncrease {
+		t.Fatalf("watch target re-announced after restore: %+v", ev)
+	}
+	if ev := restored.progressTargetsState.evaluateProgress("p1", "g1", 42); ev.increase || ev.decrease {
+		t.Fatalf("progress target re-announced after restore: %+v", ev)
+	}
+}
+
+func TestNotifierStateCheckpointSkipsUnchangedAndStale(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, NotifierStateFileName)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	n := newCheckpointTestNotifier(dir)
+	n.states["g1"] = &NotificationState{LastTier: Tier(1)}
+	n.checkpointState(now, false)
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 間隔内は書き込まない / 間隔後でも内容が同じなら書き込まない
+	n.states["g1"].LastTier = Tier(3)
+	n.checkpointState(now.Add(time.Second), false)
+	n.states["g1"].LastTier = Tier(1)
+	n.checkpointState(now.Add(notifierStateCheckpointInterval), false)
+	if second, _ := os.ReadFile(path); string(second) != string(first) {
+		t.Fatal("checkpoint rewritten without changes")
+	}
+
+	stale := newCheckpointTestNotifier(dir)
+	stale.restoreState(now.Add(notifierStateMaxAge + time.Minute))
+	if len(stale.states) != 0 {
+		t.Fatalf("stale checkpoint restored: %+v", stale.states)
+	}
+}
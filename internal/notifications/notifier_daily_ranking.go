@@ -2,15 +2,15 @@ package notifications
 
 import (
 	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/embeds"
+	"Koukyo_discord_bot/internal/monitor"
 	"Koukyo_discord_bot/internal/utils"
 	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -18,33 +18,140 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
+// reportCheckInterval 各サーバーの定期レポートの投稿時刻を確認する間隔
+const reportCheckInterval = 30 * time.Second
+
+// reportPeriod 定期レポートの集計期間（保存データと同じ JST の日単位）
+type reportPeriod struct {
+	Cadence string
+	Key     string    // 投稿済みの判定とピーク画像のキー（monitor.*PeriodKey）
+	Start   time.Time // 初日の 0:00（JST）
+	Days    int
+}
+
+// reportBatch 同じ期間のレポートを送るサーバー
+type reportBatch struct {
+	period   reportPeriod
+	guildIDs []string
+}
+
+// lastCompletedPeriod now の時点で直近に終わった JST の日・週（月〜日）・月
+func lastCompletedPeriod(cadence string, now time.Time) reportPeriod {
+	jst := config.DefaultLocation()
+	local := now.In(jst)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, jst)
+	switch cadence {
+	case config.ReportCadenceWeekly:
+		start := today.AddDate(0, 0, -(int(today.Weekday())+6)%7-7)
+		return reportPeriod{Cadence: cadence, Key: monitor.WeekPeriodKey(start), Start: start, Days: 7}
+	case config.ReportCadenceMonthly:
+		thisMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, jst)
+		start := thisMonth.AddDate(0, -1, 0)
+		return reportPeriod{Cadence: cadence, Key: monitor.MonthPeriodKey(start), Start: start, Days: int(thisMonth.Sub(start).Hours() / 24)}
+	default:
+		start := today.AddDate(0, 0, -1)
+		return reportPeriod{Cadence: config.ReportCadenceDaily, Key: monitor.DayPeriodKey(start), Start: start, Days: 1}
+	}
+}
+
+// End 期間の終わり（最終日の翌日 0:00、JST）
+func (p reportPeriod) End() time.Time {
+	return p.Start.AddDate(0, 0, p.Days)
+}
+
+// Dates 期間内の日付キー（古い順）
+func (p reportPeriod) Dates() []string {
+	dates := make([]string, 0, p.Days)
+	for i := 0; i < p.Days; i++ {
+		dates = append(dates, p.Start.AddDate(0, 0, i).Format("2006-01-02"))
+	}
+	return dates
+}
+
 func (n *Notifier) startDailyRankingLoop() {
 	go func() {
-		jst := time.FixedZone("JST", 9*3600)
-		for {
-			now := time.Now().In(jst)
-			next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, jst)
-			sleep := time.Until(next)
-			if sleep < time.Second {
-				sleep = time.Second
+		ticker := time.NewTicker(reportCheckInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			guildIDs := make([]string, 0, len(n.session.State.Guilds))
+			for _, guild := range n.session.State.Guilds {
+				guildIDs = append(guildIDs, guild.ID)
 			}
-			timer := time.NewTimer(sleep)
-			<-timer.C
-
-			reportTime := next.Add(-time.Second).In(jst)
-			reportDate := reportTime.Format("2006-01-02")
-			if reportDate == n.lastDailyReportDate {
-				continue
+			for _, batch := range n.dueReports(now, guildIDs) {
+				if err := n.sendScheduledReport(batch.period, batch.guildIDs); err != nil {
+					log.Printf("Failed to send %s report: %v", batch.period.Cadence, err)
+				}
 			}
-			if err := n.sendDailyRankingReport(reportTime); err != nil {
-				log.Printf("Failed to send daily ranking report: %v", err)
-				continue
-			}
-			n.lastDailyReportDate = reportDate
 		}
 	}()
 }
 
+// dueReports 投稿時刻を迎えたサーバーを集計期間ごとにまとめて返す。
+// 毎日・毎週月曜・毎月1日（サーバーのタイムゾーン）の指定時刻に、その時点で終わっている直近の JST の期間を1度だけ送る。
+// JST より東のタイムゾーンで指定時刻に JST の期間がまだ終わっていなければ、終わりしだい送る。
+// 初めて見たサーバーは、すでに投稿時刻を過ぎていれば記録だけして送らない。
+// 投稿済みの期間はチェックポイントに保存するので、停止中に投稿時刻を迎えたサーバーには再起動後に送る。
+func (n *Notifier) dueReports(now time.Time, guildIDs []string) []reportBatch {
+	n.reportMu.Lock()
+	defer n.reportMu.Unlock()
+	if n.reportSent == nil {
+		n.reportSent = make(map[string]string)
+	}
+	var batches []reportBatch
+	index := make(map[string]int)
+	for _, guildID := range guildIDs {
+		gs := n.settings.GetGuildSettings(guildID)
+		rs := gs.Report()
+		period := lastCompletedPeriod(rs.Cadence, now)
+		sent, seen := n.reportSent[guildID]
+		if sent == period.Key || !reportScheduleReached(rs, period, now.In(gs.Location())) {
+			if !seen {
+				n.reportSent[guildID] = ""
+			}
+			continue
+		}
+		// タイムゾーンや頻度を変えても、同じ期間は1度だけ送る
+		n.reportSent[guildID] = period.Key
+		if !seen {
+			continue
+		}
+		i, ok := index[period.Key]
+		if !ok {
+			i = len(batches)
+			index[period.Key] = i
+			batches = append(batches, reportBatch{period: period})
+		}
+		batches[i].guildIDs = append(batches[i].guildIDs, guildID)
+	}
+	return batches
+}
+
+// reportScheduleReached 今日（local の日付）が投稿日で、指定時刻を過ぎていて、その時刻までに period が終わっているか
+func reportScheduleReached(rs config.ReportSchedule, period reportPeriod, local time.Time) bool {
+	switch rs.Cadence {
+	case config.ReportCadenceWeekly:
+		if local.Weekday() != time.Monday {
+			return false
+		}
+	case config.ReportCadenceMonthly:
+		if local.Day() != 1 {
+			return false
+		}
+	}
+	minute := rs.Minute()
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), minute/60, minute%60, 0, 0, local.Location())
+	if local.Before(scheduled) {
+		return false
+	}
+	// JST より東では、その日の JST の区切りが指定時刻より後に来ることがある
+	_, offset := local.Zone()
+	_, jstOffset := scheduled.In(config.DefaultLocation()).Zone()
+	if lead := time.Duration(offset-jstOffset) * time.Second; lead > 0 {
+		scheduled = scheduled.Add(lead)
+	}
+	return !period.End().After(scheduled)
+}
+
 type rankingEntry struct {
 	ID         string
 	Name       string
@@ -53,124 +160,253 @@ type rankingEntry struct {
 	Count      int
 }
 
-func (n *Notifier) sendDailyRankingReport(reportTime time.Time) error {
+// sendScheduledReport 集計期間 period（JST）のレポートを guildIDs へ投稿する。
+// 項目は各サーバーの設定に従い、時刻表示は各サーバーのタイムゾーン
+func (n *Notifier) sendScheduledReport(period reportPeriod, guildIDs []string) error {
 	if n.dataDir == "" {
 		return fmt.Errorf("dataDir is empty")
 	}
-	path := filepath.Join(n.dataDir, "user_activity.json")
-	entries, err := readUserActivityWithRetry(path, 3, 100*time.Millisecond)
+	entries, err := readUserActivityWithRetry(n.dataDir, 3, 100*time.Millisecond)
 	if err != nil {
 		return err
 	}
 
-	jst := time.FixedZone("JST", 9*3600)
-	dateKey := reportTime.In(jst).Format("2006-01-02")
-
-	vandals := buildRanking(entries, dateKey, true)
-	restores := buildRanking(entries, dateKey, false)
-	activities := buildActivityRanking(entries, dateKey)
-
-	vandalText := formatRanking(vandals)
-	restoreText := formatRanking(restores)
-	activityText := formatActivityRanking(activities)
-	summaryText := n.buildDailyDiffSummary(dateKey, jst)
-
-	titleDate := reportTime.In(jst).Format("2006-01-02 (JST)")
-	peakLiveImage, peakDiffImage, _, _, peakOK := n.monitor.State.GetDailyPeakImages(dateKey)
+	dates := period.Dates()
+	vandalText := formatRanking(buildRanking(entries, dates, true))
+	restoreText := formatRanking(buildRanking(entries, dates, false))
+	activityRanking := buildActivityRanking(entries, dates)
+	activityText := formatActivityRanking(activityRanking)
+	allianceText := embeds.FormatAllianceRanking(activity.BuildAlliancePeriodRanking(entries, dates), reportAllianceLimit)
+	podiumData := buildReportPodiumPNG(period, activityRanking)
+	peakLiveImage, peakDiffImage, _, _, peakOK := n.monitor.State.GetPeakImages(period.Key)
 	peakAttachmentData, peakAttachmentName := buildPeakImageAttachmentData(peakLiveImage, peakDiffImage, peakOK)
-	embed := buildDailyRankingEmbed(titleDate, summaryText, vandalText, restoreText, activityText, "")
+	leaderboardEmbed := buildReportLeaderboardEmbed(entries, period)
 
-	for _, guild := range n.session.State.Guilds {
-		gs := n.settings.GetGuildSettings(guild.ID)
+	for _, guildID := range guildIDs {
+		gs := n.settings.GetGuildSettings(guildID)
 		if !gs.AutoNotifyEnabled || gs.NotificationChannel == nil {
 			continue
 		}
-		peakFiles := buildPeakFilesForSend(peakAttachmentData, peakAttachmentName)
-		msg, err := n.session.ChannelMessageSendComplex(*gs.NotificationChannel, &discordgo.MessageSend{
-			Embeds: []*discordgo.MessageEmbed{embed},
-			Files:  peakFiles,
+		rs := gs.Report()
+		loc := gs.Location()
+		report := scheduledReport{
+			period:    period,
+			titleDate: reportTitleDate(period, loc),
+			schedule:  rs,
+		}
+		if rs.Includes(config.ReportSectionSummary) {
+			report.summaryText = n.buildPeriodDiffSummary(period, loc)
+		}
+		if rs.Includes(config.ReportSectionIncidents) {
+			report.incidentText = n.buildPeriodIncidentSummary(period.Start, period.End(), loc)
+		}
+		report.vandalText, report.restoreText, report.activityText = vandalText, restoreText, activityText
+		report.allianceText = allianceText
+
+		var peakFiles []*discordgo.File
+		if rs.Includes(config.ReportSectionPeak) {
+			peakFiles = buildPeakFilesForSend(peakAttachmentData, peakAttachmentName)
+		}
+		files := peakFiles
+		podiumURL := ""
+		if rs.Includes(config.ReportSectionActivity) && len(podiumData) > 0 {
+			files = append(files, &discordgo.File{
+				Name:        reportPodiumName,
+				ContentType: "image/png",
+				Reader:      bytes.NewReader(podiumData),
+			})
+			podiumURL = "attachment://" + reportPodiumName
+		}
+		messageEmbeds := func(peakLink string) []*discordgo.MessageEmbed {
+			out := []*discordgo.MessageEmbed{buildDailyRankingEmbed(report, peakLink)}
+			if podiumURL != "" {
+				out[0].Image = &discordgo.MessageEmbedImage{URL: podiumURL}
+			}
+			if rs.Includes(config.ReportSectionLeaderboard) && leaderboardEmbed != nil {
+				out = append(out, leaderboardEmbed)
+			}
+			return out
+		}
+		msg, err := n.sendChannelMessage(guildID, *gs.NotificationChannel, outboxKindReport, &discordgo.MessageSend{
+			Embeds: messageEmbeds(""),
+			Files:  files,
 		})
+		if errors.Is(err, errOutboxQueued) {
+			// 再送分はピーク画像リンクの差し替えをしない
+			log.Printf("%s report (%s) for guild %s deferred: %v", period.Cadence, period.Key, guildID, err)
+			continue
+		}
 		if err != nil {
-			log.Printf("Failed to send daily ranking to guild %s: %v", guild.ID, err)
+			log.Printf("Failed to send %s report to guild %s: %v", period.Cadence, guildID, err)
 			continue
 		}
-		if peakAttachmentName != "" && len(msg.Attachments) > 0 {
-			link := msg.Attachments[0].URL
-			updated := buildDailyRankingEmbed(titleDate, summaryText, vandalText, restoreText, activityText, link)
+		if peakLink := attachmentURL(msg, peakAttachmentName); len(peakFiles) > 0 && peakLink != "" {
+			// 送信後は添付の実 URL を参照する
+			if podiumURL != "" && len(msg.Embeds) > 0 && msg.Embeds[0].Image != nil {
+				podiumURL = msg.Embeds[0].Image.URL
+			}
+			updated := messageEmbeds(peakLink)
 			if _, err := n.session.ChannelMessageEditComplex(&discordgo.MessageEdit{
 				ID:      msg.ID,
 				Channel: msg.ChannelID,
-				Embeds:  &[]*discordgo.MessageEmbed{updated},
+				Embeds:  &updated,
 			}); err != nil {
-				log.Printf("Failed to update daily ranking link for guild %s: %v", guild.ID, err)
+				log.Printf("Failed to update %s report link for guild %s: %v", period.Cadence, guildID, err)
 			}
 		}
-		log.Printf("Sent daily ranking to guild %s", guild.ID)
+		log.Printf("Sent %s report (%s) to guild %s", period.Cadence, period.Key, guildID)
 	}
 
 	return nil
 }
 
-func readUserActivityWithRetry(path string, attempts int, delay time.Duration) (map[string]*activity.UserActivity, error) {
+// buildReportLeaderboardEmbed 期間の最終日までのリーダーボード（月次は直近30日、それ以外は直近7日）
+func buildReportLeaderboardEmbed(entries map[string]*activity.UserActivity, period reportPeriod) *discordgo.MessageEmbed {
+	window := activity.LeaderboardWeek
+	if period.Cadence == config.ReportCadenceMonthly {
+		window = activity.LeaderboardMonth
+	}
+	end := period.End().AddDate(0, 0, -1)
+	boards := make([]activity.Leaderboard, 0, 3)
+	for _, kind := range []string{activity.LeaderboardVandal, activity.LeaderboardRestore, activity.LeaderboardActivity} {
+		boards = append(boards, activity.BuildLeaderboard(entries, kind, window, end))
+	}
+	return embeds.BuildLeaderboardDigestEmbed(boards)
+}
+
+const (
+	// reportPodiumName 定期レポートに添付する表彰台画像のファイル名
+	reportPodiumName = "report_podium.png"
+	// reportAllianceLimit 定期レポートの同盟ランキングの件数
+	reportAllianceLimit = 10
+)
+
+// buildReportPodiumPNG 期間の総合ランキング（プラスのみ）の表彰台画像
+func buildReportPodiumPNG(period reportPeriod, ranking []rankingEntry) []byte {
+	podium := make([]embeds.PodiumEntry, 0, 9)
+	for _, entry := range ranking {
+		if entry.Count <= 0 || len(podium) == cap(podium) {
+			break
+		}
+		podium = append(podium, embeds.PodiumEntry{
+			ID:       entry.ID,
+			Name:     utils.FormatUserDisplayName(entry.Name, entry.ID),
+			Alliance: entry.Alliance,
+			Count:    entry.Count,
+		})
+	}
+	if len(podium) == 0 {
+		return nil
+	}
+	last := period.End().AddDate(0, 0, -1)
+	subtitle := period.Start.Format("2006-01-02") + " (JST)"
+	if period.Days > 1 {
+		subtitle = fmt.Sprintf("%s - %s (JST)", period.Start.Format("2006-01-02"), last.Format("2006-01-02"))
+	}
+	title := strings.ToUpper(period.Cadence[:1]) + period.Cadence[1:] + " activity ranking (restore - vandal)"
+	buf, err := embeds.BuildLeaderboardPodiumPNG(title, subtitle, podium)
+	if err != nil {
+		log.Printf("Failed to render %s report podium: %v", period.Cadence, err)
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// attachmentURL 送信済みメッセージから指定ファイル名の添付 URL を探す
+func attachmentURL(msg *discordgo.Message, name string) string {
+	if msg == nil || name == "" {
+		return ""
+	}
+	for _, a := range msg.Attachments {
+		if a.Filename == name {
+			return a.URL
+		}
+	}
+	return ""
+}
+
+// reportTitleDate タイトルの期間。JST 以外のサーバーには集計が JST 区切りであることと表示タイムゾーンを添える
+func reportTitleDate(period reportPeriod, loc *time.Location) string {
+	var label string
+	switch {
+	case period.Cadence == config.ReportCadenceMonthly:
+		label = period.Start.Format("2006-01")
+	case period.Days > 1:
+		label = period.Start.Format("2006-01-02") + "〜" + period.End().AddDate(0, 0, -1).Format("2006-01-02")
+	default:
+		label = period.Start.Format("2006-01-02")
+	}
+	if loc == config.DefaultLocation() {
+		return label + " (JST)"
+	}
+	return fmt.Sprintf("%s (JST区切り・時刻は%s)", label, period.Start.In(loc).Format("MST"))
+}
+
+func readUserActivityWithRetry(dataDir string, attempts int, delay time.Duration) (map[string]*activity.UserActivity, error) {
 	if attempts < 1 {
 		attempts = 1
 	}
 	var lastErr error
 	for i := 0; i < attempts; i++ {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			lastErr = err
-		} else {
-			var entries map[string]*activity.UserActivity
-			if err := json.Unmarshal(data, &entries); err != nil {
-				lastErr = err
-			} else {
-				return entries, nil
-			}
+		entries, err := activity.LoadUserActivityMap(dataDir)
+		if err == nil {
+			return entries, nil
 		}
+		lastErr = err
 		time.Sleep(delay)
 	}
 	return nil, lastErr
 }
 
-func buildDailyRankingEmbed(titleDate, summaryText, vandalText, restoreText, activityText, peakLink string) *discordgo.MessageEmbed {
-	fields := []*discordgo.MessageEmbedField{
-		{
-			Name:   "📈 日次サマリ",
-			Value:  summaryText,
-			Inline: false,
-		},
-		{
-			Name:   "🚨 荒らしランキング",
-			Value:  vandalText,
-			Inline: false,
-		},
-		{
-			Name:   "🛠️ 修復ランキング",
-			Value:  restoreText,
-			Inline: false,
-		},
-		{
-			Name:   "🧮 総合ランキング (修復 - 荒らし)",
-			Value:  activityText,
-			Inline: false,
-		},
+// scheduledReport 定期レポート1件分の内容（空の項目は表示しない）
+type scheduledReport struct {
+	period       reportPeriod
+	titleDate    string
+	schedule     config.ReportSchedule
+	summaryText  string
+	incidentText string
+	vandalText   string
+	restoreText  string
+	activityText string
+	allianceText string
+}
+
+// cadenceName 「日次」「週次」「月次」
+func cadenceName(cadence string) string {
+	switch cadence {
+	case config.ReportCadenceWeekly:
+		return "週次"
+	case config.ReportCadenceMonthly:
+		return "月次"
+	default:
+		return "日次"
 	}
-	if peakLink != "" {
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "🖼️ ピーク差分画像",
-			Value:  peakLink,
-			Inline: false,
-		})
+}
+
+func buildDailyRankingEmbed(report scheduledReport, peakLink string) *discordgo.MessageEmbed {
+	name := cadenceName(report.period.Cadence)
+	rs := report.schedule
+	fields := make([]*discordgo.MessageEmbedField, 0, len(config.ReportSections))
+	add := func(section, title, value string) {
+		if rs.Includes(section) && value != "" {
+			fields = append(fields, &discordgo.MessageEmbedField{Name: title, Value: value, Inline: false})
+		}
 	}
+	add(config.ReportSectionSummary, "📈 "+name+"サマリ", report.summaryText)
+	add(config.ReportSectionIncidents, "🧾 インシデント", report.incidentText)
+	add(config.ReportSectionVandal, "🚨 荒らしランキング", report.vandalText)
+	add(config.ReportSectionRestore, "🛠️ 修復ランキング", report.restoreText)
+	add(config.ReportSectionActivity, "🧮 総合ランキング (修復 - 荒らし)", report.activityText)
+	add(config.ReportSectionAlliance, "🏴 同盟ランキング (荒らし数順)", report.allianceText)
+	add(config.ReportSectionPeak, "🖼️ ピーク差分画像", peakLink)
 	return &discordgo.MessageEmbed{
-		Title:       "📊 日次ランキング",
-		Description: fmt.Sprintf("%s の荒らし/修復/総合ランキング", titleDate),
+		Title:       "📊 " + name + "ランキング",
+		Description: fmt.Sprintf("%s の荒らし/修復/総合ランキング", report.titleDate),
 		Color:       0x1E90FF,
 		Fields:      fields,
 		Timestamp:   time.Now().Format(time.RFC3339),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "自動日次レポート",
+			Text: "自動" + name + "レポート",
 		},
 	}
 }
@@ -210,14 +446,17 @@ func buildPeakFilesForSend(attachmentData []byte, attachmentName string) []*disc
 	}}
 }
 
-func buildRanking(entries map[string]*activity.UserActivity, dateKey string, vandal bool) []rankingEntry {
+// buildRanking 期間（JST の日付キー）内の荒らし/修復数の合計でランキングを作る
+func buildRanking(entries map[string]*activity.UserActivity, dates []string, vandal bool) []rankingEntry {
 	out := make([]rankingEntry, 0)
 	for id, entry := range entries {
 		var count int
-		if vandal {
-			count = entry.DailyVandalCounts[dateKey]
-		} else {
-			count = entry.DailyRestoredCounts[dateKey]
+		for _, dateKey := range dates {
+			if vandal {
+				count += entry.DailyVandalCounts[dateKey]
+			} else {
+				count += entry.DailyRestoredCounts[dateKey]
+			}
 		}
 		if count <= 0 {
 			continue
@@ -263,13 +502,19 @@ func formatRanking(entries []rankingEntry) string {
 	return strings.Join(lines, "\n")
 }
 
-func (n *Notifier) buildDailyDiffSummary(dateKey string, jst *time.Location) string {
+// buildPeriodDiffSummary 期間内の日次サマリをまとめた差分率の要約
+func (n *Notifier) buildPeriodDiffSummary(period reportPeriod, loc *time.Location) string {
 	if n.monitor == nil || n.monitor.State == nil {
 		return "監視データなし"
 	}
 
-	summary, ok := n.monitor.State.GetDailySummary(dateKey)
-	if !ok {
+	var summaries []monitor.DailySummary
+	for _, dateKey := range period.Dates() {
+		if summary, ok := n.monitor.State.GetDailySummary(dateKey); ok {
+			summaries = append(summaries, summary)
+		}
+	}
+	if len(summaries) == 0 {
 		return strings.Join([]string{
 			"最新差分率: N/A",
 			"最大差分率: N/A",
@@ -278,6 +523,7 @@ func (n *Notifier) buildDailyDiffSummary(dateKey string, jst *time.Location) str
 			"記録数: 0",
 		}, "\n")
 	}
+	summary := mergeDailySummaries(summaries)
 	overall := summary.Overall
 	weighted := summary.Weighted
 
@@ -289,18 +535,25 @@ func (n *Notifier) buildDailyDiffSummary(dateKey string, jst *time.Location) str
 	if weighted.Count > 0 {
 		avgWeighted = weighted.Sum / float64(weighted.Count)
 	}
+	clock := "15:04"
+	if period.Days > 1 {
+		clock = "01/02 15:04"
+	}
 
 	lines := []string{
 		fmt.Sprintf("最新差分率: %s", formatPercent(overall.Latest, overall.Count > 0)),
-		fmt.Sprintf("最大差分率: %s %s", formatPercent(overall.Max, overall.Count > 0), formatTimeJST(overall.PeakAt, overall.Count > 0, jst)),
+		fmt.Sprintf("最大差分率: %s %s", formatPercent(overall.Max, overall.Count > 0), formatClock(overall.PeakAt, overall.Count > 0, loc, clock)),
 		fmt.Sprintf("最小差分率: %s", formatPercent(overall.Min, overall.Count > 0)),
 		fmt.Sprintf("平均差分率: %s", formatPercent(avgOverall, overall.Count > 0)),
 		fmt.Sprintf("記録数: %d", overall.Count),
 	}
+	if period.Days > 1 {
+		lines = append(lines, fmt.Sprintf("集計日数: %d/%d日", len(summaries), period.Days))
+	}
 	if weighted.Count > 0 {
 		lines = append(lines,
 			fmt.Sprintf("最新加重差分率: %s", formatPercent(weighted.Latest, true)),
-			fmt.Sprintf("最大加重差分率: %s %s", formatPercent(weighted.Max, true), formatTimeJST(weighted.PeakAt, true, jst)),
+			fmt.Sprintf("最大加重差分率: %s %s", formatPercent(weighted.Max, true), formatClock(weighted.PeakAt, true, loc, clock)),
 			fmt.Sprintf("最小加重差分率: %s", formatPercent(weighted.Min, true)),
 			fmt.Sprintf("平均加重差分率: %s", formatPercent(avgWeighted, true)),
 		)
@@ -308,6 +561,39 @@ func (n *Notifier) buildDailyDiffSummary(dateKey string, jst *time.Location) str
 	return strings.Join(lines, "\n")
 }
 
+// mergeDailySummaries 複数日の日次サマリを1つにまとめる
+func mergeDailySummaries(summaries []monitor.DailySummary) monitor.DailySummary {
+	var merged monitor.DailySummary
+	for _, s := range summaries {
+		merged.Overall = mergeDailyMetric(merged.Overall, s.Overall)
+		merged.Weighted = mergeDailyMetric(merged.Weighted, s.Weighted)
+	}
+	return merged
+}
+
+func mergeDailyMetric(a, b monitor.DailyMetricSummary) monitor.DailyMetricSummary {
+	if b.Count == 0 {
+		return a
+	}
+	if a.Count == 0 {
+		return b
+	}
+	if b.LatestAt.After(a.LatestAt) {
+		a.Latest = b.Latest
+		a.LatestAt = b.LatestAt
+	}
+	if b.Max > a.Max {
+		a.Max = b.Max
+		a.PeakAt = b.PeakAt
+	}
+	if b.Min < a.Min {
+		a.Min = b.Min
+	}
+	a.Sum += b.Sum
+	a.Count += b.Count
+	return a
+}
+
 func formatPercent(value float64, ok bool) string {
 	if !ok {
 		return "N/A"
@@ -315,17 +601,21 @@ func formatPercent(value float64, ok bool) string {
 	return fmt.Sprintf("%.2f%%", value)
 }
 
-func formatTimeJST(t time.Time, ok bool, jst *time.Location) string {
+func formatClock(t time.Time, ok bool, loc *time.Location, layout string) string {
 	if !ok || t.IsZero() {
 		return ""
 	}
-	return fmt.Sprintf("(%s)", t.In(jst).Format("15:04"))
+	return fmt.Sprintf("(%s)", t.In(loc).Format(layout))
 }
 
-func buildActivityRanking(entries map[string]*activity.UserActivity, dateKey string) []rankingEntry {
+// buildActivityRanking 期間（JST の日付キー）内の総合スコアの合計でランキングを作る
+func buildActivityRanking(entries map[string]*activity.UserActivity, dates []string) []rankingEntry {
 	out := make([]rankingEntry, 0)
 	for id, entry := range entries {
-		count := entry.DailyActivityScores[dateKey]
+		count := 0
+		for _, dateKey := range dates {
+			count += entry.DailyActivityScores[dateKey]
+		}
 		if count == 0 {
 			continue
 		}
@@ -2,7 +2,16 @@ package notifications
 
 import (
 	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/incidents"
+	"Koukyo_discord_bot/internal/monitor"
 )
 
 func TestBuildPeakFilesForSendCreatesFreshReader(t *testing.T) {
@@ -52,3 +61,135 @@ func TestBuildPeakImageAttachmentDataFallsBackToDiffCopy(t *testing.T) {
 		t.Fatalf("diff slice was mutated; expected copied data")
 	}
 }
+
+func TestBuildDailyIncidentSummaryCountsIncidentsStartedThatDay(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*3600)
+	store := incidents.NewStore("", nil)
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, jst)
+	store.Observe(day.Add(-time.Hour), incidents.Observation{Percentage: 9, DiffPixels: 90})
+	store.Observe(day.Add(-time.Minute), incidents.Observation{})
+	store.Observe(day.Add(21*time.Hour+3*time.Minute), incidents.Observation{Percentage: 3, DiffPixels: 30})
+	store.Observe(day.Add(21*time.Hour+47*time.Minute), incidents.Observation{})
+
+	n := &Notifier{incidents: store}
+	got := n.buildPeriodIncidentSummary(day, day.AddDate(0, 0, 1), jst)
+	if !strings.Contains(got, "件数: 1") || !strings.Contains(got, "#2 01/02 21:03–21:47 (44分) ピーク 3.00%") {
+		t.Fatalf("unexpected summary:\n%s", got)
+	}
+	if got := n.buildPeriodIncidentSummary(day.AddDate(0, 0, 1), day.AddDate(0, 0, 2), jst); got != "なし" {
+		t.Fatalf("expected no incidents, got %q", got)
+	}
+}
+
+func TestDueReportsFollowGuildSchedule(t *testing.T) {
+	t.Parallel()
+
+	sm := config.NewSettingsManager(filepath.Join(t.TempDir(), "settings.json"))
+	defer sm.Close()
+	sm.SetGuildSettings("utc", config.GuildSettings{Timezone: "UTC"})
+	sm.SetGuildSettings("utc10", config.GuildSettings{Timezone: "UTC", ReportSchedule: &config.ReportSchedule{Time: "10:00"}})
+	sm.SetGuildSettings("sydney", config.GuildSettings{Timezone: "Australia/Sydney", ReportSchedule: &config.ReportSchedule{Cadence: config.ReportCadenceWeekly}})
+	n := &Notifier{settings: sm}
+	guilds := []string{"jst", "utc", "utc10", "sydney"}
+
+	due := func(at time.Time) map[string]string {
+		got := make(map[string]string)
+		for _, batch := range n.dueReports(at, guilds) {
+			for _, guildID := range batch.guildIDs {
+				got[guildID] = batch.period.Key
+			}
+		}
+		return got
+	}
+	expect := func(at time.Time, want map[string]string) {
+		t.Helper()
+		if got := due(at); !reflect.DeepEqual(got, want) {
+			t.Fatalf("at %s: got %v, want %v", at.Format(time.RFC3339), got, want)
+		}
+	}
+
+	// 初回は記録のみ（2026-03-01 は日曜）
+	expect(time.Date(2026, 3, 1, 14, 50, 0, 0, time.UTC), map[string]string{})
+	// 15:00 UTC = JST の 0:00。JST のサーバーが前日分を、月曜 02:00 (AEDT) のシドニーが JST で終わった週の分を受け取る
+	expect(time.Date(2026, 3, 1, 15, 0, 10, 0, time.UTC), map[string]string{"jst": "2026-03-01", "sydney": "week:2026-02-23"})
+	// UTC の 0:00 には直近で終わった JST の1日分を送る
+	expect(time.Date(2026, 3, 2, 0, 0, 10, 0, time.UTC), map[string]string{"utc": "2026-03-01"})
+	// 10:00 指定は 10:00 に送る
+	expect(time.Date(2026, 3, 2, 10, 0, 10, 0, time.UTC), map[string]string{"utc10": "2026-03-01"})
+	// 同じ日の JST 区切り後に再送しない
+	expect(time.Date(2026, 3, 2, 15, 0, 10, 0, time.UTC), map[string]string{"jst": "2026-03-02"})
+
+	// 翌週（シドニーのみ確認）: シドニーの月曜 0:00 (AEDT) は JST の日曜 22:00。JST の週が終わるのを待って月〜日の分を送る
+	guilds = []string{"sydney"}
+	expect(time.Date(2026, 3, 8, 13, 0, 10, 0, time.UTC), map[string]string{})
+	expect(time.Date(2026, 3, 8, 15, 0, 10, 0, time.UTC), map[string]string{"sydney": "week:2026-03-02"})
+}
+
+func TestReportPeriodsAndAggregation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC) // JST 03/02 (月) 05:00
+	weekly := lastCompletedPeriod(config.ReportCadenceWeekly, now)
+	if weekly.Key != "week:2026-02-23" || weekly.Days != 7 || weekly.Dates()[6] != "2026-03-01" {
+		t.Fatalf("weekly = %+v", weekly)
+	}
+	monthly := lastCompletedPeriod(config.ReportCadenceMonthly, now)
+	if monthly.Key != "month:2026-02" || monthly.Days != 28 || reportTitleDate(monthly, config.DefaultLocation()) != "2026-02 (JST)" {
+		t.Fatalf("monthly = %+v", monthly)
+	}
+	if got := reportTitleDate(weekly, time.UTC); got != "2026-02-23〜2026-03-01 (JST区切り・時刻はUTC)" {
+		t.Fatalf("weekly title = %q", got)
+	}
+
+	entries := map[string]*activity.UserActivity{
+		"1": {Name: "a", DailyVandalCounts: map[string]int{"2026-02-23": 2, "2026-03-01": 3, "2026-03-02": 50}},
+		"2": {Name: "b", DailyVandalCounts: map[string]int{"2026-02-25": 4}},
+	}
+	ranking := buildRanking(entries, weekly.Dates(), true)
+	if len(ranking) != 2 || ranking[0].Name != "a" || ranking[0].Count != 5 || ranking[1].Count != 4 {
+		t.Fatalf("ranking = %+v", ranking)
+	}
+
+	t1 := time.Date(2026, 2, 23, 12, 0, 0, 0, time.UTC)
+	t2 := t1.AddDate(0, 0, 1)
+	merged := mergeDailySummaries([]monitor.DailySummary{
+		{Overall: monitor.DailyMetricSummary{Latest: 3, LatestAt: t1, Max: 9, PeakAt: t1, Min: 1, Sum: 10, Count: 4}},
+		{Overall: monitor.DailyMetricSummary{Latest: 2, LatestAt: t2, Max: 5, PeakAt: t2, Min: 0.5, Sum: 6, Count: 3}},
+	})
+	if o := merged.Overall; o.Latest != 2 || o.Max != 9 || !o.PeakAt.Equal(t1) || o.Min != 0.5 || o.Sum != 16 || o.Count != 7 || merged.Weighted.Count != 0 {
+		t.Fatalf("merged = %+v", merged)
+	}
+}
+
+func TestDueReportsSendMissedReportAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	sm := config.NewSettingsManager(filepath.Join(dir, "settings.json"))
+	defer sm.Close()
+	sm.SetGuildSettings("weekly", config.GuildSettings{ReportSchedule: &config.ReportSchedule{Cadence: config.ReportCadenceWeekly}})
+	guilds := []string{"weekly"}
+
+	// 前の週に記録してから停止し、月曜の投稿時刻を過ぎてから再起動する
+	before := time.Date(2026, 2, 26, 3, 0, 0, 0, time.UTC)
+	n := newCheckpointTestNotifier(dir)
+	n.settings = sm
+	if due := n.dueReports(before, guilds); len(due) != 0 {
+		t.Fatalf("first sighting sent reports: %+v", due)
+	}
+	n.checkpointState(before, true)
+
+	after := time.Date(2026, 3, 2, 3, 0, 0, 0, time.UTC) // JST 03/02 (月) 12:00
+	restarted := newCheckpointTestNotifier(dir)
+	restarted.settings = sm
+	restarted.restoreState(after)
+	due := restarted.dueReports(after, guilds)
+	if len(due) != 1 || due[0].period.Key != "week:2026-02-23" || len(due[0].guildIDs) != 1 {
+		t.Fatalf("missed weekly report not sent after restart: %+v", due)
+	}
+	if due := restarted.dueReports(after.Add(time.Minute), guilds); len(due) != 0 {
+		t.Fatalf("report sent twice: %+v", due)
+	}
+}
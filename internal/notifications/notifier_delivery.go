@@ -0,0 +1,244 @@
+package notifications
+
+import (
+	"Koukyo_discord_bot/internal/config"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// digestCheckInterval ダイジェストの投稿時刻を確認する間隔
+	digestCheckInterval = 30 * time.Second
+	// digestMaxItems 1ギルドで保持する項目数の上限（超過分は古い順に捨てて件数のみ数える）
+	digestMaxItems = 200
+	// digestDescriptionLimit Embed description の上限（4096）に余裕を持たせた値
+	digestDescriptionLimit = 3800
+)
+
+// digestItem ダイジェストに載せる通知1件
+type digestItem struct {
+	at       time.Time
+	kind     string // 表示用の種類（Tier上昇、小規模差分、新規荒らし…）
+	line     string // ダイジェストの1行
+	critical bool   // ダイジェスト配信でも即時送信する
+	key      string // 同じキーは最新の1件だけ残す（小規模差分の編集など）
+}
+
+// dispatchKind ログ用の種類（重要通知は種類を持たない）
+func (item digestItem) dispatchKind() string {
+	if item.kind == "" {
+		return "重要通知"
+	}
+	return item.kind
+}
+
+// deliverFunc ギルドの配信モードに従って送信処理を投入する
+type deliverFunc func(guildID string, settings config.GuildSettings, item digestItem, fn dispatchFunc)
+
+// postFunc 通知をチャンネルへ送る（一時的な失敗は outbox に保存される）
+type postFunc func(guildID, channelID, kind string, msg *discordgo.MessageSend) (*discordgo.Message, error)
+
+// deliveryStrategy ギルドごとの通知の届け方。高/低優先度キューの手前に挟む。
+type deliveryStrategy interface {
+	high(item digestItem, fn dispatchFunc)
+	low(key string, item digestItem, fn dispatchFunc)
+}
+
+// immediateDelivery 従来どおりその都度キューへ投入する
+type immediateDelivery struct {
+	n *Notifier
+}
+
+func (d immediateDelivery) high(item digestItem, fn dispatchFunc) { d.n.enqueueHigh(item.dispatchKind(), fn) }
+
+func (d immediateDelivery) low(key string, _ digestItem, fn dispatchFunc) { d.n.enqueueLow(key, fn) }
+
+// digestDelivery 重要な通知以外をバッファし、digest ループが N 分ごとにまとめて送る
+type digestDelivery struct {
+	n       *Notifier
+	guildID string
+}
+
+func (d digestDelivery) high(item digestItem, fn dispatchFunc) {
+	if item.critical {
+		d.n.enqueueHigh(item.dispatchKind(), fn)
+		return
+	}
+	d.n.bufferDigest(d.guildID, item)
+}
+
+func (d digestDelivery) low(key string, item digestItem, fn dispatchFunc) {
+	if item.critical {
+		d.n.enqueueLow(key, fn)
+		return
+	}
+	if item.key == "" {
+		item.key = key
+	}
+	d.n.bufferDigest(d.guildID, item)
+}
+
+// delivery ギルド設定に応じた配信戦略（ダイジェストは通知チャンネル必須）
+func (n *Notifier) delivery(guildID string, settings config.GuildSettings) deliveryStrategy {
+	if settings.DigestEnabled() && settings.NotificationChannel != nil {
+		return digestDelivery{n: n, guildID: guildID}
+	}
+	return immediateDelivery{n: n}
+}
+
+// deliverHigh 高優先度の送信処理をギルドの配信モードに従って投入する
+func (n *Notifier) deliverHigh(guildID string, settings config.GuildSettings, item digestItem, fn dispatchFunc) {
+	if item.at.IsZero() {
+		item.at = time.Now()
+	}
+	n.delivery(guildID, settings).high(item, fn)
+}
+
+// deliverLow 低優先度（キー単位で最新のみ）の送信処理をギルドの配信モードに従って投入する
+func (n *Notifier) deliverLow(guildID string, settings config.GuildSettings, key string, item digestItem, fn dispatchFunc) {
+	if item.at.IsZero() {
+		item.at = time.Now()
+	}
+	n.delivery(guildID, settings).low(key, item, fn)
+}
+
+// digestBuffer ギルドごとの未送信ダイジェスト
+type digestBuffer struct {
+	since   time.Time
+	items   []digestItem
+	dropped int
+}
+
+func (n *Notifier) bufferDigest(guildID string, item digestItem) {
+	n.digestMu.Lock()
+	defer n.digestMu.Unlock()
+	if n.digests == nil {
+		n.digests = make(map[string]*digestBuffer)
+	}
+	buf := n.digests[guildID]
+	if buf == nil {
+		buf = &digestBuffer{since: item.at}
+		n.digests[guildID] = buf
+	}
+	if item.key != "" {
+		for i, existing := range buf.items {
+			if existing.key == item.key {
+				buf.items = append(buf.items[:i], buf.items[i+1:]...)
+				break
+			}
+		}
+	}
+	buf.items = append(buf.items, item)
+	if over := len(buf.items) - digestMaxItems; over > 0 {
+		buf.items = append([]digestItem(nil), buf.items[over:]...)
+		buf.dropped += over
+	}
+}
+
+// takeDueDigests 投稿時刻に達した（またはダイジェストを解除した）ギルドのバッファを取り出す
+func (n *Notifier) takeDueDigests(now time.Time, settingsFor func(guildID string) config.GuildSettings) map[string]*digestBuffer {
+	n.digestMu.Lock()
+	defer n.digestMu.Unlock()
+	due := make(map[string]*digestBuffer)
+	for guildID, buf := range n.digests {
+		settings := settingsFor(guildID)
+		if settings.DigestEnabled() && now.Sub(buf.since) < settings.DigestInterval() {
+			continue
+		}
+		due[guildID] = buf
+		delete(n.digests, guildID)
+	}
+	return due
+}
+
+func (n *Notifier) startDigestLoop() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("PANIC in digestLoop: %v", r)
+			}
+		}()
+
+		ticker := time.NewTicker(digestCheckInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			for guildID, buf := range n.takeDueDigests(now, n.settings.GetGuildSettings) {
+				settings := n.settings.GetGuildSettings(guildID)
+				if settings.NotificationChannel == nil {
+					continue
+				}
+				channelID := *settings.NotificationChannel
+				embed := n.buildDigestEmbed(buf, settings.Location(), now)
+				n.enqueueHigh(outboxKindDigest, func() {
+					msg := &discordgo.MessageSend{
+						Content: n.artworkPrefix() + "📰 通知ダイジェスト",
+						Embeds:  []*discordgo.MessageEmbed{embed},
+					}
+					err := n.outbox.send(guildID, channelID, n.artwork().ID, outboxKindDigest, nil, msg, func(msg *discordgo.MessageSend) error {
+						_, err := n.session.ChannelMessageSendComplex(channelID, msg)
+						return err
+					})
+					if err != nil {
+						log.Printf("Failed to send digest to guild %s: %v", guildID, err)
+					}
+				})
+			}
+		}
+	}()
+}
+
+// buildDigestEmbed バッファ内容を1つの Embed にまとめる
+func (n *Notifier) buildDigestEmbed(buf *digestBuffer, loc *time.Location, now time.Time) *discordgo.MessageEmbed {
+	counts := make(map[string]int)
+	var kinds []string
+	for _, item := range buf.items {
+		if counts[item.kind] == 0 {
+			kinds = append(kinds, item.kind)
+		}
+		counts[item.kind]++
+	}
+
+	var b strings.Builder
+	omitted := buf.dropped
+	for i, item := range buf.items {
+		line := fmt.Sprintf("`%s` %s\n", item.at.In(loc).Format("15:04"), item.line)
+		if b.Len()+len(line) > digestDescriptionLimit {
+			omitted += len(buf.items) - i
+			break
+		}
+		b.WriteString(line)
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "…他 %d件", omitted)
+	}
+
+	summary := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		summary = append(summary, fmt.Sprintf("%s %d件", kind, counts[kind]))
+	}
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("📰 通知ダイジェスト（%d件）", len(buf.items)+buf.dropped),
+		Description: strings.TrimRight(b.String(), "\n"),
+		Color:       0x5865F2,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "期間",
+				Value:  fmt.Sprintf("%s ～ %s", buf.since.In(loc).Format("15:04"), now.In(loc).Format("15:04")),
+				Inline: true,
+			},
+			{
+				Name:   "内訳",
+				Value:  strings.Join(summary, " / "),
+				Inline: true,
+			},
+		},
+		Timestamp: now.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "自動通知システム - ダイジェスト配信",
+		},
+	}
+}
@@ -0,0 +1,129 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Koukyo_discord_bot/internal/config"
+)
+
+func TestBufferDigestCoalescesByKey(t *testing.T) {
+	t.Parallel()
+
+	n := &Notifier{}
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	n.bufferDigest("g1", digestItem{at: now, kind: "小規模差分", line: "1px", key: "small"})
+	n.bufferDigest("g1", digestItem{at: now.Add(time.Minute), kind: "新規荒らし", line: "user"})
+	n.bufferDigest("g1", digestItem{at: now.Add(2 * time.Minute), kind: "小規模差分", line: "3px", key: "small"})
+
+	buf := n.digests["g1"]
+	if len(buf.items) != 2 {
+		t.Fatalf("items = %d, want 2", len(buf.items))
+	}
+	if last := buf.items[1]; last.line != "3px" {
+		t.Fatalf("coalesced item = %q, want latest", last.line)
+	}
+	if !buf.since.Equal(now) {
+		t.Fatalf("since = %v, want first item time", buf.since)
+	}
+}
+
+func TestDigestDeliveryBypassesBufferForCritical(t *testing.T) {
+	t.Parallel()
+
+	n := &Notifier{dispatchHigh: make(chan dispatchFunc, 1)}
+	channelID := "c1"
+	settings := config.DefaultGuildSettings
+	settings.NotificationChannel = &channelID
+	settings.DeliveryMode = config.DeliveryDigest
+
+	n.deliverHigh("g1", settings, digestItem{kind: "Tier上昇", line: "up"}, func() {})
+	n.deliverHigh("g1", settings, digestItem{critical: true}, func() {})
+
+	if got := len(n.digests["g1"].items); got != 1 {
+		t.Fatalf("buffered = %d, want 1", got)
+	}
+	if got := len(n.dispatchHigh); got != 1 {
+		t.Fatalf("queued = %d, want 1", got)
+	}
+}
+
+func TestTakeDueDigests(t *testing.T) {
+	t.Parallel()
+
+	n := &Notifier{}
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	n.bufferDigest("g1", digestItem{at: now, kind: "Tier上昇", line: "up"})
+
+	digest := config.DefaultGuildSettings
+	digest.DeliveryMode = config.DeliveryDigest
+	digest.DigestIntervalMinutes = 10
+	settingsFor := func(string) config.GuildSettings { return digest }
+
+	if due := n.takeDueDigests(now.Add(5*time.Minute), settingsFor); len(due) != 0 {
+		t.Fatalf("due before interval = %d", len(due))
+	}
+	if due := n.takeDueDigests(now.Add(10*time.Minute), settingsFor); len(due) != 1 {
+		t.Fatalf("due after interval = %d", len(due))
+	}
+	if len(n.digests) != 0 {
+		t.Fatalf("buffer not cleared: %d", len(n.digests))
+	}
+
+	// 即時配信に戻したギルドの残りはすぐ送る
+	n.bufferDigest("g1", digestItem{at: now, kind: "Tier上昇", line: "up"})
+	immediate := func(string) config.GuildSettings { return config.DefaultGuildSettings }
+	if due := n.takeDueDigests(now, immediate); len(due) != 1 {
+		t.Fatalf("due after switching to immediate = %d", len(due))
+	}
+}
+
+func TestBuildDigestEmbedSummarizesKinds(t *testing.T) {
+	t.Parallel()
+
+	n := &Notifier{}
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	buf := &digestBuffer{
+		since: now.Add(-15 * time.Minute),
+		items: []digestItem{
+			{at: now.Add(-10 * time.Minute), kind: "Tier上昇", line: "a"},
+			{at: now.Add(-5 * time.Minute), kind: "新規荒らし", line: "b"},
+			{at: now.Add(-time.Minute), kind: "Tier上昇", line: "c"},
+		},
+		dropped: 2,
+	}
+	embed := n.buildDigestEmbed(buf, time.UTC, now)
+	if !strings.Contains(embed.Title, "5件") {
+		t.Fatalf("title = %q", embed.Title)
+	}
+	if got := embed.Fields[1].Value; got != "Tier上昇 2件 / 新規荒らし 1件" {
+		t.Fatalf("summary = %q", got)
+	}
+	if !strings.Contains(embed.Description, "…他 2件") {
+		t.Fatalf("description = %q", embed.Description)
+	}
+}
+
+func TestTierLadderCritical(t *testing.T) {
+	t.Parallel()
+
+	settings := config.DefaultGuildSettings
+	settings.MentionThreshold = 50
+	ladder := newTierLadder(settings)
+	if ladder.critical(Tier(3), 30, settings) {
+		t.Fatal("default ladder below mention threshold should not be critical")
+	}
+	if !ladder.critical(Tier(5), 55, settings) {
+		t.Fatal("default ladder at mention threshold should be critical")
+	}
+
+	settings.TierLadder = []config.TierLevel{{Threshold: 1}, {Threshold: 20, Ping: true}}
+	ladder = newTierLadder(settings)
+	if ladder.critical(ladder.tier(5), 5, settings) {
+		t.Fatal("silent custom tier should not be critical")
+	}
+	if !ladder.critical(ladder.tier(25), 25, settings) {
+		t.Fatal("ping custom tier should be critical")
+	}
+}
@@ -40,8 +40,11 @@ func (n *Notifier) CheckAndNotifyDM() {
 	isZero := isZeroDiff(diffValue)
 	currentTier := calculateTier(diffValue, dmDiffThreshold)
 
+	now := time.Now()
+	maintenance := n.maintenanceGuilds(now)
 	for _, userID := range n.settings.GetDMEnabledUserIDs() {
-		n.checkAndNotifyDMUser(userID, data, diffValue, isZero, currentTier)
+		muted := len(maintenance) > 0 && n.dmMuted(userID, maintenance, now)
+		n.checkAndNotifyDMUser(userID, data, diffValue, isZero, currentTier, muted)
 	}
 }
 
@@ -56,7 +59,7 @@ func (n *Notifier) getDMUserState(userID string) *dmUserState {
 	return s
 }
 
-func (n *Notifier) checkAndNotifyDMUser(userID string, _ *monitor.MonitorData, diffValue float64, isZero bool, currentTier Tier) {
+func (n *Notifier) checkAndNotifyDMUser(userID string, _ *monitor.MonitorData, diffValue float64, isZero bool, currentTier Tier, muted bool) {
 	state := n.getDMUserState(userID)
 	state.mu.Lock()
 
@@ -83,6 +86,11 @@ func (n *Notifier) checkAndNotifyDMUser(userID string, _ *monitor.MonitorData, d
 		state.mu.Unlock()
 		return
 	}
+	if muted {
+		state.mu.Unlock()
+		log.Printf("DM notification muted by maintenance user=%s: %s", userID, msg)
+		return
+	}
 
 	// クールダウンチェック（差分振動によるDMスパムを防止）
 	now := time.Now()
@@ -93,7 +101,7 @@ func (n *Notifier) checkAndNotifyDMUser(userID string, _ *monitor.MonitorData, d
 	state.lastNotify = now
 	state.mu.Unlock()
 
-	n.enqueueHigh(func() {
+	n.enqueueHigh("DM", func() {
 		n.sendDMNotification(userID, msg)
 	})
 }
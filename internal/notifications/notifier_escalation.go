@@ -0,0 +1,92 @@
+package notifications
+
+import (
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/incidents"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// escalationState エスカレーション設定ごとの継続開始時刻と送信済みフラグ（監視ループからのみ触る）
+type escalationState struct {
+	since     time.Time
+	escalated bool
+	held      bool // 静音時間で保留中（ログを1回だけ出す）
+}
+
+// dueEscalations 継続時間に達したエスカレーションを返し、状態を更新する。
+// silent（静音時間）の間は送信済みにせず保留し、静音時間が明けた時点でまだ続いていれば返す。
+func dueEscalations(guildID string, state *NotificationState, settings config.GuildSettings, ladder tierLadder, currentTier Tier, isZero, silent bool, now time.Time) []config.TierEscalation {
+	if len(settings.TierEscalations) == 0 {
+		state.escalations = nil
+		return nil
+	}
+	if len(state.escalations) != len(settings.TierEscalations) {
+		state.escalations = make([]escalationState, len(settings.TierEscalations))
+	}
+
+	bound := ladder.lowerBound(currentTier)
+	var due []config.TierEscalation
+	for i, esc := range settings.TierEscalations {
+		st := &state.escalations[i]
+		if isZero || currentTier == TierNone || bound < esc.MinThreshold {
+			*st = escalationState{}
+			continue
+		}
+		if st.since.IsZero() {
+			st.since = now
+			continue
+		}
+		if st.escalated || now.Sub(st.since) < time.Duration(esc.AfterMinutes)*time.Minute {
+			continue
+		}
+		if silent {
+			if !st.held {
+				st.held = true
+				log.Printf("Escalation held by quiet hours guild=%s: >=%.2f%% for %dm", guildID, esc.MinThreshold, esc.AfterMinutes)
+			}
+			continue
+		}
+		st.escalated = true
+		st.held = false
+		due = append(due, esc)
+	}
+	return due
+}
+
+// checkEscalations 一定以上の Tier が設定時間続いたらエスカレーション先ロールへ通知する。
+// 静音時間（メンションなし）中は保留し、明けた時点でまだ続いていれば送る。
+func (n *Notifier) checkEscalations(guildID string, state *NotificationState, settings config.GuildSettings, ladder tierLadder, currentTier Tier, diffValue float64, isZero, silent bool, now time.Time) {
+	for _, esc := range dueEscalations(guildID, state, settings, ladder, currentTier, isZero, silent, now) {
+		n.deliverHigh(guildID, settings, digestItem{critical: true}, func() {
+			n.sendEscalation(guildID, settings, ladder, esc, currentTier, diffValue)
+		})
+	}
+}
+
+func (n *Notifier) sendEscalation(guildID string, settings config.GuildSettings, ladder tierLadder, esc config.TierEscalation, tier Tier, diffValue float64) {
+	metricLabel := "差分率"
+	if settings.NotificationMetric == "weighted" {
+		metricLabel = "加重差分率"
+	}
+	message := fmt.Sprintf(
+		"%s⏫ 【Wplace速報】エスカレーション: %sが%s以上のまま%s継続しています [現在%.2f%%・%s]",
+		roleMentions(esc.RoleIDs),
+		metricLabel,
+		config.FormatPercent(esc.MinThreshold),
+		incidents.FormatDuration(time.Duration(esc.AfterMinutes)*time.Minute),
+		diffValue,
+		ladder.label(tier),
+	)
+	err := n.sendEpisodeMessage(guildID, settings, outboxKindEscalation, &discordgo.MessageSend{
+		Content: n.artworkPrefix() + message,
+	}, fmt.Sprintf("⏫ エスカレーション %s %.2f%%", metricLabel, diffValue))
+	if err != nil {
+		log.Printf("Failed to send escalation to guild %s: %v", guildID, err)
+	} else {
+		log.Printf("Escalation sent to guild %s: >=%.2f%% for %dm", guildID, esc.MinThreshold, esc.AfterMinutes)
+	}
+}
@@ -0,0 +1,98 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"Koukyo_discord_bot/internal/incidents"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// incidentSummaryLimit 日次レポートに並べるインシデント数
+const incidentSummaryLimit = 5
+
+func (n *Notifier) startIncidentTracking() {
+	n.incidents = incidents.NewStore(n.dataDir, n.monitor.GetActivityCounts)
+}
+
+// observeIncident 監視ループごとに全体差分率でインシデントを開始/更新/終了する（サーバー設定に依存しない）
+func (n *Notifier) observeIncident(now time.Time) {
+	if n.incidents == nil {
+		return
+	}
+	data := n.monitor.GetLatestData()
+	if data == nil {
+		return
+	}
+	obs := incidents.Observation{
+		Artwork:     n.artwork().ID,
+		Percentage:  data.DiffPercentage,
+		Weighted:    data.WeightedDiffPercentage,
+		DiffPixels:  data.DiffPixels,
+		TotalPixels: data.TotalPixels,
+	}
+	if images := n.monitor.GetLatestImages(); images != nil {
+		obs.LiveImage = images.LiveImage
+		obs.DiffImage = images.DiffImage
+	}
+	opened, closed := n.incidents.Observe(now, obs)
+	if opened != nil {
+		log.Printf("incident #%d opened artwork=%s diff=%.2f%%", opened.ID, obs.Artwork, opened.PeakPercentage)
+	}
+	if closed != nil {
+		log.Printf("incident #%d closed artwork=%s duration=%s peak=%.2f%% vandals=%d fixers=%d",
+			closed.ID, obs.Artwork, closed.Duration(now), closed.PeakPercentage, len(closed.Vandals), len(closed.Fixers))
+	}
+}
+
+// appendClosedIncidentField 修復完了通知に直前に終了したインシデントの要約を付ける
+func (n *Notifier) appendClosedIncidentField(embed *discordgo.MessageEmbed) {
+	inc := n.incidents.LastClosed()
+	if inc == nil || time.Since(inc.EndedAt) > time.Minute {
+		return
+	}
+	lines := []string{
+		fmt.Sprintf("継続 %s / ピークから復旧まで %s", incidents.FormatDuration(inc.Duration(inc.EndedAt)), incidents.FormatDuration(inc.TimeToRecovery())),
+		fmt.Sprintf("ピーク %.2f%% (%dpx)", inc.PeakPercentage, inc.PeakDiffPixels),
+		fmt.Sprintf("荒らし %d人 %dpx / 修復 %d人 %dpx", len(inc.Vandals), inc.VandalPixels(), len(inc.Fixers), inc.FixerPixels()),
+		fmt.Sprintf("`/incidents id:%d` で詳細", inc.ID),
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   fmt.Sprintf("🧾 インシデント #%d", inc.ID),
+		Value:  strings.Join(lines, "\n"),
+		Inline: false,
+	})
+}
+
+// buildPeriodIncidentSummary [from, to) に開始したインシデントの要約。時刻は loc で表示する
+func (n *Notifier) buildPeriodIncidentSummary(from, to time.Time, loc *time.Location) string {
+	if n.incidents == nil {
+		return "記録なし"
+	}
+	list := n.incidents.StartedBetween(from, to)
+	if len(list) == 0 {
+		return "なし"
+	}
+	now := time.Now()
+	var total time.Duration
+	worst := list[0]
+	for _, inc := range list {
+		total += inc.Duration(now)
+		if inc.PeakPercentage > worst.PeakPercentage {
+			worst = inc
+		}
+	}
+	lines := []string{
+		fmt.Sprintf("件数: %d / 合計継続: %s / 最大ピーク: #%d %.2f%%", len(list), incidents.FormatDuration(total), worst.ID, worst.PeakPercentage),
+	}
+	for i := len(list) - 1; i >= 0 && len(lines) <= incidentSummaryLimit; i-- {
+		lines = append(lines, incidents.SummaryLine(list[i], now, loc))
+	}
+	if rest := len(list) - incidentSummaryLimit; rest > 0 {
+		lines = append(lines, fmt.Sprintf("…他 %d件（`/incidents` で一覧）", rest))
+	}
+	return strings.Join(lines, "\n")
+}
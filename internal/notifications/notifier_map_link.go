@@ -3,26 +3,36 @@ package notifications
 import (
 	"fmt"
 
-	"Koukyo_discord_bot/internal/utils"
+	"Koukyo_discord_bot/internal/config"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func mainMonitorWplaceURL() string {
-	return utils.BuildMainMonitorWplaceURL()
+// artwork 通知対象アートワークの定義を返す
+func (n *Notifier) artwork() config.Artwork {
+	if n == nil {
+		return config.DefaultArtwork()
+	}
+	return n.monitor.Artwork()
 }
 
-func mainMonitorFullsizeString() string {
-	return utils.MainMonitorFullsizeString()
+// artworkPrefix 2件目以降のアートワークの通知本文に付ける識別子
+func (n *Notifier) artworkPrefix() string {
+	art := n.artwork()
+	if art.ID == config.DefaultArtworkID {
+		return ""
+	}
+	return fmt.Sprintf("[%s] ", art.DisplayName())
 }
 
-func appendMainMonitorMapField(embed *discordgo.MessageEmbed) {
+func (n *Notifier) appendArtworkMapField(embed *discordgo.MessageEmbed) {
 	if embed == nil {
 		return
 	}
+	art := n.artwork()
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 		Name:   "Wplace.live",
-		Value:  fmt.Sprintf("[地図で見る](%s)\n`/get fullsize:%s`", mainMonitorWplaceURL(), mainMonitorFullsizeString()),
+		Value:  fmt.Sprintf("[地図で見る](%s)\n`/get fullsize:%s`", art.WplaceURL(), art.FullsizeString()),
 		Inline: false,
 	})
 }
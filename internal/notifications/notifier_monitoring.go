@@ -19,6 +19,30 @@ func (n *Notifier) StartMonitoring() {
 	n.startAchievementLoop()
 	n.startDispatchWorker()
 	n.startWplaceHealthLoop()
+	n.startIncidentTracking()
+	n.startQuietWindowLoop()
+	n.startDigestLoop()
+	n.registerStandaloneSource()
+	n.startMonitoringLoop()
+
+	log.Println("Notification monitoring started")
+}
+
+// StartArtworkMonitoring 2件目以降のアートワーク用に差分通知ループのみを開始する。
+// 日次ランキング・追加監視・DM速報などサーバー全体の処理は primary の Notifier が担う。
+func (n *Notifier) StartArtworkMonitoring() {
+	n.secondary = true
+	n.startDispatchWorker()
+	n.startIncidentTracking()
+	n.startQuietWindowLoop()
+	n.startDigestLoop()
+	n.registerStandaloneSource()
+	n.startMonitoringLoop()
+
+	log.Printf("Notification monitoring started (artwork=%s)", n.artwork().ID)
+}
+
+func (n *Notifier) startMonitoringLoop() {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -30,13 +54,20 @@ func (n *Notifier) StartMonitoring() {
 		defer ticker.Stop()
 
 		lastHeartbeat := time.Now()
-		for range ticker.C {
-			n.maybeRunStandaloneFallback(time.Now())
+		for {
+			// 通知状態は監視ループ内でだけ書き出す（判定途中の状態を保存しないため）
+			select {
+			case done := <-n.stateSaveRequests:
+				n.checkpointState(time.Now(), true)
+				close(done)
+				continue
+			case <-ticker.C:
+			}
 
 			// Lightweight heartbeat to detect a stuck monitoring loop.
 			if time.Since(lastHeartbeat) >= 60*time.Second {
 				lastHeartbeat = time.Now()
-				log.Printf("notifier: monitoring heartbeat power_save=%v guilds=%d", n.monitor.State.IsPowerSaveMode(), len(n.session.State.Guilds))
+				log.Printf("notifier: monitoring heartbeat artwork=%s power_save=%v guilds=%d", n.artwork().ID, n.monitor.State.IsPowerSaveMode(), len(n.session.State.Guilds))
 			}
 
 			// 監視データが更新されたら全サーバーをチェック
@@ -50,7 +81,9 @@ func (n *Notifier) StartMonitoring() {
 				// don't edit stale messages that sit above the resume notification.
 				n.resetAllSmallDiffMessageTracking()
 				// For debugging: notify resume, but never block the monitoring loop.
-				go n.notifyPowerSaveResume()
+				if !n.secondary {
+					go n.notifyPowerSaveResume()
+				}
 			}
 			if !n.lastPowerSaveMode && currentPowerSave {
 				// Entering power-save also resets pointers to avoid cross-cycle edits.
@@ -62,11 +95,18 @@ func (n *Notifier) StartMonitoring() {
 				continue
 			}
 
+			n.observeIncident(time.Now())
+
 			// Botが参加している全サーバーをチェック
 			for _, guild := range n.session.State.Guilds {
 				guildID := guild.ID
 				n.CheckAndNotify(guildID)
 			}
+			n.checkpointState(time.Now(), false)
+
+			if n.secondary {
+				continue
+			}
 
 			// DM速報チェック
 			n.CheckAndNotifyDM()
@@ -102,8 +142,6 @@ func (n *Notifier) StartMonitoring() {
 
 		}
 	}()
-
-	log.Println("Notification monitoring started")
 }
 
 func (n *Notifier) notifyPowerSaveResume() {
@@ -112,10 +150,9 @@ func (n *Notifier) notifyPowerSaveResume() {
 		if !gs.AutoNotifyEnabled || gs.NotificationChannel == nil {
 			continue
 		}
-		_, err := n.session.ChannelMessageSend(
-			*gs.NotificationChannel,
-			"🌅 省電力モードを解除しました。更新を再開します。",
-		)
+		_, err := n.sendChannelMessage(guild.ID, *gs.NotificationChannel, outboxKindMonitoring, &discordgo.MessageSend{
+			Content: "🌅 省電力モードを解除しました。更新を再開します。",
+		})
 		if err != nil {
 			log.Printf("Failed to send power-save resume notification to guild %s: %v", guild.ID, err)
 		}
@@ -136,7 +173,6 @@ func (n *Notifier) postTimelapseToGuilds(frames []monitor.TimelapseFrame) {
 	startTime := frames[0].Timestamp
 	endTime := frames[frameCount-1].Timestamp
 	duration := endTime.Sub(startTime)
-	jst := time.FixedZone("JST", 9*3600)
 
 	// 投稿対象ギルド
 	for _, guild := range n.session.State.Guilds {
@@ -145,6 +181,7 @@ func (n *Notifier) postTimelapseToGuilds(frames []monitor.TimelapseFrame) {
 			continue
 		}
 		reader := bytes.NewReader(gifBuf.Bytes())
+		loc := gs.Location()
 		embed := &discordgo.MessageEmbed{
 			Title:       "📽️ タイムラプス完了",
 			Description: "差分率 30%→0.2% の期間を自動生成しました",
@@ -152,7 +189,7 @@ func (n *Notifier) postTimelapseToGuilds(frames []monitor.TimelapseFrame) {
 			Fields: []*discordgo.MessageEmbedField{
 				{
 					Name:   "期間",
-					Value:  fmt.Sprintf("%s ～ %s (JST)", startTime.In(jst).Format("2006-01-02 15:04:05"), endTime.In(jst).Format("2006-01-02 15:04:05")),
+					Value:  fmt.Sprintf("%s ～ %s (%s)", startTime.In(loc).Format("2006-01-02 15:04:05"), endTime.In(loc).Format("2006-01-02 15:04:05"), endTime.In(loc).Format("MST")),
 					Inline: false,
 				},
 				{
@@ -168,7 +205,7 @@ func (n *Notifier) postTimelapseToGuilds(frames []monitor.TimelapseFrame) {
 			},
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
-		_, err := n.session.ChannelMessageSendComplex(*gs.NotificationChannel, &discordgo.MessageSend{
+		_, err := n.sendChannelMessage(guild.ID, *gs.NotificationChannel, outboxKindTimelapse, &discordgo.MessageSend{
 			Embeds: []*discordgo.MessageEmbed{embed},
 			Files: []*discordgo.File{{
 				Name:        "timelapse.gif",
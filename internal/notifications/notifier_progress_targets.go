@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/webhooks"
 	"github.com/bwmarrin/discordgo"
 	_ "golang.org/x/image/webp"
 )
@@ -24,6 +25,7 @@ type progressTargetStatus struct {
 	NextRun     time.Time
 	Running     bool
 	GuildStates map[string]*progressNotificationState
+	Last        targetLastResult
 }
 
 type progressNotificationState struct {
@@ -92,7 +94,7 @@ func (n *Notifier) startProgressTargetsLoop() {
 }
 
 // HandleProgressTargetManual triggers a one-off fetch for a target id and posts to the channel.
-func (n *Notifier) HandleProgressTargetManual(channelID, targetID string) bool {
+func (n *Notifier) HandleProgressTargetManual(guildID, channelID, targetID string) bool {
 	if n == nil || n.progressTargetsState == nil {
 		return false
 	}
@@ -106,7 +108,7 @@ func (n *Notifier) HandleProgressTargetManual(channelID, targetID string) bool {
 			log.Printf("progress_targets: manual fetch failed channel=%s target=%s err=%v", channelID, target.ID, err)
 			return
 		}
-		n.sendProgressManual(channelID, target, result)
+		n.sendProgressManual(guildID, channelID, target, result)
 	}()
 	return true
 }
@@ -114,9 +116,11 @@ func (n *Notifier) HandleProgressTargetManual(channelID, targetID string) bool {
 func (n *Notifier) runProgressTarget(target progressTargetConfig) {
 	result, err := n.buildProgressTargetResult(target)
 	if err != nil {
+		n.progressTargetsState.recordProgressResult(target.ID, newTargetLastResult(0, 0, err))
 		n.handleProgressTargetError(target, err, true)
 		return
 	}
+	n.progressTargetsState.recordProgressResult(target.ID, newTargetLastResult(result.progressPercent, result.diffPixels, nil))
 	for _, guild := range n.session.State.Guilds {
 		settings := n.settings.GetGuildSettings(guild.ID)
 		if !settings.ProgressNotifyEnabled || settings.ProgressChannel == nil {
@@ -126,11 +130,22 @@ func (n *Notifier) runProgressTarget(target progressTargetConfig) {
 		if !ev.increase && !ev.decrease {
 			continue
 		}
+		hook := webhooks.TargetData{TargetID: target.ID, Label: target.Label, Percent: result.progressPercent, DiffPixels: result.diffPixels, Tier: int(ev.tier)}
 		if ev.increase {
-			n.sendProgressNotification(*settings.ProgressChannel, settings, target, result, false, ev.tier)
+			n.emitTargetWebhook(webhooks.EventProgressTargetChanged, guild.ID, hook, "increase")
 		}
 		if ev.decrease {
-			n.sendProgressNotification(*settings.ProgressChannel, settings, target, result, true, ev.tier)
+			n.emitTargetWebhook(webhooks.EventProgressTargetChanged, guild.ID, hook, "decrease")
+		}
+		if gate, reason := notificationGate(settings, time.Now()); gate == gateMuted {
+			n.recordMuted(guild.ID, reason, "進捗監視 "+target.Label, 0, time.Now())
+			continue
+		}
+		if ev.increase {
+			n.sendProgressNotification(guild.ID, *settings.ProgressChannel, settings, target, result, false, ev.tier)
+		}
+		if ev.decrease {
+			n.sendProgressNotification(guild.ID, *settings.ProgressChannel, settings, target, result, true, ev.tier)
 		}
 	}
 	n.progressTargetsState.clearProgressErrorNotified(target.ID)
@@ -149,6 +164,7 @@ func (n *Notifier) buildProgressTargetResult(target progressTargetConfig) (*targ
 }
 
 func (n *Notifier) sendProgressNotification(
+	guildID string,
 	channelID string,
 	settings config.GuildSettings,
 	target progressTargetConfig,
@@ -158,7 +174,7 @@ func (n *Notifier) sendProgressNotification(
 ) {
 	embed := n.buildProgressEmbed("🎨 ピクセルアート進捗", target, result, isVandal, tier)
 
-	_, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+	_, err := n.sendChannelMessage(guildID, channelID, outboxKindProgress, &discordgo.MessageSend{
 		Embeds: []*discordgo.MessageEmbed{embed},
 		Files: []*discordgo.File{
 			{
@@ -234,9 +250,9 @@ func (n *Notifier) buildProgressEmbed(title string, target progressTargetConfig,
 	}
 }
 
-func (n *Notifier) sendProgressManual(channelID string, target progressTargetConfig, result *targetResult) {
+func (n *Notifier) sendProgressManual(guildID, channelID string, target progressTargetConfig, result *targetResult) {
 	embed := n.buildProgressEmbed("📌 ピクセルアート進捗 (手動取得)", target, result, false, TierNone)
-	_, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+	_, err := n.sendChannelMessage(guildID, channelID, outboxKindProgress, &discordgo.MessageSend{
 		Embeds: []*discordgo.MessageEmbed{embed},
 		Files: []*discordgo.File{
 			{
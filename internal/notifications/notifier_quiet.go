@@ -0,0 +1,295 @@
+package notifications
+
+import (
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/incidents"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// quietWindowCheckInterval 静音時間/メンテナンスの終了を確認する間隔
+const quietWindowCheckInterval = 30 * time.Second
+
+// notifyGate ギルドへの通知可否
+type notifyGate int
+
+const (
+	gateOpen   notifyGate = iota
+	gateSilent            // メンションなしで通知
+	gateMuted             // 通知しない（記録のみ）
+)
+
+// mutedWindow 通知を止めている間の記録（終了時のまとめ用）
+type mutedWindow struct {
+	reason    string
+	startedAt time.Time
+	events    map[string]int
+	order     []string
+	peak      float64
+	peakAt    time.Time
+}
+
+// quiet 静音時間中に何も起きなかったか（止めた通知・差分・新しいインシデントが無い）。
+// 毎日の静音時間で空のまとめを送らないために使う。メンテナンスは明けたことを常に知らせる
+func (w *mutedWindow) quiet(incidentCount int) bool {
+	return w.reason != "メンテナンス" && len(w.order) == 0 && w.peak == 0 && incidentCount == 0
+}
+
+// notificationGate 静音時間/メンテナンスによる通知可否と理由
+func notificationGate(settings config.GuildSettings, now time.Time) (notifyGate, string) {
+	if settings.Maintenance != nil && settings.Maintenance.Active(now) {
+		return gateMuted, "メンテナンス"
+	}
+	if q := settings.QuietHours; q != nil && q.Active(now, settings.Location()) {
+		if q.Mode == config.QuietModeMute {
+			return gateMuted, "静音時間"
+		}
+		return gateSilent, "静音時間"
+	}
+	return gateOpen, ""
+}
+
+// mentionsAllowed 静音時間（メンションなし）中でないか
+func mentionsAllowed(settings config.GuildSettings) bool {
+	gate, _ := notificationGate(settings, time.Now())
+	return gate == gateOpen
+}
+
+// recordMuted 止めた通知を記録する。kind が空なら差分率のピークだけ更新する。
+func (n *Notifier) recordMuted(guildID, reason, kind string, value float64, now time.Time) {
+	n.mutedMu.Lock()
+	defer n.mutedMu.Unlock()
+	w := n.ensureMutedWindowLocked(guildID, reason, now)
+	if value > w.peak {
+		w.peak = value
+		w.peakAt = now
+	}
+	if kind == "" {
+		return
+	}
+	if _, ok := w.events[kind]; !ok {
+		w.order = append(w.order, kind)
+	}
+	w.events[kind]++
+	log.Printf("notify muted (%s) guild=%s artwork=%s kind=%s value=%.2f%%", reason, guildID, n.artwork().ID, kind, value)
+}
+
+func (n *Notifier) ensureMutedWindowLocked(guildID, reason string, now time.Time) *mutedWindow {
+	if n.muted == nil {
+		n.muted = make(map[string]*mutedWindow)
+	}
+	w := n.muted[guildID]
+	if w == nil {
+		w = &mutedWindow{reason: reason, startedAt: now, events: make(map[string]int)}
+		n.muted[guildID] = w
+	}
+	return w
+}
+
+// recordMutedCheck 通知停止中の CheckAndNotify。送るはずだった通知を記録して状態だけ進める。
+func (n *Notifier) recordMutedCheck(guildID, reason string, state *NotificationState, settings config.GuildSettings, diffValue float64, currentTier Tier, isZero bool, now time.Time) {
+	kind := ""
+	switch {
+	case state.WasZeroDiff && !isZero:
+		kind = "変化検知"
+	case !state.WasZeroDiff && isZero:
+		kind = "修復完了"
+	case !isZero && currentTier > state.LastTier:
+		kind = "Tier上昇"
+	case !isZero && currentTier < state.LastTier:
+		kind = "Tier減少"
+	}
+	n.recordMuted(guildID, reason, kind, diffValue, now)
+	n.updateState(state, isZero, currentTier, diffValue, settings)
+}
+
+// maintenanceGuilds メンテナンス中のサーバー
+func (n *Notifier) maintenanceGuilds(now time.Time) []string {
+	var guildIDs []string
+	for _, guild := range n.session.State.Guilds {
+		if mw := n.settings.GetGuildSettings(guild.ID).Maintenance; mw != nil && mw.Active(now) {
+			guildIDs = append(guildIDs, guild.ID)
+		}
+	}
+	return guildIDs
+}
+
+// dmMuted userID が所属するサーバーのいずれかがメンテナンス中なら、そのユーザーへの DM速報を止める
+func (n *Notifier) dmMuted(userID string, maintenance []string, now time.Time) bool {
+	for _, guildID := range maintenance {
+		if n.isGuildMember(guildID, userID, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// dmMemberCacheTTL サーバー所属の確認結果を使い回す時間
+const dmMemberCacheTTL = time.Hour
+
+type dmMembership struct {
+	member    bool
+	checkedAt time.Time
+}
+
+// isGuildMember userID が guildID のメンバーか。State に無ければ API で確認して結果をしばらく覚えておく。
+func (n *Notifier) isGuildMember(guildID, userID string, now time.Time) bool {
+	if m, err := n.session.State.Member(guildID, userID); err == nil && m != nil {
+		return true
+	}
+	key := guildID + "/" + userID
+	n.dmUserStatesMu.Lock()
+	cached, ok := n.dmMembers[key]
+	n.dmUserStatesMu.Unlock()
+	if ok && now.Sub(cached.checkedAt) < dmMemberCacheTTL {
+		return cached.member
+	}
+
+	_, err := n.session.GuildMember(guildID, userID)
+	if err != nil && isTransientDiscordError(err) {
+		log.Printf("DM maintenance check: failed to look up member guild=%s user=%s: %v", guildID, userID, err)
+		return false
+	}
+	n.dmUserStatesMu.Lock()
+	if n.dmMembers == nil {
+		n.dmMembers = make(map[string]dmMembership)
+	}
+	n.dmMembers[key] = dmMembership{member: err == nil, checkedAt: now}
+	n.dmUserStatesMu.Unlock()
+	return err == nil
+}
+
+func (n *Notifier) startQuietWindowLoop() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("PANIC in quietWindowLoop: %v", r)
+			}
+		}()
+
+		ticker := time.NewTicker(quietWindowCheckInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			n.checkQuietWindows(now)
+		}
+	}()
+}
+
+// checkQuietWindows 通知停止の開始を記録し、終了したギルドへまとめを投稿する
+func (n *Notifier) checkQuietWindows(now time.Time) {
+	for _, guild := range n.session.State.Guilds {
+		settings := n.settings.GetGuildSettings(guild.ID)
+		gate, reason := notificationGate(settings, now)
+
+		n.mutedMu.Lock()
+		if gate == gateMuted {
+			start := now
+			if reason == "メンテナンス" {
+				start = settings.Maintenance.Start
+			}
+			n.ensureMutedWindowLocked(guild.ID, reason, start)
+			n.mutedMu.Unlock()
+			continue
+		}
+		w := n.muted[guild.ID]
+		delete(n.muted, guild.ID)
+		n.mutedMu.Unlock()
+
+		if w != nil {
+			n.sendMutedSummary(guild.ID, settings, w, now)
+		}
+	}
+}
+
+// sendMutedSummary 通知停止期間のまとめを投稿する
+func (n *Notifier) sendMutedSummary(guildID string, settings config.GuildSettings, w *mutedWindow, now time.Time) {
+	if !settings.AutoNotifyEnabled || settings.NotificationChannel == nil {
+		return
+	}
+	loc := settings.Location()
+	startedIncidents := n.incidents.StartedBetween(w.startedAt, now)
+	if w.quiet(len(startedIncidents)) {
+		log.Printf("Muted summary for guild %s skipped: nothing happened during %s", guildID, w.reason)
+		return
+	}
+
+	title := "🌙 静音時間終了"
+	if w.reason == "メンテナンス" {
+		title = "🛠️ メンテナンス終了"
+	}
+	eventText := "なし"
+	if len(w.order) > 0 {
+		lines := make([]string, 0, len(w.order))
+		for _, kind := range w.order {
+			lines = append(lines, fmt.Sprintf("%s: %d件", kind, w.events[kind]))
+		}
+		eventText = strings.Join(lines, "\n")
+	}
+	peakText := "—"
+	if !w.peakAt.IsZero() {
+		peakText = fmt.Sprintf("%.2f%% (%s)", w.peak, w.peakAt.In(loc).Format("15:04"))
+	}
+	currentText := "—"
+	recovered := false
+	if data := n.monitor.GetLatestData(); data != nil {
+		current := getDiffValue(data, settings.NotificationMetric)
+		currentText = fmt.Sprintf("%.2f%%", current)
+		recovered = isZeroDiff(current)
+	}
+	incidentText := "なし"
+	if list := startedIncidents; len(list) > 0 {
+		lines := make([]string, 0, min(len(list), incidentSummaryLimit))
+		for _, inc := range list[:min(len(list), incidentSummaryLimit)] {
+			lines = append(lines, incidents.SummaryLine(inc, now, loc))
+		}
+		incidentText = strings.Join(lines, "\n")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: "期間中に止めていた通知のまとめです",
+		Color:       0x5865F2,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "期間", Value: fmt.Sprintf("%s ～ %s", w.startedAt.In(loc).Format("01/02 15:04"), now.In(loc).Format("01/02 15:04")), Inline: false},
+			{Name: "止めた通知", Value: eventText, Inline: true},
+			{Name: "最大差分率", Value: peakText, Inline: true},
+			{Name: "現在の差分率", Value: currentText, Inline: true},
+			{Name: "🧾 期間中のインシデント", Value: incidentText, Inline: false},
+		},
+		Timestamp: now.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "自動通知システム",
+		},
+	}
+	// 停止中に修復が完了していたら、まとめを送った後にスレッドも閉じる
+	var episode *OutboxEpisode
+	if recovered {
+		episode = &OutboxEpisode{Finish: "✅ 修復完了"}
+	}
+	channelID := *settings.NotificationChannel
+	err := n.outbox.send(guildID, channelID, n.artwork().ID, outboxKindMuted, episode, &discordgo.MessageSend{
+		Content: n.artworkPrefix() + title,
+		Embeds:  []*discordgo.MessageEmbed{embed},
+	}, func(msg *discordgo.MessageSend) error {
+		_, err := n.session.ChannelMessageSendComplex(channelID, msg)
+		return err
+	})
+	if errors.Is(err, errOutboxQueued) {
+		log.Printf("Muted summary for guild %s deferred: %v", guildID, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to send muted summary to guild %s: %v", guildID, err)
+		return
+	}
+	log.Printf("Muted summary sent to guild %s (%s, %d kinds)", guildID, w.reason, len(w.order))
+
+	if recovered {
+		n.finishIncidentThread(guildID, "✅ 修復完了")
+	}
+}
@@ -0,0 +1,90 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+
+	"Koukyo_discord_bot/internal/config"
+)
+
+func TestNotificationGate(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*3600)
+	night := time.Date(2026, 1, 2, 3, 0, 0, 0, jst)
+	settings := config.DefaultGuildSettings
+	if gate, _ := notificationGate(settings, night); gate != gateOpen {
+		t.Fatalf("gate without quiet hours = %v", gate)
+	}
+
+	settings.QuietHours = &config.QuietHours{Start: "02:00", End: "07:00", Mode: config.QuietModeSilent}
+	if gate, _ := notificationGate(settings, night); gate != gateSilent {
+		t.Fatalf("silent quiet hours gate = %v", gate)
+	}
+	if gate, _ := notificationGate(settings, night.Add(5*time.Hour)); gate != gateOpen {
+		t.Fatalf("gate after quiet hours = %v", gate)
+	}
+
+	settings.Maintenance = &config.MaintenanceWindow{Start: night.Add(-time.Hour), End: night.Add(time.Hour)}
+	if gate, reason := notificationGate(settings, night); gate != gateMuted || reason != "メンテナンス" {
+		t.Fatalf("maintenance gate = %v %q", gate, reason)
+	}
+}
+
+func TestRecordMutedCheckCountsSkippedNotifications(t *testing.T) {
+	t.Parallel()
+
+	n := &Notifier{}
+	settings := config.DefaultGuildSettings
+	state := &NotificationState{LastTier: TierNone, WasZeroDiff: true}
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	n.recordMutedCheck("g", "メンテナンス", state, settings, 25, Tier20, false, now)
+	n.recordMutedCheck("g", "メンテナンス", state, settings, 35, Tier30, false, now.Add(time.Minute))
+	n.recordMutedCheck("g", "メンテナンス", state, settings, 35, Tier30, false, now.Add(2*time.Minute))
+	n.recordMutedCheck("g", "メンテナンス", state, settings, 0, TierNone, true, now.Add(3*time.Minute))
+
+	w := n.muted["g"]
+	if w == nil {
+		t.Fatalf("muted window not recorded")
+	}
+	if w.events["変化検知"] != 1 || w.events["Tier上昇"] != 1 || w.events["修復完了"] != 1 || len(w.order) != 3 {
+		t.Fatalf("unexpected events: %+v order=%v", w.events, w.order)
+	}
+	if w.peak != 35 || !w.peakAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected peak %.2f at %s", w.peak, w.peakAt)
+	}
+	if !state.WasZeroDiff || state.LastTier != TierNone {
+		t.Fatalf("state not advanced: %+v", state)
+	}
+}
+
+func TestMutedSummarySkipsUneventfulQuietHours(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 7, 0, 0, 0, time.UTC)
+	quiet := &mutedWindow{reason: "静音時間", startedAt: now.Add(-5 * time.Hour), events: map[string]int{}}
+	if !quiet.quiet(0) {
+		t.Fatal("uneventful quiet hours should be skipped")
+	}
+	if quiet.quiet(1) {
+		t.Fatal("quiet hours with a new incident should be summarized")
+	}
+	maintenance := &mutedWindow{reason: "メンテナンス", startedAt: now.Add(-time.Hour), events: map[string]int{}}
+	if maintenance.quiet(0) {
+		t.Fatal("maintenance end should always be announced")
+	}
+
+	// 何もなければ送信処理まで進まない（session が無くても落ちない）
+	n := &Notifier{}
+	settings := config.DefaultGuildSettings
+	settings.AutoNotifyEnabled = true
+	channelID := "c1"
+	settings.NotificationChannel = &channelID
+	n.sendMutedSummary("g", settings, quiet, now)
+
+	n.recordMuted("g", "静音時間", "", 1.5, now.Add(-time.Hour))
+	if n.muted["g"].quiet(0) {
+		t.Fatal("a diff during quiet hours should be summarized")
+	}
+}
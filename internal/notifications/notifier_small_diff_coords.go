@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"image/png"
 
+	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/utils"
 )
 
 func smallDiffCoordinateLines(diffPNG []byte, limit int) ([]string, error) {
-	coords, err := smallDiffCoordinatesFromDiffPNG(diffPNG, limit)
+	return smallDiffCoordinateLinesAt(defaultDiffOrigin(), diffPNG, limit)
+}
+
+func smallDiffCoordinateLinesAt(origin utils.Coordinate, diffPNG []byte, limit int) ([]string, error) {
+	coords, err := smallDiffCoordinatesFromDiffPNGAt(origin, diffPNG, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -26,6 +31,11 @@ func smallDiffCoordinateLines(diffPNG []byte, limit int) ([]string, error) {
 }
 
 func smallDiffCoordinatesFromDiffPNG(diffPNG []byte, limit int) ([]*utils.Coordinate, error) {
+	return smallDiffCoordinatesFromDiffPNGAt(defaultDiffOrigin(), diffPNG, limit)
+}
+
+// smallDiffCoordinatesFromDiffPNGAt 差分画像の左上を origin として絶対座標へ変換する
+func smallDiffCoordinatesFromDiffPNGAt(origin utils.Coordinate, diffPNG []byte, limit int) ([]*utils.Coordinate, error) {
 	if len(diffPNG) == 0 || limit <= 0 {
 		return nil, nil
 	}
@@ -40,8 +50,8 @@ func smallDiffCoordinatesFromDiffPNG(diffPNG []byte, limit int) ([]*utils.Coordi
 		return nil, nil
 	}
 
-	baseAbsX := utils.MainMonitorTileX*utils.WplaceTileSize + utils.MainMonitorPixelX
-	baseAbsY := utils.MainMonitorTileY*utils.WplaceTileSize + utils.MainMonitorPixelY
+	baseAbsX := origin.TileX*utils.WplaceTileSize + origin.PixelX
+	baseAbsY := origin.TileY*utils.WplaceTileSize + origin.PixelY
 
 	out := make([]*utils.Coordinate, 0, limit)
 	for y := b.Min.Y; y < b.Max.Y; y++ {
@@ -70,3 +80,21 @@ func absoluteToCoordinate(absX, absY int) *utils.Coordinate {
 		PixelY: absY % utils.WplaceTileSize,
 	}
 }
+
+func defaultDiffOrigin() utils.Coordinate {
+	return utils.Coordinate{
+		TileX:  utils.MainMonitorTileX,
+		TileY:  utils.MainMonitorTileY,
+		PixelX: utils.MainMonitorPixelX,
+		PixelY: utils.MainMonitorPixelY,
+	}
+}
+
+func artworkOrigin(art config.Artwork) utils.Coordinate {
+	return utils.Coordinate{
+		TileX:  art.TileX,
+		TileY:  art.TileY,
+		PixelX: art.PixelX,
+		PixelY: art.PixelY,
+	}
+}
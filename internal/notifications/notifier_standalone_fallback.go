@@ -2,6 +2,7 @@ package notifications
 
 import (
 	"Koukyo_discord_bot/internal/monitor"
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -14,33 +15,58 @@ const (
 	standaloneBaseInterval     = 2 * time.Second
 	standaloneMaxInterval      = 5 * time.Minute
 	standaloneErrorNotifyEvery = 10 * time.Minute
-	kikuTemplateFile           = "1818-806-989-358_kiku_only.webp"
 )
 
 var forceStandaloneMode = os.Getenv("MONITOR_FORCE_STANDALONE") == "1"
 
-func (n *Notifier) maybeRunStandaloneFallback(now time.Time) {
-	if n == nil || n.monitor == nil || n.watchTargetsState == nil {
-		return
-	}
+// standaloneSource wplace のタイルから直接差分を計算する最下位の取得元。
+// 上位の取得元（WebSocket / HTTPポーリング）が standaloneTriggerAfter 以上停止すると Monitor が起動する。
+type standaloneSource struct {
+	n *Notifier
+}
 
-	wsUnavailable := forceStandaloneMode || n.monitor.IsWSUnavailableFor(standaloneTriggerAfter)
-	if !wsUnavailable {
-		n.leaveStandaloneFallbackIfActive(now)
+func (s *standaloneSource) Name() string { return "standalone" }
+
+func (s *standaloneSource) Run(ctx context.Context, sink *monitor.SourceSink) error {
+	n := s.n
+	n.enterStandaloneFallbackIfNeeded(time.Now())
+	defer func() {
+		n.leaveStandaloneFallbackIfActive(time.Now())
 		n.resetStandaloneScheduleLocked()
-		return
-	}
-	n.enterStandaloneFallbackIfNeeded(now)
+	}()
 
-	n.standaloneMu.Lock()
-	if !n.standaloneNextRun.IsZero() && now.Before(n.standaloneNextRun) {
+	for {
+		n.runStandaloneOnce(time.Now(), sink)
+
+		n.standaloneMu.Lock()
+		delay := time.Until(n.standaloneNextRun)
 		n.standaloneMu.Unlock()
+		if delay < standaloneBaseInterval {
+			delay = standaloneBaseInterval
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// registerStandaloneSource スタンドアロン取得を Monitor の最下位の取得元として登録する
+func (n *Notifier) registerStandaloneSource() {
+	if n == nil || n.monitor == nil || n.watchTargetsState == nil {
 		return
 	}
-	n.standaloneMu.Unlock()
+	n.monitor.AddSource(monitor.SourceConfig{
+		Source:        &standaloneSource{n: n},
+		ActivateAfter: standaloneTriggerAfter,
+	})
+}
 
+func (n *Notifier) runStandaloneOnce(now time.Time, sink *monitor.SourceSink) {
 	cfg, err := n.resolveStandaloneTarget()
 	if err != nil {
+		sink.Unavailable(now)
 		n.scheduleStandaloneFailure(now, err)
 		log.Printf("standalone fallback: target resolve failed: %v", err)
 		return
@@ -48,6 +74,7 @@ func (n *Notifier) maybeRunStandaloneFallback(now time.Time) {
 
 	result, err := n.buildWatchTargetResult(cfg)
 	if err != nil {
+		sink.Unavailable(now)
 		n.scheduleStandaloneFailure(now, err)
 		log.Printf("standalone fallback: build failed target=%s err=%v", cfg.ID, err)
 		return
@@ -60,8 +87,13 @@ func (n *Notifier) maybeRunStandaloneFallback(now time.Time) {
 		TotalPixels:    result.template.OpaqueCount,
 	}
 
-	// 菊のみテンプレートで加重差分を計算する。
-	kikuTemplate, kikuErr := n.watchTargetsState.loadTemplateFromDataDir(n.dataDir, kikuTemplateFile)
+	// 菊のみテンプレート（アートワークの加重テンプレート）で加重差分を計算する。
+	weightedTemplate := n.artwork().WeightedTemplate
+	var kikuTemplate *watchTemplate
+	kikuErr := fmt.Errorf("weighted template is not configured")
+	if weightedTemplate != "" {
+		kikuTemplate, kikuErr = n.watchTargetsState.loadTemplateFromDataDir(n.dataDir, weightedTemplate)
+	}
 	if kikuErr == nil {
 		if liveImg, decodeErr := decodePNGToNRGBA(result.livePNG); decodeErr == nil {
 			kikuDiff, _ := buildDiffMask(kikuTemplate.Img, liveImg)
@@ -82,17 +114,25 @@ func (n *Notifier) maybeRunStandaloneFallback(now time.Time) {
 		log.Printf("standalone fallback: kiku template unavailable, skipping weighted diff: %v", kikuErr)
 	}
 
-	n.monitor.State.UpdateData(data)
-	n.monitor.State.UpdateImages(&monitor.ImageData{
-		LiveImage: result.livePNG,
-		DiffImage: result.diffPNG,
-		Timestamp: now,
-	})
-	n.monitor.EnqueueDiffImageToTracker(result.diffPNG)
+	sink.UpdateData(data)
+	sink.UpdateImages(result.livePNG, result.diffPNG)
+	sink.Healthy()
 	n.scheduleStandaloneSuccess(now)
 }
 
 func (n *Notifier) resolveStandaloneTarget() (watchTargetConfig, error) {
+	art := n.artwork()
+	if n.secondary {
+		// 2件目以降のアートワークは環境変数の上書きを受けず、定義どおりに取得する。
+		return watchTargetConfig{
+			ID:       "standalone-" + art.ID,
+			Label:    "Standalone " + art.DisplayName(),
+			Origin:   art.Origin(),
+			Template: art.Template,
+			Interval: standaloneBaseInterval,
+		}, nil
+	}
+
 	targetID := strings.TrimSpace(os.Getenv("MONITOR_STANDALONE_TARGET_ID"))
 	if targetID != "" && n.watchTargetsState != nil {
 		targets, err := n.watchTargetsState.loadConfigs()
@@ -110,10 +150,10 @@ func (n *Notifier) resolveStandaloneTarget() (watchTargetConfig, error) {
 	origin := strings.TrimSpace(os.Getenv("MONITOR_STANDALONE_ORIGIN"))
 	template := strings.TrimSpace(os.Getenv("MONITOR_STANDALONE_TEMPLATE"))
 	if origin == "" {
-		origin = "1818-806-989-358"
+		origin = art.Origin()
 	}
 	if template == "" {
-		template = "1818-806-989-358.png"
+		template = art.Template
 	}
 	return watchTargetConfig{
 		ID:       "standalone-default",
@@ -193,8 +233,17 @@ func (n *Notifier) leaveStandaloneFallbackIfActive(now time.Time) {
 	n.standaloneStartedAt = time.Time{}
 	n.standaloneMu.Unlock()
 
+	// 停止時（シャットダウンなど）は上位の取得元が復帰していないので通知しない
+	recovered := n.monitor.ActiveSourceName()
+	if recovered == "" || recovered == "standalone" {
+		return
+	}
+	label := "WS接続"
+	if recovered != "websocket" {
+		label = fmt.Sprintf("上位の取得元（%s）", recovered)
+	}
 	duration := now.Sub(startedAt).Round(time.Second)
-	n.notifyStandaloneToGuilds(fmt.Sprintf("✅ WS接続が復帰したため、スタンドアロンフォールバックを終了しました（継続時間: %s）。", duration))
+	n.notifyStandaloneToGuilds(fmt.Sprintf("✅ %sが復帰したため、スタンドアロンフォールバックを終了しました（継続時間: %s）。", label, duration))
 }
 
 func (n *Notifier) maybeNotifyStandaloneErrorSummary(now time.Time, err error) {
@@ -236,7 +285,7 @@ func (n *Notifier) notifyStandaloneToGuilds(content string) {
 			continue
 		}
 		channelID := *gs.NotificationChannel
-		n.enqueueHigh(func() {
+		n.enqueueHigh("スタンドアロン切替", func() {
 			if _, err := n.session.ChannelMessageSend(channelID, content); err != nil {
 				log.Printf("standalone fallback notification failed guild=%s channel=%s err=%v", guildID, channelID, err)
 			}
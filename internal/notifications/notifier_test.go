@@ -0,0 +1,24 @@
+package notifications
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"testing"
+)
+
+func TestFormatPainterPixelsShowsConfidenceInterval(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		item activity.PainterPixelCount
+		want string
+	}{
+		{activity.PainterPixelCount{Pixels: 12}, "12px"},
+		{activity.PainterPixelCount{Pixels: 70, Estimated: 63, Low: 55, High: 83}, "~70 px (est., 95% CI 55–83)"},
+		{activity.PainterPixelCount{Pixels: 70, Estimated: 63}, "~70 px (est.)"},
+	}
+	for _, tc := range cases {
+		if got := formatPainterPixels(tc.item); got != tc.want {
+			t.Errorf("formatPainterPixels(%+v) = %q, want %q", tc.item, got, tc.want)
+		}
+	}
+}
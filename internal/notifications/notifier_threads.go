@@ -0,0 +1,189 @@
+package notifications
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/incidents"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// incidentThreadAutoArchive 進行中スレッドの自動アーカイブ時間（分）。復旧時は明示的にアーカイブする。
+const incidentThreadAutoArchive = 1440
+
+// incidentThreadNameLimit Discord のスレッド名上限
+const incidentThreadNameLimit = 100
+
+// incidentThread 進行中エピソードのスレッド（NotificationState.mu で保護）
+type incidentThread struct {
+	ChannelID      string    `json:"channel_id"`      // 起点メッセージのあるチャンネル
+	StarterID      string    `json:"starter_id"`      // 起点メッセージ（状態表示を編集する）
+	StarterContent string    `json:"starter_content"` // 起点メッセージの元の本文
+	ThreadID       string    `json:"thread_id"`
+	StartedAt      time.Time `json:"started_at"`
+}
+
+// sendEpisodeMessage 差分通知を送信する。Discord 側の一時的な失敗は outbox に保存して後で再送する。
+// 修復完了の通知は送信後（outbox に保存した場合は再送後）にスレッドをアーカイブする。
+func (n *Notifier) sendEpisodeMessage(guildID string, settings config.GuildSettings, kind string, send *discordgo.MessageSend, status string) error {
+	channelID := *settings.NotificationChannel
+	episode := &OutboxEpisode{Status: status}
+	if kind == outboxKindCompleted {
+		episode.Finish = status
+	}
+	err := n.outbox.send(guildID, channelID, n.artwork().ID, kind, episode, send, func(msg *discordgo.MessageSend) error {
+		return n.postEpisodeMessage(guildID, settings, msg, status)
+	})
+	if episode.Finish != "" && !errors.Is(err, errOutboxQueued) {
+		n.finishIncidentThread(guildID, episode.Finish)
+	}
+	return err
+}
+
+// postEpisodeMessage スレッド有効時は最初の通知からスレッドを作成し、
+// 以降はスレッドへ送って起点メッセージの状態表示を更新する。
+func (n *Notifier) postEpisodeMessage(guildID string, settings config.GuildSettings, send *discordgo.MessageSend, status string) error {
+	channelID := *settings.NotificationChannel
+	if !settings.IncidentThreadsEnabled {
+		_, err := n.session.ChannelMessageSendComplex(channelID, send)
+		return err
+	}
+
+	state := n.getState(guildID)
+	state.mu.Lock()
+	thread := state.thread
+	state.mu.Unlock()
+
+	if thread == nil || thread.ChannelID != channelID {
+		msg, err := n.session.ChannelMessageSendComplex(channelID, send)
+		if err != nil {
+			return err
+		}
+		thread = n.startIncidentThread(channelID, msg, settings.Location())
+		if thread == nil {
+			return nil
+		}
+		state.mu.Lock()
+		state.thread = thread
+		state.mu.Unlock()
+		n.updateThreadStarter(thread, status)
+		return nil
+	}
+
+	if _, err := n.session.ChannelMessageSendComplex(thread.ThreadID, send); err != nil {
+		// スレッドが削除された等。次の通知で新しいスレッドを作り直す。
+		// 一時的な失敗ならスレッドは残し、outbox からの再送も同じスレッドへ送る。
+		if !isTransientDiscordError(err) {
+			state.mu.Lock()
+			if state.thread == thread {
+				state.thread = nil
+			}
+			state.mu.Unlock()
+		}
+		return fmt.Errorf("thread %s: %w", thread.ThreadID, err)
+	}
+	n.updateThreadStarter(thread, status)
+	return nil
+}
+
+// startIncidentThread 起点メッセージからスレッドを作成する（失敗時は nil）
+func (n *Notifier) startIncidentThread(channelID string, starter *discordgo.Message, loc *time.Location) *incidentThread {
+	if starter == nil {
+		return nil
+	}
+	now := time.Now()
+	ch, err := n.session.MessageThreadStartComplex(channelID, starter.ID, &discordgo.ThreadStart{
+		Name:                n.incidentThreadName(now, loc),
+		AutoArchiveDuration: incidentThreadAutoArchive,
+	})
+	if err != nil {
+		log.Printf("Failed to start incident thread in channel %s: %v", channelID, err)
+		return nil
+	}
+	return &incidentThread{
+		ChannelID:      channelID,
+		StarterID:      starter.ID,
+		StarterContent: starter.Content,
+		ThreadID:       ch.ID,
+		StartedAt:      now,
+	}
+}
+
+// incidentThreadName スレッド名（例: 🚨 インシデント #12 01/02 21:03）。時刻はサーバーのタイムゾーン
+func (n *Notifier) incidentThreadName(now time.Time, loc *time.Location) string {
+	label := "🚨 インシデント"
+	if inc := n.incidents.Current(); inc != nil {
+		label = fmt.Sprintf("🚨 インシデント #%d", inc.ID)
+	}
+	name := fmt.Sprintf("%s%s %s", n.artworkPrefix(), label, now.In(loc).Format("01/02 15:04"))
+	if runes := []rune(name); len(runes) > incidentThreadNameLimit {
+		name = string(runes[:incidentThreadNameLimit])
+	}
+	return name
+}
+
+// updateThreadStarter 起点メッセージの本文末尾に現在の状態を表示する
+func (n *Notifier) updateThreadStarter(thread *incidentThread, status string) {
+	content := threadStarterContent(thread.StarterContent, status, time.Now())
+	if _, err := n.session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:      thread.StarterID,
+		Channel: thread.ChannelID,
+		Content: &content,
+	}); err != nil {
+		log.Printf("Failed to update incident thread starter %s: %v", thread.StarterID, err)
+	}
+}
+
+func threadStarterContent(original, status string, now time.Time) string {
+	return fmt.Sprintf("%s\n📌 現在の状態: %s（<t:%d:R>更新）", original, status, now.Unix())
+}
+
+// finishIncidentThread 復旧時に状態表示を更新してスレッドをアーカイブする
+func (n *Notifier) finishIncidentThread(guildID, status string) {
+	state := n.getState(guildID)
+	state.mu.Lock()
+	thread := state.thread
+	state.thread = nil
+	state.mu.Unlock()
+	if thread == nil {
+		return
+	}
+
+	n.updateThreadStarter(thread, fmt.Sprintf("%s（継続 %s）", status, incidents.FormatDuration(time.Since(thread.StartedAt))))
+	archived := true
+	if _, err := n.session.ChannelEditComplex(thread.ThreadID, &discordgo.ChannelEdit{Archived: &archived}); err != nil {
+		log.Printf("Failed to archive incident thread %s: %v", thread.ThreadID, err)
+	}
+}
+
+// notifyNewVandalInThreads 進行中スレッドに新規荒らしユーザーを投稿する
+func (n *Notifier) notifyNewVandalInThreads(user activity.UserActivity) {
+	n.mu.RLock()
+	threads := make(map[string]string, len(n.states))
+	for guildID, state := range n.states {
+		state.mu.Lock()
+		if state.thread != nil {
+			threads[guildID] = state.thread.ThreadID
+		}
+		state.mu.Unlock()
+	}
+	n.mu.RUnlock()
+
+	for guildID, threadID := range threads {
+		if !n.settings.GetGuildSettings(guildID).IncidentThreadsEnabled {
+			continue
+		}
+		embed, file := buildUserNotifyEmbed("🚨 新規荒らしユーザー検知", user, true)
+		send := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+		if file != nil {
+			send.Files = []*discordgo.File{file}
+		}
+		if _, err := n.session.ChannelMessageSendComplex(threadID, send); err != nil {
+			log.Printf("Failed to send vandal user to incident thread %s (guild %s): %v", threadID, guildID, err)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/incidents"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestIncidentThreadNameIncludesCurrentIncident(t *testing.T) {
+	t.Parallel()
+
+	store := incidents.NewStore("", nil)
+	store.Observe(time.Now(), incidents.Observation{Percentage: 2, DiffPixels: 20})
+	n := &Notifier{incidents: store}
+
+	now := time.Date(2026, 1, 2, 12, 3, 0, 0, time.UTC)
+	if got, want := n.incidentThreadName(now, config.DefaultLocation()), "🚨 インシデント #1 01/02 21:03"; got != want {
+		t.Fatalf("thread name = %q, want %q", got, want)
+	}
+	if got, want := (&Notifier{}).incidentThreadName(now, time.UTC), "🚨 インシデント 01/02 12:03"; got != want {
+		t.Fatalf("thread name without store = %q, want %q", got, want)
+	}
+}
+
+func TestThreadStarterContentKeepsOriginalMessage(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1767355380, 0)
+	got := threadStarterContent("🔔 変化検知", "🚨 差分率 12.00%", now)
+	if !strings.HasPrefix(got, "🔔 変化検知\n") || !strings.Contains(got, "🚨 差分率 12.00%") || !strings.Contains(got, "<t:1767355380:R>") {
+		t.Fatalf("unexpected starter content: %q", got)
+	}
+}
+
+// fakeDiscordCall fakeDiscord が受け取ったリクエスト
+type fakeDiscordCall struct {
+	method string
+	path   string // /api/vN を除いたパス（例: /channels/c1/messages）
+	body   string
+}
+
+// fakeDiscord Discord REST API の代わりに応答を返してリクエストを記録する http.RoundTripper
+type fakeDiscord struct {
+	mu      sync.Mutex
+	nextID  int
+	calls   []fakeDiscordCall
+	failing bool // true の間はメッセージ送信を 500 で失敗させる
+}
+
+func newFakeDiscordSession(t *testing.T) (*discordgo.Session, *fakeDiscord) {
+	t.Helper()
+	fake := &fakeDiscord{}
+	session, err := discordgo.New("Bot test")
+	if err != nil {
+		t.Fatalf("discordgo.New returned error: %v", err)
+	}
+	session.Client = &http.Client{Transport: fake}
+	session.MaxRestRetries = 0
+	return session, fake
+}
+
+func (f *fakeDiscord) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/api/v"+discordgo.APIVersion)
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	f.mu.Lock()
+	f.calls = append(f.calls, fakeDiscordCall{method: req.Method, path: path, body: string(body)})
+	f.nextID++
+	id := fmt.Sprintf("%d", f.nextID)
+	failing := f.failing && req.Method == http.MethodPost && strings.HasSuffix(path, "/messages")
+	f.mu.Unlock()
+
+	status := http.StatusOK
+	var sent struct {
+		Content string `json:"content"`
+	}
+	_ = json.Unmarshal(body, &sent)
+	resp := map[string]any{"id": "m" + id, "channel_id": parts[1], "content": sent.Content}
+	switch {
+	case failing:
+		status = http.StatusInternalServerError
+		resp = map[string]any{"message": "unavailable", "code": 0}
+	case strings.HasSuffix(path, "/threads"):
+		resp = map[string]any{"id": "t" + id, "parent_id": parts[1], "type": discordgo.ChannelTypeGuildPublicThread}
+	case req.Method == http.MethodPatch && len(parts) == 2:
+		resp = map[string]any{"id": parts[1], "type": discordgo.ChannelTypeGuildPublicThread}
+	}
+	payload, _ := json.Marshal(resp)
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(payload)),
+		Request:    req,
+	}, nil
+}
+
+// take 記録したリクエストを取り出してリセットする
+func (f *fakeDiscord) take() []fakeDiscordCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := f.calls
+	f.calls = nil
+	return calls
+}
+
+func (f *fakeDiscord) setFailing(failing bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failing = failing
+}
+
+// newThreadTestNotifier スレッド有効なギルド g1（通知チャンネル c1）を持つ Notifier を作る
+func newThreadTestNotifier(t *testing.T) (*Notifier, *fakeDiscord, config.GuildSettings) {
+	t.Helper()
+	session, fake := newFakeDiscordSession(t)
+	settings := config.NewSettingsManager(filepath.Join(t.TempDir(), "settings.json"))
+	t.Cleanup(func() { settings.Close() })
+	channelID := "c1"
+	settings.UpdateGuildSetting("g1", func(gs *config.GuildSettings) {
+		gs.NotificationChannel = &channelID
+		gs.IncidentThreadsEnabled = true
+	})
+	n := &Notifier{session: session, settings: settings, states: make(map[string]*NotificationState)}
+	return n, fake, settings.GetGuildSettings("g1")
+}
+
+// assertCalls リクエストの メソッド と パス が want と一致するか確認する
+func assertCalls(t *testing.T, got []fakeDiscordCall, want ...string) {
+	t.Helper()
+	paths := make([]string, len(got))
+	for i, c := range got {
+		paths[i] = c.method + " " + c.path
+	}
+	if strings.Join(paths, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("requests:\n%s\nwant:\n%s", strings.Join(paths, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestEpisodeMessagesCreatePostIntoAndArchiveThread(t *testing.T) {
+	t.Parallel()
+	n, fake, settings := newThreadTestNotifier(t)
+
+	// 最初の通知: チャンネルへ送ってスレッドを作り、起点メッセージに状態を表示する
+	if err := n.sendEpisodeMessage("g1", settings, outboxKindDetected, &discordgo.MessageSend{Content: "検知"}, "🚨 差分率 5.00%"); err != nil {
+		t.Fatalf("detected send returned error: %v", err)
+	}
+	assertCalls(t, fake.take(),
+		"POST /channels/c1/messages",
+		"POST /channels/c1/messages/m1/threads",
+		"PATCH /channels/c1/messages/m1",
+	)
+	thread := n.getState("g1").thread
+	if thread == nil || thread.ThreadID != "t2" || thread.StarterID != "m1" || thread.StarterContent != "検知" {
+		t.Fatalf("thread not started: %+v", thread)
+	}
+
+	// 以降の通知: スレッドへ送り、起点メッセージを更新する
+	if err := n.sendEpisodeMessage("g1", settings, outboxKindTierUp, &discordgo.MessageSend{Content: "Tier up"}, "🚨 差分率 12.00%"); err != nil {
+		t.Fatalf("tier send returned error: %v", err)
+	}
+	calls := fake.take()
+	assertCalls(t, calls,
+		"POST /channels/t2/messages",
+		"PATCH /channels/c1/messages/m1",
+	)
+	if !strings.Contains(calls[1].body, "🚨 差分率 12.00%") {
+		t.Fatalf("starter not updated with status: %s", calls[1].body)
+	}
+
+	// 修復完了: スレッドへ送ってからアーカイブする
+	if err := n.sendEpisodeMessage("g1", settings, outboxKindCompleted, &discordgo.MessageSend{Content: "完了"}, "✅ 修復完了"); err != nil {
+		t.Fatalf("completed send returned error: %v", err)
+	}
+	calls = fake.take()
+	assertCalls(t, calls,
+		"POST /channels/t2/messages",
+		"PATCH /channels/c1/messages/m1",
+		"PATCH /channels/c1/messages/m1",
+		"PATCH /channels/t2",
+	)
+	if !strings.Contains(calls[3].body, `"archived":true`) {
+		t.Fatalf("thread not archived: %s", calls[3].body)
+	}
+	if n.getState("g1").thread != nil {
+		t.Fatal("thread must be cleared after completion")
+	}
+}
+
+func TestOutboxReplayPostsIntoThreadAndArchives(t *testing.T) {
+	t.Parallel()
+	n, fake, settings := newThreadTestNotifier(t)
+	outbox := NewOutbox(n.session, t.TempDir())
+	n.SetOutbox(outbox)
+
+	if err := n.sendEpisodeMessage("g1", settings, outboxKindDetected, &discordgo.MessageSend{Content: "検知"}, "🚨 差分率 5.00%"); err != nil {
+		t.Fatalf("detected send returned error: %v", err)
+	}
+	fake.take()
+
+	// Discord の一時的な失敗: 完了通知は outbox に保存され、スレッドは再送までアーカイブしない
+	fake.setFailing(true)
+	err := n.sendEpisodeMessage("g1", settings, outboxKindCompleted, &discordgo.MessageSend{Content: "完了"}, "✅ 修復完了")
+	if !errors.Is(err, errOutboxQueued) {
+		t.Fatalf("completed send err = %v, want queued", err)
+	}
+	assertCalls(t, fake.take(), "POST /channels/t2/messages")
+	if n.getState("g1").thread == nil {
+		t.Fatal("thread must stay open until the completion is delivered")
+	}
+	if entries := outbox.Entries("g1"); len(entries) != 1 || entries[0].Episode == nil || entries[0].Episode.Finish != "✅ 修復完了" {
+		t.Fatalf("outbox entries = %+v", entries)
+	}
+
+	// 再送: 同じスレッドへ投稿してからアーカイブする
+	fake.setFailing(false)
+	if done := outbox.Flush(); done != 1 {
+		t.Fatalf("Flush = %d, want 1", done)
+	}
+	calls := fake.take()
+	assertCalls(t, calls,
+		"POST /channels/t2/messages",
+		"PATCH /channels/c1/messages/m1",
+		"PATCH /channels/c1/messages/m1",
+		"PATCH /channels/t2",
+	)
+	if !strings.Contains(calls[0].body, "完了") || !strings.Contains(calls[3].body, `"archived":true`) {
+		t.Fatalf("unexpected replay requests: %+v", calls)
+	}
+	if n.getState("g1").thread != nil || len(outbox.Entries("g1")) != 0 {
+		t.Fatal("thread and outbox entry must be cleared after replay")
+	}
+}
@@ -1,9 +1,11 @@
 package notifications
 
 import (
+	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/monitor"
 	"fmt"
 	"math"
+	"strings"
 )
 
 // Tier 通知段階
@@ -125,3 +127,141 @@ func tierRangeLabel(tier Tier, threshold float64) string {
 		return fmt.Sprintf("%.0f%%未満", threshold)
 	}
 }
+
+// tierLadderFallbackColors 色未指定の独自Tierに下の段から割り当てる配色
+var tierLadderFallbackColors = []int{0xFFFF00, 0xFFD700, 0xFFA500, 0xFF4500, 0xFF0000, 0xDC143C, 0xB22222, 0x7F0000}
+
+// tierLadder ギルドの Tier 段階。TierLadder 未設定なら NotificationThreshold 以上を10%刻みで扱う。
+// 独自ラダーでは Tier(i) が levels[i-1] を表す。
+type tierLadder struct {
+	levels    []config.TierLevel
+	threshold float64
+}
+
+func newTierLadder(settings config.GuildSettings) tierLadder {
+	return tierLadder{levels: settings.TierLadder, threshold: settings.NotificationThreshold}
+}
+
+func (l tierLadder) custom() bool { return len(l.levels) > 0 }
+
+// tier 差分率から Tier を計算
+func (l tierLadder) tier(diffValue float64) Tier {
+	if !l.custom() {
+		return calculateTier(diffValue, l.threshold)
+	}
+	tier := TierNone
+	for i, level := range l.levels {
+		if diffValue >= level.Threshold {
+			tier = Tier(i + 1)
+		}
+	}
+	return tier
+}
+
+func (l tierLadder) level(tier Tier) (config.TierLevel, bool) {
+	if !l.custom() || tier <= TierNone || int(tier) > len(l.levels) {
+		return config.TierLevel{}, false
+	}
+	return l.levels[tier-1], true
+}
+
+// lowerBound Tier の下限（%）。エスカレーション判定に使う。
+func (l tierLadder) lowerBound(tier Tier) float64 {
+	if tier <= TierNone {
+		return 0
+	}
+	if level, ok := l.level(tier); ok {
+		return level.Threshold
+	}
+	return math.Max(l.threshold, float64(tier)*10)
+}
+
+func (l tierLadder) color(tier Tier) int {
+	level, ok := l.level(tier)
+	if !ok {
+		return getTierColor(tier)
+	}
+	if level.Color != 0 {
+		return level.Color
+	}
+	return tierLadderFallbackColors[min(int(tier)-1, len(tierLadderFallbackColors)-1)]
+}
+
+// label 減少通知などに使う Tier の範囲表示
+func (l tierLadder) label(tier Tier) string {
+	if !l.custom() {
+		return tierRangeLabel(tier, l.threshold)
+	}
+	level, ok := l.level(tier)
+	if !ok {
+		return config.FormatPercent(l.levels[0].Threshold) + "未満"
+	}
+	if level.Name == "" {
+		return level.DisplayName()
+	}
+	return fmt.Sprintf("「%s」(%s以上)", level.Name, config.FormatPercent(level.Threshold))
+}
+
+// increaseDesc 上昇通知の「〜しました」部分
+func (l tierLadder) increaseDesc(tier Tier) string {
+	if l.custom() {
+		return l.label(tier) + "に増加"
+	}
+	switch tier {
+	case Tier100:
+		return "100%に急増!!"
+	case Tier90:
+		return "90%台に増加"
+	case Tier80:
+		return "80%台に増加"
+	case Tier70:
+		return "70%台に増加"
+	case Tier60:
+		return "60%台に増加"
+	case Tier50:
+		return "50%以上に急増"
+	case Tier40:
+		return "40%台に増加"
+	case Tier30:
+		return "30%台に増加"
+	case Tier20:
+		return "20%台に増加"
+	case Tier10:
+		return "10%台に増加"
+	default:
+		return "変動"
+	}
+}
+
+// mention 上昇通知の先頭に付けるメンション。
+// 独自ラダーは段ごとの設定、デフォルトは MentionThreshold / MentionRole を使う。
+func (l tierLadder) mention(tier Tier, diffValue float64, settings config.GuildSettings) string {
+	if !l.custom() {
+		if diffValue >= settings.MentionThreshold && settings.MentionRole != nil {
+			return fmt.Sprintf("<@&%s> ", *settings.MentionRole)
+		}
+		return ""
+	}
+	level, ok := l.level(tier)
+	if !ok || !level.Ping {
+		return ""
+	}
+	return roleMentions(level.MentionRoles)
+}
+
+// critical ダイジェスト配信でも即時に送る Tier か（メンション対象の段）
+func (l tierLadder) critical(tier Tier, diffValue float64, settings config.GuildSettings) bool {
+	if !l.custom() {
+		return diffValue >= settings.MentionThreshold
+	}
+	level, ok := l.level(tier)
+	return ok && level.Ping
+}
+
+func roleMentions(roleIDs []string) string {
+	var b strings.Builder
+	for _, id := range roleIDs {
+		fmt.Fprintf(&b, "<@&%s> ", id)
+	}
+	return b.String()
+}
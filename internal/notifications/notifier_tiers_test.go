@@ -0,0 +1,125 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+
+	"Koukyo_discord_bot/internal/config"
+)
+
+func TestTierLadderDefaultMatchesLegacyTiers(t *testing.T) {
+	t.Parallel()
+
+	role := "42"
+	settings := config.DefaultGuildSettings
+	settings.MentionRole = &role
+	ladder := newTierLadder(settings)
+
+	if got := ladder.tier(35); got != calculateTier(35, settings.NotificationThreshold) || got != Tier30 {
+		t.Fatalf("tier(35) = %v", got)
+	}
+	if got := ladder.label(Tier30); got != "30%台" {
+		t.Fatalf("label = %q", got)
+	}
+	if got := ladder.mention(Tier50, 55, settings); got != "<@&42> " {
+		t.Fatalf("mention = %q", got)
+	}
+	if got := ladder.mention(Tier30, 35, settings); got != "" {
+		t.Fatalf("mention below threshold = %q", got)
+	}
+}
+
+func TestTierLadderCustomLevels(t *testing.T) {
+	t.Parallel()
+
+	settings := config.DefaultGuildSettings
+	settings.TierLadder = []config.TierLevel{
+		{Threshold: 0.5, Name: "注意"},
+		{Threshold: 2},
+		{Threshold: 15, Name: "警報", Color: 0x123456, Ping: true, MentionRoles: []string{"1", "2"}},
+	}
+	ladder := newTierLadder(settings)
+
+	cases := []struct {
+		value float64
+		want  Tier
+	}{{0.4, TierNone}, {0.5, Tier(1)}, {14.9, Tier(2)}, {80, Tier(3)}}
+	for _, tc := range cases {
+		if got := ladder.tier(tc.value); got != tc.want {
+			t.Errorf("tier(%v) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+	if got := ladder.label(TierNone); got != "0.5%未満" {
+		t.Errorf("label(none) = %q", got)
+	}
+	if got := ladder.label(Tier(2)); got != "2%以上" {
+		t.Errorf("label(2) = %q", got)
+	}
+	if got := ladder.increaseDesc(Tier(3)); got != "「警報」(15%以上)に増加" {
+		t.Errorf("increaseDesc(3) = %q", got)
+	}
+	if got := ladder.color(Tier(3)); got != 0x123456 {
+		t.Errorf("color(3) = %#x", got)
+	}
+	if got := ladder.mention(Tier(3), 20, settings); got != "<@&1> <@&2> " {
+		t.Errorf("mention(3) = %q", got)
+	}
+	if got := ladder.mention(Tier(1), 1, settings); got != "" {
+		t.Errorf("mention(1) = %q", got)
+	}
+}
+
+func TestDueEscalationsFiresOnceAfterDuration(t *testing.T) {
+	t.Parallel()
+
+	settings := config.DefaultGuildSettings
+	settings.TierLadder = []config.TierLevel{{Threshold: 2}, {Threshold: 5}}
+	settings.TierEscalations = []config.TierEscalation{{MinThreshold: 5, AfterMinutes: 30, RoleIDs: []string{"9"}}}
+	ladder := newTierLadder(settings)
+	state := &NotificationState{}
+	base := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	if due := dueEscalations("g1", state, settings, ladder, Tier(2), false, false, base); len(due) != 0 {
+		t.Fatalf("escalated immediately: %+v", due)
+	}
+	if due := dueEscalations("g1", state, settings, ladder, Tier(1), false, false, base.Add(10*time.Minute)); len(due) != 0 {
+		t.Fatalf("escalated below threshold: %+v", due)
+	}
+	// 5%未満に落ちたので継続時間はリセットされる
+	dueEscalations("g1", state, settings, ladder, Tier(2), false, false, base.Add(11*time.Minute))
+	if due := dueEscalations("g1", state, settings, ladder, Tier(2), false, false, base.Add(40*time.Minute)); len(due) != 0 {
+		t.Fatalf("escalated before 30 minutes of persistence: %+v", due)
+	}
+	if due := dueEscalations("g1", state, settings, ladder, Tier(2), false, false, base.Add(41*time.Minute)); len(due) != 1 {
+		t.Fatalf("expected escalation after 30 minutes, got %+v", due)
+	}
+	if due := dueEscalations("g1", state, settings, ladder, Tier(2), false, false, base.Add(90*time.Minute)); len(due) != 0 {
+		t.Fatalf("escalation repeated: %+v", due)
+	}
+}
+
+func TestDueEscalationsHeldDuringQuietHoursFireAfterwards(t *testing.T) {
+	t.Parallel()
+
+	settings := config.DefaultGuildSettings
+	settings.TierLadder = []config.TierLevel{{Threshold: 2}, {Threshold: 5}}
+	settings.TierEscalations = []config.TierEscalation{{MinThreshold: 5, AfterMinutes: 30, RoleIDs: []string{"9"}}}
+	ladder := newTierLadder(settings)
+	state := &NotificationState{}
+	base := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	dueEscalations("g1", state, settings, ladder, Tier(2), false, true, base)
+	if due := dueEscalations("g1", state, settings, ladder, Tier(2), false, true, base.Add(31*time.Minute)); len(due) != 0 {
+		t.Fatalf("escalated during quiet hours: %+v", due)
+	}
+	if state.escalations[0].escalated {
+		t.Fatal("escalation held by quiet hours must not be marked as sent")
+	}
+	// 静音時間が明けてもまだ続いていれば送る
+	if due := dueEscalations("g1", state, settings, ladder, Tier(2), false, false, base.Add(60*time.Minute)); len(due) != 1 {
+		t.Fatalf("expected held escalation after quiet hours, got %+v", due)
+	}
+	if due := dueEscalations("g1", state, settings, ladder, Tier(2), false, false, base.Add(61*time.Minute)); len(due) != 0 {
+		t.Fatalf("escalation repeated: %+v", due)
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"Koukyo_discord_bot/internal/config"
 	"Koukyo_discord_bot/internal/utils"
+	"Koukyo_discord_bot/internal/webhooks"
 
 	"github.com/bwmarrin/discordgo"
 	_ "golang.org/x/image/webp"
@@ -32,6 +33,7 @@ type watchTargetStatus struct {
 	NextRun     time.Time
 	Running     bool
 	GuildStates map[string]*NotificationState
+	Last        targetLastResult
 }
 
 type watchTargetsRuntime struct {
@@ -108,7 +110,7 @@ func (n *Notifier) startWatchTargetsLoop() {
 }
 
 // HandleWatchTargetManual triggers a one-off fetch for a target id and posts to the channel.
-func (n *Notifier) HandleWatchTargetManual(channelID, targetID string) bool {
+func (n *Notifier) HandleWatchTargetManual(guildID, channelID, targetID string) bool {
 	if n == nil || n.watchTargetsState == nil {
 		return false
 	}
@@ -122,7 +124,7 @@ func (n *Notifier) HandleWatchTargetManual(channelID, targetID string) bool {
 			log.Printf("watch_targets: manual fetch failed channel=%s target=%s err=%v", channelID, target.ID, err)
 			return
 		}
-		n.sendWatchTargetManual(channelID, target, result)
+		n.sendWatchTargetManual(guildID, channelID, target, result)
 	}()
 	return true
 }
@@ -130,9 +132,11 @@ func (n *Notifier) HandleWatchTargetManual(channelID, targetID string) bool {
 func (n *Notifier) runWatchTarget(target watchTargetConfig) {
 	result, err := n.buildWatchTargetResult(target)
 	if err != nil {
+		n.watchTargetsState.recordResult(target.ID, newTargetLastResult(0, 0, err))
 		n.handleWatchTargetError(target, err, true)
 		return
 	}
+	n.watchTargetsState.recordResult(target.ID, newTargetLastResult(result.percent, result.diffPixels, nil))
 	for _, guild := range n.session.State.Guilds {
 		settings := n.settings.GetGuildSettings(guild.ID)
 		if !settings.AutoNotifyEnabled || settings.NotificationChannel == nil {
@@ -142,17 +146,52 @@ func (n *Notifier) runWatchTarget(target watchTargetConfig) {
 		if !eval.sendIncrease && !eval.sendDecrease && !eval.sendRecover && !eval.sendComplete {
 			continue
 		}
+		hook := webhooks.TargetData{TargetID: target.ID, Label: target.Label, Percent: result.percent, DiffPixels: result.diffPixels, Tier: int(eval.tier)}
 		if eval.sendRecover {
-			n.sendWatchTargetZeroRecoveryNotification(*settings.NotificationChannel, settings, target, result)
+			n.emitTargetWebhook(webhooks.EventWatchTargetChanged, guild.ID, hook, "detected")
 		}
 		if eval.sendComplete {
-			n.sendWatchTargetZeroCompletionNotification(*settings.NotificationChannel, settings, target, result)
+			n.emitTargetWebhook(webhooks.EventWatchTargetChanged, guild.ID, hook, "completed")
 		}
 		if eval.sendIncrease {
-			n.sendWatchTargetIncreaseNotification(*settings.NotificationChannel, settings, target, result, eval.tier)
+			n.emitTargetWebhook(webhooks.EventWatchTargetChanged, guild.ID, hook, "increase")
 		}
 		if eval.sendDecrease {
-			n.sendWatchTargetDecreaseNotification(*settings.NotificationChannel, settings, target, result, eval.tier)
+			n.emitTargetWebhook(webhooks.EventWatchTargetChanged, guild.ID, hook, "decrease")
+		}
+		if gate, reason := notificationGate(settings, time.Now()); gate == gateMuted {
+			n.recordMuted(guild.ID, reason, "追加監視 "+target.Label, 0, time.Now())
+			continue
+		}
+		channelID := *settings.NotificationChannel
+		item := digestItem{kind: outboxKindWatch}
+		if eval.sendRecover {
+			item.line = fmt.Sprintf("🏯 追加監視 `%s`: 変化検知（%.2f%%）", target.Label, result.percent)
+			n.deliverHigh(guild.ID, settings, item, func() {
+				n.sendWatchTargetZeroRecoveryNotification(guild.ID, channelID, settings, target, result)
+			})
+		}
+		if eval.sendComplete {
+			item.line = fmt.Sprintf("🏯 追加監視 `%s`: 修復完了", target.Label)
+			n.deliverHigh(guild.ID, settings, item, func() {
+				n.sendWatchTargetZeroCompletionNotification(guild.ID, channelID, settings, target, result)
+			})
+		}
+		if eval.sendIncrease {
+			item.line = fmt.Sprintf("🏯 追加監視 `%s`: %sに増加（%.2f%%）", target.Label, tierRangeLabel(eval.tier, settings.NotificationThreshold), result.percent)
+			item.critical = result.percent >= settings.MentionThreshold
+			tier := eval.tier
+			n.deliverHigh(guild.ID, settings, item, func() {
+				n.sendWatchTargetIncreaseNotification(guild.ID, channelID, settings, target, result, tier)
+			})
+			item.critical = false
+		}
+		if eval.sendDecrease {
+			item.line = fmt.Sprintf("🏯 追加監視 `%s`: %sまで減少（%.2f%%）", target.Label, tierRangeLabel(eval.tier, settings.NotificationThreshold), result.percent)
+			tier := eval.tier
+			n.deliverHigh(guild.ID, settings, item, func() {
+				n.sendWatchTargetDecreaseNotification(guild.ID, channelID, settings, target, result, tier)
+			})
 		}
 	}
 	n.watchTargetsState.clearErrorNotified(target.ID)
@@ -185,6 +224,7 @@ func (n *Notifier) buildWatchTargetResult(target watchTargetConfig) (*watchTarge
 }
 
 func (n *Notifier) sendWatchTargetIncreaseNotification(
+	guildID string,
 	channelID string,
 	settings config.GuildSettings,
 	target watchTargetConfig,
@@ -192,7 +232,7 @@ func (n *Notifier) sendWatchTargetIncreaseNotification(
 	tier Tier,
 ) {
 	mentionStr := ""
-	if result.percent >= settings.MentionThreshold && settings.MentionRole != nil {
+	if result.percent >= settings.MentionThreshold && settings.MentionRole != nil && mentionsAllowed(settings) {
 		mentionStr = fmt.Sprintf("<@&%s> ", *settings.MentionRole)
 	}
 	tierDesc := "変動"
@@ -221,10 +261,11 @@ func (n *Notifier) sendWatchTargetIncreaseNotification(
 
 	content := fmt.Sprintf("%s【Wplace速報】 🚨 差分率が%sしました！[現在%.2f%%]\n対象: `%s`", mentionStr, tierDesc, result.percent, target.Label)
 	embed := n.buildWatchTargetEmbed("🏯 Wplace 荒らし検知 (追加監視)", target, result, getTierColor(tier))
-	n.sendWatchTargetMessage(channelID, content, embed, target, result)
+	n.sendWatchTargetMessage(guildID, channelID, content, embed, target, result)
 }
 
 func (n *Notifier) sendWatchTargetDecreaseNotification(
+	guildID string,
 	channelID string,
 	settings config.GuildSettings,
 	target watchTargetConfig,
@@ -233,10 +274,11 @@ func (n *Notifier) sendWatchTargetDecreaseNotification(
 ) {
 	content := fmt.Sprintf("【Wplace速報】 差分率が%sまで減少しました。[現在%.2f%%]\n対象: `%s`", tierRangeLabel(tier, settings.NotificationThreshold), result.percent, target.Label)
 	embed := n.buildWatchTargetEmbed("🏯 Wplace 差分減少 (追加監視)", target, result, getTierColor(tier))
-	n.sendWatchTargetMessage(channelID, content, embed, target, result)
+	n.sendWatchTargetMessage(guildID, channelID, content, embed, target, result)
 }
 
 func (n *Notifier) sendWatchTargetZeroRecoveryNotification(
+	guildID string,
 	channelID string,
 	_ config.GuildSettings,
 	target watchTargetConfig,
@@ -244,10 +286,11 @@ func (n *Notifier) sendWatchTargetZeroRecoveryNotification(
 ) {
 	content := fmt.Sprintf("🔔 【Wplace速報】変化検知 差分率: **%.2f%%**に上昇\n対象: `%s`", result.percent, target.Label)
 	embed := n.buildWatchTargetEmbed("🟢 Wplace 変化検知 (追加監視)", target, result, 0x00FF00)
-	n.sendWatchTargetMessage(channelID, content, embed, target, result)
+	n.sendWatchTargetMessage(guildID, channelID, content, embed, target, result)
 }
 
 func (n *Notifier) sendWatchTargetZeroCompletionNotification(
+	guildID string,
 	channelID string,
 	_ config.GuildSettings,
 	target watchTargetConfig,
@@ -255,7 +298,7 @@ func (n *Notifier) sendWatchTargetZeroCompletionNotification(
 ) {
 	content := fmt.Sprintf("✅ 【Wplace速報】修復完了！ 差分率: **0.00%%** # Pixel Perfect!\n対象: `%s`", target.Label)
 	embed := n.buildWatchTargetEmbed("🎉 Wplace 修復完了 (追加監視)", target, result, 0x00FF00)
-	n.sendWatchTargetMessage(channelID, content, embed, target, result)
+	n.sendWatchTargetMessage(guildID, channelID, content, embed, target, result)
 }
 
 func (n *Notifier) buildWatchTargetEmbed(title string, target watchTargetConfig, result *watchTargetResult, colorCode int) *discordgo.MessageEmbed {
@@ -313,20 +356,21 @@ func (n *Notifier) buildWatchTargetEmbed(title string, target watchTargetConfig,
 	return embed
 }
 
-func (n *Notifier) sendWatchTargetManual(channelID string, target watchTargetConfig, result *watchTargetResult) {
+func (n *Notifier) sendWatchTargetManual(guildID, channelID string, target watchTargetConfig, result *watchTargetResult) {
 	content := fmt.Sprintf("📌 追加監視 手動取得: `%s`", target.Label)
 	embed := n.buildWatchTargetEmbed("📌 追加監視 手動取得", target, result, 0x3498DB)
-	n.sendWatchTargetMessage(channelID, content, embed, target, result)
+	n.sendWatchTargetMessage(guildID, channelID, content, embed, target, result)
 }
 
 func (n *Notifier) sendWatchTargetMessage(
+	guildID string,
 	channelID string,
 	content string,
 	embed *discordgo.MessageEmbed,
 	target watchTargetConfig,
 	result *watchTargetResult,
 ) {
-	_, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+	_, err := n.sendChannelMessage(guildID, channelID, outboxKindWatch, &discordgo.MessageSend{
 		Content: content,
 		Embeds:  []*discordgo.MessageEmbed{embed},
 		Files: []*discordgo.File{
@@ -0,0 +1,70 @@
+package notifications
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/monitor"
+	"Koukyo_discord_bot/internal/webhooks"
+)
+
+// SetWebhooks 外部 Webhook の送信先を設定する（Start 前に呼ぶ）
+func (n *Notifier) SetWebhooks(d *webhooks.Dispatcher) {
+	n.webhooks = d
+}
+
+// emitWebhook イベントを外部 Webhook へ送る（未設定なら何もしない）
+func (n *Notifier) emitWebhook(eventType, guildID string, data any) {
+	if n.webhooks == nil {
+		return
+	}
+	n.webhooks.Emit(webhooks.NewEvent(eventType, n.artwork().ID, guildID, data))
+}
+
+// emitDiffWebhooks CheckAndNotify の状態遷移（検知・修復完了・Tier変動）を外部 Webhook へ送る。
+// 状態更新前に呼ぶ。
+func (n *Notifier) emitDiffWebhooks(guildID string, state *NotificationState, settings config.GuildSettings, data *monitor.MonitorData, ladder tierLadder, diffValue float64, currentTier Tier, isZero bool) {
+	if n.webhooks == nil {
+		return
+	}
+	payload := webhooks.DiffData{
+		Metric:       settings.NotificationMetric,
+		DiffPercent:  diffValue,
+		DiffPixels:   data.DiffPixels,
+		TotalPixels:  data.TotalPixels,
+		Tier:         int(currentTier),
+		PreviousTier: int(state.LastTier),
+	}
+	if currentTier != TierNone {
+		payload.TierLabel = ladder.label(currentTier)
+	}
+	switch {
+	case state.WasZeroDiff && !isZero:
+		n.emitWebhook(webhooks.EventDiffDetected, guildID, payload)
+	case !state.WasZeroDiff && isZero:
+		n.emitWebhook(webhooks.EventDiffCompleted, guildID, payload)
+	}
+	if !isZero && currentTier > state.LastTier {
+		n.emitWebhook(webhooks.EventTierUp, guildID, payload)
+	} else if !isZero && currentTier < state.LastTier {
+		n.emitWebhook(webhooks.EventTierDown, guildID, payload)
+	}
+}
+
+// emitTargetWebhook 追加監視/進捗監視の変化を外部 Webhook へ送る
+func (n *Notifier) emitTargetWebhook(eventType, guildID string, payload webhooks.TargetData, change string) {
+	payload.Change = change
+	n.emitWebhook(eventType, guildID, payload)
+}
+
+func userWebhookData(user activity.UserActivity) webhooks.UserData {
+	return webhooks.UserData{
+		UserID:        user.ID,
+		Name:          user.Name,
+		AllianceName:  user.AllianceName,
+		DiscordID:     user.DiscordID,
+		VandalCount:   user.VandalCount,
+		RestoredCount: user.RestoredCount,
+		Banned:        user.Banned,
+		TimedOut:      user.TimedOut,
+	}
+}
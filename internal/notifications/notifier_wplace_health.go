@@ -9,11 +9,13 @@ import (
 	"sync"
 	"time"
 
+	"Koukyo_discord_bot/internal/utils"
+	"Koukyo_discord_bot/internal/webhooks"
+
 	"github.com/bwmarrin/discordgo"
 )
 
 const (
-	wplaceHealthURL      = "https://backend.wplace.live/health"
 	wplaceHealthInterval = 3 * time.Minute
 	wplaceHealthTimeout  = 10 * time.Second
 	// HTTP失敗がこの回数連続したら障害とみなす（一時的なネットワーク揺らぎを除外）
@@ -101,7 +103,7 @@ func wplaceFailReason(resp *wplaceHealthResponse, err error) string {
 }
 
 func fetchWplaceHealth(client *http.Client) (*wplaceHealthResponse, error) {
-	req, err := http.NewRequest(http.MethodGet, wplaceHealthURL, nil)
+	req, err := http.NewRequest(http.MethodGet, utils.WplaceBackendURL()+"/health", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +131,7 @@ func fetchWplaceHealth(client *http.Client) (*wplaceHealthResponse, error) {
 }
 
 func (n *Notifier) notifyWplaceOutage(reason string) {
+	n.emitWebhook(webhooks.EventWplaceOutage, "", webhooks.OutageData{Reason: reason})
 	embed := &discordgo.MessageEmbed{
 		Title:       "⚠️ wplace 障害検知",
 		Description: "wplace.live が正常に応答していません。",
@@ -143,6 +146,7 @@ func (n *Notifier) notifyWplaceOutage(reason string) {
 
 func (n *Notifier) notifyWplaceRecovery(since time.Time) {
 	downtime := time.Since(since)
+	n.emitWebhook(webhooks.EventWplaceRecovered, "", webhooks.OutageData{DowntimeSeconds: downtime.Seconds()})
 	embed := &discordgo.MessageEmbed{
 		Title:       "✅ wplace 復旧",
 		Description: "wplace.live が復旧しました。",
@@ -161,7 +165,9 @@ func (n *Notifier) broadcastToNotificationChannels(embed *discordgo.MessageEmbed
 		if !gs.AutoNotifyEnabled || gs.NotificationChannel == nil {
 			continue
 		}
-		_, err := n.session.ChannelMessageSendEmbed(*gs.NotificationChannel, embed)
+		_, err := n.sendChannelMessage(guild.ID, *gs.NotificationChannel, outboxKindWplaceDown, &discordgo.MessageSend{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		})
 		if err != nil {
 			log.Printf("wplace health: notify guild %s: %v", guild.ID, err)
 		}
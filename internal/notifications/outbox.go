@@ -0,0 +1,532 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"Koukyo_discord_bot/internal/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// OutboxFileName 未送信通知の保存ファイル名（data/ 直下）
+	OutboxFileName = "outbox.json"
+	// outboxDirName 未送信通知の添付ファイル置き場
+	outboxDirName = "outbox"
+	// outboxMaxEntries 保持する未送信通知の上限（超過分は古い順に捨てる）
+	outboxMaxEntries = 200
+	// outboxMaxAge これより古い未送信通知は再送せずに捨てる
+	outboxMaxAge = 24 * time.Hour
+	// outboxRetryInterval 再送を試みる間隔
+	outboxRetryInterval = 30 * time.Second
+)
+
+// 未送信通知の種類（新しい通知で古い通知を置き換える判定に使う）
+const (
+	outboxKindDetected   = "変化検知"
+	outboxKindSnapshot   = "変化スナップショット"
+	outboxKindTierUp     = "Tier上昇"
+	outboxKindTierDown   = "Tier減少"
+	outboxKindEscalation = "エスカレーション"
+	outboxKindCompleted  = "修復完了"
+	outboxKindDigest     = "ダイジェスト"
+	outboxKindMuted      = "停止中のまとめ"
+	outboxKindWplaceDown = "wplace障害"
+	outboxKindWatch      = "追加監視"
+	outboxKindProgress   = "進捗監視"
+	outboxKindVandalUser = "新規荒らし"
+	outboxKindFixUser    = "新規修復"
+	outboxKindReport     = "定期レポート"
+	outboxKindBotSuspect = "bot の疑い"
+	outboxKindUserStatus = "利用制限"
+	outboxKindMonitoring = "監視状態"
+	outboxKindTimelapse  = "タイムラプス"
+)
+
+// errOutboxQueued 通知をすぐには送らず outbox に保存したことを表す。
+// 送信後にする処理（スレッドのアーカイブなど）は再送時に OutboxEpisode に従って行われる。
+var errOutboxQueued = errors.New("queued to outbox")
+
+// OutboxAttachment 未送信通知の添付ファイル
+type OutboxAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type,omitempty"`
+	Path        string `json:"path"` // outbox/ 配下のファイル名
+}
+
+// OutboxEpisode インシデントのエピソードに属する通知の再送方法
+type OutboxEpisode struct {
+	Status string `json:"status,omitempty"` // 空でなければエピソードのスレッドへ送り、起点メッセージの状態表示を更新する
+	Finish string `json:"finish,omitempty"` // 空でなければ送信後にこの状態表示でスレッドをアーカイブする
+}
+
+// OutboxEntry 送信に失敗した通知1件
+type OutboxEntry struct {
+	ID         int                       `json:"id"`
+	GuildID    string                    `json:"guild_id"`
+	ChannelID  string                    `json:"channel_id"`
+	Artwork    string                    `json:"artwork,omitempty"`
+	Kind       string                    `json:"kind"`
+	Episode    *OutboxEpisode            `json:"episode,omitempty"`
+	Content    string                    `json:"content,omitempty"`
+	Embeds     []*discordgo.MessageEmbed `json:"embeds,omitempty"`
+	Components []json.RawMessage         `json:"components,omitempty"`
+	Files      []OutboxAttachment        `json:"files,omitempty"`
+	CreatedAt  time.Time                 `json:"created_at"`
+	Attempts   int                       `json:"attempts"`
+	LastError  string                    `json:"last_error,omitempty"`
+}
+
+// outboxReplayer エピソード付きの通知をアートワークの Notifier 経由で再送する
+type outboxReplayer func(entry *OutboxEntry, msg *discordgo.MessageSend) error
+
+type outboxFile struct {
+	NextID  int            `json:"next_id"`
+	Entries []*OutboxEntry `json:"entries"`
+}
+
+// Outbox Discord 障害などで送れなかった通知を data/ に保存し、復旧後に古い順に再送する。
+// 全アートワークの Notifier で共有する。
+type Outbox struct {
+	session *discordgo.Session
+	dir     string
+	wake    chan struct{}
+
+	mu        sync.Mutex
+	nextID    int
+	entries   []*OutboxEntry            // 古い順
+	replayers map[string]outboxReplayer // アートワークID -> 再送先
+	holds     map[string]int            // アートワークID -> 直接送らずに保存する処理の数（hold 中）
+
+	flushMu sync.Mutex
+}
+
+// NewOutbox 保存済みの未送信通知を読み込んで作成する
+func NewOutbox(session *discordgo.Session, dataDir string) *Outbox {
+	o := &Outbox{
+		session:   session,
+		dir:       dataDir,
+		wake:      make(chan struct{}, 1),
+		nextID:    1,
+		replayers: make(map[string]outboxReplayer),
+		holds:     make(map[string]int),
+	}
+	var file outboxFile
+	if _, err := utils.ReadJSONFileWithBackup(filepath.Join(dataDir, OutboxFileName), &file); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("outbox: failed to load: %v", err)
+		}
+		return o
+	}
+	o.entries = file.Entries
+	o.nextID = max(file.NextID, 1)
+	if len(o.entries) > 0 {
+		log.Printf("outbox: restored %d pending notification(s)", len(o.entries))
+	}
+	return o
+}
+
+// Start 再送ループを開始する
+func (o *Outbox) Start() {
+	if o == nil {
+		return
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("PANIC in outboxLoop: %v", r)
+			}
+		}()
+
+		ticker := time.NewTicker(outboxRetryInterval)
+		defer ticker.Stop()
+		o.Flush()
+		for {
+			select {
+			case <-ticker.C:
+			case <-o.wake:
+			}
+			o.Flush()
+		}
+	}()
+}
+
+// Entries guildID 宛ての未送信通知（空なら全件）
+func (o *Outbox) Entries(guildID string) []OutboxEntry {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var out []OutboxEntry
+	for _, e := range o.entries {
+		if guildID == "" || e.GuildID == guildID {
+			out = append(out, *e)
+		}
+	}
+	return out
+}
+
+// Clear guildID 宛ての未送信通知を破棄し、件数を返す
+func (o *Outbox) Clear(guildID string) int {
+	if o == nil {
+		return 0
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	removed := 0
+	o.entries = slices.DeleteFunc(o.entries, func(e *OutboxEntry) bool {
+		if e.GuildID != guildID {
+			return false
+		}
+		o.removeFilesLocked(e)
+		removed++
+		return true
+	})
+	if removed > 0 {
+		o.saveLocked()
+	}
+	return removed
+}
+
+// Wake 再送ループをすぐに回す
+func (o *Outbox) Wake() {
+	if o == nil {
+		return
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// setReplayer artwork のエピソード付き通知の再送先を登録する
+func (o *Outbox) setReplayer(artwork string, replay outboxReplayer) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.replayers[artwork] = replay
+}
+
+// send 通知を送る。同じギルドに未送信分があれば順番を保つため後ろに積み、
+// 一時的なエラーで失敗したら保存して後で再送する。保存した場合は errOutboxQueued を返す。
+// episode はエピソードのスレッドへ送る通知の再送方法（それ以外は nil）。
+func (o *Outbox) send(guildID, channelID, artwork, kind string, episode *OutboxEpisode, msg *discordgo.MessageSend, direct func(*discordgo.MessageSend) error) error {
+	if o == nil {
+		return direct(msg)
+	}
+	files, err := bufferMessageFiles(msg)
+	if err != nil {
+		return err
+	}
+	if reason := o.queueReason(guildID, artwork); reason != "" {
+		o.add(guildID, channelID, artwork, kind, episode, msg, files, reason)
+		o.Wake()
+		return errOutboxQueued
+	}
+	err = direct(msg)
+	if err == nil || !isTransientDiscordError(err) {
+		return err
+	}
+	o.add(guildID, channelID, artwork, kind, episode, msg, files, err.Error())
+	return fmt.Errorf("%w: %w", errOutboxQueued, err)
+}
+
+// queueReason 直接送らずに保存すべきならその理由を返す
+func (o *Outbox) queueReason(guildID, artwork string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.holds[artwork] > 0 {
+		return "高優先度キューが溢れたため保存"
+	}
+	if slices.ContainsFunc(o.entries, func(e *OutboxEntry) bool { return e.GuildID == guildID }) {
+		return "未送信の通知の後ろに追加"
+	}
+	return ""
+}
+
+// hold release までの間、artwork の通知を直接送らずに保存する（溢れた送信処理の退避用）
+func (o *Outbox) hold(artwork string) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.holds[artwork]++
+}
+
+func (o *Outbox) release(artwork string) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.holds[artwork]--; o.holds[artwork] <= 0 {
+		delete(o.holds, artwork)
+	}
+}
+
+// add 未送信通知を保存する。新しい通知で意味のなくなった古い通知は捨てる。
+func (o *Outbox) add(guildID, channelID, artwork, kind string, episode *OutboxEpisode, msg *discordgo.MessageSend, files map[string][]byte, reason string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.entries = slices.DeleteFunc(o.entries, func(e *OutboxEntry) bool {
+		if e.GuildID != guildID || e.Artwork != artwork || !outboxSupersedes(kind, e.Kind) {
+			return false
+		}
+		log.Printf("outbox: dropping obsolete #%d (%s) guild=%s superseded by %s", e.ID, e.Kind, e.GuildID, kind)
+		o.removeFilesLocked(e)
+		return true
+	})
+
+	entry := &OutboxEntry{
+		ID:        o.nextID,
+		GuildID:   guildID,
+		ChannelID: channelID,
+		Artwork:   artwork,
+		Kind:      kind,
+		Episode:   episode,
+		Content:   msg.Content,
+		Embeds:    msg.Embeds,
+		CreatedAt: time.Now(),
+		LastError: reason,
+	}
+	o.nextID++
+	for _, c := range msg.Components {
+		raw, err := json.Marshal(c)
+		if err != nil {
+			log.Printf("outbox: failed to encode component: %v", err)
+			continue
+		}
+		entry.Components = append(entry.Components, raw)
+	}
+	for i, f := range msg.Files {
+		name := fmt.Sprintf("%d-%d-%s", entry.ID, i, filepath.Base(f.Name))
+		if err := utils.WriteFileAtomic(filepath.Join(o.dir, outboxDirName, name), files[f.Name]); err != nil {
+			log.Printf("outbox: failed to save attachment %s: %v", f.Name, err)
+			continue
+		}
+		entry.Files = append(entry.Files, OutboxAttachment{Name: f.Name, ContentType: f.ContentType, Path: name})
+	}
+	o.entries = append(o.entries, entry)
+	if over := len(o.entries) - outboxMaxEntries; over > 0 {
+		for _, e := range o.entries[:over] {
+			log.Printf("outbox: full, dropping #%d (%s) guild=%s", e.ID, e.Kind, e.GuildID)
+			o.removeFilesLocked(e)
+		}
+		o.entries = slices.Clone(o.entries[over:])
+	}
+	o.saveLocked()
+	log.Printf("outbox: queued #%d (%s) guild=%s channel=%s: %s", entry.ID, kind, guildID, channelID, reason)
+}
+
+// Flush 古い順に再送する。一時的なエラーが出たギルドはそこで止め（順番を保つため）、
+// 他のギルドの分は続けて送る。送信したか破棄した件数を返す。
+func (o *Outbox) Flush() int {
+	if o == nil {
+		return 0
+	}
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+
+	o.mu.Lock()
+	pending := slices.Clone(o.entries)
+	o.mu.Unlock()
+
+	done := 0
+	blocked := make(map[string]bool)
+	for _, entry := range pending {
+		if blocked[entry.GuildID] {
+			continue
+		}
+		if time.Since(entry.CreatedAt) > outboxMaxAge {
+			log.Printf("outbox: dropping stale #%d (%s) guild=%s", entry.ID, entry.Kind, entry.GuildID)
+			o.remove(entry)
+			done++
+			continue
+		}
+		msg, err := o.message(entry)
+		if err != nil {
+			log.Printf("outbox: dropping #%d (%s) guild=%s: %v", entry.ID, entry.Kind, entry.GuildID, err)
+			o.remove(entry)
+			done++
+			continue
+		}
+		err = o.deliver(entry, msg)
+		switch {
+		case err == nil:
+			log.Printf("outbox: delivered #%d (%s) guild=%s", entry.ID, entry.Kind, entry.GuildID)
+			o.remove(entry)
+			done++
+		case !isTransientDiscordError(err):
+			log.Printf("outbox: dropping #%d (%s) guild=%s: %v", entry.ID, entry.Kind, entry.GuildID, err)
+			o.remove(entry)
+			done++
+		default:
+			blocked[entry.GuildID] = true
+			o.mu.Lock()
+			entry.Attempts++
+			entry.LastError = err.Error()
+			o.saveLocked()
+			o.mu.Unlock()
+		}
+	}
+	return done
+}
+
+// deliver エピソード付きの通知は元の Notifier のスレッド経路で、それ以外はチャンネルへ直接送る
+func (o *Outbox) deliver(entry *OutboxEntry, msg *discordgo.MessageSend) error {
+	if entry.Episode != nil {
+		o.mu.Lock()
+		replay := o.replayers[entry.Artwork]
+		o.mu.Unlock()
+		if replay != nil {
+			return replay(entry, msg)
+		}
+	}
+	_, err := o.session.ChannelMessageSendComplex(entry.ChannelID, msg)
+	return err
+}
+
+func (o *Outbox) message(entry *OutboxEntry) (*discordgo.MessageSend, error) {
+	msg := &discordgo.MessageSend{Content: entry.Content, Embeds: entry.Embeds}
+	for _, raw := range entry.Components {
+		c, err := discordgo.MessageComponentFromJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("component: %w", err)
+		}
+		msg.Components = append(msg.Components, c)
+	}
+	for _, f := range entry.Files {
+		data, err := os.ReadFile(filepath.Join(o.dir, outboxDirName, f.Path))
+		if err != nil {
+			return nil, fmt.Errorf("attachment %s: %w", f.Name, err)
+		}
+		msg.Files = append(msg.Files, &discordgo.File{Name: f.Name, ContentType: f.ContentType, Reader: bytes.NewReader(data)})
+	}
+	return msg, nil
+}
+
+func (o *Outbox) remove(entry *OutboxEntry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = slices.DeleteFunc(o.entries, func(e *OutboxEntry) bool { return e == entry })
+	o.removeFilesLocked(entry)
+	o.saveLocked()
+}
+
+func (o *Outbox) removeFilesLocked(entry *OutboxEntry) {
+	for _, f := range entry.Files {
+		if err := os.Remove(filepath.Join(o.dir, outboxDirName, f.Path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("outbox: failed to remove attachment %s: %v", f.Path, err)
+		}
+	}
+}
+
+func (o *Outbox) saveLocked() {
+	payload, err := json.MarshalIndent(outboxFile{NextID: o.nextID, Entries: o.entries}, "", "  ")
+	if err != nil {
+		log.Printf("outbox: failed to encode: %v", err)
+		return
+	}
+	if err := utils.WriteFileAtomic(filepath.Join(o.dir, OutboxFileName), payload); err != nil {
+		log.Printf("outbox: failed to save: %v", err)
+	}
+}
+
+// outboxSupersedes kind の通知が届けば old の通知は送る意味がないか
+func outboxSupersedes(kind, old string) bool {
+	switch kind {
+	case outboxKindCompleted:
+		// 修復完了が出ていれば、それ以前の検知・Tier変動・エスカレーションは古い
+		return old != outboxKindDigest && old != outboxKindMuted
+	case outboxKindTierUp, outboxKindTierDown:
+		return old == outboxKindTierUp || old == outboxKindTierDown
+	}
+	return false
+}
+
+// bufferMessageFiles 添付の Reader を読み切ってメモリに置き換える（失敗時に保存・再送できるように）
+func bufferMessageFiles(msg *discordgo.MessageSend) (map[string][]byte, error) {
+	if len(msg.Files) == 0 {
+		return nil, nil
+	}
+	files := make(map[string][]byte, len(msg.Files))
+	for _, f := range msg.Files {
+		data, err := io.ReadAll(f.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("read attachment %s: %w", f.Name, err)
+		}
+		files[f.Name] = data
+		f.Reader = bytes.NewReader(data)
+	}
+	return files, nil
+}
+
+// isTransientDiscordError 時間をおけば成功しうるエラーか（通信エラー・429・5xx）
+func isTransientDiscordError(err error) bool {
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		code := restErr.Response.StatusCode
+		return code == http.StatusTooManyRequests || code >= 500
+	}
+	return true
+}
+
+// SetOutbox 送信失敗した通知の保存先を設定する（Start 前に呼ぶ）
+func (n *Notifier) SetOutbox(o *Outbox) {
+	n.outbox = o
+	o.setReplayer(n.artwork().ID, n.replayOutboxEntry)
+}
+
+// replayOutboxEntry 保存されていたエピソードの通知を、スレッドへの投稿とアーカイブを含めて再送する
+func (n *Notifier) replayOutboxEntry(entry *OutboxEntry, msg *discordgo.MessageSend) error {
+	var err error
+	if entry.Episode.Status != "" {
+		settings := n.settings.GetGuildSettings(entry.GuildID)
+		channelID := entry.ChannelID
+		settings.NotificationChannel = &channelID
+		err = n.postEpisodeMessage(entry.GuildID, settings, msg, entry.Episode.Status)
+	} else {
+		_, err = n.session.ChannelMessageSendComplex(entry.ChannelID, msg)
+	}
+	// 再送しない失敗なら、スレッドだけは閉じておく
+	if entry.Episode.Finish != "" && (err == nil || !isTransientDiscordError(err)) {
+		n.finishIncidentThread(entry.GuildID, entry.Episode.Finish)
+	}
+	return err
+}
+
+// sendChannelMessage チャンネルへ通知を送る。一時的な失敗は outbox に保存して後で再送する（errOutboxQueued）。
+// 直接送れた場合だけ送信したメッセージを返す。
+func (n *Notifier) sendChannelMessage(guildID, channelID, kind string, msg *discordgo.MessageSend) (*discordgo.Message, error) {
+	var sent *discordgo.Message
+	err := n.outbox.send(guildID, channelID, n.artwork().ID, kind, nil, msg, func(msg *discordgo.MessageSend) error {
+		var err error
+		sent, err = n.session.ChannelMessageSendComplex(channelID, msg)
+		return err
+	})
+	return sent, err
+}
+
+// Outbox 送信失敗した通知の保存先（未設定なら nil）
+func (n *Notifier) Outbox() *Outbox {
+	if n == nil {
+		return nil
+	}
+	return n.outbox
+}
@@ -0,0 +1,178 @@
+package notifications
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestOutboxCollapsesObsoleteEntries(t *testing.T) {
+	t.Parallel()
+
+	o := NewOutbox(nil, t.TempDir())
+	add := func(guildID, kind string) {
+		o.add(guildID, "c1", "koukyo", kind, nil, &discordgo.MessageSend{Content: kind}, nil, "test")
+	}
+	add("g1", outboxKindDetected)
+	add("g1", outboxKindTierUp)
+	add("g1", outboxKindTierUp)
+	add("g2", outboxKindTierUp)
+
+	if got := len(o.Entries("g1")); got != 2 {
+		t.Fatalf("g1 entries after tier updates = %d, want 2 (detected + latest tier)", got)
+	}
+
+	add("g1", outboxKindDigest)
+	add("g1", outboxKindCompleted)
+	entries := o.Entries("g1")
+	if len(entries) != 2 || entries[0].Kind != outboxKindDigest || entries[1].Kind != outboxKindCompleted {
+		t.Fatalf("g1 entries after completion = %+v", entries)
+	}
+	if got := len(o.Entries("g2")); got != 1 {
+		t.Fatalf("other guild must be untouched, got %d", got)
+	}
+}
+
+func TestOutboxPersistsEntriesAndAttachments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	o := NewOutbox(nil, dir)
+	msg := &discordgo.MessageSend{
+		Content: "hello",
+		Embeds:  []*discordgo.MessageEmbed{{Title: "embed", Image: &discordgo.MessageEmbedImage{URL: "attachment://diff.png"}}},
+		Files:   []*discordgo.File{{Name: "diff.png", ContentType: "image/png", Reader: bytes.NewReader([]byte("png"))}},
+	}
+	files, err := bufferMessageFiles(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.add("g1", "c1", "koukyo", outboxKindTierUp, &OutboxEpisode{Status: "📈 Tier上昇"}, msg, files, "HTTP 503")
+
+	restored := NewOutbox(nil, dir)
+	entries := restored.Entries("")
+	if len(entries) != 1 || entries[0].Content != "hello" || entries[0].Embeds[0].Title != "embed" {
+		t.Fatalf("restored entries = %+v", entries)
+	}
+	replay, err := restored.message(&entries[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(replay.Files[0].Reader); err != nil || buf.String() != "png" || replay.Files[0].Name != "diff.png" {
+		t.Fatalf("attachment not restored: %q err=%v", buf.String(), err)
+	}
+
+	if removed := restored.Clear("g1"); removed != 1 {
+		t.Fatalf("Clear = %d, want 1", removed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, outboxDirName, entries[0].Files[0].Path)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("attachment should be removed, stat err=%v", err)
+	}
+}
+
+func TestIsTransientDiscordError(t *testing.T) {
+	t.Parallel()
+
+	restErr := func(code int) error {
+		return &discordgo.RESTError{Response: &http.Response{StatusCode: code}}
+	}
+	if !isTransientDiscordError(errors.New("dial tcp: i/o timeout")) {
+		t.Error("network errors should be transient")
+	}
+	if !isTransientDiscordError(restErr(http.StatusBadGateway)) {
+		t.Error("5xx should be transient")
+	}
+	if isTransientDiscordError(restErr(http.StatusForbidden)) {
+		t.Error("403 should not be retried")
+	}
+}
+
+func TestEnqueueHighSpillsInOrderWhenFull(t *testing.T) {
+	t.Parallel()
+
+	n := &Notifier{dispatchHigh: make(chan dispatchFunc, 1)}
+	var got []int
+	for i := range 3 {
+		n.enqueueHigh("test", func() { got = append(got, i) })
+	}
+	if high, _ := n.GetDroppedNotificationStats(); high != 0 {
+		t.Fatalf("dropped = %d, want 0", high)
+	}
+	for range 3 {
+		(<-n.dispatchHigh)()
+		n.refillDispatchHigh()
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 1 || got[2] != 2 {
+		t.Fatalf("order = %v", got)
+	}
+}
+
+func TestEnqueueHighSavesOverflowToOutbox(t *testing.T) {
+	t.Parallel()
+
+	n := &Notifier{
+		dispatchHigh:  make(chan dispatchFunc, 1),
+		dispatchSpill: make(chan dispatchFunc, 1),
+		outbox:        NewOutbox(nil, t.TempDir()),
+	}
+	for range dispatchOverflowLimit + 1 {
+		n.enqueueHigh("test", func() {})
+	}
+	n.enqueueHigh(outboxKindVandalUser, func() {
+		if _, err := n.sendChannelMessage("g1", "c1", outboxKindVandalUser, &discordgo.MessageSend{Content: "spilled"}); !errors.Is(err, errOutboxQueued) {
+			t.Errorf("spilled send err = %v, want queued", err)
+		}
+	})
+	if high, _ := n.GetDroppedNotificationStats(); high != 0 {
+		t.Fatalf("dropped = %d, want 0", high)
+	}
+	close(n.dispatchSpill)
+	n.runDispatchSpill()
+
+	entries := n.outbox.Entries("g1")
+	if len(entries) != 1 || entries[0].Kind != outboxKindVandalUser || entries[0].Content != "spilled" {
+		t.Fatalf("outbox entries = %+v", entries)
+	}
+	if reason := n.outbox.queueReason("g2", n.artwork().ID); reason != "" {
+		t.Fatalf("hold must be released after spilling, got %q", reason)
+	}
+}
+
+func TestOutboxFlushReplaysEpisodeEntriesWithComponents(t *testing.T) {
+	t.Parallel()
+
+	o := NewOutbox(nil, t.TempDir())
+	msg := &discordgo.MessageSend{
+		Content: "完了",
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "詳細", Style: discordgo.LinkButton, URL: "https://example.com"},
+		}}},
+	}
+	o.add("g1", "c1", "koukyo", outboxKindCompleted, &OutboxEpisode{Status: "✅ 修復完了", Finish: "✅ 修復完了"}, msg, nil, "HTTP 503")
+
+	var replayed *OutboxEntry
+	var replayedMsg *discordgo.MessageSend
+	o.setReplayer("koukyo", func(entry *OutboxEntry, msg *discordgo.MessageSend) error {
+		replayed, replayedMsg = entry, msg
+		return nil
+	})
+	if done := o.Flush(); done != 1 {
+		t.Fatalf("Flush = %d, want 1", done)
+	}
+	if replayed == nil || replayed.Episode == nil || replayed.Episode.Finish != "✅ 修復完了" {
+		t.Fatalf("episode entry not replayed through notifier: %+v", replayed)
+	}
+	row, ok := replayedMsg.Components[0].(*discordgo.ActionsRow)
+	if len(replayedMsg.Components) != 1 || !ok || len(row.Components) != 1 {
+		t.Fatalf("components not restored: %#v", replayedMsg.Components)
+	}
+	if len(o.Entries("")) != 0 {
+		t.Fatal("delivered entry should be removed")
+	}
+}
@@ -0,0 +1,112 @@
+package notifications
+
+import (
+	"log"
+	"time"
+)
+
+// TargetStatus 追加監視（watch_targets / progress_targets）の直近状態
+type TargetStatus struct {
+	Kind          string // "watch" または "progress"
+	ID            string
+	Label         string
+	Origin        string
+	Interval      time.Duration
+	NextRun       time.Time
+	Running       bool
+	LastCheckedAt time.Time
+	// Percent watch は差分率、progress は進捗率
+	Percent    float64
+	DiffPixels int
+	LastError  string
+}
+
+// targetLastResult 直近の取得結果（通知判定とは独立に保持する）
+type targetLastResult struct {
+	CheckedAt  time.Time
+	Percent    float64
+	DiffPixels int
+	Err        string
+}
+
+func newTargetLastResult(percent float64, diffPixels int, err error) targetLastResult {
+	last := targetLastResult{CheckedAt: time.Now(), Percent: percent, DiffPixels: diffPixels}
+	if err != nil {
+		last.Err = err.Error()
+	}
+	return last
+}
+
+// TargetStatuses 設定済みターゲットの直近状態を watch → progress の順で返す
+func (n *Notifier) TargetStatuses() []TargetStatus {
+	if n == nil {
+		return nil
+	}
+	var out []TargetStatus
+	if w := n.watchTargetsState; w != nil {
+		cfgs, err := w.loadConfigs()
+		if err != nil {
+			log.Printf("watch_targets: failed to load config: %v", err)
+		}
+		w.mu.Lock()
+		for _, cfg := range cfgs {
+			ts := newTargetStatus("watch", cfg)
+			if st, ok := w.statuses[cfg.ID]; ok {
+				ts.applyRuntime(st.NextRun, st.Running, st.Last)
+			}
+			out = append(out, ts)
+		}
+		w.mu.Unlock()
+	}
+	if p := n.progressTargetsState; p != nil {
+		cfgs, err := p.loadProgressConfigs()
+		if err != nil {
+			log.Printf("progress_targets: failed to load config: %v", err)
+		}
+		p.mu.Lock()
+		for _, cfg := range cfgs {
+			ts := newTargetStatus("progress", cfg)
+			if st, ok := p.statuses[cfg.ID]; ok {
+				ts.applyRuntime(st.NextRun, st.Running, st.Last)
+			}
+			out = append(out, ts)
+		}
+		p.mu.Unlock()
+	}
+	return out
+}
+
+func newTargetStatus(kind string, cfg commonTargetConfig) TargetStatus {
+	return TargetStatus{
+		Kind:     kind,
+		ID:       cfg.ID,
+		Label:    cfg.Label,
+		Origin:   cfg.Origin,
+		Interval: cfg.Interval,
+	}
+}
+
+func (ts *TargetStatus) applyRuntime(nextRun time.Time, running bool, last targetLastResult) {
+	ts.NextRun = nextRun
+	ts.Running = running
+	ts.LastCheckedAt = last.CheckedAt
+	ts.Percent = last.Percent
+	ts.DiffPixels = last.DiffPixels
+	ts.LastError = last.Err
+}
+
+func (w *watchTargetsRuntime) recordResult(targetID string, last targetLastResult) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if st, ok := w.statuses[targetID]; ok {
+		st.Last = last
+	}
+}
+
+func (w *progressTargetsRuntime) recordProgressResult(targetID string, last targetLastResult) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if st, ok := w.statuses[targetID]; ok {
+		st.Last = last
+	}
+}
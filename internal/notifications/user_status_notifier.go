@@ -0,0 +1,65 @@
+package notifications
+
+import (
+	"Koukyo_discord_bot/internal/activity"
+	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/embeds"
+	"Koukyo_discord_bot/internal/utils"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// UserStatusNotifier 既知の荒らしユーザーが wplace で BAN / タイムアウトされたことを荒らし通知チャンネルへ送る
+type UserStatusNotifier struct {
+	session  *discordgo.Session
+	settings *config.SettingsManager
+	deliver  deliverFunc // ギルドの配信モード（nil なら即時送信）
+	post     postFunc
+}
+
+func NewUserStatusNotifier(session *discordgo.Session, settings *config.SettingsManager, deliver deliverFunc, post postFunc) *UserStatusNotifier {
+	return &UserStatusNotifier{
+		session:  session,
+		settings: settings,
+		deliver:  deliver,
+		post:     post,
+	}
+}
+
+func (n *UserStatusNotifier) Notify(user activity.UserActivity) {
+	status := embeds.FormatUserStatus(user.Banned, user.TimedOut)
+	for _, guild := range n.session.State.Guilds {
+		gs := n.settings.GetGuildSettings(guild.ID)
+		if gs.NotificationVandalChannel == nil {
+			continue
+		}
+		channelID := *gs.NotificationVandalChannel
+		guildID := guild.ID
+		loc := n.settings.GuildLocation(guildID)
+		send := func() {
+			embed, file := buildUserNotifyEmbed("🔨 荒らしユーザーの利用制限", user, true)
+			embed.Color = 0x2C3E50
+			embed.Fields = append(embed.Fields,
+				&discordgo.MessageEmbedField{Name: "状態", Value: status, Inline: true},
+				&discordgo.MessageEmbedField{Name: "状態の履歴", Value: embeds.FormatStatusHistory(user.StatusHistory, loc, 5)},
+			)
+			msg := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+			if file != nil {
+				msg.Files = []*discordgo.File{file}
+			}
+			if _, err := n.post(guildID, channelID, outboxKindUserStatus, msg); err != nil {
+				log.Printf("Failed to send user status notification to guild %s: %v", guildID, err)
+			}
+		}
+		if n.deliver == nil {
+			send()
+			continue
+		}
+		n.deliver(guildID, gs, digestItem{
+			kind: outboxKindUserStatus,
+			line: fmt.Sprintf("🔨 %s: %s", status, utils.FormatUserDisplayName(user.Name, user.ID)),
+		}, send)
+	}
+}
@@ -3,6 +3,8 @@ package notifications
 import (
 	"Koukyo_discord_bot/internal/activity"
 	"Koukyo_discord_bot/internal/config"
+	"Koukyo_discord_bot/internal/utils"
+	"fmt"
 	"log"
 
 	"github.com/bwmarrin/discordgo"
@@ -11,12 +13,16 @@ import (
 type VandalUserNotifier struct {
 	session  *discordgo.Session
 	settings *config.SettingsManager
+	deliver  deliverFunc // ギルドの配信モード（nil なら即時送信）
+	post     postFunc
 }
 
-func NewVandalUserNotifier(session *discordgo.Session, settings *config.SettingsManager) *VandalUserNotifier {
+func NewVandalUserNotifier(session *discordgo.Session, settings *config.SettingsManager, deliver deliverFunc, post postFunc) *VandalUserNotifier {
 	return &VandalUserNotifier{
 		session:  session,
 		settings: settings,
+		deliver:  deliver,
+		post:     post,
 	}
 }
 
@@ -27,18 +33,24 @@ func (n *VandalUserNotifier) Notify(user activity.UserActivity) {
 			continue
 		}
 		channelID := *gs.NotificationVandalChannel
-		embed, file := buildUserNotifyEmbed("🚨 新規荒らしユーザー検知", user, true)
-		if file != nil {
-			if _, err := n.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-				Embeds: []*discordgo.MessageEmbed{embed},
-				Files:  []*discordgo.File{file},
-			}); err != nil {
-				log.Printf("Failed to send vandal user notification to guild %s: %v", guild.ID, err)
+		guildID := guild.ID
+		send := func() {
+			embed, file := buildUserNotifyEmbed("🚨 新規荒らしユーザー検知", user, true)
+			msg := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+			if file != nil {
+				msg.Files = []*discordgo.File{file}
+			}
+			if _, err := n.post(guildID, channelID, outboxKindVandalUser, msg); err != nil {
+				log.Printf("Failed to send vandal user notification to guild %s: %v", guildID, err)
 			}
-			continue
 		}
-		if _, err := n.session.ChannelMessageSendEmbed(channelID, embed); err != nil {
-			log.Printf("Failed to send vandal user notification to guild %s: %v", guild.ID, err)
+		if n.deliver == nil {
+			send()
+			continue
 		}
+		n.deliver(guildID, gs, digestItem{
+			kind: outboxKindVandalUser,
+			line: fmt.Sprintf("🚨 新規荒らし: %s", utils.FormatUserDisplayName(user.Name, user.ID)),
+		}, send)
 	}
 }
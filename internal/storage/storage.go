@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// BackendJSON データディレクトリ直下の JSON ファイル（既定）
+	BackendJSON = "json"
+	// BackendBolt データディレクトリ直下の組み込み DB（bbolt）
+	BackendBolt = "bolt"
+
+	// DBFileName 組み込み DB のファイル名（アートワークのデータディレクトリごと）
+	DBFileName = "koukyo.db"
+
+	// openTimeout 別プロセスが DB を掴んでいるときに待つ時間
+	openTimeout = 5 * time.Second
+)
+
+var metaBucket = []byte("meta")
+
+// Migration JSON ファイルから DB への一度きりの移行。完了した Name は meta バケットに記録し、次回からは実行しない
+type Migration struct {
+	Name string
+	Run  func(dataDir string, tx *bolt.Tx) error
+}
+
+var (
+	mu         sync.Mutex
+	dbs        = make(map[string]*bolt.DB)
+	migrations []Migration
+)
+
+// Backend 環境変数 STORAGE_BACKEND の値（json / bolt。未指定や不明な値は json）
+func Backend() string {
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("STORAGE_BACKEND")), BackendBolt) {
+		return BackendBolt
+	}
+	return BackendJSON
+}
+
+// RegisterMigration 移行処理を登録する（各パッケージの init から呼ぶ）
+func RegisterMigration(m Migration) {
+	mu.Lock()
+	defer mu.Unlock()
+	migrations = append(migrations, m)
+}
+
+// Open dataDir の DB を開く。同じディレクトリは使い回し、開いたときに未実行の移行を行う
+func Open(dataDir string) (*bolt.DB, error) {
+	if dataDir == "" {
+		return nil, fmt.Errorf("dataDir is empty")
+	}
+	key, err := filepath.Abs(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if db, ok := dbs[key]; ok {
+		return db, nil
+	}
+	if err := os.MkdirAll(key, 0o755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(filepath.Join(key, DBFileName), 0o644, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", DBFileName, err)
+	}
+	if err := runMigrations(dataDir, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	dbs[key] = db
+	return db, nil
+}
+
+// Close dataDir の DB を閉じる（開いていなければ何もしない）
+func Close(dataDir string) error {
+	key, err := filepath.Abs(dataDir)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	db, ok := dbs[key]
+	if !ok {
+		return nil
+	}
+	delete(dbs, key)
+	return db.Close()
+}
+
+// CloseAll 開いている DB をすべて閉じる（終了時）
+func CloseAll() {
+	mu.Lock()
+	defer mu.Unlock()
+	for key, db := range dbs {
+		if err := db.Close(); err != nil {
+			log.Printf("failed to close %s: %v", filepath.Join(key, DBFileName), err)
+		}
+		delete(dbs, key)
+	}
+}
+
+func runMigrations(dataDir string, db *bolt.DB) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			doneKey := []byte("migration:" + m.Name)
+			if meta.Get(doneKey) != nil {
+				continue
+			}
+			if err := m.Run(dataDir, tx); err != nil {
+				return fmt.Errorf("migration %s: %w", m.Name, err)
+			}
+			if err := meta.Put(doneKey, []byte(time.Now().UTC().Format(time.RFC3339))); err != nil {
+				return err
+			}
+			log.Printf("storage: migrated %s in %s", m.Name, dataDir)
+		}
+		return nil
+	})
+}
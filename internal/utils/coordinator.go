@@ -119,3 +119,13 @@ func FormatHyphenCoords(coord *Coordinate) string {
 	return fmt.Sprintf("%d-%d-%d-%d",
 		coord.TileX, coord.TileY, coord.PixelX, coord.PixelY)
 }
+
+// CoordinateFromAbsolute 全体のピクセル座標（タイル×1000+ピクセル）からタイル座標とピクセル座標に分解
+func CoordinateFromAbsolute(absX, absY int) *Coordinate {
+	return &Coordinate{
+		TileX:  absX / WplaceTileSize,
+		TileY:  absY / WplaceTileSize,
+		PixelX: absX % WplaceTileSize,
+		PixelY: absY % WplaceTileSize,
+	}
+}
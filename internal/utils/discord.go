@@ -25,35 +25,44 @@ func FormatUserDisplayName(name, id string) string {
 	}
 }
 
-// DecodePictureDataURL converts a base64 image data URL into a discord file.
-func DecodePictureDataURL(value string) *discordgo.File {
+// DecodePictureData decodes a base64 image data URL into raw bytes and its content type.
+func DecodePictureData(value string) ([]byte, string) {
 	if value == "" || !strings.HasPrefix(value, "data:image/") {
-		return nil
+		return nil, ""
 	}
 	parts := strings.SplitN(value, ",", 2)
 	if len(parts) != 2 {
-		return nil
+		return nil, ""
 	}
 	header := parts[0]
 	payload := parts[1]
 	if !strings.Contains(header, ";base64") {
-		return nil
+		return nil, ""
 	}
 	data, err := base64.StdEncoding.DecodeString(payload)
 	if err != nil || len(data) == 0 {
+		return nil, ""
+	}
+	return data, strings.TrimPrefix(strings.SplitN(header, ";", 2)[0], "data:")
+}
+
+// DecodePictureDataURL converts a base64 image data URL into a discord file.
+func DecodePictureDataURL(value string) *discordgo.File {
+	data, contentType := DecodePictureData(value)
+	if data == nil {
 		return nil
 	}
 	ext := "png"
-	switch {
-	case strings.Contains(header, "image/jpeg"):
+	switch contentType {
+	case "image/jpeg":
 		ext = "jpg"
-	case strings.Contains(header, "image/webp"):
+	case "image/webp":
 		ext = "webp"
 	}
 	filename := "user_picture." + ext
 	return &discordgo.File{
 		Name:        filename,
-		ContentType: strings.TrimPrefix(strings.SplitN(header, ";", 2)[0], "data:"),
+		ContentType: contentType,
 		Reader:      bytes.NewReader(data),
 	}
 }
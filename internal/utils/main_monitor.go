@@ -12,24 +12,26 @@ const (
 )
 
 func BuildMainMonitorWplaceURL() string {
-	centerAbsX := float64(MainMonitorTileX*WplaceTileSize+MainMonitorPixelX) + float64(MainMonitorWidth)/2
-	centerAbsY := float64(MainMonitorTileY*WplaceTileSize+MainMonitorPixelY) + float64(MainMonitorHeight)/2
+	return BuildAreaWplaceURL(MainMonitorTileX, MainMonitorTileY, MainMonitorPixelX, MainMonitorPixelY, MainMonitorWidth, MainMonitorHeight)
+}
+
+func MainMonitorFullsizeString() string {
+	return AreaFullsizeString(MainMonitorTileX, MainMonitorTileY, MainMonitorPixelX, MainMonitorPixelY, MainMonitorWidth, MainMonitorHeight)
+}
+
+// BuildAreaWplaceURL 左上座標とサイズで指定した領域の中心を表示する Wplace URL を返す
+func BuildAreaWplaceURL(tileX, tileY, pixelX, pixelY, width, height int) string {
+	centerAbsX := float64(tileX*WplaceTileSize+pixelX) + float64(width)/2
+	centerAbsY := float64(tileY*WplaceTileSize+pixelY) + float64(height)/2
 	centerTileX := int(centerAbsX) / WplaceTileSize
 	centerTileY := int(centerAbsY) / WplaceTileSize
 	centerPixelX := int(centerAbsX) % WplaceTileSize
 	centerPixelY := int(centerAbsY) % WplaceTileSize
 	center := TilePixelCenterToLngLat(centerTileX, centerTileY, centerPixelX, centerPixelY)
-	return BuildWplaceURL(center.Lng, center.Lat, ZoomFromImageSize(MainMonitorWidth, MainMonitorHeight))
+	return BuildWplaceURL(center.Lng, center.Lat, ZoomFromImageSize(width, height))
 }
 
-func MainMonitorFullsizeString() string {
-	return fmt.Sprintf(
-		"%d-%d-%d-%d-%d-%d",
-		MainMonitorTileX,
-		MainMonitorTileY,
-		MainMonitorPixelX,
-		MainMonitorPixelY,
-		MainMonitorWidth,
-		MainMonitorHeight,
-	)
+// AreaFullsizeString `/get fullsize:` に渡す形式の文字列を返す
+func AreaFullsizeString(tileX, tileY, pixelX, pixelY, width, height int) string {
+	return fmt.Sprintf("%d-%d-%d-%d-%d-%d", tileX, tileY, pixelX, pixelY, width, height)
 }
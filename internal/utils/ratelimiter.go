@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 )
@@ -19,13 +20,28 @@ type hostLimiter struct {
 	requests chan *request
 	ticker   *time.Ticker
 	done     chan struct{}
+
+	statsMu   sync.Mutex
+	processed uint64
+	waitTotal time.Duration
+	lastWait  time.Duration
+}
+
+// HostStats ホスト別の待ち行列と待ち時間の統計
+type HostStats struct {
+	Host      string
+	Queued    int           // 実行待ちのリクエスト数
+	Processed uint64        // 実行したリクエスト数（累計）
+	WaitTotal time.Duration // キュー投入から実行開始までの待ち時間（累計）
+	LastWait  time.Duration // 直近に実行したリクエストの待ち時間
 }
 
 // request キューに入れるリクエスト
 type request struct {
-	fn       func() (interface{}, error)
-	resultCh chan *result
-	ctx      context.Context
+	fn         func() (interface{}, error)
+	resultCh   chan *result
+	ctx        context.Context
+	enqueuedAt time.Time
 }
 
 // result リクエストの結果
@@ -51,9 +67,10 @@ func (rl *RateLimiter) Do(ctx context.Context, host string, fn func() (interface
 	hl := rl.getOrCreateHostLimiter(host)
 
 	req := &request{
-		fn:       fn,
-		resultCh: make(chan *result, 1),
-		ctx:      ctx,
+		fn:         fn,
+		resultCh:   make(chan *result, 1),
+		ctx:        ctx,
+		enqueuedAt: time.Now(),
 	}
 
 	select {
@@ -110,6 +127,7 @@ func (rl *RateLimiter) worker(hl *hostLimiter) {
 				}
 
 				// リクエスト実行
+				hl.recordWait(time.Since(req.enqueuedAt))
 				value, err := req.fn()
 				req.resultCh <- &result{value: value, err: err}
 
@@ -132,6 +150,34 @@ func (rl *RateLimiter) worker(hl *hostLimiter) {
 	}
 }
 
+func (hl *hostLimiter) recordWait(wait time.Duration) {
+	hl.statsMu.Lock()
+	hl.processed++
+	hl.waitTotal += wait
+	hl.lastWait = wait
+	hl.statsMu.Unlock()
+}
+
+// Stats ホスト別の統計をホスト名順で返す
+func (rl *RateLimiter) Stats() []HostStats {
+	rl.mu.Lock()
+	out := make([]HostStats, 0, len(rl.hostLimits))
+	for host, hl := range rl.hostLimits {
+		hl.statsMu.Lock()
+		out = append(out, HostStats{
+			Host:      host,
+			Queued:    len(hl.requests),
+			Processed: hl.processed,
+			WaitTotal: hl.waitTotal,
+			LastWait:  hl.lastWait,
+		})
+		hl.statsMu.Unlock()
+	}
+	rl.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+	return out
+}
+
 // Close すべてのワーカーを停止
 func (rl *RateLimiter) Close() {
 	rl.mu.Lock()
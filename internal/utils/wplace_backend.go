@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultWplaceBackendURL wplace バックエンドの既定URL
+const DefaultWplaceBackendURL = "https://backend.wplace.live"
+
+var wplaceBackendOverride struct {
+	mu  sync.RWMutex
+	url string
+}
+
+// WplaceBackendURL タイル・ピクセル・ヘルスAPIの接続先を返す。
+// SetWplaceBackendURL → 環境変数 WPLACE_BACKEND_URL → 既定値 の順に決まる（末尾の / は除去）。
+func WplaceBackendURL() string {
+	wplaceBackendOverride.mu.RLock()
+	url := wplaceBackendOverride.url
+	wplaceBackendOverride.mu.RUnlock()
+	if url == "" {
+		url = strings.TrimSpace(os.Getenv("WPLACE_BACKEND_URL"))
+	}
+	if url == "" {
+		url = DefaultWplaceBackendURL
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// SetWplaceBackendURL 接続先をプロセス内で上書きする（ローカルエミュレーター向け。空文字で解除）
+func SetWplaceBackendURL(url string) {
+	wplaceBackendOverride.mu.Lock()
+	wplaceBackendOverride.url = strings.TrimSpace(url)
+	wplaceBackendOverride.mu.Unlock()
+}
@@ -0,0 +1,234 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// queueSize 送信先ごとの未送信イベント上限（超過分は捨てる）
+	queueSize = 256
+	// defaultMaxAttempts 1イベントあたりの最大送信回数
+	defaultMaxAttempts = 6
+	// defaultBaseDelay 再送間隔の初期値（1回ごとに倍、maxDelay まで）
+	defaultBaseDelay = 2 * time.Second
+	// maxDelay 再送間隔の上限
+	maxDelay = 2 * time.Minute
+	// requestTimeout 1回の送信のタイムアウト
+	requestTimeout = 10 * time.Second
+)
+
+// 送信時のヘッダー
+const (
+	HeaderEvent     = "X-Koukyo-Event"
+	HeaderDelivery  = "X-Koukyo-Delivery"
+	HeaderTimestamp = "X-Koukyo-Timestamp"
+	HeaderSignature = "X-Koukyo-Signature"
+)
+
+// Options Dispatcher の動作設定（ゼロ値は既定値）
+type Options struct {
+	Client      *http.Client
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// Dispatcher イベントを各送信先へ非同期に配送する。nil でも Emit できる。
+type Dispatcher struct {
+	workers []*worker
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+
+	closeMu sync.RWMutex
+	closed  bool // Close 後の Emit は捨てる（閉じたキューへ送らない）
+}
+
+type worker struct {
+	endpoint    Endpoint
+	client      *http.Client
+	maxAttempts int
+	baseDelay   time.Duration
+	queue       chan Event
+
+	mu      sync.Mutex
+	dropped uint64
+}
+
+// New 送信先ごとにワーカーを起動する
+func New(endpoints []Endpoint, opts Options) *Dispatcher {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: requestTimeout}
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = defaultBaseDelay
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{cancel: cancel}
+	for _, ep := range endpoints {
+		w := &worker{
+			endpoint:    ep,
+			client:      opts.Client,
+			maxAttempts: opts.MaxAttempts,
+			baseDelay:   opts.BaseDelay,
+			queue:       make(chan Event, queueSize),
+		}
+		d.workers = append(d.workers, w)
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			w.run(ctx)
+		}()
+	}
+	return d
+}
+
+// Emit フィルタに合う送信先のキューへ積む（ブロックしない）。Close 後は何もしない。
+func (d *Dispatcher) Emit(ev Event) {
+	if d == nil {
+		return
+	}
+	d.closeMu.RLock()
+	defer d.closeMu.RUnlock()
+	if d.closed {
+		return
+	}
+	for _, w := range d.workers {
+		if !w.endpoint.Accepts(ev) {
+			continue
+		}
+		select {
+		case w.queue <- ev:
+		default:
+			w.mu.Lock()
+			w.dropped++
+			dropped := w.dropped
+			w.mu.Unlock()
+			log.Printf("webhooks: queue full for %s, dropping %s (total dropped: %d)", w.endpoint.Name, ev.Type, dropped)
+		}
+	}
+}
+
+// Close 未送信分を最大 timeout 待ってからワーカーを止める
+func (d *Dispatcher) Close(timeout time.Duration) {
+	if d == nil {
+		return
+	}
+	d.closeMu.Lock()
+	if d.closed {
+		d.closeMu.Unlock()
+		return
+	}
+	d.closed = true
+	for _, w := range d.workers {
+		close(w.queue)
+	}
+	d.closeMu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf("webhooks: shutdown timed out after %s, abandoning pending events", timeout)
+	}
+	d.cancel()
+}
+
+func (w *worker) run(ctx context.Context) {
+	for ev := range w.queue {
+		body, err := json.Marshal(ev)
+		if err != nil {
+			log.Printf("webhooks: marshal %s: %v", ev.Type, err)
+			continue
+		}
+		w.deliver(ctx, ev, body)
+	}
+}
+
+// deliver 成功するか再送不能になるまで送る（送信先ごとに順番を保つ）
+func (w *worker) deliver(ctx context.Context, ev Event, body []byte) {
+	delay := w.baseDelay
+	for attempt := 1; ; attempt++ {
+		retry, wait, err := w.post(ctx, ev, body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= w.maxAttempts {
+			log.Printf("webhooks: giving up %s (%s) to %s after %d attempt(s): %v", ev.Type, ev.ID, w.endpoint.Name, attempt, err)
+			return
+		}
+		if wait <= 0 {
+			wait = delay
+			delay = min(delay*2, maxDelay)
+		}
+		log.Printf("webhooks: %s to %s failed (attempt %d/%d), retrying in %s: %v", ev.Type, w.endpoint.Name, attempt, w.maxAttempts, wait, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// post 1回送信する。retry は再送する価値があるか、wait は Retry-After の指定。
+func (w *worker) post(ctx context.Context, ev Event, body []byte) (retry bool, wait time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Koukyo_discord_bot-webhooks")
+	req.Header.Set(HeaderEvent, ev.Type)
+	req.Header.Set(HeaderDelivery, ev.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if w.endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(w.endpoint.Secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, 0, nil
+	}
+	err = fmt.Errorf("status %d", resp.StatusCode)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		if secs, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && secs > 0 {
+			wait = min(time.Duration(secs)*time.Second, maxDelay)
+		}
+		return true, wait, err
+	}
+	return false, 0, err
+}
+
+// Sign 署名ヘッダーの値（"sha256=" + HMAC-SHA256(secret, timestamp + "." + body) の16進）
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 受信側向け: 署名ヘッダーが正しいか
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,93 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"Koukyo_discord_bot/internal/utils"
+)
+
+// FileName 送信先定義ファイル名（data/ 直下）
+const FileName = "webhooks.json"
+
+// Endpoint 送信先1件の定義
+type Endpoint struct {
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Secret    string   `json:"secret,omitempty"`     // HMAC-SHA256 の鍵
+	SecretEnv string   `json:"secret_env,omitempty"` // 鍵を環境変数から読む場合の変数名（secret より優先）
+	Events    []string `json:"events,omitempty"`     // 送るイベント（空なら全件。"tier.*" のような前方一致可）
+	Guilds    []string `json:"guilds,omitempty"`     // ギルド単位のイベントを絞る（空なら全ギルド）
+}
+
+// Accepts このイベントを送るか
+func (e Endpoint) Accepts(ev Event) bool {
+	if len(e.Guilds) > 0 && ev.GuildID != "" && !slices.Contains(e.Guilds, ev.GuildID) {
+		return false
+	}
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, pattern := range e.Events {
+		if pattern == "*" || pattern == ev.Type {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(ev.Type, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadEndpoints webhooks.json を読み込む。ファイルが無い場合は nil を返す。
+func LoadEndpoints(path string) ([]Endpoint, error) {
+	var root struct {
+		Endpoints []Endpoint `json:"endpoints"`
+	}
+	if _, err := utils.ReadJSONFileWithBackup(path, &root); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return normalizeEndpoints(root.Endpoints)
+}
+
+func normalizeEndpoints(list []Endpoint) ([]Endpoint, error) {
+	seen := make(map[string]struct{}, len(list))
+	out := make([]Endpoint, 0, len(list))
+	for i, ep := range list {
+		ep.Name = strings.TrimSpace(ep.Name)
+		ep.URL = strings.TrimSpace(ep.URL)
+		if ep.Name == "" {
+			ep.Name = fmt.Sprintf("endpoint-%d", i+1)
+		}
+		if _, ok := seen[ep.Name]; ok {
+			return nil, fmt.Errorf("endpoints[%d]: duplicate name %q", i, ep.Name)
+		}
+		seen[ep.Name] = struct{}{}
+		u, err := url.Parse(ep.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("endpoint %s: invalid url %q", ep.Name, ep.URL)
+		}
+		if env := strings.TrimSpace(ep.SecretEnv); env != "" {
+			ep.Secret = os.Getenv(env)
+			if ep.Secret == "" {
+				return nil, fmt.Errorf("endpoint %s: secret_env %s is empty", ep.Name, env)
+			}
+		}
+		// 署名の無い送信は受け手が検証できないので許可しない
+		if ep.Secret == "" {
+			return nil, fmt.Errorf("endpoint %s: secret or secret_env is required", ep.Name)
+		}
+		for j, pattern := range ep.Events {
+			ep.Events[j] = strings.TrimSpace(pattern)
+		}
+		out = append(out, ep)
+	}
+	return out, nil
+}
@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// SchemaVersion イベント JSON の版（互換性のない変更時のみ上げる）
+const SchemaVersion = 1
+
+// イベント種別
+const (
+	EventTierUp                = "tier.up"                 // Tier 上昇（ギルド単位）
+	EventTierDown              = "tier.down"               // Tier 減少（ギルド単位）
+	EventDiffDetected          = "diff.detected"           // 0% から変化を検知（ギルド単位）
+	EventDiffCompleted         = "diff.completed"          // 0% に修復完了（ギルド単位）
+	EventWplaceOutage          = "wplace.outage"           // wplace 障害検知
+	EventWplaceRecovered       = "wplace.recovered"        // wplace 復旧
+	EventWatchTargetChanged    = "watch_target.changed"    // 追加監視の変化（ギルド単位）
+	EventProgressTargetChanged = "progress_target.changed" // 進捗監視の変化（ギルド単位）
+	EventUserVandal            = "user.vandal"             // 新規荒らしユーザー
+	EventUserFix               = "user.fix"                // 新規修復ユーザー
+	EventUserSuspectedBot      = "user.suspected_bot"      // 自動描画の疑い
+	EventUserRestricted        = "user.restricted"         // 既知の荒らしユーザーの BAN / タイムアウト
+	EventAchievementUnlocked   = "achievement.unlocked"    // 実績獲得
+)
+
+// Event 送信する JSON の外枠。Data の中身はイベント種別ごとの *Data 構造体。
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Version    int       `json:"version"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Artwork    string    `json:"artwork,omitempty"`
+	GuildID    string    `json:"guild_id,omitempty"`
+	Data       any       `json:"data"`
+}
+
+// DiffData tier.* / diff.* のペイロード
+type DiffData struct {
+	Metric       string  `json:"metric"` // "overall" / "weighted"
+	DiffPercent  float64 `json:"diff_percent"`
+	DiffPixels   int     `json:"diff_pixels"`
+	TotalPixels  int     `json:"total_pixels"`
+	Tier         int     `json:"tier"`
+	TierLabel    string  `json:"tier_label,omitempty"`
+	PreviousTier int     `json:"previous_tier"`
+}
+
+// OutageData wplace.* のペイロード
+type OutageData struct {
+	Reason          string  `json:"reason,omitempty"`
+	DowntimeSeconds float64 `json:"downtime_seconds,omitempty"`
+}
+
+// TargetData watch_target.changed / progress_target.changed のペイロード
+type TargetData struct {
+	TargetID   string  `json:"target_id"`
+	Label      string  `json:"label"`
+	Change     string  `json:"change"`  // "increase" / "decrease" / "detected" / "completed"
+	Percent    float64 `json:"percent"` // 追加監視は差分率、進捗監視は進捗率
+	DiffPixels int     `json:"diff_pixels"`
+	Tier       int     `json:"tier"`
+}
+
+// UserData user.* のペイロード
+type UserData struct {
+	UserID        string `json:"user_id"`
+	Name          string `json:"name"`
+	AllianceName  string `json:"alliance_name,omitempty"`
+	DiscordID     string `json:"discord_id,omitempty"`
+	VandalCount   int    `json:"vandal_count"`
+	RestoredCount int    `json:"restored_count"`
+	Banned        bool   `json:"banned,omitempty"`
+	TimedOut      bool   `json:"timed_out,omitempty"`
+	// user.suspected_bot のみ
+	BotEvidence []BotEvidenceData `json:"bot_evidence,omitempty"`
+}
+
+// BotEvidenceData bot の疑いの根拠1つ
+type BotEvidenceData struct {
+	Kind   string `json:"kind"` // cadence / scanline / charge_rate
+	Detail string `json:"detail"`
+}
+
+// AchievementData achievement.unlocked のペイロード
+type AchievementData struct {
+	Achievement string `json:"achievement"`
+	DiscordID   string `json:"discord_id,omitempty"`
+	DiscordName string `json:"discord_name,omitempty"`
+	WplaceID    string `json:"wplace_id,omitempty"`
+	WplaceName  string `json:"wplace_name,omitempty"`
+}
+
+// NewEvent ID と時刻を埋めたイベントを作成
+func NewEvent(eventType, artwork, guildID string, data any) Event {
+	return Event{
+		ID:         newEventID(),
+		Type:       eventType,
+		Version:    SchemaVersion,
+		OccurredAt: time.Now().UTC(),
+		Artwork:    artwork,
+		GuildID:    guildID,
+		Data:       data,
+	}
+}
+
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
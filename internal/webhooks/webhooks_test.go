@@ -0,0 +1,159 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEndpointAccepts(t *testing.T) {
+	t.Parallel()
+
+	ep := Endpoint{Events: []string{"tier.*", EventDiffCompleted}, Guilds: []string{"g1"}}
+	cases := []struct {
+		ev   Event
+		want bool
+	}{
+		{Event{Type: EventTierUp, GuildID: "g1"}, true},
+		{Event{Type: EventDiffCompleted, GuildID: "g1"}, true},
+		{Event{Type: EventDiffDetected, GuildID: "g1"}, false},
+		{Event{Type: EventTierDown, GuildID: "g2"}, false},
+		{Event{Type: "tier.up"}, true}, // ギルドに紐付かないイベントはギルド条件を無視
+	}
+	for _, tc := range cases {
+		if got := ep.Accepts(tc.ev); got != tc.want {
+			t.Errorf("Accepts(%s, guild=%q) = %v, want %v", tc.ev.Type, tc.ev.GuildID, got, tc.want)
+		}
+	}
+	if !(Endpoint{}).Accepts(Event{Type: EventUserFix}) {
+		t.Error("endpoint without filters should accept everything")
+	}
+}
+
+func TestLoadEndpoints(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if eps, err := LoadEndpoints(filepath.Join(dir, FileName)); err != nil || eps != nil {
+		t.Fatalf("missing file: eps=%v err=%v", eps, err)
+	}
+
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte(`{"endpoints":[{"url":" https://example.com/hook ","secret":"s"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	eps, err := LoadEndpoints(path)
+	if err != nil || len(eps) != 1 || eps[0].Name != "endpoint-1" || eps[0].URL != "https://example.com/hook" {
+		t.Fatalf("eps=%+v err=%v", eps, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"endpoints":[{"url":"ftp://example.com"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEndpoints(path); err == nil {
+		t.Fatal("expected invalid url error")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"endpoints":[{"url":"https://example.com/hook"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEndpoints(path); err == nil {
+		t.Fatal("expected missing secret error")
+	}
+}
+
+func TestDispatcherSignsAndRetries(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	attempts := 0
+	received := make(chan Event, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if !Verify("topsecret", r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)) {
+			t.Errorf("bad signature %q", r.Header.Get(HeaderSignature))
+		}
+		var ev Event
+		if err := json.Unmarshal(body, &ev); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		if r.Header.Get(HeaderEvent) != ev.Type || r.Header.Get(HeaderDelivery) != ev.ID {
+			t.Errorf("headers do not match body: %v", r.Header)
+		}
+		received <- ev
+	}))
+	defer srv.Close()
+
+	d := New([]Endpoint{{Name: "test", URL: srv.URL, Secret: "topsecret"}}, Options{BaseDelay: time.Millisecond})
+	defer d.Close(time.Second)
+	d.Emit(NewEvent(EventDiffCompleted, "koukyo", "g1", DiffData{Metric: "overall"}))
+
+	select {
+	case ev := <-received:
+		if ev.Type != EventDiffCompleted || ev.Version != SchemaVersion || ev.Artwork != "koukyo" || ev.GuildID != "g1" {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestDispatcherDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	d := New([]Endpoint{{Name: "test", URL: srv.URL}}, Options{BaseDelay: time.Millisecond})
+	d.Emit(NewEvent(EventUserVandal, "koukyo", "", UserData{UserID: "1"}))
+	d.Close(5 * time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestNilDispatcherIsNoop(t *testing.T) {
+	t.Parallel()
+
+	var d *Dispatcher
+	d.Emit(NewEvent(EventTierUp, "", "", nil))
+	d.Close(time.Second)
+}
+
+func TestDispatcherEmitAfterCloseIsDropped(t *testing.T) {
+	t.Parallel()
+
+	d := New([]Endpoint{{Name: "test", URL: "http://127.0.0.1:0"}}, Options{BaseDelay: time.Millisecond})
+	d.Close(time.Second)
+	d.Emit(NewEvent(EventTierUp, "koukyo", "g1", nil))
+	d.Close(time.Second)
+}
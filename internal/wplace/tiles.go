@@ -11,6 +11,7 @@ import (
 	"log"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"Koukyo_discord_bot/internal/utils"
@@ -36,25 +37,60 @@ type tileCacheEntry struct {
 }
 
 var tileCache struct {
-	mu    sync.RWMutex
-	items map[string]tileCacheEntry
+	mu     sync.RWMutex
+	items  map[string]tileCacheEntry
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// tilePathFormats バックエンドURLに続くタイルのパス形式（新しい順）
+var tilePathFormats = []string{
+	"/tile/%d/%d.png",
+	"/files/s0/tiles/%d/%d.png",
 }
 
 var (
-	tileURLFormat string
-	urlFormatMu   sync.RWMutex
+	tileURLFormat  string
+	tileURLBackend string
+	urlFormatMu    sync.RWMutex
+	detectFormatMu sync.Mutex
 )
 
 func init() {
 	tileCache.items = make(map[string]tileCacheEntry)
-	detectTileURLFormat()
 }
 
-func detectTileURLFormat() {
+// ensureTileURLFormat 現在のバックエンドに対してタイルURL形式を判定する（バックエンド変更時は再判定）
+func ensureTileURLFormat() string {
+	backend := utils.WplaceBackendURL()
+	urlFormatMu.RLock()
+	format, detectedFor := tileURLFormat, tileURLBackend
+	urlFormatMu.RUnlock()
+	if format != "" && detectedFor == backend {
+		return format
+	}
+
+	detectFormatMu.Lock()
+	defer detectFormatMu.Unlock()
+	urlFormatMu.RLock()
+	format, detectedFor = tileURLFormat, tileURLBackend
+	urlFormatMu.RUnlock()
+	if format != "" && detectedFor == backend {
+		return format
+	}
+	format = detectTileURLFormat(backend)
+	urlFormatMu.Lock()
+	tileURLFormat = format
+	tileURLBackend = backend
+	urlFormatMu.Unlock()
+	return format
+}
+
+func detectTileURLFormat(backend string) string {
 	// Test both URL formats with a known tile (0, 0)
-	formats := []string{
-		"https://backend.wplace.live/tile/%d/%d.png",
-		"https://backend.wplace.live/files/s0/tiles/%d/%d.png",
+	formats := make([]string, 0, len(tilePathFormats))
+	for _, path := range tilePathFormats {
+		formats = append(formats, backend+path)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -76,22 +112,19 @@ func detectTileURLFormat() {
 		resp.Body.Close()
 
 		if resp.StatusCode == http.StatusOK {
-			tileURLFormat = format
 			log.Printf("✅ Detected working tile URL format: %s", format)
-			return
+			return format
 		}
 		log.Printf("Tile URL format %s returned status %d", format, resp.StatusCode)
 	}
 
 	// Fallback to newer format
-	tileURLFormat = formats[0]
-	log.Printf("⚠️ No working tile URL format detected, using default: %s", tileURLFormat)
+	log.Printf("⚠️ No working tile URL format detected, using default: %s", formats[0])
+	return formats[0]
 }
 
 func GetTileURLFormat() string {
-	urlFormatMu.RLock()
-	defer urlFormatMu.RUnlock()
-	return tileURLFormat
+	return ensureTileURLFormat()
 }
 
 func DownloadTile(ctx context.Context, limiter *utils.RateLimiter, tileX, tileY int) ([]byte, error) {
@@ -104,15 +137,15 @@ func DownloadTileNoCache(ctx context.Context, limiter *utils.RateLimiter, tileX,
 
 func downloadTile(ctx context.Context, limiter *utils.RateLimiter, tileX, tileY int, useCache bool) ([]byte, error) {
 	cacheBust := time.Now().UnixNano() % 10000000
-	urlFormatMu.RLock()
-	format := tileURLFormat
-	urlFormatMu.RUnlock()
+	format := ensureTileURLFormat()
 	url := fmt.Sprintf(format+"?t=%d", tileX, tileY, cacheBust)
 	cacheKey := fmt.Sprintf("%d-%d", tileX, tileY)
 	if useCache {
 		if data, ok := getTileFromCache(cacheKey); ok {
+			tileCache.hits.Add(1)
 			return data, nil
 		}
+		tileCache.misses.Add(1)
 	}
 
 	doReq := func() (interface{}, error) {
@@ -331,6 +364,14 @@ func CombineTilesCroppedImage(
 	return out, nil
 }
 
+// TileCacheStats タイルキャッシュのヒット/ミス数（累計、キャッシュ利用時のみ）と保持件数を返す
+func TileCacheStats() (hits, misses uint64, entries int) {
+	tileCache.mu.RLock()
+	entries = len(tileCache.items)
+	tileCache.mu.RUnlock()
+	return tileCache.hits.Load(), tileCache.misses.Load(), entries
+}
+
 func getTileFromCache(key string) ([]byte, bool) {
 	now := time.Now()
 